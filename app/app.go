@@ -2,20 +2,61 @@ package app
 
 import (
 	"database/sql"
+	"sample/constans"
+	"sample/models"
 	"sample/repositories"
 	"sample/repositories/accountRepository"
+	"sample/repositories/auditRepository"
+	"sample/repositories/feeRepository"
+	"sample/repositories/fxRepository"
+	"sample/repositories/healthRepository"
+	"sample/repositories/holdRepository"
+	"sample/repositories/ledgerRepository"
+	"sample/repositories/limitRepository"
+	"sample/repositories/scheduledTransferRepository"
 	"sample/repositories/transactionRepository"
+	"sample/repositories/webhookRepository"
 	"sample/services"
+	"sample/utils"
 )
 
-func SetupApp(DB *sql.DB, repo repositories.Repository) services.UsecaseService {
+func SetupApp(DB *sql.DB, repo repositories.Repository, cfg models.Config) services.UsecaseService {
 
 	// Repository
 	accountRepo := accountRepository.NewAccountRepository(repo)
 	transactionRepo := transactionRepository.NewTransactionRepository(repo)
+	ledgerRepo := ledgerRepository.NewLedgerRepository(repo)
+	scheduledTransferRepo := scheduledTransferRepository.NewScheduledTransferRepository(repo)
+	limitRepo := limitRepository.NewLimitRepository(repo)
+	feeRepo := feeRepository.NewFeeRepository(repo)
+	holdRepo := holdRepository.NewHoldRepository(repo)
+	fxRepo := fxRepository.NewFxRepository(repo)
+	webhookRepo := webhookRepository.NewWebhookRepository(repo)
+	auditRepo := auditRepository.NewAuditRepository(repo)
+	healthRepo := healthRepository.NewHealthRepository(repo)
+
+	// Akun sistem ledger (CASH_VAULT, FEE_INCOME, FX_POSITION_*)
+	if err := ledgerRepo.EnsureSystemLedgerAccounts(); err != nil {
+		utils.LogError("SetupApp", constans.EMPTY_VALUE, "EnsureSystemLedgerAccounts", err)
+	}
+
+	// Limit transaksi default per tier akun
+	if err := limitRepo.EnsureDefaultTransactionLimits(); err != nil {
+		utils.LogError("SetupApp", constans.EMPTY_VALUE, "EnsureDefaultTransactionLimits", err)
+	}
+
+	// Skema biaya default tarik tunai & transfer
+	if err := feeRepo.EnsureDefaultFeeRules(); err != nil {
+		utils.LogError("SetupApp", constans.EMPTY_VALUE, "EnsureDefaultFeeRules", err)
+	}
+
+	// Index collection audit trail di MongoDB
+	if err := auditRepo.EnsureAuditIndexes(); err != nil {
+		utils.LogError("SetupApp", constans.EMPTY_VALUE, "EnsureAuditIndexes", err)
+	}
 
 	// Services
-	usecaseSvc := services.NewUsecaseService(DB, accountRepo, transactionRepo)
+	usecaseSvc := services.NewUsecaseService(DB, accountRepo, transactionRepo, ledgerRepo, scheduledTransferRepo, limitRepo, feeRepo, holdRepo, fxRepo, webhookRepo, auditRepo, healthRepo, cfg)
 
 	return usecaseSvc
 }
@@ -1,39 +1,389 @@
 package config
 
 import (
-	"github.com/joho/godotenv"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"os"
-)
+	"path/filepath"
+	"reflect"
+	"sample/helpers"
+	"sample/models"
+	"sample/notification"
+	"sample/tracing"
+	"strconv"
+	"strings"
 
-var (
-	DBDriver = GetEnv("DB_DRIVER", "postgres")
-	DBName  = GetEnv("DB_NAME", "local")
-	DBHost  = GetEnv("DB_HOST", "localhost")
-	DBPort  = GetEnv("DB_PORT", "5432")
-	DBUser  = GetEnv("DB_USER", "root")
-	DBPass  = GetEnv("DB_PASS", "")
-	SSLMode  = GetEnv("SSL_MODE", "disable")
-
-	REDISHost = GetEnv("REDIS_HOST")
-	REDISPass = GetEnv("REDIS_PASS")
-	REDISPort = GetEnv("REDIS_PORT")
-
-	MONGOHost = GetEnv("MONGO_HOST")
-	MONGOPort = GetEnv("MONGO_PORT")
-	MONGODB = GetEnv("MONGO_DB")
+	"github.com/joho/godotenv"
 )
 
-func GetEnv(key string, value... string) string {
-	if err := godotenv.Load(".env"); err != nil {
-		panic("Error Load file .env not found")
+// AppEnvs nilai APP_ENV yang punya file environment/environment-{APP_ENV}.json
+var AppEnvs = []string{"dev", "stg", "prod"}
+
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// Default nilai awal konfigurasi sebelum ditimpa file JSON, .env dan environment variable
+func Default() models.Config {
+	return models.Config{
+		EnvironmentConfig: models.EnvironmentConfig{
+			AppEnv:  "dev",
+			AppName: "Sample",
+		},
+		AppPort:    "8080",
+		DriverName: "postgres",
+		Database: models.Database{
+			DBName:  "local",
+			DBHost:  "localhost",
+			DBPort:  "5432",
+			DBUser:  "root",
+			SSLMode: "disable",
+		},
+		Redis: models.Redis{
+			Host: "localhost",
+			Port: "6379",
+		},
+		Mongo: models.Mongo{
+			Host:   "localhost",
+			Port:   "27017",
+			DBName: "sample",
+		},
+		JWT: models.JWT{
+			AccessTTLMinutes: 15,
+			RefreshTTLHours:  168,
+		},
+		TransferInquiryTTLSeconds: 300,
+		HoldExpiryIntervalSeconds: 60,
+		HealthCheckTimeoutSeconds: 2,
+		ShutdownTimeoutSeconds:    30,
+		Scheduler: models.Scheduler{
+			IntervalSeconds:      60,
+			RetryIntervalMinutes: 60,
+			MaxRetry:             3,
+			BatchSize:            20,
+		},
+		Webhook: models.Webhook{
+			TimeoutSeconds:   10,
+			BatchSize:        50,
+			IntervalSeconds:  5,
+			RetryBaseSeconds: 30,
+			MaxAttempts:      8,
+		},
+		Tracing: models.Tracing{
+			Exporter:      "none",
+			OTLPEndpoint:  "localhost:4318",
+			SamplePercent: 100,
+		},
+		RateLimit: models.RateLimits{
+			PublicLimit:              10,
+			PublicWindowSeconds:      60,
+			PrivateLimit:             120,
+			PrivateWindowSeconds:     60,
+			TransactionLimit:         30,
+			TransactionWindowSeconds: 60,
+			AdminLimit:               300,
+			AdminWindowSeconds:       60,
+			PINResetLimit:            5,
+			PINResetWindowSeconds:    900,
+			LoginLimit:               10,
+			LoginWindowSeconds:       900,
+		},
+		PINLockout: models.PINLockout{
+			MaxAttempts:          3,
+			LockoutMinutes:       15,
+			LockoutMultiplier:    2,
+			MaxTemporaryLockouts: 3,
+		},
+		PINReset: models.PINReset{
+			CodeTTLMinutes:  5,
+			MaxAttempts:     5,
+			MaxCodes:        3,
+			CooldownMinutes: 60,
+		},
+		Notification: models.Notification{
+			Channel:        "console",
+			SMTPPort:       "587",
+			TimeoutSeconds: 10,
+		},
+		StepUp: models.StepUp{
+			ThresholdAmount: 10000000,
+			CodeTTLSeconds:  300,
+		},
+	}
+}
+
+// Load memuat konfigurasi sekali saat start dengan urutan prioritas (paling akhir menang):
+// Default, environment/environment.json, environment/environment-{APP_ENV}.json, file .env,
+// lalu environment variable proses. Folder environment bisa diganti lewat CONFIG_DIR
+func Load() (models.Config, error) {
+	cfg := Default()
+
+	dotenv, err := godotenv.Read(".env")
+	if err != nil && !os.IsNotExist(err) {
+		return cfg, fmt.Errorf("read .env: %v", err)
+	}
+	lookup := func(key string) string {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+		return dotenv[key]
+	}
+
+	dir := lookup("CONFIG_DIR")
+	if dir == "" {
+		dir = "environment"
+	}
+
+	if err := loadJSON(filepath.Join(dir, "environment.json"), &cfg); err != nil {
+		return cfg, err
+	}
+
+	appEnv := lookup("APP_ENV")
+	if appEnv == "" {
+		appEnv = cfg.AppEnv
+	}
+	appEnv = strings.ToLower(appEnv)
+	if !contains(AppEnvs, appEnv) {
+		return cfg, fmt.Errorf("invalid config: APP_ENV must be one of %s, got %q", strings.Join(AppEnvs, ", "), appEnv)
 	}
 
-	if os.Getenv(key) != "" {
-		return os.Getenv(key)
-	} else {
-		if len(value) > 0 {
-			return value[0]
+	if err := loadJSON(filepath.Join(dir, "environment-"+appEnv+".json"), &cfg); err != nil {
+		return cfg, err
+	}
+
+	var problems []string
+	problems = append(problems, applyEnv(reflect.ValueOf(&cfg).Elem(), func(key string) string { return dotenv[key] })...)
+	problems = append(problems, applyEnv(reflect.ValueOf(&cfg).Elem(), os.Getenv)...)
+	cfg.AppEnv = strings.ToLower(cfg.AppEnv)
+
+	problems = append(problems, Validate(cfg)...)
+	if len(problems) > 0 {
+		return cfg, errors.New("invalid config: " + strings.Join(problems, "; "))
+	}
+
+	return cfg, nil
+}
+
+// Validate daftar masalah konfigurasi, kosong jika valid
+func Validate(cfg models.Config) []string {
+	var problems []string
+
+	if !contains(AppEnvs, cfg.AppEnv) {
+		problems = append(problems, fmt.Sprintf("APP_ENV must be one of %s, got %q", strings.Join(AppEnvs, ", "), cfg.AppEnv))
+	}
+
+	required := []struct {
+		key   string
+		value string
+	}{
+		{"DB_DRIVER", cfg.DriverName},
+		{"DB_NAME", cfg.Database.DBName},
+		{"DB_HOST", cfg.Database.DBHost},
+		{"DB_USER", cfg.Database.DBUser},
+		{"REDIS_HOST", cfg.Redis.Host},
+		{"MONGO_HOST", cfg.Mongo.Host},
+		{"MONGO_DB", cfg.Mongo.DBName},
+		{"JWT_KEY", cfg.JWT.Key},
+	}
+	for _, r := range required {
+		if strings.TrimSpace(r.value) == "" {
+			problems = append(problems, r.key+" is required")
+		}
+	}
+
+	ports := []struct {
+		key   string
+		value string
+	}{
+		{"APP_PORT", cfg.AppPort},
+		{"DB_PORT", cfg.Database.DBPort},
+		{"REDIS_PORT", cfg.Redis.Port},
+		{"MONGO_PORT", cfg.Mongo.Port},
+	}
+	for _, p := range ports {
+		if port, err := strconv.Atoi(p.value); err != nil || port <= 0 || port > 65535 {
+			problems = append(problems, fmt.Sprintf("%s must be a port number between 1 and 65535, got %q", p.key, p.value))
+		}
+	}
+
+	if !contains(sslModes, cfg.Database.SSLMode) {
+		problems = append(problems, fmt.Sprintf("SSL_MODE must be one of %s, got %q", strings.Join(sslModes, ", "), cfg.Database.SSLMode))
+	}
+
+	positives := []struct {
+		key   string
+		value int
+	}{
+		{"JWT_ACCESS_TTL_MINUTES", cfg.JWT.AccessTTLMinutes},
+		{"JWT_REFRESH_TTL_HOURS", cfg.JWT.RefreshTTLHours},
+		{"TRANSFER_INQUIRY_TTL_SECONDS", cfg.TransferInquiryTTLSeconds},
+		{"HOLD_EXPIRY_INTERVAL_SECONDS", cfg.HoldExpiryIntervalSeconds},
+		{"HEALTH_CHECK_TIMEOUT_SECONDS", cfg.HealthCheckTimeoutSeconds},
+		{"SHUTDOWN_TIMEOUT_SECONDS", cfg.ShutdownTimeoutSeconds},
+		{"SCHEDULER_INTERVAL_SECONDS", cfg.Scheduler.IntervalSeconds},
+		{"SCHEDULER_RETRY_INTERVAL_MINUTES", cfg.Scheduler.RetryIntervalMinutes},
+		{"SCHEDULER_MAX_RETRY", cfg.Scheduler.MaxRetry},
+		{"SCHEDULER_BATCH_SIZE", cfg.Scheduler.BatchSize},
+		{"WEBHOOK_TIMEOUT_SECONDS", cfg.Webhook.TimeoutSeconds},
+		{"WEBHOOK_BATCH_SIZE", cfg.Webhook.BatchSize},
+		{"WEBHOOK_INTERVAL_SECONDS", cfg.Webhook.IntervalSeconds},
+		{"WEBHOOK_RETRY_BASE_SECONDS", cfg.Webhook.RetryBaseSeconds},
+		{"WEBHOOK_MAX_ATTEMPTS", cfg.Webhook.MaxAttempts},
+		{"RATE_LIMIT_PUBLIC", cfg.RateLimit.PublicLimit},
+		{"RATE_LIMIT_PUBLIC_WINDOW_SECONDS", cfg.RateLimit.PublicWindowSeconds},
+		{"RATE_LIMIT_PRIVATE", cfg.RateLimit.PrivateLimit},
+		{"RATE_LIMIT_PRIVATE_WINDOW_SECONDS", cfg.RateLimit.PrivateWindowSeconds},
+		{"RATE_LIMIT_TRANSACTION", cfg.RateLimit.TransactionLimit},
+		{"RATE_LIMIT_TRANSACTION_WINDOW_SECONDS", cfg.RateLimit.TransactionWindowSeconds},
+		{"RATE_LIMIT_ADMIN", cfg.RateLimit.AdminLimit},
+		{"RATE_LIMIT_ADMIN_WINDOW_SECONDS", cfg.RateLimit.AdminWindowSeconds},
+		{"RATE_LIMIT_PIN_RESET", cfg.RateLimit.PINResetLimit},
+		{"RATE_LIMIT_PIN_RESET_WINDOW_SECONDS", cfg.RateLimit.PINResetWindowSeconds},
+		{"RATE_LIMIT_LOGIN", cfg.RateLimit.LoginLimit},
+		{"RATE_LIMIT_LOGIN_WINDOW_SECONDS", cfg.RateLimit.LoginWindowSeconds},
+		{"PIN_MAX_ATTEMPTS", cfg.PINLockout.MaxAttempts},
+		{"PIN_LOCKOUT_MINUTES", cfg.PINLockout.LockoutMinutes},
+		{"PIN_LOCKOUT_MULTIPLIER", cfg.PINLockout.LockoutMultiplier},
+		{"PIN_RESET_CODE_TTL_MINUTES", cfg.PINReset.CodeTTLMinutes},
+		{"PIN_RESET_MAX_ATTEMPTS", cfg.PINReset.MaxAttempts},
+		{"PIN_RESET_MAX_CODES", cfg.PINReset.MaxCodes},
+		{"PIN_RESET_COOLDOWN_MINUTES", cfg.PINReset.CooldownMinutes},
+		{"NOTIFICATION_TIMEOUT_SECONDS", cfg.Notification.TimeoutSeconds},
+		{"STEP_UP_CODE_TTL_SECONDS", cfg.StepUp.CodeTTLSeconds},
+	}
+	for _, p := range positives {
+		if p.value <= 0 {
+			problems = append(problems, fmt.Sprintf("%s must be greater than 0, got %d", p.key, p.value))
+		}
+	}
+
+	if _, err := helpers.ParseTrustedProxies(cfg.TrustedProxies); err != nil {
+		problems = append(problems, fmt.Sprintf("TRUSTED_PROXIES must be a comma separated list of IP or CIDR: %v", err))
+	}
+
+	if !contains(tracing.Exporters, cfg.Tracing.Exporter) {
+		problems = append(problems, fmt.Sprintf("TRACING_EXPORTER must be one of %s, got %q", strings.Join(tracing.Exporters, ", "), cfg.Tracing.Exporter))
+	}
+	if cfg.Tracing.Exporter == tracing.ExporterOTLP && strings.TrimSpace(cfg.Tracing.OTLPEndpoint) == "" {
+		problems = append(problems, "TRACING_OTLP_ENDPOINT is required when TRACING_EXPORTER is otlp")
+	}
+	if cfg.Tracing.SamplePercent < 0 || cfg.Tracing.SamplePercent > 100 {
+		problems = append(problems, fmt.Sprintf("TRACING_SAMPLE_PERCENT must be between 0 and 100, got %d", cfg.Tracing.SamplePercent))
+	}
+
+	if cfg.PINLockout.MaxTemporaryLockouts < 0 {
+		problems = append(problems, fmt.Sprintf("PIN_MAX_TEMPORARY_LOCKOUTS must not be negative, got %d", cfg.PINLockout.MaxTemporaryLockouts))
+	}
+
+	if cfg.StepUp.ThresholdAmount < 0 {
+		problems = append(problems, fmt.Sprintf("STEP_UP_THRESHOLD_AMOUNT must not be negative, got %d", cfg.StepUp.ThresholdAmount))
+	}
+
+	problems = append(problems, validateNotification(cfg)...)
+
+	return problems
+}
+
+// validateNotification channel notifikasi dan kelengkapan setting-nya. Channel console
+// menulis kode reset PIN ke log sehingga ditolak di prod
+func validateNotification(cfg models.Config) []string {
+	var problems []string
+
+	switch cfg.Notification.Channel {
+	case notification.ChannelConsole:
+		if cfg.AppEnv == "prod" {
+			problems = append(problems, "NOTIFICATION_CHANNEL console is not allowed when APP_ENV is prod")
+		}
+	case notification.ChannelSMS:
+		if strings.TrimSpace(cfg.Notification.SMSURL) == "" {
+			problems = append(problems, "NOTIFICATION_SMS_URL is required when NOTIFICATION_CHANNEL is sms")
+		}
+	case notification.ChannelEmail:
+		if strings.TrimSpace(cfg.Notification.SMTPHost) == "" {
+			problems = append(problems, "NOTIFICATION_SMTP_HOST is required when NOTIFICATION_CHANNEL is email")
+		}
+		if port, err := strconv.Atoi(cfg.Notification.SMTPPort); err != nil || port <= 0 || port > 65535 {
+			problems = append(problems, fmt.Sprintf("NOTIFICATION_SMTP_PORT must be a port number between 1 and 65535, got %q", cfg.Notification.SMTPPort))
+		}
+		if strings.TrimSpace(cfg.Notification.EmailFrom) == "" {
+			problems = append(problems, "NOTIFICATION_EMAIL_FROM is required when NOTIFICATION_CHANNEL is email")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("NOTIFICATION_CHANNEL must be one of %s, got %q", strings.Join(notification.Channels, ", "), cfg.Notification.Channel))
+	}
+
+	return problems
+}
+
+// loadJSON menimpa cfg dengan isi file JSON. File yang tidak ada dilewati,
+// field yang tidak dikenal dianggap salah ketik dan ditolak
+func loadJSON(path string, cfg *models.Config) error {
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read %s: %v", path, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cfg); err != nil {
+		return fmt.Errorf("parse %s: %v", path, err)
+	}
+
+	return nil
+}
+
+// applyEnv menimpa field bertag env yang nilainya tidak kosong, termasuk field struct di dalamnya
+func applyEnv(v reflect.Value, lookup func(string) string) []string {
+	var problems []string
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			problems = append(problems, applyEnv(field, lookup)...)
+			continue
+		}
+
+		key := v.Type().Field(i).Tag.Get("env")
+		if key == "" {
+			continue
+		}
+		value := lookup(key)
+		if value == "" {
+			continue
+		}
+
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(value)
+		case reflect.Int:
+			number, err := strconv.Atoi(value)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s must be an integer, got %q", key, value))
+				continue
+			}
+			field.SetInt(int64(number))
+		case reflect.Bool:
+			flag, err := strconv.ParseBool(value)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s must be true or false, got %q", key, value))
+				continue
+			}
+			field.SetBool(flag)
+		}
+	}
+
+	return problems
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
 		}
-		return ""
 	}
-}
\ No newline at end of file
+	return false
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sample/constans"
+	"sample/models"
+	"strings"
+	"testing"
+)
+
+func setEnv(t *testing.T, values map[string]string) {
+	t.Helper()
+	for key, value := range values {
+		previous, ok := os.LookupEnv(key)
+		os.Setenv(key, value)
+		t.Cleanup(func() {
+			if ok {
+				os.Setenv(key, previous)
+			} else {
+				os.Unsetenv(key)
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "environment.json"), `{"app_name": "Sample", "app_env": "DEV"}`)
+	writeFile(t, filepath.Join(dir, "environment-stg.json"), `{"database": {"db_name": "staging", "db_user": "json-user"}, "webhook": {"max_attempts": 4}}`)
+
+	setEnv(t, map[string]string{
+		"CONFIG_DIR": dir,
+		"APP_ENV":    "STG",
+		"DB_USER":    "env-user",
+		"JWT_KEY":    "secret",
+
+		"TRACING_EXPORTER":      "otlp",
+		"TRACING_OTLP_INSECURE": "true",
+	})
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if cfg.AppEnv != "stg" || cfg.AppName != "Sample" {
+		t.Errorf("app = %s/%s, want stg/Sample", cfg.AppEnv, cfg.AppName)
+	}
+	if cfg.Database.DBName != "staging" || cfg.Database.DBUser != "env-user" || cfg.Database.DBHost != "localhost" {
+		t.Errorf("database = %+v, want db_name from JSON, user from env, host default", cfg.Database)
+	}
+	if cfg.Webhook.MaxAttempts != 4 || cfg.Webhook.BatchSize != 50 {
+		t.Errorf("webhook = %+v, want max_attempts 4 and default batch size", cfg.Webhook)
+	}
+	if cfg.Tracing.Exporter != "otlp" || !cfg.Tracing.OTLPInsecure || cfg.Tracing.OTLPEndpoint != "localhost:4318" {
+		t.Errorf("tracing = %+v, want otlp, insecure from env and default endpoint", cfg.Tracing)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	dir := t.TempDir()
+	setEnv(t, map[string]string{
+		"CONFIG_DIR":                 dir,
+		"APP_ENV":                    "dev",
+		"JWT_KEY":                    "",
+		"DB_PORT":                    "postgres",
+		"SCHEDULER_MAX_RETRY":        "three",
+		"WEBHOOK_BATCH_SIZE":         "-1",
+		"APP_PORT":                   "8080",
+		"SSL_MODE":                   "disable",
+		"TRACING_EXPORTER":           "jaeger",
+		"TRACING_OTLP_INSECURE":      "yes",
+		"PIN_LOCKOUT_MINUTES":        "0",
+		"PIN_MAX_TEMPORARY_LOCKOUTS": "-1",
+		"NOTIFICATION_CHANNEL":       "sms",
+		"STEP_UP_THRESHOLD_AMOUNT":   "-1",
+	})
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("Load should fail")
+	}
+
+	for _, want := range []string{
+		"JWT_KEY is required",
+		`DB_PORT must be a port number`,
+		`SCHEDULER_MAX_RETRY must be an integer, got "three"`,
+		"WEBHOOK_BATCH_SIZE must be greater than 0, got -1",
+		`TRACING_EXPORTER must be one of none, stdout, otlp, got "jaeger"`,
+		`TRACING_OTLP_INSECURE must be true or false, got "yes"`,
+		"PIN_LOCKOUT_MINUTES must be greater than 0, got 0",
+		"PIN_MAX_TEMPORARY_LOCKOUTS must not be negative, got -1",
+		"NOTIFICATION_SMS_URL is required when NOTIFICATION_CHANNEL is sms",
+		"STEP_UP_THRESHOLD_AMOUNT must not be negative, got -1",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+
+	writeFile(t, filepath.Join(dir, "environment-dev.json"), `{"databse": {}}`)
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "databse") {
+		t.Errorf("unknown JSON field error = %v", err)
+	}
+
+	setEnv(t, map[string]string{"APP_ENV": "qa"})
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "APP_ENV must be one of dev, stg, prod") {
+		t.Errorf("APP_ENV error = %v", err)
+	}
+}
+
+func TestValidateNotification(t *testing.T) {
+	cfg := Default()
+	cfg.JWT.Key = "secret"
+	cfg.AppEnv = "prod"
+	if problems := strings.Join(Validate(cfg), "; "); !strings.Contains(problems, "NOTIFICATION_CHANNEL console is not allowed when APP_ENV is prod") {
+		t.Errorf("console in prod problems = %q", problems)
+	}
+
+	cfg.Notification.Channel = "email"
+	cfg.Notification.SMTPHost = "smtp.example.com"
+	if problems := strings.Join(Validate(cfg), "; "); problems != "NOTIFICATION_EMAIL_FROM is required when NOTIFICATION_CHANNEL is email" {
+		t.Errorf("email problems = %q", problems)
+	}
+
+	cfg.Notification.EmailFrom = "noreply@example.com"
+	if problems := Validate(cfg); len(problems) != 0 {
+		t.Errorf("valid email config problems = %v", problems)
+	}
+}
+
+func TestValidateTrustedProxies(t *testing.T) {
+	cfg := Default()
+	cfg.JWT.Key = "secret"
+	cfg.TrustedProxies = "10.0.0.0/8, 192.168.1.10, ::1"
+	if problems := Validate(cfg); len(problems) != 0 {
+		t.Errorf("valid proxies problems = %v", problems)
+	}
+
+	cfg.TrustedProxies = "10.0.0.0/8, proxy.internal"
+	if problems := strings.Join(Validate(cfg), "; "); !strings.Contains(problems, `TRUSTED_PROXIES must be a comma separated list of IP or CIDR: invalid trusted proxy "proxy.internal"`) {
+		t.Errorf("invalid proxies problems = %q", problems)
+	}
+}
+
+func TestStepUpRequired(t *testing.T) {
+	stepUp := Default().StepUp
+	stepUp.ThresholdAmount = 1000000
+
+	for amount, want := range map[string]bool{"999999.99": false, "1000000": false, "1000000.01": true} {
+		if got := stepUp.Required(models.MustParseMoney(amount, constans.DEFAULT_CURRENCY)); got != want {
+			t.Errorf("Required(%s) = %v, want %v", amount, got, want)
+		}
+	}
+
+	stepUp.ThresholdAmount = 0
+	if stepUp.Required(models.MustParseMoney("1000000000", constans.DEFAULT_CURRENCY)) {
+		t.Error("threshold 0 should disable step-up")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"sample/models"
 
 	_ "github.com/lib/pq"
 )
@@ -12,21 +13,21 @@ import (
 var db *sql.DB
 
 // Open Connection
-func OpenConnection() error {
+func OpenConnection(cfg models.Config) error {
 	var err error
-	db, err = setupConnection()
+	db, err = setupConnection(cfg)
 	log.Printf("Error OpenConnection: %v\n", err)
 	log.Printf("DB open: %v\n", db)
 	return err
 }
 
 // setupConnection adalah
-func setupConnection() (*sql.DB, error) {
+func setupConnection(cfg models.Config) (*sql.DB, error) {
 	var connection = fmt.Sprintf("user=%s password=%s dbname=%s host=%s port=%s sslmode=%s",
-		DBUser, DBPass, DBName, DBHost, DBPort, SSLMode)
-	fmt.Println("Connection Info:", DBDriver, connection)
+		cfg.Database.DBUser, cfg.Database.DBPass, cfg.Database.DBName, cfg.Database.DBHost, cfg.Database.DBPort, cfg.Database.SSLMode)
+	fmt.Println("Connection Info:", cfg.DriverName, connection)
 
-	db, err := sql.Open(DBDriver, connection)
+	db, err := sql.Open(cfg.DriverName, connection)
 	log.Printf("Error setupConnection: %v\n", err)
 	log.Printf("db setup: %v\n", db)
 	if err != nil {
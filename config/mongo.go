@@ -3,29 +3,32 @@ package config
 import (
 	"fmt"
 	"context"
+	"sample/models"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func ConnectMongo(ctx context.Context, DBCollection... string) *mongo.Database  {
-	connection := fmt.Sprintf("mongodb://%s:%s", MONGOHost, MONGOPort)
+// ConnectMongo membuat client MongoDB. Driver terhubung secara lazy, error di sini berarti
+// konfigurasi salah; status koneksi sebenarnya dicek lewat /health/ready
+func ConnectMongo(ctx context.Context, cfg models.Mongo, DBCollection... string) (*mongo.Database, error)  {
+	connection := fmt.Sprintf("mongodb://%s:%s", cfg.Host, cfg.Port)
 	fmt.Println("Connection Mongo:", connection)
 	clientOptions := options.Client()
 	clientOptions.ApplyURI(connection)
 	client, err := mongo.NewClient(clientOptions)
 	if err != nil {
-		return nil
+		return nil, err
 	}
 
 	err = client.Connect(ctx)
 	if err != nil {
-		return nil
+		return nil, err
 	}
 
-	col := MONGODB
+	col := cfg.DBName
 	if len(DBCollection) > 0 {
 		col = DBCollection[0]
 	}
 
-	return client.Database(col)
+	return client.Database(col), nil
 }
\ No newline at end of file
@@ -1,24 +1,34 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"math/rand"
+	"sample/models"
+	"sample/tracing"
 	"time"
 
 	"github.com/gomodule/redigo/redis"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
 )
 
-var redisPool *redis.Pool
+var (
+	redisPool   *redis.Pool
+	redisConfig models.Redis
+)
 
-// InitRedisPool menginisialisasi connection pool untuk Redis
-func InitRedisPool() error {
+// InitRedisPool menginisialisasi connection pool untuk Redis, cfg disimpan
+// agar GetRedisConn bisa inisialisasi ulang jika pool belum ada
+func InitRedisPool(cfg models.Redis) error {
+	redisConfig = cfg
 	redisPool = &redis.Pool{
 		MaxIdle:     10,
 		MaxActive:   100,
 		IdleTimeout: 240 * time.Second,
 		Wait:        true,
 		Dial: func() (redis.Conn, error) {
-			connStr := fmt.Sprintf("%s:%s", REDISHost, REDISPort)
+			connStr := fmt.Sprintf("%s:%s", cfg.Host, cfg.Port)
 
 			log.Printf("Connecting to Redis at %s", connStr)
 
@@ -33,8 +43,8 @@ func InitRedisPool() error {
 				return nil, fmt.Errorf("failed to connect to redis: %w", err)
 			}
 
-			if REDISPass != "" {
-				if _, err := conn.Do("AUTH", REDISPass); err != nil {
+			if cfg.Pass != "" {
+				if _, err := conn.Do("AUTH", cfg.Pass); err != nil {
 					conn.Close()
 					log.Printf("Failed to authenticate to Redis: %v", err)
 					return nil, fmt.Errorf("failed to authenticate: %w", err)
@@ -69,7 +79,7 @@ func InitRedisPool() error {
 func GetRedisConn() redis.Conn {
 	if redisPool == nil {
 		log.Println("Warning: Redis pool is nil, initializing...")
-		if err := InitRedisPool(); err != nil {
+		if err := InitRedisPool(redisConfig); err != nil {
 			log.Printf("Failed to initialize Redis pool: %v", err)
 			return nil
 		}
@@ -84,87 +94,529 @@ func CloseRedisPool() {
 	}
 }
 
-func PingRedis() error {
-	conn := GetRedisConn()
-	if conn == nil {
-		return fmt.Errorf("failed to get redis connection")
+// RedisPoolStats statistik pool Redis untuk metrics, kosong jika pool belum diinisialisasi
+func RedisPoolStats() redis.PoolStats {
+	if redisPool == nil {
+		return redis.PoolStats{}
+	}
+	return redisPool.Stats()
+}
+
+// PingRedis cek koneksi Redis, dibatasi deadline ctx. Pool tidak diinisialisasi ulang di sini
+func PingRedis(ctx context.Context) error {
+	if redisPool == nil {
+		return fmt.Errorf("redis pool is not initialized")
+	}
+
+	conn, err := redisPool.GetContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get redis connection: %w", err)
 	}
 	defer conn.Close()
 
-	_, err := conn.Do("PING")
+	timeout := 5 * time.Second
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+
+	_, err = redis.DoWithTimeout(conn, timeout, "PING")
 	return err
 }
 
-// SetResetToken menyimpan reset token dan account number dengan expiry time
-func SetResetToken(token string, accountNumber string, expiryTime time.Time) error {
+// redisDo menjalankan satu perintah Redis sebagai child span dari ctx. Key tidak dicatat
+// karena bisa berisi token
+func redisDo(ctx context.Context, conn redis.Conn, command string, args ...interface{}) (interface{}, error) {
+	_, span := tracing.Start(ctx, "redis "+command, semconv.DBSystemRedis, semconv.DBOperationKey.String(command))
+	reply, err := conn.Do(command, args...)
+	tracing.End(span, err)
+	return reply, err
+}
+
+// SetResetToken menyimpan hash kode reset PIN untuk satu akun dengan expiry time.
+// Key per akun sehingga kode baru menggantikan kode lama. Batas penerbitan dan percobaan
+// dihitung terpisah per akun (ReserveResetCode / HitResetToken) dan tidak ikut di-reset
+func SetResetToken(ctx context.Context, accountNumber string, hashedCode string, expiryTime time.Time) error {
 	conn := GetRedisConn()
 	if conn == nil {
 		return fmt.Errorf("failed to get redis connection")
 	}
 	defer conn.Close()
 
-	// Hitung selisih detik dari sekarang sampai expiry time
-	expirySeconds := int(time.Until(expiryTime).Seconds())
+	// Hitung selisih milidetik dari sekarang sampai expiry time
+	expiryMillis := time.Until(expiryTime).Milliseconds()
 
 	// Validasi expiry tidak negatif atau terlalu kecil
-	if expirySeconds <= 0 {
+	if expiryMillis <= 0 {
 		return fmt.Errorf("expiry time must be in the future")
 	}
 
-	tokenKey := fmt.Sprintf("reset_token:%s", token)
+	tokenKey := fmt.Sprintf("reset_token:%s", accountNumber)
 
-	_, err := conn.Do("SETEX", tokenKey, expirySeconds, accountNumber)
-	if err != nil {
+	conn.Send("MULTI")
+	conn.Send("DEL", tokenKey)
+	conn.Send("HSET", tokenKey, "hash", hashedCode)
+	conn.Send("PEXPIRE", tokenKey, expiryMillis)
+	if _, err := redisDo(ctx, conn, "EXEC"); err != nil {
 		return fmt.Errorf("failed to store token: %w", err)
 	}
 
-	// log.Printf("Token stored with expiry in %d seconds", expirySeconds)
 	return nil
 }
 
-// SetResetTokenWithDuration menyimpan reset token dengan durasi
-func SetResetTokenWithDuration(token string, accountNumber string, duration time.Duration) error {
+// SetResetTokenWithDuration menyimpan hash kode reset PIN dengan durasi
+func SetResetTokenWithDuration(ctx context.Context, accountNumber string, hashedCode string, duration time.Duration) error {
 	expiryTime := time.Now().Add(duration)
-	return SetResetToken(token, accountNumber, expiryTime)
+	return SetResetToken(ctx, accountNumber, hashedCode, expiryTime)
 }
 
-// SetResetTokenWithSeconds menyimpan reset token dengan detik
-func SetResetTokenWithSeconds(token string, accountNumber string, seconds int) error {
+// SetResetTokenWithSeconds menyimpan hash kode reset PIN dengan detik
+func SetResetTokenWithSeconds(ctx context.Context, accountNumber string, hashedCode string, seconds int) error {
 	expiryTime := time.Now().Add(time.Duration(seconds) * time.Second)
-	return SetResetToken(token, accountNumber, expiryTime)
+	return SetResetToken(ctx, accountNumber, hashedCode, expiryTime)
+}
+
+// hitCodeScript menambah hitungan percobaan kode (OTP step-up) lalu mengembalikan
+// {attempts, field ARGV...}, nil jika kode sudah kedaluwarsa / tidak ada. Atomic agar
+// percobaan paralel tetap terhitung
+var hitCodeScript = redis.NewScript(1, `
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return false
+end
+local attempts = redis.call('HINCRBY', KEYS[1], 'attempts', 1)
+local values = redis.call('HMGET', KEYS[1], unpack(ARGV))
+table.insert(values, 1, attempts)
+return values
+`)
+
+// hitCode menjalankan hitCodeScript untuk key dengan field yang diminta
+func hitCode(ctx context.Context, conn redis.Conn, key string, fields ...interface{}) ([]interface{}, error) {
+	_, span := tracing.Start(ctx, "redis EVALSHA", semconv.DBSystemRedis, semconv.DBOperationKey.String("EVALSHA"))
+	values, err := redis.Values(hitCodeScript.Do(conn, append([]interface{}{key}, fields...)...))
+	tracing.End(span, err)
+	return values, err
+}
+
+// reserveResetScript jatah kode reset PIN per akun dalam satu jendela cooldown (KEYS[1] reset_guard).
+// Ditolak jika kode yang diterbitkan sudah ARGV[1] atau percobaan salah sudah ARGV[2].
+// Jendela dimulai saat kode / percobaan pertama dan tidak diperpanjang. Return {allowed, pttl}
+var reserveResetScript = redis.NewScript(1, `
+local issued = tonumber(redis.call('HGET', KEYS[1], 'issued') or '0')
+local attempts = tonumber(redis.call('HGET', KEYS[1], 'attempts') or '0')
+if issued >= tonumber(ARGV[1]) or attempts >= tonumber(ARGV[2]) then
+	return {0, redis.call('PTTL', KEYS[1])}
+end
+redis.call('HINCRBY', KEYS[1], 'issued', 1)
+if redis.call('PTTL', KEYS[1]) < 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+end
+return {1, redis.call('PTTL', KEYS[1])}
+`)
+
+// hitResetScript mencatat percobaan verifikasi kode reset PIN di counter per akun (KEYS[2] reset_guard),
+// bukan per kode, sehingga meminta kode baru tidak mengembalikan jatah percobaan.
+// Return {attempts, hash}, nil jika kode (KEYS[1]) sudah kedaluwarsa / tidak ada
+var hitResetScript = redis.NewScript(2, `
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return false
+end
+local attempts = redis.call('HINCRBY', KEYS[2], 'attempts', 1)
+if redis.call('PTTL', KEYS[2]) < 0 then
+	redis.call('PEXPIRE', KEYS[2], ARGV[1])
+end
+return {attempts, redis.call('HGET', KEYS[1], 'hash')}
+`)
+
+// ReserveResetCode ambil satu jatah penerbitan kode reset PIN untuk akun. False beserta sisa
+// cooldown jika sudah maxCodes kode diterbitkan atau maxAttempts percobaan salah dalam jendela
+func ReserveResetCode(ctx context.Context, accountNumber string, maxCodes, maxAttempts int, window time.Duration) (bool, time.Duration, error) {
+	conn := GetRedisConn()
+	if conn == nil {
+		return false, 0, fmt.Errorf("failed to get redis connection")
+	}
+	defer conn.Close()
+
+	guardKey := fmt.Sprintf("reset_guard:%s", accountNumber)
+
+	_, span := tracing.Start(ctx, "redis EVALSHA", semconv.DBSystemRedis, semconv.DBOperationKey.String("EVALSHA"))
+	values, err := redis.Values(reserveResetScript.Do(conn, guardKey, maxCodes, maxAttempts, window.Milliseconds()))
+	tracing.End(span, err)
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to reserve reset code: %w", err)
+	}
+
+	var allowed, pttl int64
+	if _, err := redis.Scan(values, &allowed, &pttl); err != nil {
+		return false, 0, fmt.Errorf("failed to reserve reset code: %w", err)
+	}
+
+	return allowed == 1, time.Duration(pttl) * time.Millisecond, nil
+}
+
+// HitResetToken mencatat satu percobaan verifikasi kode reset PIN, return hash kode dan jumlah
+// percobaan akun dalam jendela cooldown termasuk yang ini (lintas kode yang diterbitkan)
+func HitResetToken(ctx context.Context, accountNumber string, window time.Duration) (string, int, error) {
+	conn := GetRedisConn()
+	if conn == nil {
+		return "", 0, fmt.Errorf("failed to get redis connection")
+	}
+	defer conn.Close()
+
+	tokenKey := fmt.Sprintf("reset_token:%s", accountNumber)
+	guardKey := fmt.Sprintf("reset_guard:%s", accountNumber)
+
+	_, span := tracing.Start(ctx, "redis EVALSHA", semconv.DBSystemRedis, semconv.DBOperationKey.String("EVALSHA"))
+	values, err := redis.Values(hitResetScript.Do(conn, tokenKey, guardKey, window.Milliseconds()))
+	tracing.End(span, err)
+	if err != nil {
+		if err == redis.ErrNil {
+			return "", 0, fmt.Errorf("token expired or not found")
+		}
+		return "", 0, fmt.Errorf("failed to get token: %w", err)
+	}
+
+	var (
+		hashedCode string
+		attempts   int
+	)
+	if _, err := redis.Scan(values, &attempts, &hashedCode); err != nil {
+		return "", 0, fmt.Errorf("failed to get token: %w", err)
+	}
+
+	return hashedCode, attempts, nil
 }
 
-// GetAccountNumberByToken mendapatkan account number dari token
-func GetAccountNumberByToken(token string) (string, error) {
+// ClearResetGuard hapus counter kode dan percobaan reset PIN akun setelah PIN berhasil direset
+func ClearResetGuard(ctx context.Context, accountNumber string) error {
+	conn := GetRedisConn()
+	if conn == nil {
+		return fmt.Errorf("failed to get redis connection")
+	}
+	defer conn.Close()
+
+	_, err := redisDo(ctx, conn, "DEL", fmt.Sprintf("reset_guard:%s", accountNumber))
+	return err
+}
+
+// DeleteResetToken menghapus kode reset PIN, return false jika kode sudah dihapus
+// request lain (sekali pakai)
+func DeleteResetToken(ctx context.Context, accountNumber string) (bool, error) {
+	conn := GetRedisConn()
+	if conn == nil {
+		return false, fmt.Errorf("failed to get redis connection")
+	}
+	defer conn.Close()
+
+	tokenKey := fmt.Sprintf("reset_token:%s", accountNumber)
+
+	deleted, err := redis.Int(redisDo(ctx, conn, "DEL", tokenKey))
+	return deleted == 1, err
+}
+
+// SetStepUpChallenge menyimpan challenge OTP transaksi (JSON) beserta hash OTP dengan ttl
+func SetStepUpChallenge(ctx context.Context, challengeID string, hashedCode string, value string, ttl time.Duration) error {
+	conn := GetRedisConn()
+	if conn == nil {
+		return fmt.Errorf("failed to get redis connection")
+	}
+	defer conn.Close()
+
+	challengeKey := fmt.Sprintf("step_up:%s", challengeID)
+
+	conn.Send("MULTI")
+	conn.Send("HSET", challengeKey, "hash", hashedCode, "value", value, "attempts", 0)
+	conn.Send("PEXPIRE", challengeKey, ttl.Milliseconds())
+	if _, err := redisDo(ctx, conn, "EXEC"); err != nil {
+		return fmt.Errorf("failed to store step-up challenge: %w", err)
+	}
+
+	return nil
+}
+
+// HitStepUpChallenge mencatat satu percobaan konfirmasi OTP, return hash OTP, challenge
+// (JSON) dan jumlah percobaan termasuk yang ini
+func HitStepUpChallenge(ctx context.Context, challengeID string) (string, string, int, error) {
+	conn := GetRedisConn()
+	if conn == nil {
+		return "", "", 0, fmt.Errorf("failed to get redis connection")
+	}
+	defer conn.Close()
+
+	challengeKey := fmt.Sprintf("step_up:%s", challengeID)
+
+	values, err := hitCode(ctx, conn, challengeKey, "hash", "value")
+	if err != nil {
+		if err == redis.ErrNil {
+			return "", "", 0, fmt.Errorf("step-up challenge expired or not found")
+		}
+		return "", "", 0, fmt.Errorf("failed to get step-up challenge: %w", err)
+	}
+
+	var (
+		hashedCode string
+		value      string
+		attempts   int
+	)
+	if _, err := redis.Scan(values, &attempts, &hashedCode, &value); err != nil {
+		return "", "", 0, fmt.Errorf("failed to get step-up challenge: %w", err)
+	}
+
+	return hashedCode, value, attempts, nil
+}
+
+// DeleteStepUpChallenge menghapus challenge OTP, return false jika challenge sudah dihapus
+// request lain (sekali pakai)
+func DeleteStepUpChallenge(ctx context.Context, challengeID string) (bool, error) {
+	conn := GetRedisConn()
+	if conn == nil {
+		return false, fmt.Errorf("failed to get redis connection")
+	}
+	defer conn.Close()
+
+	challengeKey := fmt.Sprintf("step_up:%s", challengeID)
+
+	deleted, err := redis.Int(redisDo(ctx, conn, "DEL", challengeKey))
+	return deleted == 1, err
+}
+
+// AcquireIdempotencyKey menyimpan idempotency key hanya jika belum ada (SET NX),
+// return false jika key sudah dipakai request lain
+func AcquireIdempotencyKey(ctx context.Context, key string, value string, ttl time.Duration) (bool, error) {
+	conn := GetRedisConn()
+	if conn == nil {
+		return false, fmt.Errorf("failed to get redis connection")
+	}
+	defer conn.Close()
+
+	idempotencyKey := fmt.Sprintf("idempotency:%s", key)
+
+	_, err := redis.String(redisDo(ctx, conn, "SET", idempotencyKey, value, "EX", int(ttl.Seconds()), "NX"))
+	if err != nil {
+		if err == redis.ErrNil {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to acquire idempotency key: %w", err)
+	}
+
+	return true, nil
+}
+
+// GetIdempotencyKey mendapatkan record idempotency key yang tersimpan
+func GetIdempotencyKey(ctx context.Context, key string) (string, error) {
 	conn := GetRedisConn()
 	if conn == nil {
 		return "", fmt.Errorf("failed to get redis connection")
 	}
 	defer conn.Close()
 
-	tokenKey := fmt.Sprintf("reset_token:%s", token)
+	idempotencyKey := fmt.Sprintf("idempotency:%s", key)
 
-	accountNumber, err := redis.String(conn.Do("GET", tokenKey))
+	value, err := redis.String(redisDo(ctx, conn, "GET", idempotencyKey))
 	if err != nil {
 		if err == redis.ErrNil {
-			return "", fmt.Errorf("token expired or not found")
+			return "", fmt.Errorf("idempotency key expired or not found")
 		}
-		return "", fmt.Errorf("failed to get token: %w", err)
+		return "", fmt.Errorf("failed to get idempotency key: %w", err)
 	}
 
-	return accountNumber, nil
+	return value, nil
+}
+
+// SetIdempotencyKey menimpa record idempotency key dengan response final
+func SetIdempotencyKey(ctx context.Context, key string, value string, ttl time.Duration) error {
+	conn := GetRedisConn()
+	if conn == nil {
+		return fmt.Errorf("failed to get redis connection")
+	}
+	defer conn.Close()
+
+	idempotencyKey := fmt.Sprintf("idempotency:%s", key)
+
+	_, err := redisDo(ctx, conn, "SETEX", idempotencyKey, int(ttl.Seconds()), value)
+	if err != nil {
+		return fmt.Errorf("failed to store idempotency key: %w", err)
+	}
+
+	return nil
 }
 
-// DeleteResetToken menghapus token setelah digunakan
-func DeleteResetToken(token string) error {
+// DeleteIdempotencyKey menghapus idempotency key agar request boleh diulang
+func DeleteIdempotencyKey(ctx context.Context, key string) error {
 	conn := GetRedisConn()
 	if conn == nil {
 		return fmt.Errorf("failed to get redis connection")
 	}
 	defer conn.Close()
 
-	tokenKey := fmt.Sprintf("reset_token:%s", token)
+	idempotencyKey := fmt.Sprintf("idempotency:%s", key)
 
-	_, err := conn.Do("DEL", tokenKey)
+	_, err := redisDo(ctx, conn, "DEL", idempotencyKey)
 	return err
 }
+
+// SetRefreshToken menyimpan jti refresh token yang masih aktif untuk account number
+func SetRefreshToken(ctx context.Context, jti string, accountNumber string, ttl time.Duration) error {
+	conn := GetRedisConn()
+	if conn == nil {
+		return fmt.Errorf("failed to get redis connection")
+	}
+	defer conn.Close()
+
+	tokenKey := fmt.Sprintf("refresh_token:%s", jti)
+
+	_, err := redisDo(ctx, conn, "SETEX", tokenKey, int(ttl.Seconds()), accountNumber)
+	if err != nil {
+		return fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeRefreshToken mengambil sekaligus menghapus refresh token (sekali pakai),
+// return error jika token sudah dipakai atau kedaluwarsa
+func ConsumeRefreshToken(ctx context.Context, jti string) (string, error) {
+	conn := GetRedisConn()
+	if conn == nil {
+		return "", fmt.Errorf("failed to get redis connection")
+	}
+	defer conn.Close()
+
+	tokenKey := fmt.Sprintf("refresh_token:%s", jti)
+
+	conn.Send("MULTI")
+	conn.Send("GET", tokenKey)
+	conn.Send("DEL", tokenKey)
+	values, err := redis.Values(redisDo(ctx, conn, "EXEC"))
+	if err != nil {
+		return "", fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	accountNumber, err := redis.String(values[0], nil)
+	if err != nil {
+		if err == redis.ErrNil {
+			return "", fmt.Errorf("refresh token expired or not found")
+		}
+		return "", fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	return accountNumber, nil
+}
+
+// SetTransferInquiry menyimpan quote transfer-inquiry (JSON) dengan expiry
+func SetTransferInquiry(ctx context.Context, inquiryID string, value string, ttl time.Duration) error {
+	conn := GetRedisConn()
+	if conn == nil {
+		return fmt.Errorf("failed to get redis connection")
+	}
+	defer conn.Close()
+
+	inquiryKey := fmt.Sprintf("transfer_inquiry:%s", inquiryID)
+
+	_, err := redisDo(ctx, conn, "SETEX", inquiryKey, int(ttl.Seconds()), value)
+	if err != nil {
+		return fmt.Errorf("failed to store transfer inquiry: %w", err)
+	}
+
+	return nil
+}
+
+// GetTransferInquiry mendapatkan quote transfer-inquiry tanpa menghapusnya
+func GetTransferInquiry(ctx context.Context, inquiryID string) (string, error) {
+	conn := GetRedisConn()
+	if conn == nil {
+		return "", fmt.Errorf("failed to get redis connection")
+	}
+	defer conn.Close()
+
+	inquiryKey := fmt.Sprintf("transfer_inquiry:%s", inquiryID)
+
+	value, err := redis.String(redisDo(ctx, conn, "GET", inquiryKey))
+	if err != nil {
+		if err == redis.ErrNil {
+			return "", fmt.Errorf("transfer inquiry expired or not found")
+		}
+		return "", fmt.Errorf("failed to get transfer inquiry: %w", err)
+	}
+
+	return value, nil
+}
+
+// ConsumeTransferInquiry mengambil sekaligus menghapus quote (sekali pakai),
+// sehingga satu inquiry tidak bisa diposting dua kali
+func ConsumeTransferInquiry(ctx context.Context, inquiryID string) (string, error) {
+	conn := GetRedisConn()
+	if conn == nil {
+		return "", fmt.Errorf("failed to get redis connection")
+	}
+	defer conn.Close()
+
+	inquiryKey := fmt.Sprintf("transfer_inquiry:%s", inquiryID)
+
+	conn.Send("MULTI")
+	conn.Send("GET", inquiryKey)
+	conn.Send("DEL", inquiryKey)
+	values, err := redis.Values(redisDo(ctx, conn, "EXEC"))
+	if err != nil {
+		return "", fmt.Errorf("failed to get transfer inquiry: %w", err)
+	}
+
+	value, err := redis.String(values[0], nil)
+	if err != nil {
+		if err == redis.ErrNil {
+			return "", fmt.Errorf("transfer inquiry expired or already used")
+		}
+		return "", fmt.Errorf("failed to get transfer inquiry: %w", err)
+	}
+
+	return value, nil
+}
+
+// rateLimitScript sliding window log: sorted set berisi timestamp (ms) tiap request dalam jendela.
+// Hapus yang sudah keluar jendela, tolak jika sudah penuh, selain itu catat request ini.
+// Return {allowed, remaining, retry_after_ms}
+var rateLimitScript = redis.NewScript(1, `
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+if count < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[4])
+	redis.call('PEXPIRE', KEYS[1], window)
+	return {1, limit - count - 1, 0}
+end
+
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+return {0, 0, tonumber(oldest[2]) + window - now}
+`)
+
+// RateLimitHit mencatat satu request untuk key di sliding window Redis, atomic lewat Lua script
+// sehingga batas berlaku bersama untuk semua instance
+func RateLimitHit(ctx context.Context, key string, limit int, window time.Duration) (models.RateLimitResult, error) {
+	var result models.RateLimitResult
+
+	conn := GetRedisConn()
+	if conn == nil {
+		return result, fmt.Errorf("failed to get redis connection")
+	}
+	defer conn.Close()
+
+	rateLimitKey := fmt.Sprintf("rate_limit:%s", key)
+	now := time.Now()
+	member := fmt.Sprintf("%d-%d", now.UnixNano(), rand.Int63())
+
+	_, span := tracing.Start(ctx, "redis EVALSHA", semconv.DBSystemRedis, semconv.DBOperationKey.String("EVALSHA"))
+	values, err := redis.Int64s(rateLimitScript.Do(conn, rateLimitKey, now.UnixNano()/int64(time.Millisecond), window.Milliseconds(), limit, member))
+	tracing.End(span, err)
+	if err != nil {
+		return result, fmt.Errorf("failed to hit rate limit: %w", err)
+	}
+	if len(values) != 3 {
+		return result, fmt.Errorf("unexpected rate limit reply %v", values)
+	}
+
+	result.Allowed = values[0] == 1
+	result.Remaining = int(values[1])
+	result.RetryAfter = time.Duration(values[2]) * time.Millisecond
+	return result, nil
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"context"
+	"os"
+	"sample/models"
+	"testing"
+	"time"
+)
+
+// testRedis pakai Redis test (REDIS_TEST_HOST / REDIS_TEST_PORT, default localhost:6379),
+// test dilewati jika tidak tersedia
+func testRedis(t *testing.T) {
+	cfg := models.Redis{Host: os.Getenv("REDIS_TEST_HOST"), Port: os.Getenv("REDIS_TEST_PORT"), Pass: os.Getenv("REDIS_TEST_PASS")}
+	if cfg.Host == "" {
+		cfg.Host = "localhost"
+	}
+	if cfg.Port == "" {
+		cfg.Port = "6379"
+	}
+
+	if err := InitRedisPool(cfg); err != nil {
+		redisPool, redisConfig = nil, models.Redis{}
+		t.Skipf("test Redis not available: %v", err)
+	}
+	t.Cleanup(func() {
+		CloseRedisPool()
+		redisPool, redisConfig = nil, models.Redis{}
+	})
+}
+
+func TestResetAttemptsSurviveNewCode(t *testing.T) {
+	testRedis(t)
+
+	var (
+		ctx           = context.Background()
+		accountNumber = "test-" + time.Now().Format("150405.000000000")
+		window        = time.Minute
+		maxCodes      = 3
+		maxAttempts   = 3
+	)
+	defer ClearResetGuard(ctx, accountNumber)
+	defer DeleteResetToken(ctx, accountNumber)
+
+	issue := func() bool {
+		allowed, _, err := ReserveResetCode(ctx, accountNumber, maxCodes, maxAttempts, window)
+		if err != nil {
+			t.Fatalf("ReserveResetCode: %v", err)
+		}
+		if allowed {
+			if err := SetResetToken(ctx, accountNumber, "hash", time.Now().Add(window)); err != nil {
+				t.Fatalf("SetResetToken: %v", err)
+			}
+		}
+		return allowed
+	}
+
+	if !issue() {
+		t.Fatal("first code was not issued")
+	}
+	for want := 1; want <= 2; want++ {
+		if _, attempts, err := HitResetToken(ctx, accountNumber, window); err != nil || attempts != want {
+			t.Fatalf("HitResetToken = %d, %v, want %d", attempts, err, want)
+		}
+	}
+
+	// Kode baru tidak mengembalikan jatah percobaan
+	if !issue() {
+		t.Fatal("second code was not issued")
+	}
+	if _, attempts, err := HitResetToken(ctx, accountNumber, window); err != nil || attempts != 3 {
+		t.Fatalf("HitResetToken after new code = %d, %v, want 3", attempts, err)
+	}
+
+	// Percobaan habis: kode baru ditolak sampai jendela cooldown berakhir
+	allowed, retryAfter, err := ReserveResetCode(ctx, accountNumber, maxCodes, maxAttempts, window)
+	if err != nil || allowed || retryAfter <= 0 || retryAfter > window {
+		t.Fatalf("ReserveResetCode after max attempts = %v, %s, %v, want rejected with cooldown", allowed, retryAfter, err)
+	}
+
+	// Reset berhasil mengembalikan jatah
+	if err := ClearResetGuard(ctx, accountNumber); err != nil {
+		t.Fatal(err)
+	}
+	if !issue() {
+		t.Fatal("code after ClearResetGuard was not issued")
+	}
+}
+
+func TestResetCodeIssueLimit(t *testing.T) {
+	testRedis(t)
+
+	ctx := context.Background()
+	accountNumber := "test-" + time.Now().Format("150405.000000000")
+	defer ClearResetGuard(ctx, accountNumber)
+
+	for i := 1; i <= 3; i++ {
+		allowed, _, err := ReserveResetCode(ctx, accountNumber, 2, 5, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := i <= 2; allowed != want {
+			t.Errorf("code %d allowed = %v, want %v", i, allowed, want)
+		}
+	}
+}
@@ -6,18 +6,171 @@ const (
 	FAILED_CODE  = "103"
 
 	// Error berhubungan dengan data
-	DATA_NOT_FOUND_CODE = "201"
-	VALIDATE_ERROR_CODE = "202"
+	DATA_NOT_FOUND_CODE           = "201"
+	VALIDATE_ERROR_CODE           = "202"
+	IDEMPOTENCY_KEY_MISMATCH_CODE = "203"
+	IDEMPOTENCY_IN_PROGRESS_CODE  = "204"
+
+	// Error berhubungan dengan autentikasi
+	UNAUTHORIZED_CODE      = "301"
+	FORBIDDEN_CODE         = "302"
+	TOO_MANY_REQUESTS_CODE = "303"
 
 	SYSTEM_ERROR_CODE    = "501"
 	UNDEFINED_ERROR_CODE = "502"
 
 	ACCOUNT_BALANCE_BELOW_MINIMUM_CODE = "402"
+	REVERSAL_LIMIT_EXCEEDED_CODE       = "403"
+	TRANSACTION_LIMIT_EXCEEDED_CODE    = "404"
 
 	EMPTY_VALUE = ""
 
+	// Idempotency-Key untuk endpoint perpindahan uang
+	IDEMPOTENCY_HEADER             = "Idempotency-Key"
+	IDEMPOTENCY_REPLAY_HEADER      = "Idempotent-Replayed"
+	IDEMPOTENCY_STATUS_IN_PROGRESS = "IN_PROGRESS"
+	IDEMPOTENCY_STATUS_COMPLETED   = "COMPLETED"
+
+	// Autentikasi JWT
+	ROLE_CUSTOMER          = "CUSTOMER"
+	ROLE_ADMIN             = "ADMIN"
+	ADMIN_KEY_HEADER       = "X-Admin-Key"
+	TOKEN_TYPE_ACCESS      = "ACCESS"
+	TOKEN_TYPE_REFRESH     = "REFRESH"
+	AUTH_SCHEME            = "Bearer"
+	CONTEXT_ACCOUNT_NUMBER = "account_number"
+	CONTEXT_ROLE           = "role"
+	CONTEXT_CLIENT_IP      = "client_ip"
+
+	// Rate limit per grup route
+	CLIENT_ID_HEADER            = "X-Client-Id"
+	RETRY_AFTER_HEADER          = "Retry-After"
+	RATE_LIMIT_LIMIT_HEADER     = "X-RateLimit-Limit"
+	RATE_LIMIT_REMAINING_HEADER = "X-RateLimit-Remaining"
+
+	// Transfer terjadwal (standing order)
+	SCHEDULE_FREQUENCY_ONCE    = "ONCE"
+	SCHEDULE_FREQUENCY_DAILY   = "DAILY"
+	SCHEDULE_FREQUENCY_WEEKLY  = "WEEKLY"
+	SCHEDULE_FREQUENCY_MONTHLY = "MONTHLY"
+
+	SCHEDULE_STATUS_ACTIVE    = "ACTIVE"
+	SCHEDULE_STATUS_PAUSED    = "PAUSED"
+	SCHEDULE_STATUS_COMPLETED = "COMPLETED"
+	SCHEDULE_STATUS_FAILED    = "FAILED"
+	SCHEDULE_STATUS_CANCELLED = "CANCELLED"
+
+	EXECUTION_STATUS_SUCCESS = "SUCCESS"
+	EXECUTION_STATUS_RETRY   = "RETRY"
+	EXECUTION_STATUS_FAILED  = "FAILED"
+
+	// Status transaksi
+	TRANSACTION_STATUS_PENDING  = "PENDING"
+	TRANSACTION_STATUS_SUCCESS  = "SUCCESS"
+	TRANSACTION_STATUS_FAILED   = "FAILED"
+	TRANSACTION_STATUS_REVERSED = "REVERSED"
+
+	// Status hold dana (otorisasi)
+	HOLD_STATUS_ACTIVE   = "ACTIVE"
+	HOLD_STATUS_CAPTURED = "CAPTURED"
+	HOLD_STATUS_RELEASED = "RELEASED"
+	HOLD_STATUS_EXPIRED  = "EXPIRED"
+
+	// Webhook partner: jenis event, status pengiriman dan header callback
+	WEBHOOK_EVENT_TRANSACTION_CREATED = "transaction.created"
+	WEBHOOK_EVENT_ACCOUNT_BLOCKED     = "account.blocked"
+	WEBHOOK_EVENT_PIN_RESET           = "pin.reset"
+
+	WEBHOOK_DELIVERY_STATUS_PENDING = "PENDING"
+	WEBHOOK_DELIVERY_STATUS_SUCCESS = "SUCCESS"
+	WEBHOOK_DELIVERY_STATUS_FAILED  = "FAILED"
+
+	WEBHOOK_ID_HEADER        = "X-Webhook-Id"
+	WEBHOOK_EVENT_HEADER     = "X-Webhook-Event"
+	WEBHOOK_TIMESTAMP_HEADER = "X-Webhook-Timestamp"
+	WEBHOOK_SIGNATURE_HEADER = "X-Webhook-Signature"
+
+	// Audit trail (MongoDB): nama collection, hasil, actor dan nomor referensi request
+	AUDIT_COLLECTION      = "audit_log"
+	AUDIT_OUTCOME_SUCCESS = "SUCCESS"
+	AUDIT_OUTCOME_FAILED  = "FAILED"
+	AUDIT_ACTOR_ANONYMOUS = "ANONYMOUS"
+	REFERENCE_HEADER      = "X-Reference-No"
+	CONTEXT_AUDIT         = "audit"
+
+	// Jenis aksi yang dicatat di audit trail
+	AUDIT_ACTION_ACCOUNT_CREATE              = "account.create"
+	AUDIT_ACTION_ACCOUNT_UPDATE              = "account.update"
+	AUDIT_ACTION_ACCOUNT_DELETE              = "account.delete"
+	AUDIT_ACTION_PIN_CHANGE                  = "pin.change"
+	AUDIT_ACTION_PIN_FORGOT                  = "pin.forgot"
+	AUDIT_ACTION_PIN_RESET                   = "pin.reset"
+	AUDIT_ACTION_ACCOUNT_UNBLOCK             = "account.unblock"
+	AUDIT_ACTION_DEPOSIT                     = "transaction.deposit"
+	AUDIT_ACTION_WITHDRAW                    = "transaction.withdraw"
+	AUDIT_ACTION_TRANSFER                    = "transaction.transfer"
+	AUDIT_ACTION_TRANSACTION_CONFIRM         = "transaction.confirm"
+	AUDIT_ACTION_REVERSAL                    = "transaction.reversal"
+	AUDIT_ACTION_SCHEDULED_TRANSFER_CREATE   = "scheduled_transfer.create"
+	AUDIT_ACTION_SCHEDULED_TRANSFER_UPDATE   = "scheduled_transfer.update"
+	AUDIT_ACTION_SCHEDULED_TRANSFER_CANCEL   = "scheduled_transfer.cancel"
+	AUDIT_ACTION_HOLD_CREATE                 = "hold.create"
+	AUDIT_ACTION_HOLD_CAPTURE                = "hold.capture"
+	AUDIT_ACTION_HOLD_RELEASE                = "hold.release"
+	AUDIT_ACTION_FX_CONVERT                  = "fx.convert"
+	AUDIT_ACTION_FX_RATE_SET                 = "fx_rate.set"
+	AUDIT_ACTION_WEBHOOK_SUBSCRIPTION_CREATE = "webhook_subscription.create"
+	AUDIT_ACTION_WEBHOOK_SUBSCRIPTION_UPDATE = "webhook_subscription.update"
+	AUDIT_ACTION_WEBHOOK_REDELIVER           = "webhook_delivery.redeliver"
+
+	// Status akun
+	ACCOUNT_STATUS_ACTIVE      = "ACTIVE"
+	ACCOUNT_STATUS_BLOCKED_PIN = "BLOCKED_PIN"
+
+	// Riwayat kunci PIN per akun
+	PIN_LOCK_EVENT_TEMPORARY_LOCK  = "TEMPORARY_LOCK"
+	PIN_LOCK_EVENT_PERMANENT_BLOCK = "PERMANENT_BLOCK"
+	PIN_LOCK_EVENT_ADMIN_UNBLOCK   = "ADMIN_UNBLOCK"
+	PIN_LOCK_EVENT_PIN_RESET       = "PIN_RESET"
+	PIN_LOCK_ACTOR_SYSTEM          = "SYSTEM"
+
+	// Tier akun, menentukan limit transaksi
+	ACCOUNT_TIER_BASIC   = "BASIC"
+	ACCOUNT_TIER_PREMIUM = "PREMIUM"
+
+	// Jenis skema biaya
+	FEE_TYPE_FLAT       = "FLAT"
+	FEE_TYPE_PERCENTAGE = "PERCENTAGE"
+	FEE_TYPE_TIERED     = "TIERED"
+
+	// Mata uang utama rekening, saldo mata uang lain disimpan di pocket
+	DEFAULT_CURRENCY = "IDR"
+	CURRENCY_USD     = "USD"
+	CURRENCY_SGD     = "SGD"
+
 	PRODUCT_COLLECTION = "product_col"
 
+	// Jenis transaksi yang membutuhkan konfirmasi OTP (step-up)
+	STEP_UP_TYPE_WITHDRAW = "WITHDRAW"
+	STEP_UP_TYPE_TRANSFER = "TRANSFER"
+
+	// Kode akun sistem pada ledger double-entry
+	LEDGER_CASH_VAULT  = "CASH_VAULT"
+	LEDGER_FEE_INCOME  = "FEE_INCOME"
+	LEDGER_FX_POSITION = "FX_POSITION" // per mata uang, contoh FX_POSITION_USD
+
+	// Jenis jurnal
+	JOURNAL_TYPE_OPENING       = "OPENING"
+	JOURNAL_TYPE_DEPOSIT       = "DEPOSIT"
+	JOURNAL_TYPE_WITHDRAW      = "WITHDRAW"
+	JOURNAL_TYPE_TRANSFER      = "TRANSFER"
+	JOURNAL_TYPE_REVERSAL      = "REVERSAL"
+	JOURNAL_TYPE_FX_CONVERSION = "FX_CONVERSION"
+
+	// Status health check dependency
+	HEALTH_STATUS_UP   = "UP"
+	HEALTH_STATUS_DOWN = "DOWN"
+
 	// Layout timestamp untuk format waktu
 	LAYOUT_TIMESTAMP = "2006-01-02 15:04:05"
 	LAYOUT_DATE      = "2006-01-02"
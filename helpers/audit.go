@@ -0,0 +1,73 @@
+package helpers
+
+import (
+	"encoding/json"
+	"sample/constans"
+	"sample/models"
+
+	"github.com/labstack/echo"
+)
+
+// auditLog catatan audit request berjalan yang disiapkan middleware Audit,
+// nil jika route tidak diaudit sehingga semua helper di bawah menjadi no-op
+func auditLog(ctx echo.Context) *models.AuditLog {
+	auditLog, _ := ctx.Get(constans.CONTEXT_AUDIT).(*models.AuditLog)
+	return auditLog
+}
+
+// AuditAccount rekening yang terdampak, untuk endpoint publik yang tidak punya subject token
+func AuditAccount(ctx echo.Context, accountNumber string) {
+	if auditLog := auditLog(ctx); auditLog != nil {
+		auditLog.AccountNumber = accountNumber
+	}
+}
+
+// AuditReferenceNo nomor referensi transaksi / jurnal yang dihasilkan aksi
+func AuditReferenceNo(ctx echo.Context, referenceNo string) {
+	if auditLog := auditLog(ctx); auditLog != nil {
+		auditLog.TransactionReferenceNo = referenceNo
+	}
+}
+
+// AuditBefore snapshot data sebelum perubahan
+func AuditBefore(ctx echo.Context, snapshot interface{}) {
+	if auditLog := auditLog(ctx); auditLog != nil {
+		auditLog.Before = auditDocument(snapshot)
+	}
+}
+
+// AuditAfter snapshot data sesudah perubahan
+func AuditAfter(ctx echo.Context, snapshot interface{}) {
+	if auditLog := auditLog(ctx); auditLog != nil {
+		auditLog.After = auditDocument(snapshot)
+	}
+}
+
+// AuditBalanceChange snapshot saldo sebelum dan sesudah perpindahan uang beserta nomor referensinya
+func AuditBalanceChange(ctx echo.Context, referenceNo string, changes ...models.AuditBalanceChange) {
+	var before, after models.AuditBalanceSnapshot
+	for _, change := range changes {
+		currency := change.After.CurrencyCode()
+		before.Balances = append(before.Balances, models.AuditBalance{AccountNumber: change.AccountNumber, Currency: currency, Balance: change.Before})
+		after.Balances = append(after.Balances, models.AuditBalance{AccountNumber: change.AccountNumber, Currency: currency, Balance: change.After})
+	}
+
+	AuditReferenceNo(ctx, referenceNo)
+	AuditBefore(ctx, before)
+	AuditAfter(ctx, after)
+}
+
+// auditDocument menyalin snapshot lewat JSON agar field dan format nominal di Mongo
+// sama dengan response API, dan perubahan struct setelahnya tidak ikut tercatat
+func auditDocument(snapshot interface{}) map[string]interface{} {
+	var document map[string]interface{}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return map[string]interface{}{"value": string(data)}
+	}
+	return document
+}
@@ -0,0 +1,95 @@
+package helpers
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sample/constans"
+	"strings"
+
+	"github.com/labstack/echo"
+)
+
+// ParseTrustedProxies daftar IP / CIDR dipisah koma (TRUSTED_PROXIES) menjadi network.
+// IP tunggal dianggap /32 (IPv4) atau /128 (IPv6)
+func ParseTrustedProxies(list string) ([]*net.IPNet, error) {
+	var result []*net.IPNet
+
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			result = append(result, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q", entry)
+		}
+		result = append(result, network)
+	}
+
+	return result, nil
+}
+
+// ResolveClientIP IP client dari koneksi TCP. X-Forwarded-For hanya dipakai jika koneksi datang
+// dari proxy terpercaya: dibaca dari kanan, hop proxy terpercaya dilewati, hop pertama di luarnya
+// adalah client. Tanpa proxy terpercaya header dari client diabaikan karena bisa dipalsukan
+func ResolveClientIP(request *http.Request, trusted []*net.IPNet) string {
+	remote, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		remote = request.RemoteAddr
+	}
+
+	if !isTrustedProxy(remote, trusted) {
+		return remote
+	}
+
+	hops := strings.Split(request.Header.Get(echo.HeaderXForwardedFor), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		if !isTrustedProxy(hop, trusted) {
+			return hop
+		}
+		remote = hop
+	}
+
+	return remote
+}
+
+func isTrustedProxy(address string, trusted []*net.IPNet) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetClientIP IP client yang di-set oleh middleware ClientIP, IP koneksi jika belum di-set
+func GetClientIP(ctx echo.Context) string {
+	if ip, ok := ctx.Get(constans.CONTEXT_CLIENT_IP).(string); ok {
+		return ip
+	}
+	return ResolveClientIP(ctx.Request(), nil)
+}
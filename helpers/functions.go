@@ -1,14 +1,15 @@
 package helpers
 
 import (
+	"context"
 	"database/sql"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"math/rand"
 	"reflect"
 	"sample/constans"
 	"sample/models"
+	"sample/tracing"
 	"strconv"
 	"strings"
 	"time"
@@ -116,6 +117,14 @@ func CheckPINHash(pin, hash string) bool {
 	return err == nil
 }
 
+// CheckPINHashContext CheckPINHash yang dicatat sebagai child span dari ctx (bcrypt sengaja lambat)
+func CheckPINHashContext(ctx context.Context, pin, hash string) bool {
+	_, span := tracing.Start(ctx, "helpers.CheckPINHash")
+	defer span.End()
+
+	return CheckPINHash(pin, hash)
+}
+
 func GenerateAccountNumber() string {
 	rand.New(rand.NewSource(time.Now().UnixNano()))
 	timestamp := time.Now().UnixNano() / 1000000
@@ -131,10 +140,9 @@ func GenerateAccountNumber() string {
 	return number
 }
 
-func GenerateResetToken() string {
-	b := make([]byte, 32)
-	rand.Read(b)
-	return hex.EncodeToString(b)
+// GenerateSecureID membuat ID acak (hex) dari crypto/rand, dipakai untuk ID yang tidak boleh ditebak
+func GenerateSecureID() (string, error) {
+	return generateTokenID()
 }
 
 func Contains(s, substr string) bool {
@@ -148,6 +156,8 @@ func TransactionDto(rows *sql.Rows) ([]models.Transaction, error) {
 	for rows.Next() {
 		var val models.Transaction
 		var sourceNumber, beneficiaryNumber sql.NullString
+		var currency string
+		var journalID, reversalOf sql.NullInt64
 
 		err := rows.Scan(
 			&val.ID,
@@ -158,6 +168,11 @@ func TransactionDto(rows *sql.Rows) ([]models.Transaction, error) {
 			&beneficiaryNumber,
 			&val.TransactionType,
 			&val.Amount,
+			&val.Fee,
+			&currency,
+			&val.Status,
+			&journalID,
+			&reversalOf,
 			&val.TransactionTime,
 			&val.CreatedAt,
 		)
@@ -167,6 +182,10 @@ func TransactionDto(rows *sql.Rows) ([]models.Transaction, error) {
 
 		val.SourceNumber = sourceNumber.String
 		val.BeneficiaryNumber = beneficiaryNumber.String
+		val.JournalID = int(journalID.Int64)
+		val.ReversalOf = int(reversalOf.Int64)
+		val.Amount.Currency = currency
+		val.Fee.Currency = currency
 
 		result = append(result, val)
 	}
@@ -181,3 +200,10 @@ func NullString(s string) sql.NullString {
 	}
 	return sql.NullString{String: s, Valid: true}
 }
+
+func NullInt(i int) sql.NullInt64 {
+	if i == 0 {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(i), Valid: true}
+}
@@ -0,0 +1,113 @@
+package helpers
+
+import (
+	cryptorand "crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"sample/constans"
+	"sample/models"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/labstack/echo"
+)
+
+// JWTSigningKey kunci HMAC untuk menandatangani token, dari config JWT_KEY
+func JWTSigningKey(cfg models.JWT) ([]byte, error) {
+	if cfg.Key == "" {
+		return nil, errors.New("JWT_KEY is not configured")
+	}
+	return []byte(cfg.Key), nil
+}
+
+// GenerateToken membuat JWT HS256 untuk account number dengan jenis token tertentu,
+// return token, jti dan waktu kedaluwarsa
+func GenerateToken(cfg models.JWT, accountNumber, role, tokenType string, ttl time.Duration) (string, string, time.Time, error) {
+	key, err := JWTSigningKey(cfg)
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	jti, err := generateTokenID()
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+
+	claims := models.JWTClaims{
+		AccountNumber: accountNumber,
+		Role:          role,
+		TokenType:     tokenType,
+		StandardClaims: jwt.StandardClaims{
+			Id:        jti,
+			Subject:   accountNumber,
+			IssuedAt:  now.Unix(),
+			NotBefore: now.Unix(),
+			ExpiresAt: expiresAt.Unix(),
+		},
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key)
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+
+	return signed, jti, expiresAt, nil
+}
+
+// generateTokenID jti acak dari crypto/rand agar refresh token tidak bisa ditebak
+func generateTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := cryptorand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// GenerateOTP kode numerik 6 digit dari crypto/rand, dipakai untuk kode reset PIN
+func GenerateOTP() (string, error) {
+	n, err := cryptorand.Int(cryptorand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// ParseToken memverifikasi signature dan masa berlaku token lalu mengembalikan claims
+func ParseToken(cfg models.JWT, tokenString string) (*models.JWTClaims, error) {
+	key, err := JWTSigningKey(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := new(models.JWTClaims)
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method.Alg() != jwt.SigningMethodHS256.Alg() {
+			return nil, errors.New("unexpected jwt signing method")
+		}
+		return key, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	return claims, nil
+}
+
+// GetAccountNumber account number pemilik token yang di-set oleh middleware JWT
+func GetAccountNumber(ctx echo.Context) string {
+	accountNumber, _ := ctx.Get(constans.CONTEXT_ACCOUNT_NUMBER).(string)
+	return accountNumber
+}
+
+// GetRole role pemilik token yang di-set oleh middleware JWT
+func GetRole(ctx echo.Context) string {
+	role, _ := ctx.Get(constans.CONTEXT_ROLE).(string)
+	return role
+}
@@ -0,0 +1,36 @@
+package helpers
+
+import (
+	"reflect"
+	"sample/models"
+
+	ut "github.com/go-playground/universal-translator"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// RegisterMoneyValidation mendaftarkan validasi untuk models.Money.
+// Money divalidasi sebagai minor unit (int64) sehingga tag "required" berlaku,
+// dan tag "money_min=10000" membandingkan nilai desimal secara eksak.
+func RegisterMoneyValidation(validate *validator.Validate, trans ut.Translator) {
+	validate.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		if m, ok := field.Interface().(models.Money); ok {
+			return m.Amount
+		}
+		return nil
+	}, models.Money{})
+
+	validate.RegisterValidation("money_min", func(fl validator.FieldLevel) bool {
+		min, err := models.ParseMoney(fl.Param(), "")
+		if err != nil {
+			return false
+		}
+		return fl.Field().Int() >= min.Amount
+	})
+
+	validate.RegisterTranslation("money_min", trans, func(ut ut.Translator) error {
+		return ut.Add("money_min", "{0} minimal {1}", true)
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		t, _ := ut.T("money_min", fe.Field(), fe.Param())
+		return t
+	})
+}
@@ -6,12 +6,25 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
 	"sample/app"
 	"sample/config"
 	"sample/helpers"
+	"sample/metrics"
+	"sample/middlewares"
+	"sample/migrations"
+	"sample/models"
 	"sample/repositories"
 	"sample/routes"
+	"sample/services/healthService"
+	"sample/services/holdService"
+	"sample/services/scheduledTransferService"
+	"sample/services/webhookService"
+	"sample/tracing"
 	"strconv"
+	"sync"
+	"syscall"
 
 	"github.com/go-playground/locales/id"
 	ut "github.com/go-playground/universal-translator"
@@ -30,7 +43,7 @@ type CustomValidator struct {
 // Passing Variable
 var (
 	uni         *ut.UniversalTranslator
-	echoHandler echo.Echo
+	echoHandler *echo.Echo
 )
 
 var ctx = context.Background()
@@ -49,56 +62,158 @@ func (cv *CustomValidator) Validate(i interface{}) error {
 }
 
 func main() {
-	if err := config.OpenConnection(); err != nil {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
+
+	// Konfigurasi: default < environment/*.json < .env < environment variable
+	cfg, err := config.Load()
+	if err != nil {
+		panic(fmt.Sprintf("Load Config Failed: %s", err.Error()))
+	}
+
+	if err := config.OpenConnection(cfg); err != nil {
 		log.Printf("error open connection: %v\n", err)
 		panic(fmt.Sprintf("Open Connection Failed: %s", err.Error()))
 	}
 	defer config.CloseConnectionDB()
 
 	// Initialize Redis Pool
-	config.InitRedisPool()
+	config.InitRedisPool(cfg.Redis)
 	defer config.CloseRedisPool()
 
 	// Mongo DB connection using database default
-	mongoDB := config.ConnectMongo(ctx)
+	mongoDB, err := config.ConnectMongo(ctx, cfg.Mongo)
+	if err != nil {
+		panic(fmt.Sprintf("Mongo Connection Failed: %s", err.Error()))
+	}
+	defer mongoDB.Client().Disconnect(ctx)
 
 	// Connection database
 	DB := config.DBConnection()
 
+	// Tolak start jika skema database belum dimigrasi sampai versi yang dibutuhkan binary ini
+	migrator, err := migrations.NewMigrator(DB)
+	if err == nil {
+		err = migrator.Check()
+	}
+	if err != nil {
+		panic(fmt.Sprintf("Schema Check Failed: %s", err.Error()))
+	}
+
+	// Tracing OpenTelemetry, exporter dari TRACING_EXPORTER
+	shutdownTracing, err := tracing.Init(cfg)
+	if err != nil {
+		panic(fmt.Sprintf("Init Tracing Failed: %s", err.Error()))
+	}
+
+	// Statistik pool Postgres dan Redis untuk /metrics
+	if err := metrics.RegisterPoolStats(DB, config.RedisPoolStats); err != nil {
+		log.Printf("error register pool metrics: %v\n", err)
+	}
+
 	// Configuration Repository
 	repo := repositories.NewRepository(DB, mongoDB, ctx)
 
 	// Configuration Repository and Services
-	services := app.SetupApp(DB, repo)
+	services := app.SetupApp(DB, repo, cfg)
+
+	// Worker berhenti saat workerCtx dibatalkan, workers menunggu putaran terakhirnya selesai
+	workerCtx, stopWorkers := context.WithCancel(ctx)
+	var workers sync.WaitGroup
+	startWorker := func(start func(context.Context)) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			start(workerCtx)
+		}()
+	}
+
+	// Worker transfer terjadwal
+	startWorker(scheduledTransferService.NewScheduledTransferWorker(services).Start)
+
+	// Worker release otomatis hold yang kedaluwarsa
+	startWorker(holdService.NewHoldExpiryWorker(services).Start)
+
+	// Worker pengiriman webhook partner (outbox -> delivery)
+	startWorker(webhookService.NewWebhookWorker(services).Start)
 
 	// Routing API
 	routes.RoutesApi(echoHandler, services)
 
-	port := fmt.Sprintf(":%s", config.GetEnv("APP_PORT", "8080"))
-	echoHandler.Logger.Fatal(echoHandler.Start(port))
+	port := fmt.Sprintf(":%s", cfg.AppPort)
+	go func() {
+		if err := echoHandler.Start(port); err != nil && err != http.ErrServerClosed {
+			echoHandler.Logger.Fatal(err)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGTERM, os.Interrupt)
+	<-quit
+
+	gracefulShutdown(cfg, stopWorkers, &workers, shutdownTracing)
+}
+
+// gracefulShutdown readiness diubah DOWN, request yang sedang berjalan dan putaran worker
+// terakhir ditunggu sampai ShutdownTimeout, lalu sisa span dikirim ke exporter.
+// Pool DB, Redis dan MongoDB ditutup oleh defer di main
+func gracefulShutdown(cfg models.Config, stopWorkers context.CancelFunc, workers *sync.WaitGroup, shutdownTracing func(context.Context) error) {
+	log.Printf("Shutting down, waiting up to %s for in-flight requests and workers\n", cfg.ShutdownTimeout())
+	healthService.MarkDraining()
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, cfg.ShutdownTimeout())
+	defer cancel()
+
+	stopWorkers()
+	if err := echoHandler.Shutdown(shutdownCtx); err != nil {
+		log.Printf("error shutdown http server: %v\n", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Println("Shutdown complete")
+	case <-shutdownCtx.Done():
+		log.Println("Shutdown timeout, workers still running")
+	}
+
+	// Kirim span yang masih di buffer exporter
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Printf("error shutdown tracing: %v\n", err)
+	}
 }
 
 func init() {
 	boardingService()
 
 	e := echo.New()
-	echoHandler = *e
+	echoHandler = e
 	validateCustom := validator.New()
 
 	id := id.New()
 	uni = ut.New(id, id)
 	trans, _ := uni.GetTranslator("id")
 	id_translations.RegisterDefaultTranslations(validateCustom, trans)
+	helpers.RegisterMoneyValidation(validateCustom, trans)
 	e.Validator = &CustomValidator{validator: validateCustom, translator: trans}
 
 	e.Static("/img/*", "assets/img")
 	e.Use(middleware.Logger())
+	e.Use(middlewares.Tracing())
+	e.Use(middlewares.Metrics())
 	e.Use(middleware.Recover())
 	e.Use(middleware.Secure())
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowCredentials: true,
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete},
+		ExposeHeaders:    []string{tracing.TraceIDHeader},
 	}))
 
 	e.HTTPErrorHandler = func(err error, c echo.Context) {
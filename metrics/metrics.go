@@ -0,0 +1,138 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"sample/models"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry registry Prometheus milik aplikasi, diekspos lewat /metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequestDuration durasi request per method, route (pola Echo) dan status HTTP
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by method, route and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// TransactionsTotal jumlah transaksi sukses per jenis (DEPOSIT, WITHDRAW, TRANSFER) dan mata uang
+	TransactionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "wallet_transactions_total",
+		Help: "Successful transactions by type and currency.",
+	}, []string{"type", "currency"})
+
+	// TransactionAmountTotal total nominal transaksi sukses dalam satuan mata uang utama
+	TransactionAmountTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "wallet_transaction_amount_total",
+		Help: "Total amount of successful transactions by type, in major currency units.",
+	}, []string{"type", "currency"})
+
+	FailedPINAttemptsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "wallet_failed_pin_attempts_total",
+		Help: "PIN verifications that failed.",
+	})
+
+	AccountsBlockedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "wallet_accounts_blocked_total",
+		Help: "Accounts blocked after too many failed PIN attempts.",
+	})
+
+	AccountsTemporarilyLockedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "wallet_accounts_temporarily_locked_total",
+		Help: "Temporary PIN lockouts applied after too many failed PIN attempts.",
+	})
+
+	ResetTokensIssuedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "wallet_pin_reset_tokens_issued_total",
+		Help: "PIN reset codes issued by forgot PIN.",
+	})
+
+	// ResetTokenDeliveryFailuresTotal kode reset PIN yang gagal dikirim ke nasabah
+	ResetTokenDeliveryFailuresTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "wallet_pin_reset_delivery_failures_total",
+		Help: "PIN reset codes that could not be delivered through the notification channel.",
+	})
+
+	// StepUpChallengesTotal challenge OTP transaksi nominal besar per hasil (issued, confirmed, rejected, expired)
+	StepUpChallengesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "wallet_step_up_challenges_total",
+		Help: "OTP step-up challenges for high-value transactions by outcome.",
+	}, []string{"outcome"})
+
+	// RateLimitedTotal request yang ditolak 429 per grup route dan backend limiter (redis, local)
+	RateLimitedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_rate_limited_total",
+		Help: "Requests rejected by the rate limiter by route group and limiter backend.",
+	}, []string{"group", "backend"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequestDuration,
+		TransactionsTotal,
+		TransactionAmountTotal,
+		FailedPINAttemptsTotal,
+		AccountsBlockedTotal,
+		AccountsTemporarilyLockedTotal,
+		ResetTokensIssuedTotal,
+		ResetTokenDeliveryFailuresTotal,
+		StepUpChallengesTotal,
+		RateLimitedTotal,
+	)
+}
+
+// Handler endpoint scrape Prometheus
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// RecordTransaction mencatat satu transaksi sukses, dipanggil setelah commit
+func RecordTransaction(transactionType string, amount models.Money) {
+	currency := amount.CurrencyCode()
+	TransactionsTotal.WithLabelValues(transactionType, currency).Inc()
+	TransactionAmountTotal.WithLabelValues(transactionType, currency).Add(float64(amount.Amount) / 100)
+}
+
+// RegisterPoolStats statistik connection pool Postgres (sql.DBStats) dan Redis (redigo PoolStats)
+// dibaca setiap kali di-scrape
+func RegisterPoolStats(db *sql.DB, redisStats func() redis.PoolStats) error {
+	if err := Registry.Register(collectors.NewDBStatsCollector(db, "postgres")); err != nil {
+		return err
+	}
+
+	redisCollectors := []prometheus.Collector{
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "redis_pool_active_connections",
+			Help: "Connections in the Redis pool, idle or in use.",
+		}, func() float64 { return float64(redisStats().ActiveCount) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "redis_pool_idle_connections",
+			Help: "Idle connections in the Redis pool.",
+		}, func() float64 { return float64(redisStats().IdleCount) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "redis_pool_wait_count_total",
+			Help: "Times a caller waited for a Redis connection.",
+		}, func() float64 { return float64(redisStats().WaitCount) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "redis_pool_wait_duration_seconds_total",
+			Help: "Total time callers waited for a Redis connection.",
+		}, func() float64 { return redisStats().WaitDuration.Seconds() }),
+	}
+
+	for _, collector := range redisCollectors {
+		if err := Registry.Register(collector); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"sample/constans"
+	"sample/helpers"
+
+	"github.com/labstack/echo"
+)
+
+// AdminAuth membatasi endpoint back-office (support staff) dengan header X-Admin-Key
+// yang dicocokkan ke config ADMIN_API_KEY. Jika adminKey kosong semua request ditolak
+func AdminAuth(adminKey string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			key := ctx.Request().Header.Get(constans.ADMIN_KEY_HEADER)
+
+			if adminKey == "" || subtle.ConstantTimeCompare([]byte(key), []byte(adminKey)) != 1 {
+				result := helpers.ResponseJSON(false, constans.UNAUTHORIZED_CODE, "Invalid or missing admin key", nil)
+				return ctx.JSON(http.StatusUnauthorized, result)
+			}
+
+			ctx.Set(constans.CONTEXT_ROLE, constans.ROLE_ADMIN)
+			return next(ctx)
+		}
+	}
+}
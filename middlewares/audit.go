@@ -0,0 +1,99 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sample/constans"
+	"sample/helpers"
+	"sample/models"
+	"sample/repositories"
+	"sample/utils"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+// referenceNoMaxLength batas header X-Reference-No dari client, lebih dari ini diganti nomor baru
+const referenceNoMaxLength = 64
+
+// Audit mencatat setiap pemanggilan endpoint yang mengubah data ke audit trail MongoDB:
+// actor, IP, nomor referensi request, snapshot before/after dari service dan hasilnya.
+// Gagal menulis audit hanya di-log, response ke client tidak berubah
+func Audit(auditRepo repositories.AuditRepository, action string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			var (
+				serviceName = "Middleware.Audit"
+				start       = time.Now()
+				request     = ctx.Request()
+				referenceNo = request.Header.Get(constans.REFERENCE_HEADER)
+			)
+
+			if referenceNo == "" || len(referenceNo) > referenceNoMaxLength {
+				referenceNo = utils.GenerateReferenceNoWithPrefix("REQ")
+			}
+			ctx.Response().Header().Set(constans.REFERENCE_HEADER, referenceNo)
+
+			auditLog := &models.AuditLog{
+				Action:      action,
+				IPAddress:   helpers.GetClientIP(ctx),
+				UserAgent:   request.UserAgent(),
+				ReferenceNo: referenceNo,
+				Method:      request.Method,
+				Path:        ctx.Path(),
+				CreatedAt:   start,
+			}
+			ctx.Set(constans.CONTEXT_AUDIT, auditLog)
+
+			// Rekam response untuk status code dan pesan
+			buffer := new(bytes.Buffer)
+			writer := &responseRecorder{Writer: io.MultiWriter(ctx.Response().Writer, buffer), ResponseWriter: ctx.Response().Writer}
+			ctx.Response().Writer = writer
+
+			err := next(ctx)
+			if err != nil {
+				ctx.Error(err)
+			}
+
+			auditLog.Actor, auditLog.ActorRole = auditActor(ctx)
+			if auditLog.AccountNumber == "" {
+				auditLog.AccountNumber = helpers.GetAccountNumber(ctx)
+			}
+
+			auditLog.StatusCode = ctx.Response().Status
+			auditLog.Outcome = constans.AUDIT_OUTCOME_SUCCESS
+			if auditLog.StatusCode >= http.StatusBadRequest {
+				auditLog.Outcome = constans.AUDIT_OUTCOME_FAILED
+			}
+
+			var response models.Response
+			if json.Unmarshal(buffer.Bytes(), &response) == nil {
+				auditLog.ResponseCode = response.StatusCode
+				auditLog.Message = response.Message
+			}
+			auditLog.DurationMs = time.Since(start).Milliseconds()
+
+			if _, err := auditRepo.AddAuditLog(*auditLog); err != nil {
+				utils.LogError(serviceName, referenceNo, "AddAuditLog", err, action)
+			}
+
+			return nil
+		}
+	}
+}
+
+// auditActor account number nasabah dari token, ADMIN untuk back-office, selain itu ANONYMOUS
+func auditActor(ctx echo.Context) (string, string) {
+	role := helpers.GetRole(ctx)
+
+	switch {
+	case role == constans.ROLE_ADMIN:
+		return constans.ROLE_ADMIN, role
+	case helpers.GetAccountNumber(ctx) != "":
+		return helpers.GetAccountNumber(ctx), role
+	default:
+		return constans.AUDIT_ACTOR_ANONYMOUS, role
+	}
+}
@@ -0,0 +1,51 @@
+package middlewares
+
+import (
+	"net/http"
+	"sample/constans"
+	"sample/helpers"
+	"sample/models"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+)
+
+// JWT memverifikasi access token pada header Authorization lalu menyimpan
+// account number dan role dari claims ke context untuk dipakai handler
+func JWT(cfg models.JWT) echo.MiddlewareFunc {
+	key, err := helpers.JWTSigningKey(cfg)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	verify := middleware.JWTWithConfig(middleware.JWTConfig{
+		SigningKey:    key,
+		SigningMethod: middleware.AlgorithmHS256,
+		Claims:        &models.JWTClaims{},
+		AuthScheme:    constans.AUTH_SCHEME,
+		ErrorHandler: func(err error) error {
+			return echo.NewHTTPError(http.StatusUnauthorized, "Invalid or expired access token")
+		},
+	})
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return verify(func(ctx echo.Context) error {
+			token, ok := ctx.Get("user").(*jwt.Token)
+			if !ok {
+				result := helpers.ResponseJSON(false, constans.UNAUTHORIZED_CODE, "Invalid or expired access token", nil)
+				return ctx.JSON(http.StatusUnauthorized, result)
+			}
+
+			claims, ok := token.Claims.(*models.JWTClaims)
+			if !ok || claims.TokenType != constans.TOKEN_TYPE_ACCESS || claims.AccountNumber == "" {
+				result := helpers.ResponseJSON(false, constans.UNAUTHORIZED_CODE, "Access token required", nil)
+				return ctx.JSON(http.StatusUnauthorized, result)
+			}
+
+			ctx.Set(constans.CONTEXT_ACCOUNT_NUMBER, claims.AccountNumber)
+			ctx.Set(constans.CONTEXT_ROLE, claims.Role)
+			return next(ctx)
+		})
+	}
+}
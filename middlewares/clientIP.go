@@ -0,0 +1,22 @@
+package middlewares
+
+import (
+	"sample/constans"
+	"sample/helpers"
+
+	"github.com/labstack/echo"
+)
+
+// ClientIP menentukan IP client sekali per request untuk rate limit dan audit.
+// X-Forwarded-For hanya dipercaya dari proxy di TRUSTED_PROXIES (IP / CIDR dipisah koma)
+func ClientIP(trustedProxies string) echo.MiddlewareFunc {
+	// Format sudah divalidasi config.Validate saat start
+	trusted, _ := helpers.ParseTrustedProxies(trustedProxies)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			ctx.Set(constans.CONTEXT_CLIENT_IP, helpers.ResolveClientIP(ctx.Request(), trusted))
+			return next(ctx)
+		}
+	}
+}
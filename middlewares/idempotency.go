@@ -0,0 +1,166 @@
+package middlewares
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sample/config"
+	"sample/constans"
+	"sample/helpers"
+	"sample/models"
+	"sample/utils"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+const (
+	// idempotencyLockTTL batas waktu request pertama dianggap masih diproses
+	idempotencyLockTTL = 60 * time.Second
+	// idempotencyResponseTTL lama response disimpan untuk replay
+	idempotencyResponseTTL  = 24 * time.Hour
+	idempotencyKeyMaxLength = 255
+)
+
+// responseRecorder menyalin body response agar bisa disimpan untuk replay
+type responseRecorder struct {
+	io.Writer
+	http.ResponseWriter
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	return w.Writer.Write(b)
+}
+
+// Idempotency menghormati header Idempotency-Key pada endpoint perpindahan uang.
+// Request pertama dieksekusi dan response-nya disimpan di Redis; request ulang
+// dengan key dan payload yang sama mendapat response identik tanpa eksekusi ulang.
+func Idempotency() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			var (
+				serviceName = "Middleware.Idempotency"
+				key         = ctx.Request().Header.Get(constans.IDEMPOTENCY_HEADER)
+				record      models.IdempotencyRecord
+			)
+
+			if key == "" {
+				return next(ctx)
+			}
+
+			if len(key) > idempotencyKeyMaxLength {
+				result := helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Idempotency-Key is too long", nil)
+				return ctx.JSON(http.StatusBadRequest, result)
+			}
+
+			body, err := ioutil.ReadAll(ctx.Request().Body)
+			if err != nil {
+				utils.LogError(serviceName, key, "ReadBody", err)
+				result := helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Invalid request body", nil)
+				return ctx.JSON(http.StatusBadRequest, result)
+			}
+			ctx.Request().Body = ioutil.NopCloser(bytes.NewReader(body))
+
+			// Key di-scope per nasabah agar key yang sama dari nasabah lain tidak bentrok
+			storeKey := helpers.GetAccountNumber(ctx) + ":" + ctx.Path() + ":" + key
+			fingerprint := requestFingerprint(ctx.Request().Method, ctx.Path(), body)
+
+			record = models.IdempotencyRecord{
+				Fingerprint: fingerprint,
+				Status:      constans.IDEMPOTENCY_STATUS_IN_PROGRESS,
+				CreatedAt:   time.Now(),
+			}
+			value, _ := json.Marshal(record)
+
+			acquired, err := config.AcquireIdempotencyKey(ctx.Request().Context(), storeKey, string(value), idempotencyLockTTL)
+			if err != nil {
+				utils.LogError(serviceName, key, "AcquireIdempotencyKey", err)
+				result := helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Unable to process Idempotency-Key. Please try again", nil)
+				return ctx.JSON(http.StatusServiceUnavailable, result)
+			}
+
+			if !acquired {
+				return replay(ctx, storeKey, key, fingerprint)
+			}
+
+			// Rekam response untuk disimpan
+			buffer := new(bytes.Buffer)
+			writer := &responseRecorder{Writer: io.MultiWriter(ctx.Response().Writer, buffer), ResponseWriter: ctx.Response().Writer}
+			ctx.Response().Writer = writer
+
+			err = next(ctx)
+			if err != nil {
+				ctx.Error(err)
+			}
+
+			status := ctx.Response().Status
+			if status >= http.StatusInternalServerError {
+				// Error sistem: izinkan client mencoba ulang dengan key yang sama
+				if delErr := config.DeleteIdempotencyKey(ctx.Request().Context(), storeKey); delErr != nil {
+					utils.LogError(serviceName, key, "DeleteIdempotencyKey", delErr)
+				}
+				return nil
+			}
+
+			record.Status = constans.IDEMPOTENCY_STATUS_COMPLETED
+			record.StatusCode = status
+			record.ContentType = ctx.Response().Header().Get(echo.HeaderContentType)
+			record.Body = buffer.Bytes()
+			value, _ = json.Marshal(record)
+
+			if err := config.SetIdempotencyKey(ctx.Request().Context(), storeKey, string(value), idempotencyResponseTTL); err != nil {
+				utils.LogError(serviceName, key, "SetIdempotencyKey", err)
+			}
+
+			return nil
+		}
+	}
+}
+
+// replay mengembalikan response tersimpan atau menolak key yang bentrok
+func replay(ctx echo.Context, storeKey, key, fingerprint string) error {
+	var (
+		serviceName = "Middleware.Idempotency"
+		record      models.IdempotencyRecord
+	)
+
+	value, err := config.GetIdempotencyKey(ctx.Request().Context(), storeKey)
+	if err == nil {
+		err = json.Unmarshal([]byte(value), &record)
+	}
+	if err != nil {
+		utils.LogError(serviceName, key, "GetIdempotencyKey", err)
+		result := helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Unable to process Idempotency-Key. Please try again", nil)
+		return ctx.JSON(http.StatusServiceUnavailable, result)
+	}
+
+	if record.Fingerprint != fingerprint {
+		utils.LogError(serviceName, key, "Replay.Fingerprint", nil, "Idempotency-Key reused with a different payload")
+		result := helpers.ResponseJSON(false, constans.IDEMPOTENCY_KEY_MISMATCH_CODE,
+			"Idempotency-Key has already been used with a different request payload", nil)
+		return ctx.JSON(http.StatusUnprocessableEntity, result)
+	}
+
+	if record.Status != constans.IDEMPOTENCY_STATUS_COMPLETED {
+		utils.LogInfo(serviceName, key, "Replay.InProgress")
+		result := helpers.ResponseJSON(false, constans.IDEMPOTENCY_IN_PROGRESS_CODE,
+			"A request with this Idempotency-Key is still being processed", nil)
+		return ctx.JSON(http.StatusConflict, result)
+	}
+
+	utils.LogInfo(serviceName, key, "Replay.Completed")
+	ctx.Response().Header().Set(constans.IDEMPOTENCY_REPLAY_HEADER, "true")
+	return ctx.Blob(record.StatusCode, record.ContentType, record.Body)
+}
+
+// requestFingerprint hash sha256 dari method, path dan body request
+func requestFingerprint(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,46 @@
+package middlewares
+
+import (
+	"net/http"
+	"sample/metrics"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+// Metrics mencatat durasi dan status setiap request ke histogram Prometheus per route.
+// Route memakai pola Echo (contoh /private/transaction/deposit) agar label tidak meledak
+func Metrics() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			start := time.Now()
+			err := next(ctx)
+
+			metrics.HTTPRequestDuration.WithLabelValues(ctx.Request().Method, routePattern(ctx), strconv.Itoa(responseStatus(ctx, err))).
+				Observe(time.Since(start).Seconds())
+			return err
+		}
+	}
+}
+
+// routePattern pola route Echo yang cocok dengan request, "unmatched" jika tidak ada route
+func routePattern(ctx echo.Context) string {
+	if route := ctx.Path(); route != "" {
+		return route
+	}
+	return "unmatched"
+}
+
+// responseStatus status HTTP yang akan diterima client, termasuk error yang belum ditulis
+// (ditulis HTTPErrorHandler setelah middleware selesai)
+func responseStatus(ctx echo.Context, err error) int {
+	status := ctx.Response().Status
+	if err != nil && !ctx.Response().Committed {
+		status = http.StatusInternalServerError
+		if httpErr, ok := err.(*echo.HTTPError); ok {
+			status = httpErr.Code
+		}
+	}
+	return status
+}
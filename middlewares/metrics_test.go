@@ -0,0 +1,59 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sample/metrics"
+	"testing"
+
+	"github.com/labstack/echo"
+)
+
+// sampleCount jumlah observasi histogram durasi untuk kombinasi label route dan status
+func sampleCount(t *testing.T, route, status string) uint64 {
+	t.Helper()
+
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, family := range families {
+		if family.GetName() != "http_request_duration_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["route"] == route && labels["status"] == status {
+				return metric.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+	return 0
+}
+
+func TestMetricsRecordsRouteAndStatus(t *testing.T) {
+	e := echo.New()
+	e.Use(Metrics())
+	e.GET("/account/:number", func(ctx echo.Context) error {
+		return ctx.NoContent(http.StatusOK)
+	})
+	e.GET("/broken", func(ctx echo.Context) error {
+		return echo.NewHTTPError(http.StatusBadRequest, "broken")
+	})
+
+	for _, path := range []string{"/account/1000000001", "/account/1000000002", "/broken"} {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// Nilai path diganti pola route, dua request ke /account/:number jadi satu seri
+	if count := sampleCount(t, "/account/:number", "200"); count != 2 {
+		t.Errorf("/account/:number 200 = %d, want 2", count)
+	}
+	if count := sampleCount(t, "/broken", "400"); count != 1 {
+		t.Errorf("/broken 400 = %d, want 1", count)
+	}
+}
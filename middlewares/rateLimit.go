@@ -0,0 +1,203 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"sample/config"
+	"sample/constans"
+	"sample/helpers"
+	"sample/metrics"
+	"sample/models"
+	"sample/utils"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+// rateLimitRedisRetry jeda sebelum Redis dicoba lagi setelah gagal, selama itu limiter in-process dipakai
+const rateLimitRedisRetry = 10 * time.Second
+
+// rateLimitStore limiter bersama antar instance, diganti di test
+var rateLimitStore = config.RateLimitHit
+
+// rateLimitRedis status Redis untuk semua grup rate limit
+var rateLimitRedis struct {
+	sync.Mutex
+	retryAt time.Time
+}
+
+// rateLimitBodyMaxBytes batas body yang dibaca key rate limit dari body request
+const rateLimitBodyMaxBytes = 64 << 10
+
+// RateLimitByIP key rate limit berdasarkan IP client dari middleware ClientIP
+// (IP koneksi, X-Forwarded-For hanya dari proxy terpercaya)
+func RateLimitByIP(ctx echo.Context) string {
+	return "ip:" + helpers.GetClientIP(ctx)
+}
+
+// RateLimitByClient key rate limit berdasarkan header X-Client-Id, IP jika header kosong
+func RateLimitByClient(ctx echo.Context) string {
+	if clientID := ctx.Request().Header.Get(constans.CLIENT_ID_HEADER); clientID != "" {
+		return "client:" + clientID
+	}
+	return RateLimitByIP(ctx)
+}
+
+// RateLimitByAccount key rate limit berdasarkan nomor rekening dari access token,
+// dipasang setelah middleware JWT. IP jika request belum terautentikasi
+func RateLimitByAccount(ctx echo.Context) string {
+	if accountNumber := helpers.GetAccountNumber(ctx); accountNumber != "" {
+		return "account:" + accountNumber
+	}
+	return RateLimitByIP(ctx)
+}
+
+// RateLimitByRequestAccount key rate limit berdasarkan account_number di body JSON, untuk endpoint
+// publik yang menyasar satu rekening (login, forgot / reset PIN) sehingga berganti IP tidak menambah
+// kuota. Body dikembalikan utuh untuk handler. IP jika account_number kosong
+func RateLimitByRequestAccount(ctx echo.Context) string {
+	var request struct {
+		AccountNumber string `json:"account_number"`
+	}
+
+	if body, err := peekRequestBody(ctx); err == nil && json.Unmarshal(body, &request) == nil && request.AccountNumber != "" {
+		return "account:" + request.AccountNumber
+	}
+	return RateLimitByIP(ctx)
+}
+
+// RateLimitByRefreshToken key rate limit berdasarkan nomor rekening pada refresh_token di body
+// yang signature-nya valid, sehingga berbagi kuota dengan login rekening yang sama. IP jika
+// token tidak valid
+func RateLimitByRefreshToken(cfg models.JWT) func(echo.Context) string {
+	return func(ctx echo.Context) string {
+		var request struct {
+			RefreshToken string `json:"refresh_token"`
+		}
+
+		if body, err := peekRequestBody(ctx); err == nil && json.Unmarshal(body, &request) == nil && request.RefreshToken != "" {
+			if claims, err := helpers.ParseToken(cfg, request.RefreshToken); err == nil && claims.AccountNumber != "" {
+				return "account:" + claims.AccountNumber
+			}
+		}
+		return RateLimitByIP(ctx)
+	}
+}
+
+// peekRequestBody baca body (maksimal rateLimitBodyMaxBytes) lalu kembalikan utuh untuk handler
+func peekRequestBody(ctx echo.Context) ([]byte, error) {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(ctx.Response(), ctx.Request().Body, rateLimitBodyMaxBytes))
+	ctx.Request().Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, err
+}
+
+// RateLimit membatasi request grup route dengan sliding window di Redis per key(ctx).
+// Request yang melewati batas ditolak 429 dengan header Retry-After. Jika Redis tidak bisa
+// dipakai, hitungan pindah ke limiter in-process (batas berlaku per instance)
+func RateLimit(group string, limit models.RateLimit, key func(echo.Context) string) echo.MiddlewareFunc {
+	local := newLocalLimiter()
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			bucket := group + ":" + key(ctx)
+			result, backend := hitRateLimit(ctx.Request().Context(), bucket, limit, local)
+
+			header := ctx.Response().Header()
+			header.Set(constans.RATE_LIMIT_LIMIT_HEADER, strconv.Itoa(limit.Limit))
+			header.Set(constans.RATE_LIMIT_REMAINING_HEADER, strconv.Itoa(result.Remaining))
+
+			if !result.Allowed {
+				metrics.RateLimitedTotal.WithLabelValues(group, backend).Inc()
+				header.Set(constans.RETRY_AFTER_HEADER, strconv.Itoa(int(math.Max(1, math.Ceil(result.RetryAfter.Seconds())))))
+
+				result := helpers.ResponseJSON(false, constans.TOO_MANY_REQUESTS_CODE, "Too many requests. Please try again later", nil)
+				return ctx.JSON(http.StatusTooManyRequests, result)
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// hitRateLimit catat request di Redis, atau di limiter in-process jika Redis sedang gagal.
+// Backend yang dipakai dikembalikan untuk label metrics
+func hitRateLimit(ctx context.Context, bucket string, limit models.RateLimit, local *localLimiter) (models.RateLimitResult, string) {
+	serviceName := "Middleware.RateLimit"
+
+	rateLimitRedis.Lock()
+	useRedis := time.Now().After(rateLimitRedis.retryAt)
+	rateLimitRedis.Unlock()
+
+	if useRedis {
+		result, err := rateLimitStore(ctx, bucket, limit.Limit, limit.Window)
+		if err == nil {
+			return result, "redis"
+		}
+
+		rateLimitRedis.Lock()
+		if time.Now().After(rateLimitRedis.retryAt) {
+			// Log sekali per jeda retry agar Redis mati tidak membanjiri log
+			utils.LogError(serviceName, bucket, "RateLimitHit", err, "Falling back to in-process limiter")
+			rateLimitRedis.retryAt = time.Now().Add(rateLimitRedisRetry)
+		}
+		rateLimitRedis.Unlock()
+	}
+
+	return local.Hit(bucket, limit, time.Now()), "local"
+}
+
+// localLimiter sliding window log in-process, cadangan saat Redis tidak tersedia
+type localLimiter struct {
+	mu        sync.Mutex
+	hits      map[string][]time.Time
+	lastSweep time.Time
+}
+
+func newLocalLimiter() *localLimiter {
+	return &localLimiter{hits: map[string][]time.Time{}}
+}
+
+// Hit mencatat satu request untuk key pada waktu now
+func (l *localLimiter) Hit(key string, limit models.RateLimit, now time.Time) models.RateLimitResult {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now, limit.Window)
+
+	cutoff := now.Add(-limit.Window)
+	hits := l.hits[key]
+	expired := 0
+	for expired < len(hits) && !hits[expired].After(cutoff) {
+		expired++
+	}
+	hits = hits[expired:]
+
+	if len(hits) >= limit.Limit {
+		l.hits[key] = hits
+		return models.RateLimitResult{RetryAfter: hits[0].Add(limit.Window).Sub(now)}
+	}
+
+	l.hits[key] = append(hits, now)
+	return models.RateLimitResult{Allowed: true, Remaining: limit.Limit - len(hits) - 1}
+}
+
+// sweep menghapus key yang tidak punya request di jendela terakhir, paling sering sekali per jendela
+func (l *localLimiter) sweep(now time.Time, window time.Duration) {
+	if now.Sub(l.lastSweep) < window {
+		return
+	}
+	l.lastSweep = now
+
+	cutoff := now.Add(-window)
+	for key, hits := range l.hits {
+		if len(hits) == 0 || !hits[len(hits)-1].After(cutoff) {
+			delete(l.hits, key)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sample/constans"
+	"sample/helpers"
+	"sample/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+func TestLocalLimiterSlidingWindow(t *testing.T) {
+	limiter := newLocalLimiter()
+	limit := models.RateLimit{Limit: 2, Window: time.Minute}
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	if result := limiter.Hit("ip:1", limit, start); !result.Allowed || result.Remaining != 1 {
+		t.Fatalf("first hit = %+v, want allowed with 1 remaining", result)
+	}
+	limiter.Hit("ip:1", limit, start.Add(30*time.Second))
+
+	result := limiter.Hit("ip:1", limit, start.Add(40*time.Second))
+	if result.Allowed || result.RetryAfter != 20*time.Second {
+		t.Fatalf("third hit = %+v, want rejected until the first hit leaves the window", result)
+	}
+
+	// Key lain punya jendela sendiri
+	if result := limiter.Hit("ip:2", limit, start.Add(40*time.Second)); !result.Allowed {
+		t.Errorf("other key = %+v, want allowed", result)
+	}
+
+	// Hit pertama keluar jendela, satu slot kembali tersedia
+	if result := limiter.Hit("ip:1", limit, start.Add(61*time.Second)); !result.Allowed || result.Remaining != 0 {
+		t.Errorf("hit after window = %+v, want allowed with 0 remaining", result)
+	}
+}
+
+func TestRateLimitFallsBackWhenRedisFails(t *testing.T) {
+	calls := 0
+	store := rateLimitStore
+	rateLimitStore = func(ctx context.Context, key string, limit int, window time.Duration) (models.RateLimitResult, error) {
+		calls++
+		return models.RateLimitResult{}, errors.New("connection refused")
+	}
+	defer func() {
+		rateLimitStore = store
+		rateLimitRedis.retryAt = time.Time{}
+	}()
+
+	e := echo.New()
+	e.Use(RateLimit("public", models.RateLimit{Limit: 2, Window: time.Minute}, RateLimitByIP))
+	e.POST("/public/account/forgot-pin", func(ctx echo.Context) error {
+		return ctx.NoContent(http.StatusOK)
+	})
+
+	var response *httptest.ResponseRecorder
+	for i := 0; i < 3; i++ {
+		request := httptest.NewRequest(http.MethodPost, "/public/account/forgot-pin", nil)
+		request.RemoteAddr = "10.0.0.1:40000"
+		response = httptest.NewRecorder()
+		e.ServeHTTP(response, request)
+	}
+
+	if calls != 1 {
+		t.Errorf("redis calls = %d, want 1 (skipped until retry after the first failure)", calls)
+	}
+	if response.Code != http.StatusTooManyRequests {
+		t.Fatalf("third request status = %d, want 429", response.Code)
+	}
+	if retryAfter := response.Header().Get(constans.RETRY_AFTER_HEADER); retryAfter != "60" {
+		t.Errorf("%s = %q, want 60", constans.RETRY_AFTER_HEADER, retryAfter)
+	}
+
+	var body models.Response
+	if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Success || body.StatusCode != constans.TOO_MANY_REQUESTS_CODE {
+		t.Errorf("body = %+v, want failed envelope with code %s", body, constans.TOO_MANY_REQUESTS_CODE)
+	}
+}
+
+func TestClientIPTrustsForwardedForOnlyFromProxies(t *testing.T) {
+	e := echo.New()
+	e.Use(ClientIP("10.0.0.0/8, 192.168.1.10"))
+	e.GET("/ip", func(ctx echo.Context) error {
+		return ctx.String(http.StatusOK, RateLimitByIP(ctx))
+	})
+
+	tests := []struct {
+		name          string
+		remoteAddr    string
+		forwardedFor  string
+		wantBucketKey string
+	}{
+		{"direct client spoofing header", "203.0.113.7:5000", "198.51.100.1", "ip:203.0.113.7"},
+		{"trusted proxy", "10.1.2.3:5000", "198.51.100.1", "ip:198.51.100.1"},
+		{"client prepends fake hop", "10.1.2.3:5000", "1.1.1.1, 198.51.100.1", "ip:198.51.100.1"},
+		{"proxy chain", "192.168.1.10:5000", "198.51.100.1, 10.9.9.9", "ip:198.51.100.1"},
+		{"trusted proxy without header", "10.1.2.3:5000", "", "ip:10.1.2.3"},
+	}
+
+	for _, tt := range tests {
+		request := httptest.NewRequest(http.MethodGet, "/ip", nil)
+		request.RemoteAddr = tt.remoteAddr
+		if tt.forwardedFor != "" {
+			request.Header.Set(echo.HeaderXForwardedFor, tt.forwardedFor)
+		}
+		response := httptest.NewRecorder()
+		e.ServeHTTP(response, request)
+
+		if got := response.Body.String(); got != tt.wantBucketKey {
+			t.Errorf("%s: key = %q, want %q", tt.name, got, tt.wantBucketKey)
+		}
+	}
+}
+
+func TestRateLimitByRequestAccountAcrossIPs(t *testing.T) {
+	store := rateLimitStore
+	rateLimitStore = func(ctx context.Context, key string, limit int, window time.Duration) (models.RateLimitResult, error) {
+		return models.RateLimitResult{}, errors.New("connection refused")
+	}
+	defer func() {
+		rateLimitStore = store
+		rateLimitRedis.retryAt = time.Time{}
+	}()
+
+	var body string
+	e := echo.New()
+	e.POST("/public/account/forgot-pin", func(ctx echo.Context) error {
+		payload, _ := ioutil.ReadAll(ctx.Request().Body)
+		body = string(payload)
+		return ctx.NoContent(http.StatusOK)
+	}, RateLimit("pin-reset", models.RateLimit{Limit: 2, Window: time.Minute}, RateLimitByRequestAccount))
+
+	send := func(remoteAddr, accountNumber string) int {
+		payload := `{"account_number":"` + accountNumber + `"}`
+		request := httptest.NewRequest(http.MethodPost, "/public/account/forgot-pin", strings.NewReader(payload))
+		request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		request.RemoteAddr = remoteAddr
+		response := httptest.NewRecorder()
+		e.ServeHTTP(response, request)
+		return response.Code
+	}
+
+	send("203.0.113.1:5000", "1000000001")
+	if body != `{"account_number":"1000000001"}` {
+		t.Errorf("handler body = %q, want original request body", body)
+	}
+	send("203.0.113.2:5000", "1000000001")
+
+	if code := send("203.0.113.3:5000", "1000000001"); code != http.StatusTooManyRequests {
+		t.Errorf("third request from new IP = %d, want 429", code)
+	}
+	if code := send("203.0.113.3:5000", "1000000002"); code != http.StatusOK {
+		t.Errorf("other account = %d, want 200", code)
+	}
+}
+
+// Login dan refresh rekening yang sama berbagi kuota, token palsu jatuh ke key IP
+func TestRateLimitLoginAndRefreshShareAccountQuota(t *testing.T) {
+	// Store bersama seperti Redis: bucket yang sama dari dua middleware berbagi hitungan
+	shared := newLocalLimiter()
+	store := rateLimitStore
+	rateLimitStore = func(ctx context.Context, key string, limit int, window time.Duration) (models.RateLimitResult, error) {
+		return shared.Hit(key, models.RateLimit{Limit: limit, Window: window}, time.Now()), nil
+	}
+	defer func() { rateLimitStore = store }()
+
+	cfg := models.JWT{Key: "test-key"}
+	refreshToken, _, _, err := helpers.GenerateToken(cfg, "1000000001", constans.ROLE_CUSTOMER, constans.TOKEN_TYPE_REFRESH, time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+
+	limit := models.RateLimit{Limit: 2, Window: time.Minute}
+	ok := func(ctx echo.Context) error { return ctx.NoContent(http.StatusOK) }
+
+	e := echo.New()
+	e.POST("/login", ok, RateLimit("login", limit, RateLimitByRequestAccount))
+	e.POST("/refresh", ok, RateLimit("login", limit, RateLimitByRefreshToken(cfg)))
+
+	send := func(path, remoteAddr, payload string) int {
+		request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(payload))
+		request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		request.RemoteAddr = remoteAddr
+		response := httptest.NewRecorder()
+		e.ServeHTTP(response, request)
+		return response.Code
+	}
+
+	send("/login", "203.0.113.1:5000", `{"account_number":"1000000001","pin":"000000"}`)
+	send("/login", "203.0.113.2:5000", `{"account_number":"1000000001","pin":"000001"}`)
+
+	if code := send("/login", "203.0.113.3:5000", `{"account_number":"1000000001","pin":"000002"}`); code != http.StatusTooManyRequests {
+		t.Errorf("login from new IP = %d, want 429", code)
+	}
+	if code := send("/refresh", "203.0.113.3:5000", `{"refresh_token":"`+refreshToken+`"}`); code != http.StatusTooManyRequests {
+		t.Errorf("refresh after login quota used = %d, want 429", code)
+	}
+	if code := send("/refresh", "203.0.113.3:5000", `{"refresh_token":"forged"}`); code != http.StatusOK {
+		t.Errorf("refresh with invalid token = %d, want 200 (keyed by IP)", code)
+	}
+}
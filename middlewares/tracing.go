@@ -0,0 +1,43 @@
+package middlewares
+
+import (
+	"sample/tracing"
+
+	"github.com/labstack/echo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing membuat span server per request. Header traceparent dari client dipakai sebagai parent,
+// span disimpan di context request (ctx.Request().Context()) dan trace ID dikembalikan di header X-Trace-Id
+func Tracing() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			request := ctx.Request()
+			route := routePattern(ctx)
+
+			parent := otel.GetTextMapPropagator().Extract(request.Context(), propagation.HeaderCarrier(request.Header))
+			spanCtx, span := tracing.Start(parent, request.Method+" "+route)
+			defer span.End()
+
+			span.SetAttributes(semconv.HTTPServerAttributesFromHTTPRequest("", route, request)...)
+			ctx.SetRequest(request.WithContext(spanCtx))
+
+			if traceID := tracing.TraceID(spanCtx); traceID != "" {
+				ctx.Response().Header().Set(tracing.TraceIDHeader, traceID)
+			}
+
+			err := next(ctx)
+
+			status := responseStatus(ctx, err)
+			span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(status)...)
+			span.SetStatus(semconv.SpanStatusFromHTTPStatusCodeAndSpanKind(status, trace.SpanKindServer))
+			if err != nil {
+				span.RecordError(err)
+			}
+			return err
+		}
+	}
+}
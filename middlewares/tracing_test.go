@@ -0,0 +1,54 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sample/tracing"
+	"testing"
+
+	"github.com/labstack/echo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracingContinuesIncomingTrace(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	e := echo.New()
+	e.Use(Tracing())
+	e.GET("/account/:number", func(ctx echo.Context) error {
+		_, span := tracing.Start(ctx.Request().Context(), "AccountRepository.FindAccountByNumber")
+		span.End()
+		return ctx.NoContent(http.StatusOK)
+	})
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	request := httptest.NewRequest(http.MethodGet, "/account/1000000001", nil)
+	request.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	response := httptest.NewRecorder()
+	e.ServeHTTP(response, request)
+
+	if got := response.Header().Get(tracing.TraceIDHeader); got != traceID {
+		t.Errorf("%s = %q, want %q", tracing.TraceIDHeader, got, traceID)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("ended spans = %d, want 2", len(spans))
+	}
+
+	child, server := spans[0], spans[1]
+	if server.Name() != "GET /account/:number" {
+		t.Errorf("server span name = %q, want route pattern", server.Name())
+	}
+	if server.Parent().SpanID().String() != "00f067aa0ba902b7" || !server.Parent().IsRemote() {
+		t.Errorf("server span parent = %s, want remote span from traceparent", server.Parent().SpanID())
+	}
+	if child.Parent().SpanID() != server.SpanContext().SpanID() || child.SpanContext().TraceID().String() != traceID {
+		t.Errorf("child span is not a child of the server span")
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sample/config"
+	"sample/constans"
+	"sample/migrations"
+	"strconv"
+	"text/tabwriter"
+)
+
+const migrateUsage = `Usage: sample migrate <command>
+
+Commands:
+  up            apply all pending migrations
+  down [n]      roll back the last n applied migrations (default 1)
+  status        show applied and pending migrations
+  to <version>  migrate up or down to exactly <version>, 0 rolls back everything`
+
+// runMigrate subcommand `migrate`, mengembalikan exit code proses
+func runMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Println(migrateUsage)
+		return 2
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("Load Config Failed: %s\n", err.Error())
+		return 1
+	}
+
+	if err := config.OpenConnection(cfg); err != nil {
+		fmt.Printf("Open Connection Failed: %s\n", err.Error())
+		return 1
+	}
+	defer config.CloseConnectionDB()
+
+	migrator, err := migrations.NewMigrator(config.DBConnection())
+	if err != nil {
+		fmt.Printf("Load Migrations Failed: %s\n", err.Error())
+		return 1
+	}
+
+	var done []migrations.Migration
+	switch args[0] {
+	case "up":
+		done, err = migrator.Up()
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps <= 0 {
+				fmt.Printf("Invalid step count %q\n", args[1])
+				return 2
+			}
+		}
+		done, err = migrator.Down(steps)
+	case "to":
+		if len(args) < 2 {
+			fmt.Println(migrateUsage)
+			return 2
+		}
+		version, convErr := strconv.Atoi(args[1])
+		if convErr != nil || version < 0 {
+			fmt.Printf("Invalid version %q\n", args[1])
+			return 2
+		}
+		done, err = migrator.To(version)
+	case "status":
+		return printMigrationStatus(migrator)
+	default:
+		fmt.Println(migrateUsage)
+		return 2
+	}
+
+	for _, migration := range done {
+		fmt.Printf("%s %04d_%s\n", args[0], migration.Version, migration.Name)
+	}
+	if err != nil {
+		fmt.Printf("Migration Failed: %s\n", err.Error())
+		return 1
+	}
+	if len(done) == 0 {
+		fmt.Println("No migrations to run")
+	}
+
+	return 0
+}
+
+// printMigrationStatus mencetak status tiap versi migrasi dalam bentuk tabel
+func printMigrationStatus(migrator migrations.Migrator) int {
+	statuses, err := migrator.Status()
+	if err != nil {
+		fmt.Printf("Migration Status Failed: %s\n", err.Error())
+		return 1
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, status := range statuses {
+		state, appliedAt := "pending", "-"
+		if status.Applied {
+			state, appliedAt = "applied", status.AppliedAt.Format(constans.LAYOUT_TIMESTAMP)
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+	}
+	w.Flush()
+
+	return 0
+}
@@ -0,0 +1,52 @@
+-- Tabel inti rekening dan mutasi. IF NOT EXISTS agar database lama yang
+-- dibuat manual bisa mengadopsi migrasi tanpa kehilangan data
+CREATE TABLE IF NOT EXISTS account (
+    id                  SERIAL PRIMARY KEY,
+    account_number      VARCHAR(20)   NOT NULL,
+    balance             NUMERIC(20,2) NOT NULL DEFAULT 0,
+    held_balance        NUMERIC(20,2) NOT NULL DEFAULT 0,
+    pin                 VARCHAR(255)  NOT NULL,
+    account_name        VARCHAR(255)  NOT NULL,
+    account_status      VARCHAR(20)   NOT NULL DEFAULT 'ACTIVE',
+    account_tier        VARCHAR(20)   NOT NULL DEFAULT 'BASIC',
+    failed_pin_attempts INTEGER       NOT NULL DEFAULT 0,
+    created_at          TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    deleted_at          TIMESTAMPTZ   NULL
+);
+
+ALTER TABLE account ADD COLUMN IF NOT EXISTS held_balance NUMERIC(20,2) NOT NULL DEFAULT 0;
+ALTER TABLE account ADD COLUMN IF NOT EXISTS account_status VARCHAR(20) NOT NULL DEFAULT 'ACTIVE';
+ALTER TABLE account ADD COLUMN IF NOT EXISTS account_tier VARCHAR(20) NOT NULL DEFAULT 'BASIC';
+ALTER TABLE account ADD COLUMN IF NOT EXISTS failed_pin_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE account ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS account_account_number_key ON account (account_number);
+
+CREATE TABLE IF NOT EXISTS transaction (
+    id                 SERIAL PRIMARY KEY,
+    account_id         INTEGER       NOT NULL REFERENCES account (id),
+    account_number     VARCHAR(20)   NOT NULL,
+    account_name       VARCHAR(255)  NOT NULL,
+    source_number      VARCHAR(20)   NULL,
+    beneficiary_number VARCHAR(20)   NULL,
+    transaction_type   CHAR(1)       NOT NULL CHECK (transaction_type IN ('D', 'C')),
+    amount             NUMERIC(20,2) NOT NULL,
+    fee                NUMERIC(20,2) NOT NULL DEFAULT 0,
+    currency           VARCHAR(3)    NOT NULL DEFAULT 'IDR',
+    status             VARCHAR(20)   NOT NULL DEFAULT 'SUCCESS',
+    reversal_of        INTEGER       NULL REFERENCES transaction (id),
+    transaction_time   TIMESTAMPTZ   NOT NULL,
+    created_at         TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    deleted_at         TIMESTAMPTZ   NULL
+);
+
+ALTER TABLE transaction ADD COLUMN IF NOT EXISTS fee NUMERIC(20,2) NOT NULL DEFAULT 0;
+ALTER TABLE transaction ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'IDR';
+ALTER TABLE transaction ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'SUCCESS';
+ALTER TABLE transaction ADD COLUMN IF NOT EXISTS reversal_of INTEGER NULL REFERENCES transaction (id);
+ALTER TABLE transaction ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ NULL;
+
+CREATE INDEX IF NOT EXISTS transaction_account_number_time_idx ON transaction (account_number, transaction_time);
+CREATE INDEX IF NOT EXISTS transaction_account_id_time_idx ON transaction (account_id, transaction_time);
+CREATE INDEX IF NOT EXISTS transaction_reversal_of_idx ON transaction (reversal_of);
@@ -0,0 +1,4 @@
+ALTER TABLE transaction DROP COLUMN IF EXISTS journal_id;
+DROP TABLE IF EXISTS journal_posting;
+DROP TABLE IF EXISTS journal_entry;
+DROP TABLE IF EXISTS ledger_account;
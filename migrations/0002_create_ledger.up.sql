@@ -0,0 +1,58 @@
+-- Buku besar double-entry: akun sistem, header jurnal dan posting debit/kredit.
+-- ledger_code posting berisi nomor rekening nasabah atau kode akun sistem, tanpa foreign key
+CREATE TABLE IF NOT EXISTS ledger_account (
+    code           VARCHAR(50)  PRIMARY KEY,
+    name           VARCHAR(255) NOT NULL,
+    account_type   VARCHAR(20)  NOT NULL,
+    normal_balance CHAR(1)      NOT NULL CHECK (normal_balance IN ('D', 'C')),
+    created_at     TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS journal_entry (
+    id           SERIAL PRIMARY KEY,
+    reference_no VARCHAR(64) NOT NULL,
+    journal_type VARCHAR(20) NOT NULL,
+    description  TEXT        NOT NULL DEFAULT '',
+    reversal_of  INTEGER     NULL REFERENCES journal_entry (id),
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE journal_entry ADD COLUMN IF NOT EXISTS reversal_of INTEGER NULL REFERENCES journal_entry (id);
+
+-- Tidak unik: GenerateReferenceNo hanya menambah 2 karakter acak per detik
+CREATE INDEX IF NOT EXISTS journal_entry_reference_no_idx ON journal_entry (reference_no);
+
+CREATE TABLE IF NOT EXISTS journal_posting (
+    id          SERIAL PRIMARY KEY,
+    journal_id  INTEGER       NOT NULL REFERENCES journal_entry (id),
+    ledger_code VARCHAR(50)   NOT NULL,
+    direction   CHAR(1)       NOT NULL CHECK (direction IN ('D', 'C')),
+    amount      NUMERIC(20,2) NOT NULL CHECK (amount > 0),
+    created_at  TIMESTAMPTZ   NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS journal_posting_journal_id_idx ON journal_posting (journal_id);
+CREATE INDEX IF NOT EXISTS journal_posting_ledger_code_idx ON journal_posting (ledger_code);
+
+ALTER TABLE transaction ADD COLUMN IF NOT EXISTS journal_id INTEGER NULL REFERENCES journal_entry (id);
+CREATE INDEX IF NOT EXISTS transaction_journal_id_idx ON transaction (journal_id);
+
+-- Jurnal saldo awal untuk rekening yang sudah punya saldo sebelum ada buku besar,
+-- agar cek rekonsiliasi (GetLedgerMismatches) tidak langsung selisih
+INSERT INTO ledger_account (code, name, account_type, normal_balance)
+VALUES ('CASH_VAULT', 'Kas Teller', 'ASSET', 'D')
+ON CONFLICT (code) DO NOTHING;
+
+WITH opening AS (
+    INSERT INTO journal_entry (reference_no, journal_type, description)
+    SELECT 'MIGRATION-' || a.account_number, 'OPENING', 'Saldo Awal Migrasi ' || a.account_number
+    FROM account a
+    WHERE a.balance > 0
+      AND NOT EXISTS (SELECT 1 FROM journal_posting p WHERE p.ledger_code = a.account_number)
+    RETURNING id, substring(reference_no FROM 11) AS account_number
+)
+INSERT INTO journal_posting (journal_id, ledger_code, direction, amount)
+SELECT o.id, l.ledger_code, l.direction, a.balance
+FROM opening o
+JOIN account a ON a.account_number = o.account_number
+CROSS JOIN LATERAL (VALUES ('CASH_VAULT', 'D'), (a.account_number, 'C')) AS l (ledger_code, direction);
@@ -0,0 +1,39 @@
+-- Transfer terjadwal dan riwayat eksekusinya. locked_by/locked_until dipakai worker
+-- untuk klaim jadwal (lease) agar satu jadwal tidak dieksekusi dua instance
+CREATE TABLE IF NOT EXISTS scheduled_transfer (
+    id                  SERIAL PRIMARY KEY,
+    account_id          INTEGER       NOT NULL REFERENCES account (id),
+    from_account_number VARCHAR(20)   NOT NULL,
+    to_account_number   VARCHAR(20)   NOT NULL,
+    amount              NUMERIC(20,2) NOT NULL CHECK (amount > 0),
+    frequency           VARCHAR(20)   NOT NULL,
+    description         TEXT          NOT NULL DEFAULT '',
+    start_date          DATE          NOT NULL,
+    end_date            DATE          NULL,
+    next_run_at         TIMESTAMPTZ   NOT NULL,
+    retry_at            TIMESTAMPTZ   NULL,
+    retry_count         INTEGER       NOT NULL DEFAULT 0,
+    status              VARCHAR(20)   NOT NULL DEFAULT 'ACTIVE',
+    last_run_at         TIMESTAMPTZ   NULL,
+    locked_by           VARCHAR(100)  NULL,
+    locked_until        TIMESTAMPTZ   NULL,
+    created_at          TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    deleted_at          TIMESTAMPTZ   NULL
+);
+
+CREATE INDEX IF NOT EXISTS scheduled_transfer_from_account_number_idx ON scheduled_transfer (from_account_number);
+CREATE INDEX IF NOT EXISTS scheduled_transfer_due_idx ON scheduled_transfer (status, (COALESCE(retry_at, next_run_at)));
+
+CREATE TABLE IF NOT EXISTS scheduled_transfer_execution (
+    id            SERIAL PRIMARY KEY,
+    schedule_id   INTEGER      NOT NULL REFERENCES scheduled_transfer (id),
+    scheduled_for TIMESTAMPTZ  NOT NULL,
+    attempt       INTEGER      NOT NULL,
+    status        VARCHAR(20)  NOT NULL,
+    reference_no  VARCHAR(64)  NOT NULL DEFAULT '',
+    message       TEXT         NOT NULL DEFAULT '',
+    executed_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS scheduled_transfer_execution_schedule_id_idx ON scheduled_transfer_execution (schedule_id, executed_at);
@@ -0,0 +1,14 @@
+-- Limit transaksi per tier rekening, nilai 0 berarti tanpa batas.
+-- Baris default diisi aplikasi saat start (EnsureDefaultTransactionLimits)
+CREATE TABLE IF NOT EXISTS transaction_limit (
+    id                 SERIAL PRIMARY KEY,
+    tier               VARCHAR(20)   NOT NULL,
+    transaction_type   VARCHAR(20)   NOT NULL,
+    max_single_amount  NUMERIC(20,2) NOT NULL DEFAULT 0,
+    max_daily_count    INTEGER       NOT NULL DEFAULT 0,
+    max_daily_amount   NUMERIC(20,2) NOT NULL DEFAULT 0,
+    max_monthly_amount NUMERIC(20,2) NOT NULL DEFAULT 0,
+    created_at         TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    UNIQUE (tier, transaction_type)
+);
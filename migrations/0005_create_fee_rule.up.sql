@@ -0,0 +1,13 @@
+-- Aturan biaya per jenis transaksi. tiers berisi daftar tier biaya dalam JSON
+CREATE TABLE IF NOT EXISTS fee_rule (
+    transaction_type   VARCHAR(20)   PRIMARY KEY,
+    fee_type           VARCHAR(20)   NOT NULL,
+    flat_amount        NUMERIC(20,2) NOT NULL DEFAULT 0,
+    percentage_bps     BIGINT        NOT NULL DEFAULT 0,
+    min_fee            NUMERIC(20,2) NOT NULL DEFAULT 0,
+    max_fee            NUMERIC(20,2) NOT NULL DEFAULT 0,
+    tiers              JSONB         NOT NULL DEFAULT '[]',
+    free_quota_monthly INTEGER       NOT NULL DEFAULT 0,
+    created_at         TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ   NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,20 @@
+-- Dana yang ditahan (hold) dari saldo rekening sampai di-capture, dilepas atau kedaluwarsa
+CREATE TABLE IF NOT EXISTS account_hold (
+    id                 SERIAL PRIMARY KEY,
+    account_id         INTEGER       NOT NULL REFERENCES account (id),
+    account_number     VARCHAR(20)   NOT NULL,
+    beneficiary_number VARCHAR(20)   NOT NULL DEFAULT '',
+    amount             NUMERIC(20,2) NOT NULL CHECK (amount > 0),
+    captured_amount    NUMERIC(20,2) NOT NULL DEFAULT 0,
+    status             VARCHAR(20)   NOT NULL,
+    description        TEXT          NOT NULL DEFAULT '',
+    reference_no       VARCHAR(64)   NULL,
+    expires_at         TIMESTAMPTZ   NOT NULL,
+    closed_at          TIMESTAMPTZ   NULL,
+    created_at         TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ   NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS account_hold_account_number_idx ON account_hold (account_number);
+CREATE INDEX IF NOT EXISTS account_hold_beneficiary_number_idx ON account_hold (beneficiary_number);
+CREATE INDEX IF NOT EXISTS account_hold_status_expires_at_idx ON account_hold (status, expires_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS fx_rate;
+DROP TABLE IF EXISTS account_pocket;
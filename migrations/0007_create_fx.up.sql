@@ -0,0 +1,22 @@
+-- Kantong valas per rekening dan kurs. Saldo IDR tetap di account.balance
+CREATE TABLE IF NOT EXISTS account_pocket (
+    id         SERIAL PRIMARY KEY,
+    account_id INTEGER       NOT NULL REFERENCES account (id),
+    currency   VARCHAR(3)    NOT NULL,
+    balance    NUMERIC(20,2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    UNIQUE (account_id, currency)
+);
+
+CREATE TABLE IF NOT EXISTS fx_rate (
+    id             SERIAL PRIMARY KEY,
+    base_currency  VARCHAR(3)    NOT NULL,
+    quote_currency VARCHAR(3)    NOT NULL,
+    rate           NUMERIC(20,8) NOT NULL CHECK (rate > 0),
+    spread_bps     BIGINT        NOT NULL DEFAULT 0,
+    effective_at   TIMESTAMPTZ   NOT NULL,
+    created_at     TIMESTAMPTZ   NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS fx_rate_pair_effective_at_idx ON fx_rate (base_currency, quote_currency, effective_at);
@@ -0,0 +1,55 @@
+-- Webhook partner: langganan, outbox event (ditulis dalam tx bisnis), antrean pengiriman
+-- per langganan dan log setiap percobaan kirim
+CREATE TABLE IF NOT EXISTS webhook_subscription (
+    id           SERIAL PRIMARY KEY,
+    partner_name VARCHAR(255) NOT NULL,
+    url          TEXT         NOT NULL,
+    secret       VARCHAR(255) NOT NULL,
+    event_types  TEXT[]       NOT NULL DEFAULT '{}',
+    is_active    BOOLEAN      NOT NULL DEFAULT TRUE,
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS webhook_outbox (
+    id            SERIAL PRIMARY KEY,
+    event_id      VARCHAR(64) NOT NULL UNIQUE,
+    event_type    VARCHAR(50) NOT NULL,
+    payload       JSONB       NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    dispatched_at TIMESTAMPTZ NULL
+);
+
+CREATE INDEX IF NOT EXISTS webhook_outbox_pending_idx ON webhook_outbox (id) WHERE dispatched_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS webhook_delivery (
+    id               SERIAL PRIMARY KEY,
+    outbox_id        INTEGER      NOT NULL REFERENCES webhook_outbox (id),
+    subscription_id  INTEGER      NOT NULL REFERENCES webhook_subscription (id),
+    status           VARCHAR(20)  NOT NULL,
+    attempts         INTEGER      NOT NULL DEFAULT 0,
+    next_attempt_at  TIMESTAMPTZ  NOT NULL,
+    last_status_code INTEGER      NULL,
+    last_error       TEXT         NULL,
+    delivered_at     TIMESTAMPTZ  NULL,
+    locked_by        VARCHAR(100) NULL,
+    locked_until     TIMESTAMPTZ  NULL,
+    created_at       TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    UNIQUE (outbox_id, subscription_id)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_due_idx ON webhook_delivery (status, next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempt (
+    id            SERIAL PRIMARY KEY,
+    delivery_id   INTEGER     NOT NULL REFERENCES webhook_delivery (id),
+    attempt       INTEGER     NOT NULL,
+    status_code   INTEGER     NOT NULL DEFAULT 0,
+    response_body TEXT        NOT NULL DEFAULT '',
+    error         TEXT        NOT NULL DEFAULT '',
+    duration_ms   BIGINT      NOT NULL DEFAULT 0,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_attempt_delivery_id_idx ON webhook_delivery_attempt (delivery_id);
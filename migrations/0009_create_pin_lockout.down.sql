@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS account_pin_lock_event;
+
+ALTER TABLE account DROP COLUMN IF EXISTS locked_until;
+ALTER TABLE account DROP COLUMN IF EXISTS pin_lockout_count;
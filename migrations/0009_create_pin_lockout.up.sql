@@ -0,0 +1,18 @@
+-- Kebijakan blokir PIN: kunci sementara bertingkat sampai locked_until, jumlah kunci
+-- sementara berturut-turut sejak PIN terakhir benar, dan riwayat kunci/buka kunci per akun
+ALTER TABLE account ADD COLUMN IF NOT EXISTS pin_lockout_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE account ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ NULL;
+
+CREATE TABLE IF NOT EXISTS account_pin_lock_event (
+    id              SERIAL PRIMARY KEY,
+    account_number  VARCHAR(20)  NOT NULL,
+    event_type      VARCHAR(20)  NOT NULL,
+    failed_attempts INTEGER      NOT NULL DEFAULT 0,
+    lockout_count   INTEGER      NOT NULL DEFAULT 0,
+    locked_until    TIMESTAMPTZ  NULL,
+    actor           VARCHAR(64)  NOT NULL,
+    reason          VARCHAR(255) NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS account_pin_lock_event_account_number_idx ON account_pin_lock_event (account_number, created_at DESC);
@@ -0,0 +1,2 @@
+ALTER TABLE account DROP COLUMN IF EXISTS email;
+ALTER TABLE account DROP COLUMN IF EXISTS phone_number;
@@ -0,0 +1,3 @@
+-- Kontak nasabah untuk pengiriman kode reset PIN (SMS / email), opsional untuk akun lama
+ALTER TABLE account ADD COLUMN IF NOT EXISTS phone_number VARCHAR(20) NULL;
+ALTER TABLE account ADD COLUMN IF NOT EXISTS email VARCHAR(255) NULL;
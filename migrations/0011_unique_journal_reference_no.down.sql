@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS journal_entry_reference_no_key;
+CREATE INDEX IF NOT EXISTS journal_entry_reference_no_idx ON journal_entry (reference_no);
@@ -0,0 +1,12 @@
+-- reference_no jurnal unik: jurnal dicari lewat reference_no (FindJournalByReferenceNo).
+-- Duplikat lama dari generator 2 karakter acak diberi akhiran id agar index bisa dibuat,
+-- jurnal dengan id terkecil mempertahankan reference_no aslinya
+UPDATE journal_entry j
+SET reference_no = j.reference_no || '-' || j.id
+WHERE EXISTS (
+    SELECT 1 FROM journal_entry o
+    WHERE o.reference_no = j.reference_no AND o.id < j.id
+);
+
+DROP INDEX IF EXISTS journal_entry_reference_no_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS journal_entry_reference_no_key ON journal_entry (reference_no);
@@ -0,0 +1 @@
+ALTER TABLE journal_posting DROP COLUMN IF EXISTS currency;
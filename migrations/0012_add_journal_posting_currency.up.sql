@@ -0,0 +1,8 @@
+-- Mata uang per posting: jurnal FX_CONVERSION berisi leg beberapa mata uang dan
+-- invariant debit = kredit harus dicek per mata uang. Posting lama diisi dari ledger_code
+-- (pocket valas 1000000001.USD, posisi valas FX_POSITION_USD), sisanya IDR
+ALTER TABLE journal_posting ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'IDR';
+
+UPDATE journal_posting
+SET currency = substring(ledger_code FROM '(?:\.|^FX_POSITION_)([A-Z]{3})$')
+WHERE ledger_code ~ '(\.|^FX_POSITION_)[A-Z]{3}$';
@@ -0,0 +1,356 @@
+package migrations
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"regexp"
+	"sample/utils"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// files skrip migrasi yang ikut ter-embed di binary, format NNNN_nama.up.sql / NNNN_nama.down.sql
+//
+//go:embed *.sql
+var files embed.FS
+
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// advisoryLockKey kunci pg_advisory_xact_lock agar dua proses migrate tidak berjalan bersamaan
+const advisoryLockKey = 7310020017
+
+const querySchemaTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+				version    INTEGER      PRIMARY KEY,
+				name       VARCHAR(255) NOT NULL,
+				applied_at TIMESTAMPTZ  NOT NULL
+		)`
+
+// Migration satu versi skema beserta skrip up dan down
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus status satu versi migrasi di database
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Load membaca seluruh migrasi yang ter-embed, urut versi naik.
+// Setiap versi wajib punya skrip up dan down
+func Load() ([]Migration, error) {
+	entries, err := files.ReadDir(".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		if version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %q", entry.Name())
+		}
+
+		content, err := files.ReadFile(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	var result []Migration
+	for _, migration := range byVersion {
+		if strings.TrimSpace(migration.Up) == "" || strings.TrimSpace(migration.Down) == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down scripts", migration.Version, migration.Name)
+		}
+		result = append(result, *migration)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+
+	return result, nil
+}
+
+// Migrator menjalankan migrasi dan mencatat versi yang sudah diterapkan di tabel schema_migrations
+type Migrator struct {
+	DB         *sql.DB
+	Migrations []Migration
+}
+
+// NewMigrator
+func NewMigrator(db *sql.DB) (Migrator, error) {
+	migrations, err := Load()
+	if err != nil {
+		return Migrator{}, err
+	}
+
+	return Migrator{
+		DB:         db,
+		Migrations: migrations,
+	}, nil
+}
+
+// LatestVersion versi migrasi tertinggi yang dikenal binary ini
+func (ctx Migrator) LatestVersion() int {
+	if len(ctx.Migrations) == 0 {
+		return 0
+	}
+	return ctx.Migrations[len(ctx.Migrations)-1].Version
+}
+
+// Up menerapkan semua migrasi yang belum diterapkan
+func (ctx Migrator) Up() ([]Migration, error) {
+	return ctx.To(ctx.LatestVersion())
+}
+
+// Down membatalkan sejumlah migrasi terakhir yang sudah diterapkan
+func (ctx Migrator) Down(steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, errors.New("steps must be greater than 0")
+	}
+
+	applied, err := ctx.appliedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	versions := sortedVersions(applied)
+	if len(versions) == 0 {
+		return nil, nil
+	}
+	if steps >= len(versions) {
+		return ctx.To(0)
+	}
+
+	return ctx.To(versions[len(versions)-steps-1])
+}
+
+// To memigrasi skema naik atau turun sampai tepat di versi target, 0 berarti membatalkan semua migrasi
+func (ctx Migrator) To(version int) ([]Migration, error) {
+	var done []Migration
+
+	if version != 0 {
+		if _, ok := ctx.find(version); !ok {
+			return nil, fmt.Errorf("unknown migration version %d", version)
+		}
+	}
+
+	applied, err := ctx.appliedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	// Turun: batalkan versi di atas target dari yang paling baru
+	versions := sortedVersions(applied)
+	for i := len(versions) - 1; i >= 0 && versions[i] > version; i-- {
+		migration, ok := ctx.find(versions[i])
+		if !ok {
+			return done, fmt.Errorf("applied migration version %d is unknown to this binary, cannot roll back", versions[i])
+		}
+
+		changed, err := ctx.step(migration, false)
+		if err != nil {
+			return done, fmt.Errorf("rollback migration %d_%s: %v", migration.Version, migration.Name, err)
+		}
+		if changed {
+			done = append(done, migration)
+		}
+	}
+
+	// Naik: terapkan versi sampai target yang belum diterapkan
+	for _, migration := range ctx.Migrations {
+		if migration.Version > version {
+			break
+		}
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		changed, err := ctx.step(migration, true)
+		if err != nil {
+			return done, fmt.Errorf("apply migration %d_%s: %v", migration.Version, migration.Name, err)
+		}
+		if changed {
+			done = append(done, migration)
+		}
+	}
+
+	return done, nil
+}
+
+// Status daftar migrasi yang dikenal binary beserta status penerapannya
+func (ctx Migrator) Status() ([]MigrationStatus, error) {
+	applied, err := ctx.appliedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []MigrationStatus
+	for _, migration := range ctx.Migrations {
+		appliedAt, ok := applied[migration.Version]
+		result = append(result, MigrationStatus{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+	}
+
+	return result, nil
+}
+
+// Check memastikan semua migrasi yang dikenal binary sudah diterapkan.
+// Versi lebih baru di database (binary lama saat rolling deploy) tetap diterima
+func (ctx Migrator) Check() error {
+	exists, err := ctx.tableExists()
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("schema is not migrated, run `migrate up` to apply version %d", ctx.LatestVersion())
+	}
+
+	applied, err := ctx.appliedVersions()
+	if err != nil {
+		return err
+	}
+
+	var pending []string
+	for _, migration := range ctx.Migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, fmt.Sprintf("%04d_%s", migration.Version, migration.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("schema is outdated, pending migration(s): %s; run `migrate up`", strings.Join(pending, ", "))
+	}
+
+	return nil
+}
+
+// step menerapkan (up) atau membatalkan (down) satu migrasi dalam satu transaksi.
+// Status versi dicek ulang setelah lock, false jika sudah dikerjakan proses lain
+func (ctx Migrator) step(migration Migration, up bool) (bool, error) {
+	var changed bool
+
+	err := utils.DBTransaction(ctx.DB, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, advisoryLockKey); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(querySchemaTable); err != nil {
+			return err
+		}
+
+		var exists bool
+		err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`, migration.Version).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if exists == up {
+			return nil
+		}
+
+		if up {
+			if _, err := tx.Exec(migration.Up); err != nil {
+				return err
+			}
+			_, err = tx.Exec(`INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)`,
+				migration.Version, migration.Name, time.Now())
+		} else {
+			if _, err := tx.Exec(migration.Down); err != nil {
+				return err
+			}
+			_, err = tx.Exec(`DELETE FROM schema_migrations WHERE version = $1`, migration.Version)
+		}
+		if err != nil {
+			return err
+		}
+
+		changed = true
+		return nil
+	})
+
+	return changed, err
+}
+
+// appliedVersions versi yang tercatat di schema_migrations, kosong jika tabel belum ada
+func (ctx Migrator) appliedVersions() (map[int]time.Time, error) {
+	result := map[int]time.Time{}
+
+	exists, err := ctx.tableExists()
+	if err != nil || !exists {
+		return result, err
+	}
+
+	rows, err := ctx.DB.Query(`SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		result[version] = appliedAt
+	}
+
+	return result, rows.Err()
+}
+
+// tableExists true jika tabel schema_migrations sudah dibuat
+func (ctx Migrator) tableExists() (bool, error) {
+	var table sql.NullString
+	err := ctx.DB.QueryRow(`SELECT to_regclass('schema_migrations')::text`).Scan(&table)
+	return table.Valid, err
+}
+
+func (ctx Migrator) find(version int) (Migration, bool) {
+	for _, migration := range ctx.Migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+func sortedVersions(applied map[int]time.Time) []int {
+	var versions []int
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+	return versions
+}
@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	migrations, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no embedded migrations")
+	}
+
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			t.Errorf("migration %d_%s: version gap, want %d", migration.Version, migration.Name, i+1)
+		}
+	}
+}
+
+// Setiap tabel yang dibuat skrip up harus di-drop oleh skrip down versi yang sama
+func TestDownDropsCreatedTables(t *testing.T) {
+	migrations, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	createTable := regexp.MustCompile(`CREATE TABLE IF NOT EXISTS (\w+)`)
+	for _, migration := range migrations {
+		for _, match := range createTable.FindAllStringSubmatch(migration.Up, -1) {
+			if !strings.Contains(migration.Down, "DROP TABLE IF EXISTS "+match[1]+";") {
+				t.Errorf("migration %d_%s: down script does not drop table %s", migration.Version, migration.Name, match[1])
+			}
+		}
+	}
+}
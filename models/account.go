@@ -7,28 +7,45 @@ import (
 
 // Account model
 type Account struct {
-	ID                int       `json:"id"`
-	AccountNumber     string    `json:"account_number"`
-	Balance           float64   `json:"balance"`
-	PIN               string    `json:"pin,omitempty"`
-	AccountName       string    `json:"account_name"`
-	AccountStatus     string    `json:"account_status"`
-	FailedPINAttempts int       `json:"failed_pin_attempts"`
-	CreatedAt         time.Time `json:"created_at"`
-	UpdatedAt         time.Time `json:"updated_at"`
+	ID                int        `json:"id"`
+	AccountNumber     string     `json:"account_number"`
+	Balance           Money      `json:"balance"`      // saldo buku (ledger balance)
+	HeldBalance       Money      `json:"held_balance"` // total hold aktif, saldo tersedia = Balance - HeldBalance
+	PIN               string     `json:"pin,omitempty"`
+	AccountName       string     `json:"account_name"`
+	AccountStatus     string     `json:"account_status"`
+	AccountTier       string     `json:"account_tier"` // BASIC, PREMIUM, menentukan limit transaksi
+	FailedPINAttempts int        `json:"failed_pin_attempts"`
+	PINLockoutCount   int        `json:"pin_lockout_count"`      // jumlah kunci sementara sejak PIN terakhir benar
+	LockedUntil       *time.Time `json:"locked_until,omitempty"` // terisi selama kunci sementara
+	PhoneNumber       string     `json:"phone_number,omitempty"` // format E.164, tujuan SMS kode reset PIN
+	Email             string     `json:"email,omitempty"`        // tujuan email kode reset PIN
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// AuditSnapshot salinan akun tanpa hash PIN untuk before/after audit trail
+func (a Account) AuditSnapshot() Account {
+	a.PIN = ""
+	return a
 }
 
 // ============== REQUEST MODELS ==============
 
 type RequestCreateAccount struct {
-	AccountName    string  `json:"account_name" validate:"required,min=3,max=255"`
-	PIN            string  `json:"pin" validate:"required,len=6"`
-	InitialDeposit float64 `json:"initial_deposit" validate:"min=0"`
+	AccountName    string `json:"account_name" validate:"required,min=3,max=255"`
+	PIN            string `json:"pin" validate:"required,len=6"`
+	InitialDeposit Money  `json:"initial_deposit" validate:"money_min=0"`
+	PhoneNumber    string `json:"phone_number" validate:"omitempty,e164"`
+	Email          string `json:"email" validate:"omitempty,email,max=255"`
 }
 
+// RequestUpdateAccount phone_number / email kosong berarti tidak diubah
 type RequestUpdateAccount struct {
 	ID          int    `json:"id" validate:"required,min=1"`
 	AccountName string `json:"account_name" validate:"required,min=3,max=255"`
+	PhoneNumber string `json:"phone_number" validate:"omitempty,e164"`
+	Email       string `json:"email" validate:"omitempty,email,max=255"`
 }
 
 type RequestDeleteAccount struct {
@@ -36,16 +53,16 @@ type RequestDeleteAccount struct {
 }
 
 type RequestUpdateBalance struct {
-	AccountNumber string  `json:"account_number" validate:"required"`
-	Amount        float64 `json:"amount" validate:"required,min=10000"`
+	AccountNumber string `json:"account_number" validate:"required"`
+	Amount        Money  `json:"amount" validate:"required,money_min=10000"`
 }
 
 type RequestBalanceInquiry struct {
-	AccountNumber string `json:"account_number" validate:"required"`
+	AccountNumber string `json:"-"` // dari token
 }
 
 type RequestChangePIN struct {
-	AccountNumber string `json:"account_number" validate:"required"`
+	AccountNumber string `json:"-"` // dari token
 	OldPIN        string `json:"old_pin" validate:"required,len=6"`
 	NewPIN        string `json:"new_pin" validate:"required,len=6"`
 }
@@ -54,8 +71,10 @@ type RequestForgotPIN struct {
 	AccountNumber string `json:"account_number" validate:"required"`
 }
 
+// RequestResetPIN reset_code kode 6 digit yang dikirim lewat SMS / email oleh forgot PIN
 type RequestResetPIN struct {
-	ResetToken    string `json:"reset_token" validate:"required"`
+	AccountNumber string `json:"account_number" validate:"required"`
+	ResetCode     string `json:"reset_code" validate:"required,len=6,numeric"`
 	NewPIN        string `json:"new_pin" validate:"required,len=6"`
 	ConfirmNewPIN string `json:"confirm_new_pin" validate:"required,len=6"`
 }
@@ -81,30 +100,34 @@ type BaseAccountResponse struct {
 
 // AccountResponse - Response lengkap dengan balance
 type AccountResponse struct {
-	ID            int     `json:"id"`
-	AccountNumber string  `json:"account_number"`
-	Balance       float64 `json:"balance"`
-	AccountName   string  `json:"account_name"`
-	AccountStatus string  `json:"account_status"`
+	ID            int    `json:"id"`
+	AccountNumber string `json:"account_number"`
+	Balance       Money  `json:"balance"`
+	AccountName   string `json:"account_name"`
+	AccountStatus string `json:"account_status"`
+	AccountTier   string `json:"account_tier"`
 	// CreatedAt     time.Time `json:"created_at"`
 	CreatedAt string `json:"created_at"`
 }
 
 type BalanceInquiryResponse struct {
-	ID            int     `json:"id"`
-	AccountNumber string  `json:"account_number"`
-	Balance       float64 `json:"balance"`
-	AccountName   string  `json:"account_name"`
-	AccountStatus string  `json:"account_status"`
+	ID               int    `json:"id"`
+	AccountNumber    string `json:"account_number"`
+	Balance          Money  `json:"balance"`
+	HeldBalance      Money  `json:"held_balance"`
+	AvailableBalance Money  `json:"available_balance"`
+	Currency         string `json:"currency"`
+	AccountName      string `json:"account_name"`
+	AccountStatus    string `json:"account_status"`
 }
 
 // AccountDetailResponse - Response detail dengan UpdatedAt
 type AccountDetailResponse struct {
-	ID            int     `json:"id"`
-	AccountNumber string  `json:"account_number"`
-	AccountName   string  `json:"account_name"`
-	Balance       float64 `json:"balance"`
-	AccountStatus string  `json:"account_status"`
+	ID            int    `json:"id"`
+	AccountNumber string `json:"account_number"`
+	AccountName   string `json:"account_name"`
+	Balance       Money  `json:"balance"`
+	AccountStatus string `json:"account_status"`
 	// CreatedAt     time.Time `json:"created_at"`
 	CreatedAt string `json:"created_at"`
 	// UpdatedAt     time.Time `json:"updated_at"`
@@ -113,11 +136,11 @@ type AccountDetailResponse struct {
 
 // CreateAccountResponse - Response khusus untuk create account
 type CreateAccountResponse struct {
-	ID            int     `json:"id"`
-	AccountNumber string  `json:"account_number"`
-	AccountName   string  `json:"account_name"`
-	Balance       float64 `json:"initial_balance"`
-	AccountStatus string  `json:"account_status"`
+	ID            int    `json:"id"`
+	AccountNumber string `json:"account_number"`
+	AccountName   string `json:"account_name"`
+	Balance       Money  `json:"initial_balance"`
+	AccountStatus string `json:"account_status"`
 	// CreatedAt     time.Time `json:"created_at"`
 	CreatedAt string `json:"created_at"`
 	// Message   string `json:"message"`
@@ -144,7 +167,7 @@ type DeleteAccountResponse struct {
 type BalanceResponse struct {
 	AccountNumber string    `json:"account_number"`
 	AccountName   string    `json:"account_name"`
-	Balance       float64   `json:"balance"`
+	Balance       Money     `json:"balance"`
 	CheckedAt     time.Time `json:"checked_at"`
 }
 
@@ -155,10 +178,10 @@ type ChangePINResponse struct {
 	ChangedAt     time.Time `json:"changed_at"`
 }
 
-// ForgotPINResponse - Response untuk forgot PIN (generate token)
+// ForgotPINResponse - Response untuk forgot PIN, sama untuk akun yang ada maupun tidak.
+// Kode reset tidak pernah dikembalikan, hanya dikirim ke kontak terdaftar
 type ForgotPINResponse struct {
 	AccountNumber string    `json:"account_number"`
-	ResetToken    string    `json:"reset_token"`
 	ExpiresAt     time.Time `json:"expires_at"`
 	Message       string    `json:"message"`
 }
@@ -176,6 +199,11 @@ type AccountListResponse struct {
 	TotalRecords int               `json:"total_records"`
 }
 
+// AvailableBalance saldo yang bisa dipakai untuk tarik tunai dan transfer (saldo buku dikurangi hold aktif)
+func (a *Account) AvailableBalance() Money {
+	return a.Balance.Sub(a.HeldBalance)
+}
+
 // ToBaseResponse converts Account to BaseAccountResponse
 func (a *Account) ToBaseResponse() BaseAccountResponse {
 	return BaseAccountResponse{
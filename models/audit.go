@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditLog satu catatan audit trail di MongoDB. Catatan hanya di-insert,
+// tidak ada jalur untuk mengubah atau menghapusnya
+type AuditLog struct {
+	ID                     primitive.ObjectID     `json:"id" bson:"_id,omitempty"`
+	Action                 string                 `json:"action" bson:"action"`
+	Actor                  string                 `json:"actor" bson:"actor"` // account number, ADMIN atau ANONYMOUS
+	ActorRole              string                 `json:"actor_role,omitempty" bson:"actor_role,omitempty"`
+	AccountNumber          string                 `json:"account_number,omitempty" bson:"account_number,omitempty"` // rekening yang terdampak
+	IPAddress              string                 `json:"ip_address" bson:"ip_address"`
+	UserAgent              string                 `json:"user_agent,omitempty" bson:"user_agent,omitempty"`
+	ReferenceNo            string                 `json:"reference_no" bson:"reference_no"` // nomor referensi request (header X-Reference-No)
+	TransactionReferenceNo string                 `json:"transaction_reference_no,omitempty" bson:"transaction_reference_no,omitempty"`
+	Method                 string                 `json:"method" bson:"method"`
+	Path                   string                 `json:"path" bson:"path"`
+	Before                 map[string]interface{} `json:"before,omitempty" bson:"before,omitempty"`
+	After                  map[string]interface{} `json:"after,omitempty" bson:"after,omitempty"`
+	Outcome                string                 `json:"outcome" bson:"outcome"` // SUCCESS, FAILED
+	StatusCode             int                    `json:"status_code" bson:"status_code"`
+	ResponseCode           string                 `json:"response_code,omitempty" bson:"response_code,omitempty"`
+	Message                string                 `json:"message,omitempty" bson:"message,omitempty"`
+	DurationMs             int64                  `json:"duration_ms" bson:"duration_ms"`
+	CreatedAt              time.Time              `json:"created_at" bson:"created_at"`
+}
+
+// AuditBalance saldo satu rekening pada snapshot before / after perpindahan uang
+type AuditBalance struct {
+	AccountNumber string `json:"account_number"`
+	Currency      string `json:"currency"`
+	Balance       Money  `json:"balance"`
+}
+
+// AuditBalanceSnapshot isi before / after untuk aksi perpindahan uang
+type AuditBalanceSnapshot struct {
+	Balances []AuditBalance `json:"balances"`
+}
+
+// AuditBalanceChange perubahan saldo satu rekening akibat perpindahan uang
+type AuditBalanceChange struct {
+	AccountNumber string
+	Before        Money
+	After         Money
+}
+
+// ============== REQUEST MODELS ==============
+
+type RequestAuditLogList struct {
+	AccountNumber string    `json:"account_number"`
+	Actor         string    `json:"actor"`
+	Action        string    `json:"action"`
+	StartTime     string    `json:"start_time"`                               // Format: 2006-01-02 15:04:05
+	EndTime       string    `json:"end_time"`                                 // Format: 2006-01-02 15:04:05
+	Page          int       `json:"page" validate:"omitempty,min=1"`          // default 1
+	Limit         int       `json:"limit" validate:"omitempty,min=1,max=100"` // default 50
+	StartAt       time.Time `json:"-"`                                        // hasil parse StartTime
+	EndAt         time.Time `json:"-"`                                        // hasil parse EndTime
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"github.com/dgrijalva/jwt-go"
+)
+
+// JWTClaims isi token akses dan refresh. Subject (account number) selalu
+// diambil dari sini, bukan dari body request
+type JWTClaims struct {
+	AccountNumber string `json:"account_number"`
+	Role          string `json:"role"`
+	TokenType     string `json:"token_type"`
+	jwt.StandardClaims
+}
+
+// ============== REQUEST MODELS ==============
+
+type RequestLogin struct {
+	AccountNumber string `json:"account_number" validate:"required"`
+	PIN           string `json:"pin" validate:"required,len=6"`
+}
+
+type RequestRefreshToken struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// ============== RESPONSE MODELS ==============
+
+type LoginResponse struct {
+	AccountNumber    string `json:"account_number"`
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int64  `json:"expires_in"`         // detik
+	RefreshExpiresIn int64  `json:"refresh_expires_in"` // detik
+}
@@ -1,20 +1,249 @@
 package models
 
+import "time"
+
+// EnvironmentConfig identitas aplikasi dari environment/environment.json
 type EnvironmentConfig struct {
-	AppEnv		string	`json:"app_env"`
-	AppName		string	`json:"app_name"`
-	AppVersion	string	`json:"app_version"`
+	AppEnv		string	`json:"app_env" env:"APP_ENV"`
+	AppName		string	`json:"app_name" env:"APP_NAME"`
+	AppVersion	string	`json:"app_version" env:"APP_VERSION"`
 }
 
+// Config konfigurasi aplikasi yang dimuat sekali saat start (config.Load).
+// Tag json untuk file environment/*.json, tag env untuk .env dan environment variable
 type Config struct {
-	DriverName	string	 `json:"driver"`
+	EnvironmentConfig
+	AppPort		string	 `json:"app_port" env:"APP_PORT"`
+	DriverName	string	 `json:"driver" env:"DB_DRIVER"`
 	Database	Database `json:"database"`
+	Redis		Redis	 `json:"redis"`
+	Mongo		Mongo	 `json:"mongo"`
+	JWT		JWT	 `json:"jwt"`
+	AdminAPIKey	string	 `json:"admin_api_key" env:"ADMIN_API_KEY"`
+	TrustedProxies	string	 `json:"trusted_proxies" env:"TRUSTED_PROXIES"` // IP / CIDR proxy yang X-Forwarded-For-nya dipercaya
+
+	TransferInquiryTTLSeconds	int	`json:"transfer_inquiry_ttl_seconds" env:"TRANSFER_INQUIRY_TTL_SECONDS"`
+	HoldExpiryIntervalSeconds	int	`json:"hold_expiry_interval_seconds" env:"HOLD_EXPIRY_INTERVAL_SECONDS"`
+	HealthCheckTimeoutSeconds	int	`json:"health_check_timeout_seconds" env:"HEALTH_CHECK_TIMEOUT_SECONDS"`
+	ShutdownTimeoutSeconds		int	`json:"shutdown_timeout_seconds" env:"SHUTDOWN_TIMEOUT_SECONDS"`
+
+	Scheduler	Scheduler `json:"scheduler"`
+	Webhook		Webhook	  `json:"webhook"`
+	Tracing		Tracing	  `json:"tracing"`
+	RateLimit	RateLimits `json:"rate_limit"`
+	PINLockout	PINLockout `json:"pin_lockout"`
+	PINReset	PINReset   `json:"pin_reset"`
+	Notification	Notification `json:"notification"`
+	StepUp		StepUp	     `json:"step_up"`
 }
 
 type Database struct {
-	DBName		string	`json:"db_name"`
-	DBPort		string	`json:"db_port"`
-	DBUser		string	`json:"db_user"`
-	DBPass		string	`json:"db_pass"`
-	SSLMode		string	`json:"ssl_mode"`
-}
\ No newline at end of file
+	DBName		string	`json:"db_name" env:"DB_NAME"`
+	DBHost		string	`json:"db_host" env:"DB_HOST"`
+	DBPort		string	`json:"db_port" env:"DB_PORT"`
+	DBUser		string	`json:"db_user" env:"DB_USER"`
+	DBPass		string	`json:"db_pass" env:"DB_PASS"`
+	SSLMode		string	`json:"ssl_mode" env:"SSL_MODE"`
+}
+
+type Redis struct {
+	Host	string	`json:"host" env:"REDIS_HOST"`
+	Port	string	`json:"port" env:"REDIS_PORT"`
+	Pass	string	`json:"pass" env:"REDIS_PASS"`
+}
+
+type Mongo struct {
+	Host	string	`json:"host" env:"MONGO_HOST"`
+	Port	string	`json:"port" env:"MONGO_PORT"`
+	DBName	string	`json:"db_name" env:"MONGO_DB"`
+}
+
+// JWT kunci HMAC dan masa berlaku token
+type JWT struct {
+	Key			string	`json:"key" env:"JWT_KEY"`
+	AccessTTLMinutes	int	`json:"access_ttl_minutes" env:"JWT_ACCESS_TTL_MINUTES"`
+	RefreshTTLHours		int	`json:"refresh_ttl_hours" env:"JWT_REFRESH_TTL_HOURS"`
+}
+
+// Scheduler pengaturan worker transfer terjadwal
+type Scheduler struct {
+	IntervalSeconds		int	`json:"interval_seconds" env:"SCHEDULER_INTERVAL_SECONDS"`
+	RetryIntervalMinutes	int	`json:"retry_interval_minutes" env:"SCHEDULER_RETRY_INTERVAL_MINUTES"`
+	MaxRetry		int	`json:"max_retry" env:"SCHEDULER_MAX_RETRY"`
+	BatchSize		int	`json:"batch_size" env:"SCHEDULER_BATCH_SIZE"`
+}
+
+// Webhook pengaturan worker pengiriman webhook partner
+type Webhook struct {
+	TimeoutSeconds		int	`json:"timeout_seconds" env:"WEBHOOK_TIMEOUT_SECONDS"`
+	BatchSize		int	`json:"batch_size" env:"WEBHOOK_BATCH_SIZE"`
+	IntervalSeconds		int	`json:"interval_seconds" env:"WEBHOOK_INTERVAL_SECONDS"`
+	RetryBaseSeconds	int	`json:"retry_base_seconds" env:"WEBHOOK_RETRY_BASE_SECONDS"`
+	MaxAttempts		int	`json:"max_attempts" env:"WEBHOOK_MAX_ATTEMPTS"`
+}
+
+// Tracing exporter span OpenTelemetry, exporter none hanya meneruskan trace ID
+type Tracing struct {
+	Exporter	string	`json:"exporter" env:"TRACING_EXPORTER"`
+	OTLPEndpoint	string	`json:"otlp_endpoint" env:"TRACING_OTLP_ENDPOINT"`
+	OTLPInsecure	bool	`json:"otlp_insecure" env:"TRACING_OTLP_INSECURE"`
+	SamplePercent	int	`json:"sample_percent" env:"TRACING_SAMPLE_PERCENT"`
+}
+
+// RateLimits batas request per grup route dalam jendela detik. Public per IP, private dan
+// transaction per nomor rekening, admin per API client. Login dan PINReset batas tambahan login /
+// refresh dan forgot / reset PIN per rekening yang disasar, berlaku lintas IP
+type RateLimits struct {
+	PublicLimit			int	`json:"public_limit" env:"RATE_LIMIT_PUBLIC"`
+	PublicWindowSeconds		int	`json:"public_window_seconds" env:"RATE_LIMIT_PUBLIC_WINDOW_SECONDS"`
+	PrivateLimit			int	`json:"private_limit" env:"RATE_LIMIT_PRIVATE"`
+	PrivateWindowSeconds		int	`json:"private_window_seconds" env:"RATE_LIMIT_PRIVATE_WINDOW_SECONDS"`
+	TransactionLimit		int	`json:"transaction_limit" env:"RATE_LIMIT_TRANSACTION"`
+	TransactionWindowSeconds	int	`json:"transaction_window_seconds" env:"RATE_LIMIT_TRANSACTION_WINDOW_SECONDS"`
+	AdminLimit			int	`json:"admin_limit" env:"RATE_LIMIT_ADMIN"`
+	AdminWindowSeconds		int	`json:"admin_window_seconds" env:"RATE_LIMIT_ADMIN_WINDOW_SECONDS"`
+	PINResetLimit			int	`json:"pin_reset_limit" env:"RATE_LIMIT_PIN_RESET"`
+	PINResetWindowSeconds		int	`json:"pin_reset_window_seconds" env:"RATE_LIMIT_PIN_RESET_WINDOW_SECONDS"`
+	LoginLimit			int	`json:"login_limit" env:"RATE_LIMIT_LOGIN"`
+	LoginWindowSeconds		int	`json:"login_window_seconds" env:"RATE_LIMIT_LOGIN_WINDOW_SECONDS"`
+}
+
+// Public batas grup /public (login, lupa PIN, buat akun)
+func (c RateLimits) Public() RateLimit {
+	return RateLimit{Limit: c.PublicLimit, Window: time.Duration(c.PublicWindowSeconds) * time.Second}
+}
+
+// Private batas seluruh grup /private
+func (c RateLimits) Private() RateLimit {
+	return RateLimit{Limit: c.PrivateLimit, Window: time.Duration(c.PrivateWindowSeconds) * time.Second}
+}
+
+// Transaction batas tambahan grup /private/transaction
+func (c RateLimits) Transaction() RateLimit {
+	return RateLimit{Limit: c.TransactionLimit, Window: time.Duration(c.TransactionWindowSeconds) * time.Second}
+}
+
+// Admin batas grup /admin
+func (c RateLimits) Admin() RateLimit {
+	return RateLimit{Limit: c.AdminLimit, Window: time.Duration(c.AdminWindowSeconds) * time.Second}
+}
+
+// PINReset batas forgot / reset PIN per rekening tujuan
+func (c RateLimits) PINReset() RateLimit {
+	return RateLimit{Limit: c.PINResetLimit, Window: time.Duration(c.PINResetWindowSeconds) * time.Second}
+}
+
+// Login batas login / refresh token per rekening
+func (c RateLimits) Login() RateLimit {
+	return RateLimit{Limit: c.LoginLimit, Window: time.Duration(c.LoginWindowSeconds) * time.Second}
+}
+
+// PINLockout kebijakan blokir PIN: setiap MaxAttempts PIN salah berturut-turut akun dikunci
+// sementara LockoutMinutes * LockoutMultiplier^(n-1), setelah MaxTemporaryLockouts kunci
+// sementara berikutnya akun diblokir permanen (BLOCKED_PIN) sampai reset PIN / unblock admin
+type PINLockout struct {
+	MaxAttempts		int	`json:"max_attempts" env:"PIN_MAX_ATTEMPTS"`
+	LockoutMinutes		int	`json:"lockout_minutes" env:"PIN_LOCKOUT_MINUTES"`
+	LockoutMultiplier	int	`json:"lockout_multiplier" env:"PIN_LOCKOUT_MULTIPLIER"`
+	MaxTemporaryLockouts	int	`json:"max_temporary_lockouts" env:"PIN_MAX_TEMPORARY_LOCKOUTS"`
+}
+
+// LockoutDuration lama kunci sementara ke-n (mulai dari 1)
+func (c PINLockout) LockoutDuration(lockoutCount int) time.Duration {
+	duration := time.Duration(c.LockoutMinutes) * time.Minute
+	for i := 1; i < lockoutCount; i++ {
+		duration *= time.Duration(c.LockoutMultiplier)
+	}
+	return duration
+}
+
+// PINReset masa berlaku kode reset PIN (lupa PIN) dan batas per akun dalam jendela CooldownMinutes:
+// paling banyak MaxCodes kode diterbitkan dan MaxAttempts percobaan salah, lintas kode.
+// Setelah batas tercapai kode baru tidak diterbitkan sampai jendela berakhir
+type PINReset struct {
+	CodeTTLMinutes	int	`json:"code_ttl_minutes" env:"PIN_RESET_CODE_TTL_MINUTES"`
+	MaxAttempts	int	`json:"max_attempts" env:"PIN_RESET_MAX_ATTEMPTS"`
+	MaxCodes	int	`json:"max_codes" env:"PIN_RESET_MAX_CODES"`
+	CooldownMinutes	int	`json:"cooldown_minutes" env:"PIN_RESET_COOLDOWN_MINUTES"`
+}
+
+// CodeTTL masa berlaku kode reset PIN
+func (c PINReset) CodeTTL() time.Duration {
+	return time.Duration(c.CodeTTLMinutes) * time.Minute
+}
+
+// Cooldown jendela batas kode dan percobaan reset PIN per akun
+func (c PINReset) Cooldown() time.Duration {
+	return time.Duration(c.CooldownMinutes) * time.Minute
+}
+
+// StepUp withdraw / transfer dengan nominal di atas ThresholdAmount (satuan utama mata uang
+// default, 0 berarti nonaktif) harus dikonfirmasi dengan OTP yang berlaku CodeTTLSeconds.
+// Batas salah OTP mengikuti PINLockout.MaxAttempts dan ikut dihitung ke kunci PIN
+type StepUp struct {
+	ThresholdAmount	int	`json:"threshold_amount" env:"STEP_UP_THRESHOLD_AMOUNT"`
+	CodeTTLSeconds	int	`json:"code_ttl_seconds" env:"STEP_UP_CODE_TTL_SECONDS"`
+}
+
+// Required nominal amount harus dikonfirmasi OTP
+func (c StepUp) Required(amount Money) bool {
+	if c.ThresholdAmount <= 0 {
+		return false
+	}
+	threshold := NewMoney(int64(c.ThresholdAmount)*moneyFactor, amount.CurrencyCode())
+	return threshold.LessThan(amount)
+}
+
+// CodeTTL masa berlaku OTP step-up
+func (c StepUp) CodeTTL() time.Duration {
+	return time.Duration(c.CodeTTLSeconds) * time.Second
+}
+
+// Notification channel pengiriman pesan ke nasabah (kode reset PIN). Channel console hanya
+// menulis ke log untuk development, sms memanggil HTTP gateway, email lewat SMTP
+type Notification struct {
+	Channel		string	`json:"channel" env:"NOTIFICATION_CHANNEL"`
+	SMSURL		string	`json:"sms_url" env:"NOTIFICATION_SMS_URL"`
+	SMSAPIKey	string	`json:"sms_api_key" env:"NOTIFICATION_SMS_API_KEY"`
+	SMTPHost	string	`json:"smtp_host" env:"NOTIFICATION_SMTP_HOST"`
+	SMTPPort	string	`json:"smtp_port" env:"NOTIFICATION_SMTP_PORT"`
+	SMTPUser	string	`json:"smtp_user" env:"NOTIFICATION_SMTP_USER"`
+	SMTPPass	string	`json:"smtp_pass" env:"NOTIFICATION_SMTP_PASS"`
+	EmailFrom	string	`json:"email_from" env:"NOTIFICATION_EMAIL_FROM"`
+	TimeoutSeconds	int	`json:"timeout_seconds" env:"NOTIFICATION_TIMEOUT_SECONDS"`
+}
+
+// Timeout batas waktu satu pengiriman notifikasi
+func (c Notification) Timeout() time.Duration {
+	return time.Duration(c.TimeoutSeconds) * time.Second
+}
+
+// AccessTTL masa berlaku access token
+func (c JWT) AccessTTL() time.Duration {
+	return time.Duration(c.AccessTTLMinutes) * time.Minute
+}
+
+// RefreshTTL masa berlaku refresh token
+func (c JWT) RefreshTTL() time.Duration {
+	return time.Duration(c.RefreshTTLHours) * time.Hour
+}
+
+// TransferInquiryTTL masa berlaku quote transfer-inquiry
+func (c Config) TransferInquiryTTL() time.Duration {
+	return time.Duration(c.TransferInquiryTTLSeconds) * time.Second
+}
+
+// HoldExpiryInterval jeda antar putaran worker release hold kedaluwarsa
+func (c Config) HoldExpiryInterval() time.Duration {
+	return time.Duration(c.HoldExpiryIntervalSeconds) * time.Second
+}
+
+// HealthCheckTimeout batas waktu cek setiap dependency pada /health/ready
+func (c Config) HealthCheckTimeout() time.Duration {
+	return time.Duration(c.HealthCheckTimeoutSeconds) * time.Second
+}
+
+// ShutdownTimeout batas waktu menunggu request dan worker selesai saat SIGTERM
+func (c Config) ShutdownTimeout() time.Duration {
+	return time.Duration(c.ShutdownTimeoutSeconds) * time.Second
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"sample/constans"
+)
+
+// FeeRule skema biaya untuk satu jenis transaksi.
+// FLAT memakai FlatAmount, PERCENTAGE memakai PercentageBps (1 bps = 0,01%) dibatasi MinFee/MaxFee,
+// TIERED memakai biaya dari tier tertinggi yang MinAmount-nya <= nominal transaksi.
+// FreeQuotaMonthly transaksi pertama setiap bulan tidak dikenakan biaya
+type FeeRule struct {
+	TransactionType  string    `json:"transaction_type"` // WITHDRAW, TRANSFER
+	FeeType          string    `json:"fee_type"`         // FLAT, PERCENTAGE, TIERED
+	FlatAmount       Money     `json:"flat_amount"`
+	PercentageBps    int64     `json:"percentage_bps"`
+	MinFee           Money     `json:"min_fee"`
+	MaxFee           Money     `json:"max_fee"` // nol berarti tanpa batas atas
+	Tiers            []FeeTier `json:"tiers,omitempty"`
+	FreeQuotaMonthly int       `json:"free_quota_monthly"`
+}
+
+// FeeTier satu tingkat biaya untuk FeeType TIERED
+type FeeTier struct {
+	MinAmount Money `json:"min_amount"`
+	Fee       Money `json:"fee"`
+}
+
+// DefaultFeeRules skema biaya awal yang dibuat saat aplikasi start jika belum ada
+var DefaultFeeRules = []FeeRule{
+	{
+		TransactionType:  constans.JOURNAL_TYPE_WITHDRAW,
+		FeeType:          constans.FEE_TYPE_FLAT,
+		FlatAmount:       MustParseMoney("2500", constans.DEFAULT_CURRENCY),
+		FreeQuotaMonthly: 5,
+	},
+	{
+		TransactionType: constans.JOURNAL_TYPE_TRANSFER,
+		FeeType:         constans.FEE_TYPE_TIERED,
+		Tiers: []FeeTier{
+			{MinAmount: MustParseMoney("0", constans.DEFAULT_CURRENCY), Fee: MustParseMoney("2500", constans.DEFAULT_CURRENCY)},
+			{MinAmount: MustParseMoney("10000000", constans.DEFAULT_CURRENCY), Fee: MustParseMoney("5000", constans.DEFAULT_CURRENCY)},
+			{MinAmount: MustParseMoney("50000000", constans.DEFAULT_CURRENCY), Fee: MustParseMoney("10000", constans.DEFAULT_CURRENCY)},
+		},
+		FreeQuotaMonthly: 3,
+	},
+}
+
+// Calculate menghitung biaya untuk nominal transaksi, tanpa memperhitungkan kuota gratis
+func (r FeeRule) Calculate(amount Money) Money {
+	fee := NewMoney(0, amount.CurrencyCode())
+
+	switch r.FeeType {
+	case constans.FEE_TYPE_FLAT:
+		fee = r.FlatAmount
+	case constans.FEE_TYPE_PERCENTAGE:
+		// Pembulatan half-up pada minor unit
+		fee = NewMoney((amount.Amount*r.PercentageBps+5000)/10000, amount.CurrencyCode())
+		if r.MinFee.IsPositive() && fee.LessThan(r.MinFee) {
+			fee = r.MinFee
+		}
+		if r.MaxFee.IsPositive() && r.MaxFee.LessThan(fee) {
+			fee = r.MaxFee
+		}
+	case constans.FEE_TYPE_TIERED:
+		matched := false
+		var matchedMin Money
+		for _, tier := range r.Tiers {
+			if amount.LessThan(tier.MinAmount) {
+				continue
+			}
+			if !matched || matchedMin.LessThan(tier.MinAmount) {
+				fee, matchedMin, matched = tier.Fee, tier.MinAmount, true
+			}
+		}
+	}
+
+	return fee
+}
@@ -0,0 +1,285 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sample/constans"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rateScale jumlah digit desimal kurs
+const (
+	rateScale  = 8
+	rateFactor = int64(100000000)
+)
+
+// SupportedCurrencies mata uang yang bisa disimpan di pocket rekening
+var SupportedCurrencies = []string{constans.DEFAULT_CURRENCY, constans.CURRENCY_USD, constans.CURRENCY_SGD}
+
+// Rate kurs eksak dengan 8 digit desimal, JSON ditulis sebagai string desimal ("16250.5")
+type Rate int64
+
+// ParseRate mengubah string desimal menjadi Rate, harus lebih dari nol
+func ParseRate(value string) (Rate, error) {
+	s := strings.TrimSpace(value)
+
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+
+	for len(fracPart) > rateScale && strings.HasSuffix(fracPart, "0") {
+		fracPart = fracPart[:len(fracPart)-1]
+	}
+
+	if intPart == "" || len(fracPart) > rateScale || !isDigits(intPart) || !isDigits(fracPart) {
+		return 0, fmt.Errorf("invalid rate: %q", value)
+	}
+
+	fracPart += strings.Repeat("0", rateScale-len(fracPart))
+
+	whole, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil || whole > (1<<63-1)/rateFactor {
+		return 0, fmt.Errorf("invalid rate: %q", value)
+	}
+	frac, _ := strconv.ParseInt(fracPart, 10, 64)
+
+	rate := Rate(whole*rateFactor + frac)
+	if rate <= 0 {
+		return 0, errors.New("invalid rate: must be greater than zero")
+	}
+
+	return rate, nil
+}
+
+// String format desimal tanpa nol di belakang, contoh "16250.5"
+func (r Rate) String() string {
+	s := fmt.Sprintf("%d.%0*d", int64(r)/rateFactor, rateScale, int64(r)%rateFactor)
+	return strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+}
+
+// MarshalJSON menulis Rate sebagai string desimal
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.String())
+}
+
+// UnmarshalJSON menerima string desimal maupun angka JSON tanpa melewati float64
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	raw := strings.TrimSpace(string(data))
+	if raw == "null" {
+		return nil
+	}
+
+	if strings.HasPrefix(raw, `"`) {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+	}
+
+	parsed, err := ParseRate(raw)
+	if err != nil {
+		return err
+	}
+
+	*r = parsed
+	return nil
+}
+
+// Scan membaca kolom numeric dari database
+func (r *Rate) Scan(src interface{}) error {
+	var raw string
+
+	switch v := src.(type) {
+	case []byte:
+		raw = string(v)
+	case string:
+		raw = v
+	default:
+		return fmt.Errorf("rate: cannot scan %T", src)
+	}
+
+	parsed, err := ParseRate(raw)
+	if err != nil {
+		return err
+	}
+
+	*r = parsed
+	return nil
+}
+
+// Value menulis Rate ke database sebagai string desimal (kolom numeric)
+func (r Rate) Value() (driver.Value, error) {
+	return r.String(), nil
+}
+
+// FxRate kurs tengah 1 BaseCurrency = Rate QuoteCurrency yang berlaku mulai EffectiveAt.
+// SpreadBps selisih yang diambil bank dari kurs tengah (1 bps = 0,01%)
+type FxRate struct {
+	ID            int       `json:"id"`
+	BaseCurrency  string    `json:"base_currency"`
+	QuoteCurrency string    `json:"quote_currency"`
+	Rate          Rate      `json:"rate"`
+	SpreadBps     int64     `json:"spread_bps"`
+	EffectiveAt   time.Time `json:"effective_at"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// FxQuote kurs konversi FromCurrency -> ToCurrency yang ditawarkan ke nasabah
+type FxQuote struct {
+	RateID       int       `json:"rate_id"`
+	FromCurrency string    `json:"from_currency"`
+	ToCurrency   string    `json:"to_currency"`
+	MidRate      Rate      `json:"mid_rate"`    // 1 FromCurrency = MidRate ToCurrency
+	QuotedRate   Rate      `json:"quoted_rate"` // MidRate setelah dikurangi spread
+	SpreadBps    int64     `json:"spread_bps"`
+	EffectiveAt  time.Time `json:"effective_at"`
+
+	// Perhitungan nominal memakai kurs tersimpan agar kurs terbalik tidak kehilangan presisi
+	storedRate Rate
+	inverse    bool
+}
+
+// NewFxQuote membentuk quote dari kurs yang tersimpan, kurs dibalik jika
+// pasangan tersimpan berlawanan arah. Spread selalu merugikan nasabah (pembulatan ke bawah)
+func NewFxQuote(rate FxRate, fromCurrency, toCurrency string) FxQuote {
+	q := FxQuote{
+		RateID:       rate.ID,
+		FromCurrency: fromCurrency,
+		ToCurrency:   toCurrency,
+		SpreadBps:    rate.SpreadBps,
+		EffectiveAt:  rate.EffectiveAt,
+		storedRate:   rate.Rate,
+		inverse:      rate.BaseCurrency != fromCurrency,
+	}
+
+	q.MidRate = Rate(q.convert(rateFactor, 0))
+	q.QuotedRate = Rate(q.convert(rateFactor, q.SpreadBps))
+
+	return q
+}
+
+// convert amount FromCurrency ke ToCurrency dengan spread (dibulatkan ke bawah)
+func (q FxQuote) convert(amount int64, spreadBps int64) int64 {
+	numerator := new(big.Int).Mul(big.NewInt(amount), big.NewInt(10000-spreadBps))
+	denominator := big.NewInt(10000)
+
+	if q.inverse {
+		numerator.Mul(numerator, big.NewInt(rateFactor))
+		denominator.Mul(denominator, big.NewInt(int64(q.storedRate)))
+	} else {
+		numerator.Mul(numerator, big.NewInt(int64(q.storedRate)))
+		denominator.Mul(denominator, big.NewInt(rateFactor))
+	}
+
+	return numerator.Quo(numerator, denominator).Int64()
+}
+
+// Convert nominal ToCurrency yang diterima untuk source FromCurrency (dibulatkan ke bawah)
+func (q FxQuote) Convert(source Money) Money {
+	return NewMoney(q.convert(source.Amount, q.SpreadBps), q.ToCurrency)
+}
+
+// SourceFor nominal FromCurrency terkecil yang menghasilkan minimal target ToCurrency
+func (q FxQuote) SourceFor(target Money) Money {
+	// Perkiraan awal lewat kebalikan kurs, lalu dinaikkan sampai hasil konversi mencukupi
+	numerator := new(big.Int).Mul(big.NewInt(target.Amount), big.NewInt(10000))
+	denominator := big.NewInt(10000 - q.SpreadBps)
+
+	if q.inverse {
+		numerator.Mul(numerator, big.NewInt(int64(q.storedRate)))
+		denominator.Mul(denominator, big.NewInt(rateFactor))
+	} else {
+		numerator.Mul(numerator, big.NewInt(rateFactor))
+		denominator.Mul(denominator, big.NewInt(int64(q.storedRate)))
+	}
+
+	source := numerator.Quo(numerator, denominator).Int64()
+	for q.convert(source, q.SpreadBps) < target.Amount {
+		source++
+	}
+
+	return NewMoney(source, q.FromCurrency)
+}
+
+// AccountPocket saldo rekening untuk satu mata uang. Pocket mata uang utama
+// adalah kolom account.balance, mata uang lain disimpan di tabel account_pocket
+type AccountPocket struct {
+	Currency         string    `json:"currency"`
+	Balance          Money     `json:"balance"`
+	AvailableBalance Money     `json:"available_balance"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// PocketLedgerCode kode ledger pocket rekening: nomor rekening untuk mata uang utama,
+// nomor rekening + kode mata uang untuk pocket valas (contoh 1234567890.USD)
+func PocketLedgerCode(accountNumber, currency string) string {
+	if currency == "" || currency == constans.DEFAULT_CURRENCY {
+		return accountNumber
+	}
+	return accountNumber + "." + currency
+}
+
+// FxPositionLedgerCode kode akun sistem posisi valas per mata uang
+func FxPositionLedgerCode(currency string) string {
+	return constans.LEDGER_FX_POSITION + "_" + currency
+}
+
+// ============== REQUEST MODELS ==============
+
+type RequestSetFxRate struct {
+	BaseCurrency  string `json:"base_currency" validate:"required,oneof=IDR USD SGD"`
+	QuoteCurrency string `json:"quote_currency" validate:"required,oneof=IDR USD SGD,nefield=BaseCurrency"`
+	Rate          Rate   `json:"rate" validate:"required,min=1"`
+	SpreadBps     int64  `json:"spread_bps" validate:"min=0,max=5000"`
+	EffectiveAt   string `json:"effective_at"` // Format: 2006-01-02 15:04:05, kosong berarti sekarang
+}
+
+type RequestFxRateList struct {
+	BaseCurrency  string `json:"base_currency" validate:"omitempty,oneof=IDR USD SGD"`
+	QuoteCurrency string `json:"quote_currency" validate:"omitempty,oneof=IDR USD SGD"`
+}
+
+type RequestFxQuote struct {
+	FromCurrency string `json:"from_currency" validate:"required,oneof=IDR USD SGD"`
+	ToCurrency   string `json:"to_currency" validate:"required,oneof=IDR USD SGD,nefield=FromCurrency"`
+	Amount       Money  `json:"amount" validate:"required,money_min=1"` // dalam FromCurrency
+}
+
+type RequestFxConvert struct {
+	AccountNumber string `json:"-"` // dari token
+	FromCurrency  string `json:"from_currency" validate:"required,oneof=IDR USD SGD"`
+	ToCurrency    string `json:"to_currency" validate:"required,oneof=IDR USD SGD,nefield=FromCurrency"`
+	Amount        Money  `json:"amount" validate:"required,money_min=1"` // dalam FromCurrency
+	PIN           string `json:"pin" validate:"required,len=6"`
+}
+
+// ============== RESPONSE MODELS ==============
+
+type FxQuoteResponse struct {
+	Quote        FxQuote `json:"quote"`
+	SourceAmount Money   `json:"source_amount"`
+	TargetAmount Money   `json:"target_amount"`
+}
+
+// FxConversion hasil konversi antar pocket, dicatat sebagai dua transaksi (debit dan kredit)
+// dalam satu jurnal FX_CONVERSION
+type FxConversion struct {
+	ReferenceNo     string  `json:"reference_no"`
+	Quote           FxQuote `json:"quote"`
+	SourceAmount    Money   `json:"source_amount"`
+	TargetAmount    Money   `json:"target_amount"`
+	SourceBalance   Money   `json:"source_balance_after"`
+	TargetBalance   Money   `json:"target_balance_after"`
+	TransactionDate string  `json:"transaction_date"`
+}
+
+type PocketBalanceResponse struct {
+	AccountNumber string          `json:"account_number"`
+	Pockets       []AccountPocket `json:"pockets"`
+	CheckedAt     time.Time       `json:"checked_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+// HealthCheck hasil cek satu dependency
+type HealthCheck struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"` // UP, DOWN
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// HealthResponse status keseluruhan beserta status per dependency
+type HealthResponse struct {
+	Status string        `json:"status"`
+	Checks []HealthCheck `json:"checks,omitempty"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+)
+
+// Hold reservasi dana (otorisasi) pada rekening. Dana yang di-hold tidak berpindah,
+// hanya mengurangi saldo tersedia sampai di-capture, di-release atau kedaluwarsa.
+// Capture mengkredit rekening merchant (BeneficiaryNumber) dan hanya merchant yang boleh
+// capture/release. Hold lama tanpa beneficiary hanya bisa di-release oleh pemiliknya
+type Hold struct {
+	ID                int        `json:"id"`
+	AccountID         int        `json:"account_id"`
+	AccountNumber     string     `json:"account_number"`
+	BeneficiaryNumber string     `json:"beneficiary_number,omitempty"`
+	Amount            Money      `json:"amount"`
+	CapturedAmount    Money      `json:"captured_amount"`
+	Status            string     `json:"status"` // ACTIVE, CAPTURED, RELEASED, EXPIRED
+	Description       string     `json:"description"`
+	ReferenceNo       string     `json:"reference_no,omitempty"` // reference transaksi hasil capture
+	ExpiresAt         time.Time  `json:"expires_at"`
+	ClosedAt          *time.Time `json:"closed_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// ============== REQUEST MODELS ==============
+
+type RequestCreateHold struct {
+	AccountNumber     string `json:"-"` // dari token
+	BeneficiaryNumber string `json:"beneficiary_number" validate:"required"`
+	Amount            Money  `json:"amount" validate:"required,money_min=10000"`
+	ExpiresInMinutes  int    `json:"expires_in_minutes" validate:"required,min=1,max=43200"` // maksimal 30 hari
+	Description       string `json:"description" validate:"max=255"`
+	PIN               string `json:"pin" validate:"required,len=6"`
+}
+
+// RequestCaptureHold Amount kosong berarti capture penuh, sisa hold otomatis di-release
+type RequestCaptureHold struct {
+	ID     int   `json:"id" validate:"required,min=1"`
+	Amount Money `json:"amount" validate:"omitempty,money_min=1"`
+}
+
+type RequestHoldByID struct {
+	ID int `json:"id" validate:"required,min=1"`
+}
+
+// ============== RESPONSE MODELS ==============
+
+type HoldResponse struct {
+	Hold             Hold  `json:"hold"`
+	AvailableBalance Money `json:"available_balance"`
+}
+
+type HoldCaptureResponse struct {
+	Hold             Hold   `json:"hold"`
+	ReferenceNo      string `json:"reference_no"`
+	CapturedAmount   Money  `json:"captured_amount"`
+	ReleasedAmount   Money  `json:"released_amount"`
+	BalanceBefore    Money  `json:"balance_before"`
+	BalanceAfter     Money  `json:"balance_after"`
+	AvailableBalance Money  `json:"available_balance"`
+	Currency         string `json:"currency"`
+	TransactionDate  string `json:"transaction_date"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// IdempotencyRecord status dan response tersimpan untuk sebuah Idempotency-Key
+type IdempotencyRecord struct {
+	Fingerprint string    `json:"fingerprint"` // hash method + path + body request pertama
+	Status      string    `json:"status"`      // IN_PROGRESS atau COMPLETED
+	StatusCode  int       `json:"status_code"`
+	ContentType string    `json:"content_type"`
+	Body        []byte    `json:"body"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"sample/constans"
+	"time"
+)
+
+// LedgerAccount akun pada buku besar (akun sistem seperti CASH_VAULT dan FEE_INCOME)
+type LedgerAccount struct {
+	Code          string    `json:"code"`
+	Name          string    `json:"name"`
+	AccountType   string    `json:"account_type"`   // ASSET, LIABILITY, INCOME
+	NormalBalance string    `json:"normal_balance"` // 'D' atau 'C'
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// JournalEntry header jurnal double-entry, satu jurnal untuk setiap perpindahan uang
+type JournalEntry struct {
+	ID          int       `json:"id"`
+	ReferenceNo string    `json:"reference_no"`
+	JournalType string    `json:"journal_type"` // OPENING, DEPOSIT, WITHDRAW, TRANSFER, REVERSAL, FX_CONVERSION
+	Description string    `json:"description"`
+	ReversalOf  int       `json:"reversal_of,omitempty"` // ID jurnal asal untuk jurnal REVERSAL
+	Postings    []Posting `json:"postings"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Posting baris debit/kredit dari sebuah jurnal
+type Posting struct {
+	ID         int       `json:"id"`
+	JournalID  int       `json:"journal_id"`
+	LedgerCode string    `json:"ledger_code"` // nomor rekening nasabah atau kode akun sistem
+	Direction  string    `json:"direction"`   // 'D' for Debit, 'C' for Credit
+	Amount     Money     `json:"amount"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// SystemLedgerAccounts akun sistem yang wajib ada di buku besar, termasuk posisi valas per mata uang
+var SystemLedgerAccounts = []LedgerAccount{
+	{Code: constans.LEDGER_CASH_VAULT, Name: "Kas Teller", AccountType: "ASSET", NormalBalance: "D"},
+	{Code: constans.LEDGER_FEE_INCOME, Name: "Pendapatan Biaya", AccountType: "INCOME", NormalBalance: "C"},
+	{Code: FxPositionLedgerCode(constans.DEFAULT_CURRENCY), Name: "Posisi Valas IDR", AccountType: "ASSET", NormalBalance: "D"},
+	{Code: FxPositionLedgerCode(constans.CURRENCY_USD), Name: "Posisi Valas USD", AccountType: "ASSET", NormalBalance: "D"},
+	{Code: FxPositionLedgerCode(constans.CURRENCY_SGD), Name: "Posisi Valas SGD", AccountType: "ASSET", NormalBalance: "D"},
+}
+
+// Request Models
+
+type RequestLedgerBalance struct {
+	LedgerCode string `json:"ledger_code" validate:"required"`
+}
+
+type RequestJournalDetail struct {
+	JournalID   int    `json:"journal_id"`
+	ReferenceNo string `json:"reference_no"`
+}
+
+// Response Models
+
+// LedgerBalanceResponse saldo yang diturunkan dari seluruh posting
+type LedgerBalanceResponse struct {
+	LedgerCode    string `json:"ledger_code"`
+	NormalBalance string `json:"normal_balance"`
+	TotalDebit    Money  `json:"total_debit"`
+	TotalCredit   Money  `json:"total_credit"`
+	Balance       Money  `json:"balance"`
+}
+
+// UnbalancedJournal jurnal yang total debit dan kreditnya tidak sama untuk satu mata uang
+type UnbalancedJournal struct {
+	JournalID   int    `json:"journal_id"`
+	ReferenceNo string `json:"reference_no"`
+	TotalDebit  Money  `json:"total_debit"`
+	TotalCredit Money  `json:"total_credit"`
+}
+
+// LedgerMismatch akun nasabah yang saldonya berbeda dengan saldo ledger
+type LedgerMismatch struct {
+	AccountNumber  string `json:"account_number"`
+	AccountBalance Money  `json:"account_balance"`
+	LedgerBalance  Money  `json:"ledger_balance"`
+}
+
+type LedgerVerifyResponse struct {
+	Balanced           bool                `json:"balanced"`
+	UnbalancedJournals []UnbalancedJournal `json:"unbalanced_journals"`
+	Mismatches         []LedgerMismatch    `json:"mismatches"`
+}
+
+// IsBalanced memastikan total debit sama dengan total kredit untuk setiap mata uang.
+// Jurnal multi-currency (FX_CONVERSION) harus seimbang per mata uang, bukan total nominal
+func (j *JournalEntry) IsBalanced() bool {
+	net := map[string]int64{}
+	for _, p := range j.Postings {
+		switch p.Direction {
+		case "D":
+			net[p.Amount.CurrencyCode()] += p.Amount.Amount
+		case "C":
+			net[p.Amount.CurrencyCode()] -= p.Amount.Amount
+		default:
+			return false
+		}
+	}
+
+	for _, amount := range net {
+		if amount != 0 {
+			return false
+		}
+	}
+	return len(j.Postings) >= 2
+}
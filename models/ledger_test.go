@@ -0,0 +1,57 @@
+package models
+
+import (
+	"sample/constans"
+	"testing"
+)
+
+func TestJournalEntryIsBalanced(t *testing.T) {
+	idr := func(amount int64) Money { return NewMoney(amount, constans.DEFAULT_CURRENCY) }
+	usd := func(amount int64) Money { return NewMoney(amount, constans.CURRENCY_USD) }
+
+	tests := []struct {
+		name     string
+		postings []Posting
+		want     bool
+	}{
+		{"single currency", []Posting{
+			{Direction: "D", Amount: idr(10000)},
+			{Direction: "C", Amount: idr(10000)},
+		}, true},
+		{"debit exceeds credit", []Posting{
+			{Direction: "D", Amount: idr(10000)},
+			{Direction: "C", Amount: idr(9000)},
+		}, false},
+		{"balanced per currency", []Posting{
+			{Direction: "D", Amount: idr(1550000)},
+			{Direction: "C", Amount: idr(1550000)},
+			{Direction: "D", Amount: usd(100)},
+			{Direction: "C", Amount: usd(100)},
+		}, true},
+		{"totals match across currencies only", []Posting{
+			{Direction: "D", Amount: idr(10000)},
+			{Direction: "C", Amount: usd(10000)},
+		}, false},
+		{"one currency unbalanced", []Posting{
+			{Direction: "D", Amount: idr(1550000)},
+			{Direction: "C", Amount: idr(1550000)},
+			{Direction: "D", Amount: usd(100)},
+			{Direction: "C", Amount: usd(90)},
+			{Direction: "C", Amount: idr(10)},
+		}, false},
+		{"unknown direction", []Posting{
+			{Direction: "D", Amount: idr(10000)},
+			{Direction: "X", Amount: idr(10000)},
+		}, false},
+		{"single posting", []Posting{
+			{Direction: "D", Amount: idr(0)},
+		}, false},
+	}
+
+	for _, tt := range tests {
+		journal := JournalEntry{Postings: tt.postings}
+		if got := journal.IsBalanced(); got != tt.want {
+			t.Errorf("%s: IsBalanced = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"sample/constans"
+	"time"
+)
+
+// TransactionLimit aturan limit per tier akun dan jenis transaksi.
+// Nilai nol berarti tanpa batas
+type TransactionLimit struct {
+	Tier             string `json:"tier"`             // BASIC, PREMIUM
+	TransactionType  string `json:"transaction_type"` // DEPOSIT, WITHDRAW, TRANSFER
+	MaxSingleAmount  Money  `json:"max_single_amount"`
+	MaxDailyCount    int    `json:"max_daily_count"`
+	MaxDailyAmount   Money  `json:"max_daily_amount"`
+	MaxMonthlyAmount Money  `json:"max_monthly_amount"`
+}
+
+// LimitUsage pemakaian limit sebuah akun untuk satu jenis transaksi
+type LimitUsage struct {
+	DailyCount    int   `json:"daily_count"`
+	DailyAmount   Money `json:"daily_amount"`
+	MonthlyCount  int   `json:"monthly_count"`
+	MonthlyAmount Money `json:"monthly_amount"`
+}
+
+// DefaultTransactionLimits limit awal yang dibuat saat aplikasi start jika belum ada
+var DefaultTransactionLimits = []TransactionLimit{
+	{Tier: constans.ACCOUNT_TIER_BASIC, TransactionType: constans.JOURNAL_TYPE_DEPOSIT,
+		MaxSingleAmount: MustParseMoney("10000000", constans.DEFAULT_CURRENCY), MaxDailyCount: 10,
+		MaxDailyAmount: MustParseMoney("20000000", constans.DEFAULT_CURRENCY), MaxMonthlyAmount: MustParseMoney("100000000", constans.DEFAULT_CURRENCY)},
+	{Tier: constans.ACCOUNT_TIER_BASIC, TransactionType: constans.JOURNAL_TYPE_WITHDRAW,
+		MaxSingleAmount: MustParseMoney("5000000", constans.DEFAULT_CURRENCY), MaxDailyCount: 10,
+		MaxDailyAmount: MustParseMoney("10000000", constans.DEFAULT_CURRENCY), MaxMonthlyAmount: MustParseMoney("50000000", constans.DEFAULT_CURRENCY)},
+	{Tier: constans.ACCOUNT_TIER_BASIC, TransactionType: constans.JOURNAL_TYPE_TRANSFER,
+		MaxSingleAmount: MustParseMoney("10000000", constans.DEFAULT_CURRENCY), MaxDailyCount: 20,
+		MaxDailyAmount: MustParseMoney("25000000", constans.DEFAULT_CURRENCY), MaxMonthlyAmount: MustParseMoney("100000000", constans.DEFAULT_CURRENCY)},
+	{Tier: constans.ACCOUNT_TIER_PREMIUM, TransactionType: constans.JOURNAL_TYPE_DEPOSIT,
+		MaxSingleAmount: MustParseMoney("100000000", constans.DEFAULT_CURRENCY), MaxDailyCount: 50,
+		MaxDailyAmount: MustParseMoney("200000000", constans.DEFAULT_CURRENCY), MaxMonthlyAmount: MustParseMoney("1000000000", constans.DEFAULT_CURRENCY)},
+	{Tier: constans.ACCOUNT_TIER_PREMIUM, TransactionType: constans.JOURNAL_TYPE_WITHDRAW,
+		MaxSingleAmount: MustParseMoney("25000000", constans.DEFAULT_CURRENCY), MaxDailyCount: 20,
+		MaxDailyAmount: MustParseMoney("50000000", constans.DEFAULT_CURRENCY), MaxMonthlyAmount: MustParseMoney("500000000", constans.DEFAULT_CURRENCY)},
+	{Tier: constans.ACCOUNT_TIER_PREMIUM, TransactionType: constans.JOURNAL_TYPE_TRANSFER,
+		MaxSingleAmount: MustParseMoney("100000000", constans.DEFAULT_CURRENCY), MaxDailyCount: 50,
+		MaxDailyAmount: MustParseMoney("200000000", constans.DEFAULT_CURRENCY), MaxMonthlyAmount: MustParseMoney("1000000000", constans.DEFAULT_CURRENCY)},
+}
+
+// ============== RESPONSE MODELS ==============
+
+// LimitUsageResponse sisa limit akun untuk semua jenis transaksi
+type LimitUsageResponse struct {
+	AccountNumber string             `json:"account_number"`
+	AccountTier   string             `json:"account_tier"`
+	Currency      string             `json:"currency"`
+	Limits        []LimitUsageDetail `json:"limits"`
+	CheckedAt     time.Time          `json:"checked_at"`
+}
+
+// LimitUsageDetail limit, pemakaian dan sisa untuk satu jenis transaksi.
+// Remaining null berarti tanpa batas
+type LimitUsageDetail struct {
+	TransactionType        string `json:"transaction_type"`
+	MaxSingleAmount        Money  `json:"max_single_amount"`
+	DailyCountLimit        int    `json:"daily_count_limit"`
+	DailyCountUsed         int    `json:"daily_count_used"`
+	DailyCountRemaining    *int   `json:"daily_count_remaining"`
+	DailyAmountLimit       Money  `json:"daily_amount_limit"`
+	DailyAmountUsed        Money  `json:"daily_amount_used"`
+	DailyAmountRemaining   *Money `json:"daily_amount_remaining"`
+	MonthlyAmountLimit     Money  `json:"monthly_amount_limit"`
+	MonthlyAmountUsed      Money  `json:"monthly_amount_used"`
+	MonthlyAmountRemaining *Money `json:"monthly_amount_remaining"`
+}
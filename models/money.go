@@ -0,0 +1,223 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sample/constans"
+	"strconv"
+	"strings"
+)
+
+// moneyScale jumlah digit desimal (minor unit) yang dipakai semua mata uang
+const (
+	moneyScale  = 2
+	moneyFactor = int64(100)
+)
+
+// Money nilai uang eksak dalam satuan terkecil (minor unit) beserta kode mata uang.
+// JSON ditulis sebagai string desimal ("150000.00") agar tidak kehilangan presisi.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// NewMoney membuat Money dari minor unit
+func NewMoney(minorUnits int64, currency string) Money {
+	return Money{Amount: minorUnits, Currency: currency}
+}
+
+// ParseMoney mengubah string desimal ("10000", "10000.5", "-12.34") menjadi Money
+func ParseMoney(value string, currency string) (Money, error) {
+	s := strings.TrimSpace(value)
+	if s == "" {
+		return Money{}, errors.New("invalid amount: empty value")
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+
+	// Nol di belakang presisi diperbolehkan, contoh "100.000" dari kolom numeric
+	for len(fracPart) > moneyScale && strings.HasSuffix(fracPart, "0") {
+		fracPart = fracPart[:len(fracPart)-1]
+	}
+
+	if intPart == "" || len(fracPart) > moneyScale || !isDigits(intPart) || !isDigits(fracPart) {
+		return Money{}, fmt.Errorf("invalid amount: %q", value)
+	}
+
+	fracPart += strings.Repeat("0", moneyScale-len(fracPart))
+
+	whole, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil || whole > (1<<63-1)/moneyFactor {
+		return Money{}, fmt.Errorf("invalid amount: %q", value)
+	}
+	frac, _ := strconv.ParseInt(fracPart, 10, 64)
+
+	amount := whole*moneyFactor + frac
+	if negative {
+		amount = -amount
+	}
+
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// MustParseMoney seperti ParseMoney tetapi panic jika format salah, untuk konstanta
+func MustParseMoney(value string, currency string) Money {
+	m, err := ParseMoney(value, currency)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// CurrencyCode kode mata uang, default ke mata uang utama jika kosong
+func (m Money) CurrencyCode() string {
+	if m.Currency == "" {
+		return constans.DEFAULT_CURRENCY
+	}
+	return m.Currency
+}
+
+func (m Money) mustSameCurrency(o Money) {
+	if m.CurrencyCode() != o.CurrencyCode() {
+		panic(fmt.Sprintf("money: currency mismatch %s vs %s", m.CurrencyCode(), o.CurrencyCode()))
+	}
+}
+
+// Add menjumlahkan dua nilai dengan mata uang yang sama
+func (m Money) Add(o Money) Money {
+	m.mustSameCurrency(o)
+	return Money{Amount: m.Amount + o.Amount, Currency: m.CurrencyCode()}
+}
+
+// Sub mengurangi dua nilai dengan mata uang yang sama
+func (m Money) Sub(o Money) Money {
+	m.mustSameCurrency(o)
+	return Money{Amount: m.Amount - o.Amount, Currency: m.CurrencyCode()}
+}
+
+// Cmp membandingkan dua nilai: -1 jika m < o, 0 jika sama, 1 jika m > o
+func (m Money) Cmp(o Money) int {
+	m.mustSameCurrency(o)
+	switch {
+	case m.Amount < o.Amount:
+		return -1
+	case m.Amount > o.Amount:
+		return 1
+	}
+	return 0
+}
+
+// LessThan true jika m < o
+func (m Money) LessThan(o Money) bool {
+	return m.Cmp(o) < 0
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+// String format desimal tanpa pemisah ribuan, contoh "-1500.25"
+func (m Money) String() string {
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/moneyFactor, moneyScale, amount%moneyFactor)
+}
+
+// MarshalJSON menulis Money sebagai string desimal
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.String())
+}
+
+// UnmarshalJSON menerima string desimal ("10000.50") maupun angka JSON (10000.50)
+// tanpa melewati float64
+func (m *Money) UnmarshalJSON(data []byte) error {
+	raw := strings.TrimSpace(string(data))
+	if raw == "null" {
+		return nil
+	}
+
+	if strings.HasPrefix(raw, `"`) {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+	} else if strings.ContainsAny(raw, "eE") {
+		return fmt.Errorf("invalid amount: %s", raw)
+	}
+
+	parsed, err := ParseMoney(raw, m.CurrencyCode())
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+	return nil
+}
+
+// Scan membaca kolom numeric dari database tanpa konversi float
+func (m *Money) Scan(src interface{}) error {
+	var (
+		parsed Money
+		err    error
+	)
+
+	switch v := src.(type) {
+	case nil:
+		parsed = Money{}
+	case []byte:
+		parsed, err = ParseMoney(string(v), m.CurrencyCode())
+	case string:
+		parsed, err = ParseMoney(v, m.CurrencyCode())
+	case int64:
+		parsed = Money{Amount: v * moneyFactor}
+	default:
+		return fmt.Errorf("money: cannot scan %T", src)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	parsed.Currency = m.CurrencyCode()
+	*m = parsed
+	return nil
+}
+
+// Value menulis Money ke database sebagai string desimal (kolom numeric)
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
@@ -0,0 +1,10 @@
+package models
+
+// NotificationMessage pesan ke nasabah, dikirim ke PhoneNumber (sms) atau Email (email)
+type NotificationMessage struct {
+	AccountNumber string `json:"account_number"`
+	PhoneNumber   string `json:"phone_number,omitempty"`
+	Email         string `json:"email,omitempty"`
+	Subject       string `json:"subject,omitempty"`
+	Body          string `json:"body"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+)
+
+// PINLockEvent riwayat kunci / buka kunci PIN satu akun. Actor berisi SYSTEM untuk kunci
+// otomatis, ADMIN untuk unblock support staff, atau nomor akun untuk reset PIN oleh nasabah
+type PINLockEvent struct {
+	ID             int        `json:"id"`
+	AccountNumber  string     `json:"account_number"`
+	EventType      string     `json:"event_type"` // TEMPORARY_LOCK, PERMANENT_BLOCK, ADMIN_UNBLOCK, PIN_RESET
+	FailedAttempts int        `json:"failed_attempts"`
+	LockoutCount   int        `json:"lockout_count"`
+	LockedUntil    *time.Time `json:"locked_until,omitempty"`
+	Actor          string     `json:"actor"`
+	Reason         string     `json:"reason,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// ============== REQUEST MODELS ==============
+
+type RequestUnblockAccount struct {
+	AccountNumber string `json:"account_number" validate:"required"`
+	Reason        string `json:"reason" validate:"required,max=255"`
+}
+
+type RequestPINLockHistory struct {
+	AccountNumber string `json:"account_number" validate:"required"`
+}
+
+// ============== RESPONSE MODELS ==============
+
+type UnblockAccountResponse struct {
+	AccountNumber string    `json:"account_number"`
+	AccountStatus string    `json:"account_status"`
+	UnblockedAt   time.Time `json:"unblocked_at"`
+}
+
+type PINLockHistoryResponse struct {
+	AccountNumber     string         `json:"account_number"`
+	AccountStatus     string         `json:"account_status"`
+	FailedPINAttempts int            `json:"failed_pin_attempts"`
+	PINLockoutCount   int            `json:"pin_lockout_count"`
+	LockedUntil       *time.Time     `json:"locked_until,omitempty"`
+	Events            []PINLockEvent `json:"events"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// RateLimit batas jumlah request dalam jendela geser (sliding window) untuk satu grup route
+type RateLimit struct {
+	Limit  int
+	Window time.Duration
+}
+
+// RateLimitResult hasil pencatatan satu request ke limiter
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration // jeda sampai request berikutnya diterima, hanya jika !Allowed
+}
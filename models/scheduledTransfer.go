@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+)
+
+// ScheduledTransfer instruksi transfer terjadwal (sekali jalan atau berulang / standing order)
+type ScheduledTransfer struct {
+	ID                int        `json:"id"`
+	AccountID         int        `json:"account_id"`
+	FromAccountNumber string     `json:"source_number"`
+	ToAccountNumber   string     `json:"beneficiary_number"`
+	Amount            Money      `json:"amount"`
+	Frequency         string     `json:"frequency"` // ONCE, DAILY, WEEKLY, MONTHLY
+	Description       string     `json:"description"`
+	StartDate         time.Time  `json:"start_date"`
+	EndDate           *time.Time `json:"end_date,omitempty"`
+	NextRunAt         time.Time  `json:"next_run_at"` // jadwal occurrence berikutnya
+	RetryAt           *time.Time `json:"retry_at,omitempty"`
+	RetryCount        int        `json:"retry_count"`
+	Status            string     `json:"status"` // ACTIVE, PAUSED, COMPLETED, FAILED, CANCELLED
+	LastRunAt         *time.Time `json:"last_run_at,omitempty"`
+	LockedBy          string     `json:"-"`
+	LockedUntil       *time.Time `json:"-"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// ScheduledTransferExecution hasil setiap eksekusi jadwal oleh worker
+type ScheduledTransferExecution struct {
+	ID           int       `json:"id"`
+	ScheduleID   int       `json:"schedule_id"`
+	ScheduledFor time.Time `json:"scheduled_for"`
+	Attempt      int       `json:"attempt"`
+	Status       string    `json:"status"` // SUCCESS, RETRY, FAILED
+	ReferenceNo  string    `json:"reference_no,omitempty"`
+	Message      string    `json:"message"`
+	ExecutedAt   time.Time `json:"executed_at"`
+}
+
+// ============== REQUEST MODELS ==============
+
+type RequestCreateScheduledTransfer struct {
+	FromAccountNumber string `json:"-"` // dari token
+	ToAccountNumber   string `json:"beneficiary_number" validate:"required"`
+	Amount            Money  `json:"amount" validate:"required,money_min=10000"`
+	Frequency         string `json:"frequency" validate:"required,oneof=ONCE DAILY WEEKLY MONTHLY"`
+	StartDate         string `json:"start_date" validate:"required"` // Format: 2006-01-02
+	EndDate           string `json:"end_date"`                       // Format: 2006-01-02, wajib kosong untuk ONCE
+	Description       string `json:"description" validate:"max=255"`
+	PIN               string `json:"pin" validate:"required,len=6"`
+}
+
+// RequestUpdateScheduledTransfer PIN wajib jika amount diubah
+type RequestUpdateScheduledTransfer struct {
+	ID      int    `json:"id" validate:"required,min=1"`
+	Amount  Money  `json:"amount" validate:"omitempty,money_min=10000"`
+	EndDate string `json:"end_date"` // Format: 2006-01-02
+	Status  string `json:"status" validate:"omitempty,oneof=ACTIVE PAUSED"`
+	PIN     string `json:"pin" validate:"omitempty,len=6"`
+}
+
+type RequestScheduledTransferByID struct {
+	ID int `json:"id" validate:"required,min=1"`
+}
+
+// ============== RESPONSE MODELS ==============
+
+type ScheduledTransferDetailResponse struct {
+	Schedule   ScheduledTransfer            `json:"schedule"`
+	Executions []ScheduledTransferExecution `json:"executions"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Request Models
+
+// RequestStatement mutasi rekening satu periode, diunduh sebagai CSV atau PDF
+type RequestStatement struct {
+	AccountNumber string `json:"-"`                                               // dari token
+	StartDate     string `json:"start_date" validate:"required"`                  // Format: 2006-01-02
+	EndDate       string `json:"end_date" validate:"required"`                    // Format: 2006-01-02
+	Currency      string `json:"currency" validate:"omitempty,oneof=IDR USD SGD"` // pocket, default IDR
+	Format        string `json:"format" validate:"required,oneof=csv pdf"`
+}
+
+// Statement Models
+
+// StatementHeader identitas rekening dan saldo awal periode
+type StatementHeader struct {
+	AccountNumber  string
+	AccountName    string
+	Currency       string
+	StartDate      string
+	EndDate        string
+	OpeningBalance Money
+	GeneratedAt    time.Time
+}
+
+// StatementLine satu baris mutasi beserta saldo berjalan setelah transaksi
+type StatementLine struct {
+	TransactionID   int
+	TransactionTime time.Time
+	Description     string
+	Debit           Money
+	Credit          Money
+	Fee             Money
+	Balance         Money
+}
+
+// StatementSummary total mutasi dan saldo akhir periode
+type StatementSummary struct {
+	TotalDebit     Money
+	TotalCredit    Money
+	TotalFee       Money
+	ClosingBalance Money
+	LineCount      int
+}
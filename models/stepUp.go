@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+)
+
+// StepUpChallenge transaksi nominal besar yang menunggu konfirmasi OTP, disimpan di Redis
+// beserta hash OTP-nya. PIN di request sudah diverifikasi dan dikosongkan sebelum disimpan
+type StepUpChallenge struct {
+	ChallengeID   string           `json:"challenge_id"`
+	Type          string           `json:"type"` // WITHDRAW, TRANSFER
+	AccountNumber string           `json:"account_number"`
+	Withdraw      *RequestWithdraw `json:"withdraw,omitempty"`
+	Transfer      *RequestTransfer `json:"transfer,omitempty"`
+	ExpiresAt     time.Time        `json:"expires_at"`
+}
+
+// ============== REQUEST MODELS ==============
+
+type RequestConfirmTransaction struct {
+	ChallengeID string `json:"challenge_id" validate:"required"`
+	OTP         string `json:"otp" validate:"required,len=6,numeric"`
+}
+
+// ============== RESPONSE MODELS ==============
+
+// StepUpChallengeResponse dikembalikan withdraw / transfer (HTTP 202) saat OTP dibutuhkan
+type StepUpChallengeResponse struct {
+	ChallengeID string    `json:"challenge_id"`
+	Type        string    `json:"type"`
+	Amount      Money     `json:"amount"`
+	Channel     string    `json:"channel"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
@@ -14,18 +14,83 @@ type Transaction struct {
 	SourceNumber      string    `json:"source_number,omitempty"`
 	BeneficiaryNumber string    `json:"beneficiary_number,omitempty"`
 	TransactionType   string    `json:"transaction_type"` // 'D' for Debit, 'C' for Credit
-	Amount            float64   `json:"amount"`
+	Amount            Money     `json:"amount"`
+	Fee               Money     `json:"fee"`    // biaya yang dibebankan ke rekening ini, di luar amount. Negatif pada baris reversal yang mengembalikan biaya
+	Status            string    `json:"status"` // PENDING, SUCCESS, FAILED, REVERSED
+	JournalID         int       `json:"journal_id,omitempty"`
+	ReversalOf        int       `json:"reversal_of,omitempty"` // ID transaksi asal jika baris ini reversal
 	TransactionTime   time.Time `json:"transaction_time"`
 	CreatedAt         time.Time `json:"created_at"`
 }
 
+// transactionStatusTransitions state machine status transaksi.
+// PENDING untuk alur asinkron (misal transfer antarbank), SUCCESS dan FAILED final
+// kecuali SUCCESS yang boleh menjadi REVERSED setelah reversal penuh
+var transactionStatusTransitions = map[string][]string{
+	constans.TRANSACTION_STATUS_PENDING:  {constans.TRANSACTION_STATUS_SUCCESS, constans.TRANSACTION_STATUS_FAILED},
+	constans.TRANSACTION_STATUS_SUCCESS:  {constans.TRANSACTION_STATUS_REVERSED},
+	constans.TRANSACTION_STATUS_FAILED:   {},
+	constans.TRANSACTION_STATUS_REVERSED: {},
+}
+
+// CanTransitionTransactionStatus cek apakah perubahan status diizinkan
+func CanTransitionTransactionStatus(from, to string) bool {
+	for _, next := range transactionStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// MovesFunds transaksi yang sudah memindahkan dana (SUCCESS, atau REVERSED yang dikoreksi lewat baris reversal)
+func (t Transaction) MovesFunds() bool {
+	return t.Status == constans.TRANSACTION_STATUS_SUCCESS || t.Status == constans.TRANSACTION_STATUS_REVERSED
+}
+
+// BalanceEffect perubahan saldo rekening akibat baris ini: debit mengurangi amount + fee,
+// kredit menambah amount dikurangi fee (fee negatif pada reversal berarti biaya dikembalikan)
+func (t Transaction) BalanceEffect() Money {
+	effect := NewMoney(0, t.Amount.CurrencyCode())
+	if !t.MovesFunds() {
+		return effect
+	}
+
+	if t.TransactionType == "D" {
+		return effect.Sub(t.Amount).Sub(t.Fee)
+	}
+	return effect.Add(t.Amount).Sub(t.Fee)
+}
+
 // Request Models
 
 type RequestTransfer struct {
-	FromAccountNumber string  `json:"source_number" validate:"required"`
-	ToAccountNumber   string  `json:"beneficiary_number" validate:"required"`
-	Amount            float64 `json:"amount" validate:"required,min=10000"`
-	PIN               string  `json:"pin" validate:"required,len=6"`
+	FromAccountNumber string `json:"-"`                  // dari token
+	InquiryID         string `json:"inquiry_id"`         // opsional, dari /transfer-inquiry
+	ToAccountNumber   string `json:"beneficiary_number"` // wajib jika tanpa inquiry_id
+	Amount            Money  `json:"amount" validate:"omitempty,money_min=10000"`
+	Currency          string `json:"currency" validate:"omitempty,oneof=IDR USD SGD"` // pocket sumber dana, default IDR
+	Convert           bool   `json:"convert"`                                         // wajib true jika currency bukan IDR
+	PIN               string `json:"pin" validate:"required,len=6"`
+}
+
+type RequestTransferInquiry struct {
+	FromAccountNumber string `json:"-"` // dari token
+	ToAccountNumber   string `json:"beneficiary_number" validate:"required"`
+	Amount            Money  `json:"amount" validate:"required,money_min=10000"`
+}
+
+// TransferInquiry quote transfer yang disimpan di Redis sampai dikonfirmasi atau kedaluwarsa
+type TransferInquiry struct {
+	InquiryID         string    `json:"inquiry_id"`
+	FromAccountNumber string    `json:"source_number"`
+	ToAccountNumber   string    `json:"beneficiary_number"`
+	BeneficiaryName   string    `json:"beneficiary_name"`
+	Amount            Money     `json:"amount"`
+	Fee               Money     `json:"fee"`
+	TotalDebit        Money     `json:"total_debit"`
+	Currency          string    `json:"currency"`
+	ExpiresAt         time.Time `json:"expires_at"`
 }
 
 type RequestCheckBalance struct {
@@ -34,32 +99,44 @@ type RequestCheckBalance struct {
 }
 
 type RequestDeposit struct {
-	AccountNumber string  `json:"account_number" validate:"required"`
-	Amount        float64 `json:"amount" validate:"required,min=10000"`
-	PIN           string  `json:"pin" validate:"required,len=6"`
+	AccountNumber string `json:"-"` // dari token
+	Amount        Money  `json:"amount" validate:"required,money_min=10000"`
+	PIN           string `json:"pin" validate:"required,len=6"`
 }
 
 type RequestWithdraw struct {
-	AccountNumber string  `json:"account_number" validate:"required"`
-	Amount        float64 `json:"amount" validate:"required,min=10000"`
-	PIN           string  `json:"pin" validate:"required,len=6"`
+	AccountNumber string `json:"-"` // dari token
+	Amount        Money  `json:"amount" validate:"required,money_min=10000"`
+	PIN           string `json:"pin" validate:"required,len=6"`
 }
 
 type RequestTransactionHistory struct {
-	AccountNumber string `json:"account_number,omitempty"`
+	AccountNumber string `json:"-"`                                        // dari token
 	StartDate     string `json:"start_date,omitempty" validate:"required"` // Format: 2006-01-02
 	EndDate       string `json:"end_date,omitempty" validate:"required"`   // Format: 2006-01-02
 	Limit         int    `json:"limit,omitempty" validate:"required"`      // Default 10
 	Page          int    `json:"page,omitempty" validate:"required"`       // Default 1
+	Status        string `json:"status,omitempty" validate:"omitempty,oneof=PENDING SUCCESS FAILED REVERSED"`
 }
 
 type RequestTransactionDetail struct {
 	TransactionID int `json:"transaction_id" validate:"required,min=1"`
 }
 
+type RequestTransactionStatus struct {
+	TransactionID int `json:"transaction_id" validate:"required,min=1"`
+}
+
+// RequestReversal membatalkan transaksi penuh (amount kosong) atau sebagian (refund)
+type RequestReversal struct {
+	TransactionID int    `json:"transaction_id" validate:"required,min=1"`
+	Amount        Money  `json:"amount" validate:"omitempty,money_min=0.01"`
+	Reason        string `json:"reason" validate:"required,max=255"`
+}
+
 // Request model untuk Transaction History List V2
 type RequestTransactionHistoryList struct {
-	AccountNumber string `json:"account_number"`
+	AccountNumber string `json:"-"`          // dari token
 	StartDate     string `json:"start_date"` // Format: 2006-01-02
 	EndDate       string `json:"end_date"`   // Format: 2006-01-02
 	SearchValue   string `json:"search_value"`
@@ -68,6 +145,7 @@ type RequestTransactionHistoryList struct {
 	ColumnOrder   string `json:"column_order_name"` // Nama kolom untuk sorting
 	PageNumber    int    `json:"page_number"`
 	PageSize      int    `json:"page_size" validate:"required"`
+	Status        string `json:"status" validate:"omitempty,oneof=PENDING SUCCESS FAILED REVERSED"`
 }
 
 // Response Models
@@ -80,38 +158,77 @@ type TransactionResponse struct {
 	BeneficiaryNumber   string    `json:"beneficiary_number,omitempty"`
 	TransactionType     string    `json:"transaction_type"`
 	TransactionTypeDesc string    `json:"transaction_type_desc"` // Debit (Keluar) / Credit (Masuk)
-	Amount              float64   `json:"amount"`
+	Amount              Money     `json:"amount"`
 	Description         string    `json:"description"` // Deskripsi transaksi
 	TransactionTime     time.Time `json:"transaction_time"`
 }
 
 type DepositResponse struct {
-	AccountNumber   string  `json:"account_number"`
-	AccountName     string  `json:"account_name"`
-	BalanceBefore   float64 `json:"balance_before"`
-	Amount          float64 `json:"amount"`
-	BalanceAfter    float64 `json:"balance_after"`
-	TransactionDate string  `json:"transaction_date"`
+	ReferenceNo     string `json:"reference_no"`
+	AccountNumber   string `json:"account_number"`
+	AccountName     string `json:"account_name"`
+	BalanceBefore   Money  `json:"balance_before"`
+	Amount          Money  `json:"amount"`
+	BalanceAfter    Money  `json:"balance_after"`
+	Currency        string `json:"currency"`
+	TransactionDate string `json:"transaction_date"`
 }
 
 type WithdrawResponse struct {
-	AccountNumber   string  `json:"account_number"`
-	AccountName     string  `json:"account_name"`
-	BalanceBefore   float64 `json:"balance_before"`
-	Amount          float64 `json:"amount"`
-	BalanceAfter    float64 `json:"balance_after"`
-	TransactionDate string  `json:"transaction_date"`
+	ReferenceNo     string `json:"reference_no"`
+	AccountNumber   string `json:"account_number"`
+	AccountName     string `json:"account_name"`
+	BalanceBefore   Money  `json:"balance_before"`
+	Amount          Money  `json:"amount"`
+	Fee             Money  `json:"fee"`
+	TotalDebit      Money  `json:"total_debit"` // amount + fee
+	BalanceAfter    Money  `json:"balance_after"`
+	Currency        string `json:"currency"`
+	TransactionDate string `json:"transaction_date"`
 }
 
 type TransferResponse struct {
+	ReferenceNo       string    `json:"reference_no"`
 	FromAccountNumber string    `json:"source_number"`
 	ToAccountNumber   string    `json:"beneficiary_number"`
-	Amount            float64   `json:"amount"`
-	FromBalanceAfter  float64   `json:"from_balance_after"`
-	FromBalanceBefore float64   `json:"from_balance_before"`
-	ToBalanceAfter    float64   `json:"to_balance_after"`
-	ToBalanceBefore   float64   `json:"to_balance_before"`
+	Amount            Money     `json:"amount"`
+	Fee               Money     `json:"fee"`
+	TotalDebit        Money     `json:"total_debit"` // amount + fee
+	FromBalanceAfter  Money     `json:"from_balance_after"`
+	FromBalanceBefore Money     `json:"from_balance_before"`
+	ToBalanceAfter    Money     `json:"to_balance_after"`
+	ToBalanceBefore   Money     `json:"to_balance_before"`
+	Currency          string    `json:"currency"`
 	TransactionDate   time.Time `json:"transaction_date"`
+
+	Conversion *FxConversion `json:"conversion,omitempty"` // konversi pocket valas, transfer dengan convert=true
+}
+
+type ReversalResponse struct {
+	ReferenceNo         string          `json:"reference_no"`
+	ReversalOf          int             `json:"reversal_of"`
+	OriginalReferenceNo string          `json:"original_reference_no"`
+	JournalType         string          `json:"journal_type"` // jenis jurnal transaksi asal
+	Amount              Money           `json:"amount"`
+	TotalReversed       Money           `json:"total_reversed"`
+	RemainingAmount     Money           `json:"remaining_amount"`
+	FeeRefunded         Money           `json:"fee_refunded"` // biaya asal yang dikembalikan, hanya pada reversal penuh
+	Currency            string          `json:"currency"`
+	Reason              string          `json:"reason"`
+	Entries             []ReversalEntry `json:"entries"`
+	TransactionDate     string          `json:"transaction_date"`
+}
+
+// ReversalEntry baris kompensasi per rekening yang terdampak
+type ReversalEntry struct {
+	TransactionID   int    `json:"transaction_id"`
+	ReversalOf      int    `json:"reversal_of"`
+	AccountNumber   string `json:"account_number"`
+	TransactionType string `json:"transaction_type"`
+	Amount          Money  `json:"amount"`
+	FeeRefunded     Money  `json:"fee_refunded"`
+	BalanceBefore   Money  `json:"balance_before"`
+	BalanceAfter    Money  `json:"balance_after"`
 }
 
 type TransactionListResponse struct {
@@ -134,13 +251,16 @@ type PaginationMeta struct {
 
 type TransactionDetailResponse struct {
 	ID                int    `json:"id"`
+	ReversalOf        int    `json:"reversal_of,omitempty"`
+	Status            string `json:"status"`
 	AccountNumber     string `json:"account_number"`
 	AccountName       string `json:"account_name"`
 	SourceNumber      string `json:"source_number,omitempty"`
 	BeneficiaryNumber string `json:"beneficiary_number,omitempty"`
 	TransactionType   string `json:"transaction_type"`
 	// TransactionTypeDesc string    `json:"transaction_type_desc"`
-	Amount float64 `json:"amount"`
+	Amount Money `json:"amount"`
+	Fee    Money `json:"fee"`
 	// Description     string    `json:"description"`
 	// TransactionTime time.Time `json:"transaction_time"`
 	TransactionTime string `json:"transaction_time"`
@@ -153,7 +273,8 @@ type TransactionSimpleResponse struct {
 	AccountNumber   string `json:"account_number"`
 	TransactionType string `json:"transaction_type"`
 	// TransactionTypeDesc string    `json:"transaction_type_desc"` // Debit (Keluar) / Credit (Masuk)
-	Amount float64 `json:"amount"`
+	Amount Money  `json:"amount"`
+	Status string `json:"status"`
 	// TransactionTime time.Time `json:"transaction_time"`
 	TransactionTime string `json:"transaction_time"`
 }
@@ -171,10 +292,11 @@ type ResponseTransactionHistoryListV2 struct {
 	AccountName   string `json:"account_name"`
 	// SourceNumber      string    `json:"source_number,omitempty"`
 	// BeneficiaryNumber string    `json:"beneficiary_number,omitempty"`
-	TransactionType string  `json:"transaction_type"` // D atau C
-	Amount          float64 `json:"amount"`
-	TransactionTime string  `json:"transaction_time"` // Format: YYYY-MM-DD HH:MM:SS
-	CreatedAt       string  `json:"created_at"`       // Format: YYYY-MM-DD HH:MM:SS
+	TransactionType string `json:"transaction_type"` // D atau C
+	Amount          Money  `json:"amount"`
+	Status          string `json:"status"`           // PENDING, SUCCESS, FAILED, REVERSED
+	TransactionTime string `json:"transaction_time"` // Format: YYYY-MM-DD HH:MM:SS
+	CreatedAt       string `json:"created_at"`       // Format: YYYY-MM-DD HH:MM:SS
 }
 
 // Response wrapper untuk Transaction History V2
@@ -187,9 +309,9 @@ type ResponseTransactionHistoryV2 struct {
 
 // Result model untuk count dan summaries
 type ResultDataTableTransactionCountAndSummaries struct {
-	Count          int64   `json:"count"`
-	SumariesDebit  float64 `json:"sumaries_debit"`
-	SumariesCredit float64 `json:"sumaries_credit"`
+	Count          int64 `json:"count"`
+	SumariesDebit  Money `json:"sumaries_debit"`
+	SumariesCredit Money `json:"sumaries_credit"`
 }
 
 // Helper function untuk membuat deskripsi transaksi
@@ -264,6 +386,17 @@ func (t *Transaction) ToSimpleResponse() TransactionSimpleResponse {
 		TransactionType: t.TransactionType,
 		// TransactionTypeDesc: t.GetTypeDescription(),
 		Amount:          t.Amount,
+		Status:          t.Status,
 		TransactionTime: t.TransactionTime.Format(constans.LAYOUT_TIMESTAMP),
 	}
 }
+
+// TransactionStatusResponse status terkini sebuah transaksi
+type TransactionStatusResponse struct {
+	TransactionID   int    `json:"transaction_id"`
+	Status          string `json:"status"`
+	TransactionType string `json:"transaction_type"`
+	Amount          Money  `json:"amount"`
+	TotalReversed   Money  `json:"total_reversed"`
+	TransactionTime string `json:"transaction_time"`
+}
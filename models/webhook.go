@@ -0,0 +1,132 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookSubscription URL partner yang menerima callback untuk jenis event tertentu.
+// Secret dipakai untuk tanda tangan HMAC dan hanya ditampilkan sekali saat dibuat
+type WebhookSubscription struct {
+	ID          int       `json:"id"`
+	PartnerName string    `json:"partner_name"`
+	URL         string    `json:"url"`
+	Secret      string    `json:"-"`
+	EventTypes  []string  `json:"event_types"`
+	IsActive    bool      `json:"is_active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WebhookEvent baris outbox, ditulis dalam tx yang sama dengan perubahan datanya.
+// Payload adalah body callback lengkap sehingga setiap retry mengirim body yang identik
+type WebhookEvent struct {
+	ID           int             `json:"id"`
+	EventID      string          `json:"event_id"`
+	EventType    string          `json:"event_type"`
+	Payload      json.RawMessage `json:"payload"`
+	CreatedAt    time.Time       `json:"created_at"`
+	DispatchedAt *time.Time      `json:"dispatched_at,omitempty"`
+}
+
+// WebhookEnvelope body callback yang diterima partner
+type WebhookEnvelope struct {
+	EventID   string      `json:"event_id"`
+	EventType string      `json:"event_type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookDelivery pengiriman satu event ke satu subscription, di-retry dengan exponential backoff
+type WebhookDelivery struct {
+	ID             int             `json:"id"`
+	OutboxID       int             `json:"outbox_id"`
+	SubscriptionID int             `json:"subscription_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Status         string          `json:"status"` // PENDING, SUCCESS, FAILED
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	LockedBy       string          `json:"-"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	URL            string          `json:"url,omitempty"`
+	Secret         string          `json:"-"`
+	IsActive       bool            `json:"-"` // status subscription saat delivery di-claim
+	Payload        json.RawMessage `json:"payload,omitempty"`
+}
+
+// WebhookDeliveryAttempt log setiap percobaan pengiriman
+type WebhookDeliveryAttempt struct {
+	ID           int       `json:"id"`
+	DeliveryID   int       `json:"delivery_id"`
+	Attempt      int       `json:"attempt"`
+	StatusCode   int       `json:"status_code,omitempty"`
+	ResponseBody string    `json:"response_body,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	DurationMs   int64     `json:"duration_ms"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TransactionCreatedData isi event transaction.created
+type TransactionCreatedData struct {
+	Transaction
+	Currency string `json:"currency"`
+}
+
+// AccountBlockedData isi event account.blocked
+type AccountBlockedData struct {
+	AccountNumber  string    `json:"account_number"`
+	AccountStatus  string    `json:"account_status"`
+	FailedAttempts int       `json:"failed_attempts"`
+	BlockedAt      time.Time `json:"blocked_at"`
+}
+
+// PINResetData isi event pin.reset
+type PINResetData struct {
+	AccountNumber string    `json:"account_number"`
+	ResetAt       time.Time `json:"reset_at"`
+}
+
+// ============== REQUEST MODELS ==============
+
+type RequestCreateWebhookSubscription struct {
+	PartnerName string   `json:"partner_name" validate:"required,max=100"`
+	URL         string   `json:"url" validate:"required,url,max=500"`
+	EventTypes  []string `json:"event_types" validate:"required,min=1,dive,oneof=transaction.created account.blocked pin.reset"`
+}
+
+// RequestUpdateWebhookSubscription field kosong tidak diubah
+type RequestUpdateWebhookSubscription struct {
+	ID         int      `json:"id" validate:"required,min=1"`
+	URL        string   `json:"url" validate:"omitempty,url,max=500"`
+	EventTypes []string `json:"event_types" validate:"omitempty,min=1,dive,oneof=transaction.created account.blocked pin.reset"`
+	IsActive   *bool    `json:"is_active"`
+}
+
+type RequestWebhookDeliveryList struct {
+	SubscriptionID int    `json:"subscription_id"`
+	Status         string `json:"status" validate:"omitempty,oneof=PENDING SUCCESS FAILED"`
+	EventType      string `json:"event_type"`
+	Limit          int    `json:"limit" validate:"omitempty,min=1,max=100"` // default 50
+}
+
+type RequestWebhookDeliveryByID struct {
+	ID int `json:"id" validate:"required,min=1"`
+}
+
+// ============== RESPONSE MODELS ==============
+
+// WebhookSubscriptionResponse Secret hanya diisi saat subscription dibuat
+type WebhookSubscriptionResponse struct {
+	Subscription WebhookSubscription `json:"subscription"`
+	Secret       string              `json:"secret,omitempty"`
+}
+
+type WebhookDeliveryDetailResponse struct {
+	Delivery WebhookDelivery          `json:"delivery"`
+	Attempts []WebhookDeliveryAttempt `json:"attempts"`
+}
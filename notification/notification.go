@@ -0,0 +1,217 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/smtp"
+	"sample/models"
+	"sample/tracing"
+	"sample/utils"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Channel pengiriman yang bisa dipilih lewat NOTIFICATION_CHANNEL
+const (
+	ChannelConsole = "console"
+	ChannelSMS     = "sms"
+	ChannelEmail   = "email"
+)
+
+// Channels daftar nilai NOTIFICATION_CHANNEL yang valid
+var Channels = []string{ChannelConsole, ChannelSMS, ChannelEmail}
+
+// ErrNoRecipient akun tidak punya kontak untuk channel yang dipakai
+var ErrNoRecipient = errors.New("account has no contact for notification channel")
+
+// Channel pengirim pesan ke nasabah
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, message models.NotificationMessage) error
+}
+
+// New channel sesuai cfg.Notification.Channel, channel tidak dikenal jatuh ke console
+// (config.Validate sudah menolaknya saat start)
+func New(cfg models.Config) Channel {
+	switch cfg.Notification.Channel {
+	case ChannelSMS:
+		return NewSMSChannel(cfg.Notification)
+	case ChannelEmail:
+		return NewEmailChannel(cfg.Notification)
+	default:
+		return ConsoleChannel{}
+	}
+}
+
+// Send kirim pesan lewat channel dengan span notification.send
+func Send(ctx context.Context, channel Channel, message models.NotificationMessage) (err error) {
+	ctx, span := tracing.Start(ctx, "notification.send", attribute.String("notification.channel", channel.Name()))
+	defer func() { tracing.End(span, err) }()
+
+	return channel.Send(ctx, message)
+}
+
+// ConsoleChannel menulis pesan ke log, hanya untuk development
+type ConsoleChannel struct{}
+
+func (ConsoleChannel) Name() string {
+	return ChannelConsole
+}
+
+func (ConsoleChannel) Send(ctx context.Context, message models.NotificationMessage) error {
+	utils.LogInfo("Notification.Console", message.AccountNumber, "Send", message.Subject, message.Body)
+	return nil
+}
+
+// SMSChannel kirim SMS lewat HTTP gateway: POST JSON {"to", "message"} dengan Bearer API key
+type SMSChannel struct {
+	URL    string
+	APIKey string
+	Client *http.Client
+}
+
+func NewSMSChannel(cfg models.Notification) SMSChannel {
+	return SMSChannel{
+		URL:    cfg.SMSURL,
+		APIKey: cfg.SMSAPIKey,
+		Client: &http.Client{Timeout: cfg.Timeout()},
+	}
+}
+
+func (c SMSChannel) Name() string {
+	return ChannelSMS
+}
+
+func (c SMSChannel) Send(ctx context.Context, message models.NotificationMessage) error {
+	if message.PhoneNumber == "" {
+		return ErrNoRecipient
+	}
+
+	payload, err := json.Marshal(map[string]string{
+		"to":      message.PhoneNumber,
+		"message": message.Body,
+	})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if c.APIKey != "" {
+		request.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+
+	response, err := c.Client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(ioutil.Discard, io.LimitReader(response.Body, 4096))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("sms gateway responded %d", response.StatusCode)
+	}
+	return nil
+}
+
+// EmailChannel kirim email lewat SMTP, dengan STARTTLS jika server mendukung
+type EmailChannel struct {
+	Host    string
+	Port    string
+	User    string
+	Pass    string
+	From    string
+	Timeout time.Duration
+}
+
+func NewEmailChannel(cfg models.Notification) EmailChannel {
+	return EmailChannel{
+		Host:    cfg.SMTPHost,
+		Port:    cfg.SMTPPort,
+		User:    cfg.SMTPUser,
+		Pass:    cfg.SMTPPass,
+		From:    cfg.EmailFrom,
+		Timeout: cfg.Timeout(),
+	}
+}
+
+func (c EmailChannel) Name() string {
+	return ChannelEmail
+}
+
+func (c EmailChannel) Send(ctx context.Context, message models.NotificationMessage) error {
+	if message.Email == "" {
+		return ErrNoRecipient
+	}
+
+	dialer := net.Dialer{Timeout: c.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(c.Host, c.Port))
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(c.Timeout))
+
+	client, err := smtp.NewClient(conn, c.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(nil); err != nil {
+			return err
+		}
+	}
+	if c.User != "" {
+		if err := client.Auth(smtp.PlainAuth("", c.User, c.Pass, c.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(c.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(message.Email); err != nil {
+		return err
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(buildEmail(c.From, message)); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// buildEmail header dan body plain text, CR/LF di subject dibuang agar tidak bisa menyisipkan header
+func buildEmail(from string, message models.NotificationMessage) []byte {
+	subject := strings.NewReplacer("\r", "", "\n", "").Replace(message.Subject)
+
+	var buffer bytes.Buffer
+	buffer.WriteString("From: " + from + "\r\n")
+	buffer.WriteString("To: " + message.Email + "\r\n")
+	buffer.WriteString("Subject: " + subject + "\r\n")
+	buffer.WriteString("MIME-Version: 1.0\r\n")
+	buffer.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buffer.WriteString("\r\n")
+	buffer.WriteString(message.Body + "\r\n")
+	return buffer.Bytes()
+}
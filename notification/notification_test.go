@@ -0,0 +1,77 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sample/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSMSChannelSend(t *testing.T) {
+	var (
+		authorization string
+		payload       map[string]string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&payload)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	channel := New(models.Config{Notification: models.Notification{
+		Channel:        ChannelSMS,
+		SMSURL:         server.URL,
+		SMSAPIKey:      "key",
+		TimeoutSeconds: 1,
+	}})
+	err := Send(context.Background(), channel, models.NotificationMessage{
+		AccountNumber: "1000000001",
+		PhoneNumber:   "+6281234567890",
+		Body:          "Kode reset PIN: 123456",
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if authorization != "Bearer key" || payload["to"] != "+6281234567890" || payload["message"] != "Kode reset PIN: 123456" {
+		t.Errorf("authorization = %q payload = %v", authorization, payload)
+	}
+
+	if err := channel.Send(context.Background(), models.NotificationMessage{AccountNumber: "1000000001"}); err != ErrNoRecipient {
+		t.Errorf("Send without phone = %v, want ErrNoRecipient", err)
+	}
+}
+
+func TestSMSChannelGatewayError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	channel := SMSChannel{URL: server.URL, Client: &http.Client{Timeout: time.Second}}
+	err := channel.Send(context.Background(), models.NotificationMessage{PhoneNumber: "+6281234567890", Body: "x"})
+	if err == nil || !strings.Contains(err.Error(), "502") {
+		t.Errorf("Send = %v, want gateway status error", err)
+	}
+}
+
+func TestNewFallsBackToConsole(t *testing.T) {
+	if channel := New(models.Config{}); channel.Name() != ChannelConsole {
+		t.Errorf("channel = %s, want console", channel.Name())
+	}
+}
+
+func TestBuildEmailStripsHeaderInjection(t *testing.T) {
+	email := string(buildEmail("noreply@example.com", models.NotificationMessage{
+		Email:   "user@example.com",
+		Subject: "Reset PIN\r\nBcc: attacker@example.com",
+		Body:    "Kode reset PIN: 123456",
+	}))
+	if strings.Contains(email, "\r\nBcc:") {
+		t.Errorf("subject CR/LF not stripped:\n%s", email)
+	}
+}
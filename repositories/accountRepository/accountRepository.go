@@ -3,13 +3,14 @@ package accountRepository
 import (
 	"database/sql"
 	"errors"
+	"sample/constans"
 	"sample/helpers"
 	"sample/models"
 	"sample/repositories"
 	"time"
 )
 
-var defineColumn = `id, account_number, balance, pin, account_name, account_status, failed_pin_attempts, created_at, updated_at`
+var defineColumn = `id, account_number, balance, held_balance, pin, account_name, account_status, account_tier, failed_pin_attempts, pin_lockout_count, locked_until, COALESCE(phone_number, ''), COALESCE(email, ''), created_at, updated_at`
 
 type accountRepository struct {
 	RepoDB repositories.Repository
@@ -32,10 +33,16 @@ func (ctx accountRepository) FindAccountById(id int) (models.Account, error) {
 		&account.ID,
 		&account.AccountNumber,
 		&account.Balance,
+		&account.HeldBalance,
 		&account.PIN,
 		&account.AccountName,
 		&account.AccountStatus,
+		&account.AccountTier,
 		&account.FailedPINAttempts,
+		&account.PINLockoutCount,
+		&account.LockedUntil,
+		&account.PhoneNumber,
+		&account.Email,
 		&account.CreatedAt,
 		&account.UpdatedAt,
 	)
@@ -60,10 +67,16 @@ func (ctx accountRepository) FindAccountByNumber(accountNumber string) (models.A
 		&account.ID,
 		&account.AccountNumber,
 		&account.Balance,
+		&account.HeldBalance,
 		&account.PIN,
 		&account.AccountName,
 		&account.AccountStatus,
+		&account.AccountTier,
 		&account.FailedPINAttempts,
+		&account.PINLockoutCount,
+		&account.LockedUntil,
+		&account.PhoneNumber,
+		&account.Email,
 		&account.CreatedAt,
 		&account.UpdatedAt,
 	)
@@ -88,10 +101,16 @@ func (ctx accountRepository) IsAccountExistsByNumber(accountNumber string) (mode
 		&account.ID,
 		&account.AccountNumber,
 		&account.Balance,
+		&account.HeldBalance,
 		&account.PIN,
 		&account.AccountName,
 		&account.AccountStatus,
+		&account.AccountTier,
 		&account.FailedPINAttempts,
+		&account.PINLockoutCount,
+		&account.LockedUntil,
+		&account.PhoneNumber,
+		&account.Email,
 		&account.CreatedAt,
 		&account.UpdatedAt,
 	)
@@ -103,19 +122,46 @@ func (ctx accountRepository) IsAccountExistsByNumber(accountNumber string) (mode
 	return account, true
 }
 
+var queryAddAccount = `INSERT INTO account (
+				account_number, balance, pin, account_name, account_status, failed_pin_attempts, created_at, updated_at, account_tier, phone_number, email
+		) VALUES (
+				$1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''), NULLIF($11, '')
+		) RETURNING id`
+
 // AddAccount membuat akun baru
 func (ctx accountRepository) AddAccount(account models.Account) (int, error) {
 	var ID int
 
-	query := `INSERT INTO account (
-				account_number, balance, pin, account_name, account_status, failed_pin_attempts, created_at, updated_at
-		) VALUES (
-				$1, $2, $3, $4, $5, $6, $7, $8
-		) RETURNING id`
-
 	now := time.Now()
 	err := ctx.RepoDB.DB.QueryRow(
-		query,
+		queryAddAccount,
+		account.AccountNumber,
+		account.Balance,
+		account.PIN,
+		account.AccountName,
+		"ACTIVE", // Default status
+		0,        // Default failed attempts
+		now,
+		now,
+		accountTier(account),
+		account.PhoneNumber,
+		account.Email,
+	).Scan(&ID)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return ID, nil
+}
+
+// AddAccountWithTx membuat akun baru dalam transaksi
+func (ctx accountRepository) AddAccountWithTx(tx *sql.Tx, account models.Account) (int, error) {
+	var ID int
+
+	now := time.Now()
+	err := tx.QueryRow(
+		queryAddAccount,
 		account.AccountNumber,
 		account.Balance,
 		account.PIN,
@@ -124,6 +170,9 @@ func (ctx accountRepository) AddAccount(account models.Account) (int, error) {
 		0,        // Default failed attempts
 		now,
 		now,
+		accountTier(account),
+		account.PhoneNumber,
+		account.Email,
 	).Scan(&ID)
 
 	if err != nil {
@@ -137,12 +186,14 @@ func (ctx accountRepository) AddAccount(account models.Account) (int, error) {
 func (ctx accountRepository) UpdateAccount(account models.Account) (int, error) {
 	var strQuery = `UPDATE account 
 					SET account_name = $2, 
+					    phone_number = NULLIF($4, ''),
+					    email = NULLIF($5, ''),
 					    updated_at = $3
 					WHERE id = $1 AND deleted_at IS NULL
 					RETURNING id`
 
 	var ID int
-	err := ctx.RepoDB.DB.QueryRow(strQuery, account.ID, account.AccountName, time.Now()).Scan(&ID)
+	err := ctx.RepoDB.DB.QueryRow(strQuery, account.ID, account.AccountName, time.Now(), account.PhoneNumber, account.Email).Scan(&ID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return 0, errors.New("Account not found")
@@ -158,6 +209,8 @@ func (ctx accountRepository) UpdatePIN(accountNumber string, newPIN string) erro
 	query := `UPDATE account 
 			  SET pin = $1, 
 			      failed_pin_attempts = 0,
+			      pin_lockout_count = 0,
+			      locked_until = NULL,
 			      account_status = 'ACTIVE',
 			      updated_at = $2
 			  WHERE account_number = $3 AND deleted_at IS NULL`
@@ -179,75 +232,45 @@ func (ctx accountRepository) UpdatePIN(accountNumber string, newPIN string) erro
 	return nil
 }
 
-// ChangePINWithTx melakukan semua operasi ChangePIN dalam satu transaksi
-// yang mencakup: verifikasi PIN, increment failed attempts jika salah, update PIN jika benar
-func (ctx accountRepository) ChangePINWithTx(tx *sql.Tx, accountNumber, oldPIN, newPIN string, currentHashedPIN string) (int, error) {
-	// 1. Cek PIN lama
-	if !helpers.CheckPINHash(oldPIN, currentHashedPIN) {
-		// PIN salah: increment failed attempts
-		var failedAttempts int
-		query := `UPDATE account 
-				  SET failed_pin_attempts = failed_pin_attempts + 1,
-					  account_status = CASE 
-						  WHEN failed_pin_attempts + 1 >= 3 THEN 'BLOCKED_PIN'
-						  ELSE account_status 
-					  END,
-					  updated_at = $1
-				  WHERE account_number = $2 AND deleted_at IS NULL
-				  RETURNING failed_pin_attempts`
-
-		err := tx.QueryRow(query, time.Now(), accountNumber).Scan(&failedAttempts)
-		if err != nil {
-			if err == sql.ErrNoRows {
-				return 0, errors.New("Account not found")
-			}
-			return 0, err
-		}
-
-		return failedAttempts, errors.New("PIN_VERIFICATION_FAILED")
-	}
-
-	// 2. PIN benar: hash PIN baru
-	hashedPIN, err := helpers.HashPIN(newPIN)
-	if err != nil {
-		return 0, errors.New("Failed to hash new PIN")
-	}
-
-	// 3. Update PIN dan reset failed attempts
+// UpdatePINWithTx update PIN dalam transaksi
+func (ctx accountRepository) UpdatePINWithTx(tx *sql.Tx, accountNumber string, newPIN string) error {
 	query := `UPDATE account 
 			  SET pin = $1, 
 			      failed_pin_attempts = 0,
+			      pin_lockout_count = 0,
+			      locked_until = NULL,
 			      account_status = 'ACTIVE',
 			      updated_at = $2
 			  WHERE account_number = $3 AND deleted_at IS NULL`
 
-	result, err := tx.Exec(query, hashedPIN, time.Now(), accountNumber)
+	result, err := tx.Exec(query, newPIN, time.Now(), accountNumber)
 	if err != nil {
-		return 0, err
+		return err
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return 0, err
+		return err
 	}
 
 	if rowsAffected == 0 {
-		return 0, errors.New("Account not found")
+		return errors.New("Account not found")
 	}
 
-	return 0, nil
+	return nil
 }
 
-// UpdatePINWithTx update PIN dalam transaksi
-func (ctx accountRepository) UpdatePINWithTx(tx *sql.Tx, accountNumber string, newPIN string) error {
+// ResetFailedPINAttempts reset failed PIN attempts, jumlah kunci sementara dan buka blokir
+func (ctx accountRepository) ResetFailedPINAttempts(accountNumber string) error {
 	query := `UPDATE account 
-			  SET pin = $1, 
-			      failed_pin_attempts = 0,
+			  SET failed_pin_attempts = 0,
+			      pin_lockout_count = 0,
+			      locked_until = NULL,
 			      account_status = 'ACTIVE',
-			      updated_at = $2
-			  WHERE account_number = $3 AND deleted_at IS NULL`
+			      updated_at = $1
+			  WHERE account_number = $2 AND deleted_at IS NULL`
 
-	result, err := tx.Exec(query, newPIN, time.Now(), accountNumber)
+	result, err := ctx.RepoDB.DB.Exec(query, time.Now(), accountNumber)
 	if err != nil {
 		return err
 	}
@@ -264,65 +287,58 @@ func (ctx accountRepository) UpdatePINWithTx(tx *sql.Tx, accountNumber string, n
 	return nil
 }
 
-// IncrementFailedPINAttempts increment failed PIN attempts dan block jika >= 3
-func (ctx accountRepository) IncrementFailedPINAttempts(accountNumber string) (int, error) {
-	var failedAttempts int
-
-	query := `UPDATE account 
-			  SET failed_pin_attempts = failed_pin_attempts + 1,
-			      account_status = CASE 
-				      WHEN failed_pin_attempts + 1 >= 3 THEN 'BLOCKED_PIN'
-				      ELSE account_status 
-			      END,
-			      updated_at = $1
-			  WHERE account_number = $2 AND deleted_at IS NULL
-			  RETURNING failed_pin_attempts`
-
-	err := ctx.RepoDB.DB.QueryRow(query, time.Now(), accountNumber).Scan(&failedAttempts)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return 0, errors.New("Account not found")
-		}
-		return 0, err
-	}
-
-	return failedAttempts, nil
-}
+// FindAccountByNumberForUpdate mencari akun dan mengunci barisnya sampai tx selesai
+func (ctx accountRepository) FindAccountByNumberForUpdate(accountNumber string, tx *sql.Tx) (models.Account, error) {
+	var account models.Account
 
-// IncrementFailedPINAttemptsWithTransaction increment failed PIN attempts dengan transaksi
-func (ctx accountRepository) IncrementFailedPINAttemptsWithTransaction(tx *sql.Tx, accountNumber string) (int, error) {
-	var failedAttempts int
+	var query = `SELECT ` + defineColumn + ` FROM account WHERE account_number = $1 AND deleted_at IS NULL FOR UPDATE`
 
-	query := `UPDATE account 
-			  SET failed_pin_attempts = failed_pin_attempts + 1,
-			      account_status = CASE 
-				      WHEN failed_pin_attempts + 1 >= 3 THEN 'BLOCKED_PIN'
-				      ELSE account_status 
-			      END,
-			      updated_at = $1
-			  WHERE account_number = $2 AND deleted_at IS NULL
-			  RETURNING failed_pin_attempts`
+	err := tx.QueryRow(query, accountNumber).Scan(
+		&account.ID,
+		&account.AccountNumber,
+		&account.Balance,
+		&account.HeldBalance,
+		&account.PIN,
+		&account.AccountName,
+		&account.AccountStatus,
+		&account.AccountTier,
+		&account.FailedPINAttempts,
+		&account.PINLockoutCount,
+		&account.LockedUntil,
+		&account.PhoneNumber,
+		&account.Email,
+		&account.CreatedAt,
+		&account.UpdatedAt,
+	)
 
-	err := tx.QueryRow(query, time.Now(), accountNumber).Scan(&failedAttempts)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return 0, errors.New("Account not found")
+			return account, errors.New("Account not found")
 		}
-		return 0, err
+		return account, err
 	}
 
-	return failedAttempts, nil
+	return account, nil
 }
 
-// ResetFailedPINAttempts reset failed PIN attempts
-func (ctx accountRepository) ResetFailedPINAttempts(accountNumber string) error {
+// UpdatePINLockStateWithTx simpan status blokir, failed attempts, jumlah kunci sementara dan locked_until
+func (ctx accountRepository) UpdatePINLockStateWithTx(tx *sql.Tx, account models.Account) error {
 	query := `UPDATE account 
-			  SET failed_pin_attempts = 0,
-			      account_status = 'ACTIVE',
-			      updated_at = $1
-			  WHERE account_number = $2 AND deleted_at IS NULL`
-
-	result, err := ctx.RepoDB.DB.Exec(query, time.Now(), accountNumber)
+			  SET account_status = $1,
+			      failed_pin_attempts = $2,
+			      pin_lockout_count = $3,
+			      locked_until = $4,
+			      updated_at = $5
+			  WHERE account_number = $6 AND deleted_at IS NULL`
+
+	result, err := tx.Exec(query,
+		account.AccountStatus,
+		account.FailedPINAttempts,
+		account.PINLockoutCount,
+		account.LockedUntil,
+		time.Now(),
+		account.AccountNumber,
+	)
 	if err != nil {
 		return err
 	}
@@ -339,16 +355,79 @@ func (ctx accountRepository) ResetFailedPINAttempts(accountNumber string) error
 	return nil
 }
 
+// AddPINLockEvent mencatat riwayat kunci / buka kunci PIN
+func (ctx accountRepository) AddPINLockEvent(event models.PINLockEvent, tx *sql.Tx) (int, error) {
+	var ID int
+
+	query := `INSERT INTO account_pin_lock_event
+			  (account_number, event_type, failed_attempts, lockout_count, locked_until, actor, reason, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			  RETURNING id`
+
+	err := tx.QueryRow(query,
+		event.AccountNumber,
+		event.EventType,
+		event.FailedAttempts,
+		event.LockoutCount,
+		event.LockedUntil,
+		event.Actor,
+		event.Reason,
+		time.Now(),
+	).Scan(&ID)
+	if err != nil {
+		return 0, err
+	}
+
+	return ID, nil
+}
+
+// GetPINLockEvents riwayat kunci PIN akun, terbaru lebih dulu
+func (ctx accountRepository) GetPINLockEvents(accountNumber string) ([]models.PINLockEvent, error) {
+	var result []models.PINLockEvent
+
+	query := `SELECT id, account_number, event_type, failed_attempts, lockout_count, locked_until, actor, reason, created_at
+			  FROM account_pin_lock_event
+			  WHERE account_number = $1
+			  ORDER BY created_at DESC, id DESC`
+
+	rows, err := ctx.RepoDB.DB.Query(query, accountNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var event models.PINLockEvent
+		err := rows.Scan(
+			&event.ID,
+			&event.AccountNumber,
+			&event.EventType,
+			&event.FailedAttempts,
+			&event.LockoutCount,
+			&event.LockedUntil,
+			&event.Actor,
+			&event.Reason,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, event)
+	}
+
+	return result, rows.Err()
+}
+
 // IncrementDecrementLastBalance update saldo akun dengan operator (+/-) dan return last balance
-func (ctx accountRepository) IncrementDecrementLastBalance(accountID int, amount float64, debitCreditOperator string, updatedAt string, tx *sql.Tx) (lastBalance float64, err error) {
+func (ctx accountRepository) IncrementDecrementLastBalance(accountID int, amount models.Money, debitCreditOperator string, updatedAt string, tx *sql.Tx) (lastBalance models.Money, err error) {
 	var args []interface{}
 
 	if debitCreditOperator != "+" && debitCreditOperator != "-" {
-		return 0, errors.New("Invalid operator. Must be '+' or '-'")
+		return lastBalance, errors.New("Invalid operator. Must be '+' or '-'")
 	}
 
 	query := `
-		UPDATE account SET balance = ROUND((balance ` + debitCreditOperator + ` $1)::numeric, 2),
+		UPDATE account SET balance = balance ` + debitCreditOperator + ` $1::numeric,
 			updated_at = $2
 		WHERE id = $3 AND deleted_at IS NULL
 		RETURNING balance
@@ -365,14 +444,138 @@ func (ctx accountRepository) IncrementDecrementLastBalance(accountID int, amount
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return 0, errors.New("Account not found")
+			return lastBalance, errors.New("Account not found")
 		}
-		return 0, err
+		return lastBalance, err
 	}
 
 	return lastBalance, nil
 }
 
+// IncrementDecrementHeldBalance update total hold akun dengan operator (+/-) dan return saldo tersedia
+func (ctx accountRepository) IncrementDecrementHeldBalance(accountID int, amount models.Money, debitCreditOperator string, updatedAt string, tx *sql.Tx) (availableBalance models.Money, err error) {
+	if debitCreditOperator != "+" && debitCreditOperator != "-" {
+		return availableBalance, errors.New("Invalid operator. Must be '+' or '-'")
+	}
+
+	query := `
+		UPDATE account SET held_balance = held_balance ` + debitCreditOperator + ` $1::numeric,
+			updated_at = $2
+		WHERE id = $3 AND deleted_at IS NULL
+		RETURNING balance - held_balance
+	`
+
+	if tx != nil {
+		err = tx.QueryRow(query, amount, updatedAt, accountID).Scan(&availableBalance)
+	} else {
+		err = ctx.RepoDB.DB.QueryRow(query, amount, updatedAt, accountID).Scan(&availableBalance)
+	}
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return availableBalance, errors.New("Account not found")
+		}
+		return availableBalance, err
+	}
+
+	return availableBalance, nil
+}
+
+// GetAvailableBalance saldo tersedia (balance - held_balance). Di dalam tx dipanggil
+// setelah saldo di-update agar membaca row yang sudah terkunci
+func (ctx accountRepository) GetAvailableBalance(accountID int, tx *sql.Tx) (models.Money, error) {
+	var (
+		availableBalance models.Money
+		err              error
+	)
+
+	query := `SELECT balance - held_balance FROM account WHERE id = $1 AND deleted_at IS NULL`
+
+	if tx != nil {
+		err = tx.QueryRow(query, accountID).Scan(&availableBalance)
+	} else {
+		err = ctx.RepoDB.DB.QueryRow(query, accountID).Scan(&availableBalance)
+	}
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return availableBalance, errors.New("Account not found")
+		}
+		return availableBalance, err
+	}
+
+	return availableBalance, nil
+}
+
+// GetAccountPockets mendapatkan pocket valas rekening (mata uang selain mata uang utama)
+func (ctx accountRepository) GetAccountPockets(accountID int) ([]models.AccountPocket, error) {
+	var result []models.AccountPocket
+
+	query := `SELECT currency, balance, updated_at FROM account_pocket WHERE account_id = $1 ORDER BY currency`
+
+	rows, err := ctx.RepoDB.DB.Query(query, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var val models.AccountPocket
+		var balance string
+
+		if err := rows.Scan(&val.Currency, &balance, &val.UpdatedAt); err != nil {
+			return result, err
+		}
+
+		val.Balance, err = models.ParseMoney(balance, val.Currency)
+		if err != nil {
+			return result, err
+		}
+		val.AvailableBalance = val.Balance
+
+		result = append(result, val)
+	}
+
+	return result, rows.Err()
+}
+
+// IncrementDecrementPocketBalance update saldo pocket valas sesuai mata uang amount dengan operator (+/-)
+// dan return saldo terakhir. Pocket dibuat otomatis, saldo negatif harus dicek pemanggil di dalam tx
+func (ctx accountRepository) IncrementDecrementPocketBalance(accountID int, amount models.Money, debitCreditOperator string, updatedAt string, tx *sql.Tx) (lastBalance models.Money, err error) {
+	var balance string
+
+	if debitCreditOperator != "+" && debitCreditOperator != "-" {
+		return lastBalance, errors.New("Invalid operator. Must be '+' or '-'")
+	}
+
+	if amount.CurrencyCode() == constans.DEFAULT_CURRENCY {
+		return lastBalance, errors.New("Main currency balance must be updated through IncrementDecrementLastBalance")
+	}
+
+	signed := amount
+	if debitCreditOperator == "-" {
+		signed = models.NewMoney(-amount.Amount, amount.CurrencyCode())
+	}
+
+	query := `INSERT INTO account_pocket (account_id, currency, balance, created_at, updated_at)
+			  VALUES ($1, $2, $3::numeric, $4, $4)
+			  ON CONFLICT (account_id, currency)
+			  DO UPDATE SET balance = account_pocket.balance + EXCLUDED.balance, updated_at = EXCLUDED.updated_at
+			  RETURNING balance`
+
+	if tx != nil {
+		err = tx.QueryRow(query, accountID, amount.CurrencyCode(), signed, updatedAt).Scan(&balance)
+	} else {
+		err = ctx.RepoDB.DB.QueryRow(query, accountID, amount.CurrencyCode(), signed, updatedAt).Scan(&balance)
+	}
+
+	if err != nil {
+		return lastBalance, err
+	}
+
+	return models.ParseMoney(balance, amount.CurrencyCode())
+}
+
 // RemoveAccount soft delete akun
 func (ctx accountRepository) RemoveAccount(id int) error {
 	result, err := ctx.RepoDB.DB.Exec(
@@ -415,23 +618,23 @@ func (ctx accountRepository) GetAccountList() ([]models.Account, error) {
 }
 
 // GetAccountBalance mendapatkan saldo akun
-func (ctx accountRepository) GetAccountBalance(accountNumber string) (float64, error) {
-	var balance float64
+func (ctx accountRepository) GetAccountBalance(accountNumber string) (models.Money, error) {
+	var balance models.Money
 
 	query := `SELECT balance FROM account WHERE account_number = $1 AND deleted_at IS NULL`
 
 	err := ctx.RepoDB.DB.QueryRow(query, accountNumber).Scan(&balance)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return 0, errors.New("Account not found")
+			return balance, errors.New("Account not found")
 		}
-		return 0, err
+		return balance, err
 	}
 
 	return balance, nil
 }
 
-// VerifyPIN verifikasi PIN akun
+// VerifyPIN verifikasi PIN akun terhadap hash bcrypt yang tersimpan
 func (ctx accountRepository) VerifyPIN(accountNumber string, pin string) (bool, error) {
 	var storedPIN string
 
@@ -445,7 +648,15 @@ func (ctx accountRepository) VerifyPIN(accountNumber string, pin string) (bool,
 		return false, err
 	}
 
-	return storedPIN == pin, nil
+	return helpers.CheckPINHash(pin, storedPIN), nil
+}
+
+// accountTier tier akun baru, default BASIC
+func accountTier(account models.Account) string {
+	if account.AccountTier == "" {
+		return constans.ACCOUNT_TIER_BASIC
+	}
+	return account.AccountTier
 }
 
 // accountDto helper untuk mapping rows ke struct
@@ -458,10 +669,16 @@ func accountDto(rows *sql.Rows) ([]models.Account, error) {
 			&val.ID,
 			&val.AccountNumber,
 			&val.Balance,
+			&val.HeldBalance,
 			&val.PIN,
 			&val.AccountName,
 			&val.AccountStatus,
+			&val.AccountTier,
 			&val.FailedPINAttempts,
+			&val.PINLockoutCount,
+			&val.LockedUntil,
+			&val.PhoneNumber,
+			&val.Email,
 			&val.CreatedAt,
 			&val.UpdatedAt,
 		)
@@ -0,0 +1,142 @@
+package auditRepository
+
+import (
+	"context"
+	"errors"
+	"sample/constans"
+	"sample/models"
+	"sample/repositories"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Batas waktu setiap operasi ke MongoDB agar audit tidak menahan request terlalu lama
+const mongoTimeout = 5 * time.Second
+
+type auditRepository struct {
+	RepoDB repositories.Repository
+}
+
+// NewAuditRepository
+func NewAuditRepository(repoDB repositories.Repository) auditRepository {
+	return auditRepository{
+		RepoDB: repoDB,
+	}
+}
+
+// collection audit_log, error jika koneksi MongoDB tidak tersedia
+func (ctx auditRepository) collection() (*mongo.Collection, error) {
+	if ctx.RepoDB.MongoDB == nil {
+		return nil, errors.New("MongoDB is not connected")
+	}
+	return ctx.RepoDB.MongoDB.Collection(constans.AUDIT_COLLECTION), nil
+}
+
+func (ctx auditRepository) context() (context.Context, context.CancelFunc) {
+	parent := ctx.RepoDB.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	return context.WithTimeout(parent, mongoTimeout)
+}
+
+// EnsureAuditIndexes index untuk filter query audit (account, actor, action) yang selalu diurutkan per waktu
+func (ctx auditRepository) EnsureAuditIndexes() error {
+	collection, err := ctx.collection()
+	if err != nil {
+		return err
+	}
+
+	mongoCtx, cancel := ctx.context()
+	defer cancel()
+
+	_, err = collection.Indexes().CreateMany(mongoCtx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "account_number", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "actor", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "action", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "reference_no", Value: 1}}},
+	})
+	return err
+}
+
+// AddAuditLog insert satu catatan audit. Repository sengaja tidak menyediakan update/delete
+func (ctx auditRepository) AddAuditLog(auditLog models.AuditLog) (string, error) {
+	collection, err := ctx.collection()
+	if err != nil {
+		return "", err
+	}
+
+	mongoCtx, cancel := ctx.context()
+	defer cancel()
+
+	if auditLog.CreatedAt.IsZero() {
+		auditLog.CreatedAt = time.Now()
+	}
+
+	inserted, err := collection.InsertOne(mongoCtx, auditLog)
+	if err != nil {
+		return "", err
+	}
+
+	if id, ok := inserted.InsertedID.(primitive.ObjectID); ok {
+		return id.Hex(), nil
+	}
+	return "", nil
+}
+
+// GetAuditLogs catatan audit terbaru lebih dulu, filter account, actor, action dan rentang waktu opsional
+func (ctx auditRepository) GetAuditLogs(filter models.RequestAuditLogList) ([]models.AuditLog, error) {
+	var result []models.AuditLog
+
+	collection, err := ctx.collection()
+	if err != nil {
+		return result, err
+	}
+
+	mongoCtx, cancel := ctx.context()
+	defer cancel()
+
+	query := bson.M{}
+	if filter.AccountNumber != "" {
+		query["account_number"] = filter.AccountNumber
+	}
+	if filter.Actor != "" {
+		query["actor"] = filter.Actor
+	}
+	if filter.Action != "" {
+		query["action"] = filter.Action
+	}
+
+	createdAt := bson.M{}
+	if !filter.StartAt.IsZero() {
+		createdAt["$gte"] = filter.StartAt
+	}
+	if !filter.EndAt.IsZero() {
+		createdAt["$lte"] = filter.EndAt
+	}
+	if len(createdAt) > 0 {
+		query["created_at"] = createdAt
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64((filter.Page - 1) * filter.Limit)).
+		SetLimit(int64(filter.Limit))
+
+	cursor, err := collection.Find(mongoCtx, query, findOptions)
+	if err != nil {
+		return result, err
+	}
+	defer cursor.Close(mongoCtx)
+
+	if err := cursor.All(mongoCtx, &result); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
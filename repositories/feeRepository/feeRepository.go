@@ -0,0 +1,77 @@
+package feeRepository
+
+import (
+	"encoding/json"
+	"sample/models"
+	"sample/repositories"
+	"time"
+)
+
+var defineColumn = `transaction_type, fee_type, flat_amount, percentage_bps, min_fee, max_fee, tiers, free_quota_monthly`
+
+type feeRepository struct {
+	RepoDB repositories.Repository
+}
+
+// NewFeeRepository
+func NewFeeRepository(repoDB repositories.Repository) feeRepository {
+	return feeRepository{
+		RepoDB: repoDB,
+	}
+}
+
+// EnsureDefaultFeeRules membuat skema biaya default jika belum ada,
+// skema yang sudah diubah di database tidak ditimpa
+func (ctx feeRepository) EnsureDefaultFeeRules() error {
+	query := `INSERT INTO fee_rule (` + defineColumn + `, created_at, updated_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+			  ON CONFLICT (transaction_type) DO NOTHING`
+
+	now := time.Now()
+	for _, r := range models.DefaultFeeRules {
+		tiers, err := json.Marshal(r.Tiers)
+		if err != nil {
+			return err
+		}
+
+		if _, err := ctx.RepoDB.DB.Exec(query, r.TransactionType, r.FeeType, r.FlatAmount, r.PercentageBps,
+			r.MinFee, r.MaxFee, tiers, r.FreeQuotaMonthly, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FindFeeRule mencari skema biaya untuk jenis transaksi,
+// sql.ErrNoRows jika jenis transaksi tidak dikenakan biaya
+func (ctx feeRepository) FindFeeRule(transactionType string) (models.FeeRule, error) {
+	var (
+		rule  models.FeeRule
+		tiers []byte
+	)
+
+	query := `SELECT ` + defineColumn + ` FROM fee_rule WHERE transaction_type = $1`
+
+	err := ctx.RepoDB.DB.QueryRow(query, transactionType).Scan(
+		&rule.TransactionType,
+		&rule.FeeType,
+		&rule.FlatAmount,
+		&rule.PercentageBps,
+		&rule.MinFee,
+		&rule.MaxFee,
+		&tiers,
+		&rule.FreeQuotaMonthly,
+	)
+	if err != nil {
+		return rule, err
+	}
+
+	if len(tiers) > 0 {
+		if err := json.Unmarshal(tiers, &rule.Tiers); err != nil {
+			return rule, err
+		}
+	}
+
+	return rule, nil
+}
@@ -0,0 +1,110 @@
+package fxRepository
+
+import (
+	"database/sql"
+	"errors"
+	"sample/models"
+	"sample/repositories"
+	"time"
+)
+
+var defineColumn = `id, base_currency, quote_currency, rate, spread_bps, effective_at, created_at`
+
+type fxRepository struct {
+	RepoDB repositories.Repository
+}
+
+// NewFxRepository
+func NewFxRepository(repoDB repositories.Repository) fxRepository {
+	return fxRepository{
+		RepoDB: repoDB,
+	}
+}
+
+// AddFxRate mencatat kurs baru. Kurs lama tidak diubah agar riwayat kurs tetap utuh
+func (ctx fxRepository) AddFxRate(rate models.FxRate) (int, error) {
+	var ID int
+
+	query := `INSERT INTO fx_rate (base_currency, quote_currency, rate, spread_bps, effective_at, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+
+	err := ctx.RepoDB.DB.QueryRow(query,
+		rate.BaseCurrency,
+		rate.QuoteCurrency,
+		rate.Rate,
+		rate.SpreadBps,
+		rate.EffectiveAt,
+		time.Now(),
+	).Scan(&ID)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return ID, nil
+}
+
+// FindEffectiveFxRate mencari kurs terbaru yang sudah berlaku untuk pasangan mata uang,
+// dari arah mana pun (USD/IDR berlaku juga untuk konversi IDR ke USD)
+func (ctx fxRepository) FindEffectiveFxRate(fromCurrency, toCurrency string, at time.Time) (models.FxRate, error) {
+	var rate models.FxRate
+
+	query := `SELECT ` + defineColumn + ` FROM fx_rate
+			  WHERE ((base_currency = $1 AND quote_currency = $2) OR (base_currency = $2 AND quote_currency = $1))
+			    AND effective_at <= $3
+			  ORDER BY effective_at DESC, id DESC
+			  LIMIT 1`
+
+	err := ctx.RepoDB.DB.QueryRow(query, fromCurrency, toCurrency, at).Scan(
+		&rate.ID,
+		&rate.BaseCurrency,
+		&rate.QuoteCurrency,
+		&rate.Rate,
+		&rate.SpreadBps,
+		&rate.EffectiveAt,
+		&rate.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return rate, errors.New("Exchange rate not found")
+		}
+		return rate, err
+	}
+
+	return rate, nil
+}
+
+// GetFxRateList riwayat kurs, filter pasangan mata uang opsional
+func (ctx fxRepository) GetFxRateList(baseCurrency, quoteCurrency string) ([]models.FxRate, error) {
+	var result []models.FxRate
+
+	query := `SELECT ` + defineColumn + ` FROM fx_rate
+			  WHERE ($1 = '' OR base_currency = $1) AND ($2 = '' OR quote_currency = $2)
+			  ORDER BY effective_at DESC, id DESC
+			  LIMIT 100`
+
+	rows, err := ctx.RepoDB.DB.Query(query, baseCurrency, quoteCurrency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var val models.FxRate
+		err := rows.Scan(
+			&val.ID,
+			&val.BaseCurrency,
+			&val.QuoteCurrency,
+			&val.Rate,
+			&val.SpreadBps,
+			&val.EffectiveAt,
+			&val.CreatedAt,
+		)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, val)
+	}
+
+	return result, rows.Err()
+}
@@ -0,0 +1,36 @@
+package healthRepository
+
+import (
+	"context"
+	"errors"
+	"sample/repositories"
+
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+type healthRepository struct {
+	RepoDB repositories.Repository
+}
+
+// NewHealthRepository
+func NewHealthRepository(repoDB repositories.Repository) healthRepository {
+	return healthRepository{
+		RepoDB: repoDB,
+	}
+}
+
+// PingDB cek koneksi Postgres, dibatasi deadline ctx
+func (ctx healthRepository) PingDB(c context.Context) error {
+	if ctx.RepoDB.DB == nil {
+		return errors.New("Postgres is not connected")
+	}
+	return ctx.RepoDB.DB.PingContext(c)
+}
+
+// PingMongo cek koneksi MongoDB ke primary, dibatasi deadline ctx
+func (ctx healthRepository) PingMongo(c context.Context) error {
+	if ctx.RepoDB.MongoDB == nil {
+		return errors.New("MongoDB is not connected")
+	}
+	return ctx.RepoDB.MongoDB.Client().Ping(c, readpref.Primary())
+}
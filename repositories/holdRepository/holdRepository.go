@@ -0,0 +1,212 @@
+package holdRepository
+
+import (
+	"database/sql"
+	"errors"
+	"sample/constans"
+	"sample/models"
+	"sample/repositories"
+	"time"
+)
+
+var defineColumn = `id, account_id, account_number, beneficiary_number, amount, captured_amount, status,
+					description, reference_no, expires_at, closed_at, created_at, updated_at`
+
+type holdRepository struct {
+	RepoDB repositories.Repository
+}
+
+// NewHoldRepository
+func NewHoldRepository(repoDB repositories.Repository) holdRepository {
+	return holdRepository{
+		RepoDB: repoDB,
+	}
+}
+
+// AddHold mencatat hold baru, ikut dalam tx bersama update held_balance akun
+func (ctx holdRepository) AddHold(hold models.Hold, tx *sql.Tx) (int, error) {
+	var (
+		ID  int
+		err error
+	)
+
+	query := `INSERT INTO account_hold (
+				account_id, account_number, beneficiary_number, amount, captured_amount, status,
+				description, expires_at, created_at, updated_at
+		) VALUES (
+				$1, $2, $3, $4, $5, $6, $7, $8, $9, $9
+		) RETURNING id`
+
+	args := []interface{}{
+		hold.AccountID,
+		hold.AccountNumber,
+		hold.BeneficiaryNumber,
+		hold.Amount,
+		models.NewMoney(0, hold.Amount.CurrencyCode()),
+		constans.HOLD_STATUS_ACTIVE,
+		hold.Description,
+		hold.ExpiresAt,
+		time.Now(),
+	}
+
+	if tx != nil {
+		err = tx.QueryRow(query, args...).Scan(&ID)
+	} else {
+		err = ctx.RepoDB.DB.QueryRow(query, args...).Scan(&ID)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return ID, nil
+}
+
+// FindHoldById mencari hold berdasarkan ID
+func (ctx holdRepository) FindHoldById(id int) (models.Hold, error) {
+	query := `SELECT ` + defineColumn + ` FROM account_hold WHERE id = $1`
+
+	rows, err := ctx.RepoDB.DB.Query(query, id)
+	if err != nil {
+		return models.Hold{}, err
+	}
+	defer rows.Close()
+
+	return firstHold(rows)
+}
+
+// FindHoldByIdForUpdate mencari hold dan mengunci barisnya sampai tx selesai
+func (ctx holdRepository) FindHoldByIdForUpdate(id int, tx *sql.Tx) (models.Hold, error) {
+	query := `SELECT ` + defineColumn + ` FROM account_hold WHERE id = $1 FOR UPDATE`
+
+	rows, err := tx.Query(query, id)
+	if err != nil {
+		return models.Hold{}, err
+	}
+	defer rows.Close()
+
+	return firstHold(rows)
+}
+
+// GetHoldsByAccount mendapatkan hold milik rekening atau yang ditujukan ke rekening (merchant)
+func (ctx holdRepository) GetHoldsByAccount(accountNumber string) ([]models.Hold, error) {
+	query := `SELECT ` + defineColumn + ` FROM account_hold
+			  WHERE account_number = $1 OR beneficiary_number = $1
+			  ORDER BY created_at DESC`
+
+	rows, err := ctx.RepoDB.DB.Query(query, accountNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return holdDto(rows)
+}
+
+// CloseHoldWithTx menutup hold yang masih ACTIVE (CAPTURED, RELEASED atau EXPIRED).
+// Guard status di WHERE memastikan hold tidak ditutup dua kali
+func (ctx holdRepository) CloseHoldWithTx(tx *sql.Tx, hold models.Hold) error {
+	query := `UPDATE account_hold
+			  SET status = $2,
+			      captured_amount = $3,
+			      reference_no = $4,
+			      closed_at = $5,
+			      updated_at = $5
+			  WHERE id = $1 AND status = $6`
+
+	result, err := tx.Exec(query,
+		hold.ID,
+		hold.Status,
+		hold.CapturedAmount,
+		hold.ReferenceNo,
+		time.Now(),
+		constans.HOLD_STATUS_ACTIVE,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("Hold is no longer active")
+	}
+
+	return nil
+}
+
+// GetExpiredHoldIDs mendapatkan ID hold ACTIVE yang sudah melewati expires_at
+func (ctx holdRepository) GetExpiredHoldIDs(now time.Time, limit int) ([]int, error) {
+	var result []int
+
+	query := `SELECT id FROM account_hold
+			  WHERE status = $1 AND expires_at <= $2
+			  ORDER BY expires_at LIMIT $3`
+
+	rows, err := ctx.RepoDB.DB.Query(query, constans.HOLD_STATUS_ACTIVE, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return result, err
+		}
+		result = append(result, id)
+	}
+
+	return result, rows.Err()
+}
+
+// firstHold mengambil satu hold dari hasil query
+func firstHold(rows *sql.Rows) (models.Hold, error) {
+	result, err := holdDto(rows)
+	if err != nil {
+		return models.Hold{}, err
+	}
+
+	if len(result) == 0 {
+		return models.Hold{}, errors.New("Hold not found")
+	}
+
+	return result[0], nil
+}
+
+// holdDto helper untuk mapping rows ke struct
+func holdDto(rows *sql.Rows) ([]models.Hold, error) {
+	var result []models.Hold
+
+	for rows.Next() {
+		var val models.Hold
+		var referenceNo sql.NullString
+
+		err := rows.Scan(
+			&val.ID,
+			&val.AccountID,
+			&val.AccountNumber,
+			&val.BeneficiaryNumber,
+			&val.Amount,
+			&val.CapturedAmount,
+			&val.Status,
+			&val.Description,
+			&referenceNo,
+			&val.ExpiresAt,
+			&val.ClosedAt,
+			&val.CreatedAt,
+			&val.UpdatedAt,
+		)
+		if err != nil {
+			return result, err
+		}
+
+		val.ReferenceNo = referenceNo.String
+		result = append(result, val)
+	}
+
+	return result, rows.Err()
+}
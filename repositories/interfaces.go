@@ -1,8 +1,10 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"sample/models"
+	"time"
 )
 
 // AccountRepository
@@ -11,13 +13,20 @@ type AccountRepository interface {
 	FindAccountByNumber(accountNumber string) (models.Account, error)
 	IsAccountExistsByNumber(accountNumber string) (models.Account, bool)
 	AddAccount(account models.Account) (int, error)
+	AddAccountWithTx(tx *sql.Tx, account models.Account) (int, error)
 	UpdateAccount(account models.Account) (int, error)
 	UpdatePIN(accountNumber string, newPIN string) error
 	UpdatePINWithTx(tx *sql.Tx, accountNumber string, newPIN string) error
-	ChangePINWithTx(tx *sql.Tx, accountNumber, oldPIN, newPIN string, currentHashedPIN string) (int, error)
-	IncrementFailedPINAttempts(accountNumber string) (int, error)
 	ResetFailedPINAttempts(accountNumber string) error
-	IncrementDecrementLastBalance(accountID int, amount float64, debitCreditOperator string, updatedAt string, tx *sql.Tx) (lastBalance float64, err error)
+	FindAccountByNumberForUpdate(accountNumber string, tx *sql.Tx) (models.Account, error)
+	UpdatePINLockStateWithTx(tx *sql.Tx, account models.Account) error
+	AddPINLockEvent(event models.PINLockEvent, tx *sql.Tx) (int, error)
+	GetPINLockEvents(accountNumber string) ([]models.PINLockEvent, error)
+	IncrementDecrementLastBalance(accountID int, amount models.Money, debitCreditOperator string, updatedAt string, tx *sql.Tx) (lastBalance models.Money, err error)
+	IncrementDecrementHeldBalance(accountID int, amount models.Money, debitCreditOperator string, updatedAt string, tx *sql.Tx) (availableBalance models.Money, err error)
+	GetAvailableBalance(accountID int, tx *sql.Tx) (models.Money, error)
+	GetAccountPockets(accountID int) ([]models.AccountPocket, error)
+	IncrementDecrementPocketBalance(accountID int, amount models.Money, debitCreditOperator string, updatedAt string, tx *sql.Tx) (lastBalance models.Money, err error)
 	RemoveAccount(id int) error
 	GetAccountList() ([]models.Account, error)
 	VerifyPIN(accountNumber string, pin string) (bool, error)
@@ -25,9 +34,100 @@ type AccountRepository interface {
 
 // TransactionRepository
 type TransactionRepository interface {
-	AddTransaction(transaction models.Transaction) (int, error)
+	AddTransaction(transaction models.Transaction, tx *sql.Tx) (int, error)
 	FindTransactionById(id int) (models.Transaction, error)
-	GetTransactionHistory(accountNumber string, startDate, endDate string, limit, page int) ([]models.Transaction, int, error)
+	FindTransactionsByJournalId(journalID int, tx *sql.Tx) ([]models.Transaction, error)
+	GetReversedAmount(transactionID int, tx *sql.Tx) (models.Money, error)
+	UpdateTransactionStatus(id int, fromStatus, toStatus string, tx *sql.Tx) error
+	GetTransactionHistory(accountNumber string, startDate, endDate, status string, limit, page int) ([]models.Transaction, int, error)
+	StreamTransactionHistory(accountNumber, startDate, endDate, status string, tx *sql.Tx, fn func(models.Transaction) error) error
+	GetBalanceBefore(accountNumber, currency, startDate string, tx *sql.Tx) (models.Money, error)
 	DataCountAndSumTransactionListByIndex(countOnly bool, filter models.RequestTransactionHistoryList) (models.ResultDataTableTransactionCountAndSummaries, error)
 	DataGetTransactionListByIndex(filter models.RequestTransactionHistoryList) ([]models.Transaction, error)
 }
+
+// LedgerRepository
+type LedgerRepository interface {
+	EnsureSystemLedgerAccounts() error
+	PostJournal(journal models.JournalEntry, tx *sql.Tx) (int, error)
+	FindJournalById(id int) (models.JournalEntry, error)
+	FindJournalByReferenceNo(referenceNo string) (models.JournalEntry, error)
+	GetLedgerBalance(ledgerCode string) (models.LedgerBalanceResponse, error)
+	GetUnbalancedJournals() ([]models.UnbalancedJournal, error)
+	GetLedgerMismatches() ([]models.LedgerMismatch, error)
+}
+
+// ScheduledTransferRepository
+type ScheduledTransferRepository interface {
+	AddScheduledTransfer(schedule models.ScheduledTransfer) (int, error)
+	FindScheduledTransferById(id int) (models.ScheduledTransfer, error)
+	GetScheduledTransfersByAccount(accountNumber string) ([]models.ScheduledTransfer, error)
+	UpdateScheduledTransfer(schedule models.ScheduledTransfer, previous models.ScheduledTransfer) error
+	RemoveScheduledTransfer(id int) error
+	ClaimDueScheduledTransfers(workerID string, limit int, lease time.Duration) ([]models.ScheduledTransfer, error)
+	CompleteScheduledRunWithTx(tx *sql.Tx, schedule models.ScheduledTransfer, expectedRunAt time.Time) error
+	ReleaseScheduledTransfer(id int, workerID string) error
+	AddScheduledTransferExecution(execution models.ScheduledTransferExecution, tx *sql.Tx) (int, error)
+	GetScheduledTransferExecutions(scheduleID int) ([]models.ScheduledTransferExecution, error)
+}
+
+// LimitRepository
+type LimitRepository interface {
+	EnsureDefaultTransactionLimits() error
+	FindTransactionLimit(tier, transactionType string) (models.TransactionLimit, error)
+	GetTransactionLimitsByTier(tier string) ([]models.TransactionLimit, error)
+	GetLimitUsage(accountID int, transactionType, direction string, now time.Time, tx *sql.Tx) (models.LimitUsage, error)
+}
+
+// FeeRepository
+type FeeRepository interface {
+	EnsureDefaultFeeRules() error
+	FindFeeRule(transactionType string) (models.FeeRule, error)
+}
+
+// HoldRepository
+type HoldRepository interface {
+	AddHold(hold models.Hold, tx *sql.Tx) (int, error)
+	FindHoldById(id int) (models.Hold, error)
+	FindHoldByIdForUpdate(id int, tx *sql.Tx) (models.Hold, error)
+	GetHoldsByAccount(accountNumber string) ([]models.Hold, error)
+	CloseHoldWithTx(tx *sql.Tx, hold models.Hold) error
+	GetExpiredHoldIDs(now time.Time, limit int) ([]int, error)
+}
+
+// FxRepository
+type FxRepository interface {
+	AddFxRate(rate models.FxRate) (int, error)
+	FindEffectiveFxRate(fromCurrency, toCurrency string, at time.Time) (models.FxRate, error)
+	GetFxRateList(baseCurrency, quoteCurrency string) ([]models.FxRate, error)
+}
+
+// WebhookRepository
+type WebhookRepository interface {
+	AddWebhookSubscription(subscription models.WebhookSubscription) (int, error)
+	FindWebhookSubscriptionById(id int) (models.WebhookSubscription, error)
+	GetWebhookSubscriptions() ([]models.WebhookSubscription, error)
+	UpdateWebhookSubscription(subscription models.WebhookSubscription) error
+	AddOutboxEvent(event models.WebhookEvent, tx *sql.Tx) (int, error)
+	DispatchOutboxEvents(limit int) (int, error)
+	ClaimDueWebhookDeliveries(workerID string, limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	CompleteWebhookDeliveryWithTx(tx *sql.Tx, delivery models.WebhookDelivery) error
+	AddWebhookDeliveryAttempt(attempt models.WebhookDeliveryAttempt, tx *sql.Tx) (int, error)
+	FindWebhookDeliveryById(id int) (models.WebhookDelivery, error)
+	GetWebhookDeliveries(filter models.RequestWebhookDeliveryList) ([]models.WebhookDelivery, error)
+	GetWebhookDeliveryAttempts(deliveryID int) ([]models.WebhookDeliveryAttempt, error)
+	RedeliverWebhookDelivery(id int) error
+}
+
+// AuditRepository
+type AuditRepository interface {
+	EnsureAuditIndexes() error
+	AddAuditLog(auditLog models.AuditLog) (string, error)
+	GetAuditLogs(filter models.RequestAuditLogList) ([]models.AuditLog, error)
+}
+
+// HealthRepository
+type HealthRepository interface {
+	PingDB(ctx context.Context) error
+	PingMongo(ctx context.Context) error
+}
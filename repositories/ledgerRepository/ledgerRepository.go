@@ -0,0 +1,269 @@
+package ledgerRepository
+
+import (
+	"database/sql"
+	"errors"
+	"sample/helpers"
+	"sample/models"
+	"sample/repositories"
+	"time"
+)
+
+var defineColumnPosting = `id, journal_id, ledger_code, direction, amount, currency, created_at`
+
+type ledgerRepository struct {
+	RepoDB repositories.Repository
+}
+
+// NewLedgerRepository
+func NewLedgerRepository(repoDB repositories.Repository) ledgerRepository {
+	return ledgerRepository{
+		RepoDB: repoDB,
+	}
+}
+
+// EnsureSystemLedgerAccounts membuat akun sistem (CASH_VAULT, FEE_INCOME) jika belum ada
+func (ctx ledgerRepository) EnsureSystemLedgerAccounts() error {
+	query := `INSERT INTO ledger_account (code, name, account_type, normal_balance, created_at)
+			  VALUES ($1, $2, $3, $4, $5)
+			  ON CONFLICT (code) DO NOTHING`
+
+	now := time.Now()
+	for _, la := range models.SystemLedgerAccounts {
+		if _, err := ctx.RepoDB.DB.Exec(query, la.Code, la.Name, la.AccountType, la.NormalBalance, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PostJournal mencatat jurnal beserta posting-nya di dalam transaksi
+// dan memastikan total debit = total kredit sebelum commit
+func (ctx ledgerRepository) PostJournal(journal models.JournalEntry, tx *sql.Tx) (int, error) {
+	var (
+		journalID  int
+		unbalanced int
+	)
+
+	if tx == nil {
+		return 0, errors.New("PostJournal must be called inside a database transaction")
+	}
+
+	if !journal.IsBalanced() {
+		return 0, errors.New("Unbalanced journal: total debit must equal total credit")
+	}
+
+	now := time.Now()
+	query := `INSERT INTO journal_entry (reference_no, journal_type, description, reversal_of, created_at)
+			  VALUES ($1, $2, $3, $4, $5) RETURNING id`
+
+	err := tx.QueryRow(query, journal.ReferenceNo, journal.JournalType, journal.Description,
+		helpers.NullInt(journal.ReversalOf), now).Scan(&journalID)
+	if err != nil {
+		return 0, err
+	}
+
+	query = `INSERT INTO journal_posting (journal_id, ledger_code, direction, amount, currency, created_at)
+			 VALUES ($1, $2, $3, $4, $5, $6)`
+
+	for _, p := range journal.Postings {
+		if !p.Amount.IsPositive() {
+			return 0, errors.New("Posting amount must be greater than zero")
+		}
+		if _, err := tx.Exec(query, journalID, p.LedgerCode, p.Direction, p.Amount, p.Amount.CurrencyCode(), now); err != nil {
+			return 0, err
+		}
+	}
+
+	// Invariant: setiap jurnal harus berjumlah nol untuk setiap mata uang
+	query = `SELECT COUNT(*) FROM (
+				SELECT currency FROM journal_posting WHERE journal_id = $1
+				GROUP BY currency
+				HAVING SUM(CASE WHEN direction = 'D' THEN amount ELSE -amount END) <> 0
+			 ) unbalanced`
+
+	if err := tx.QueryRow(query, journalID).Scan(&unbalanced); err != nil {
+		return 0, err
+	}
+
+	if unbalanced > 0 {
+		return 0, errors.New("Unbalanced journal: postings do not sum to zero")
+	}
+
+	return journalID, nil
+}
+
+// FindJournalById mencari jurnal beserta posting-nya berdasarkan ID
+func (ctx ledgerRepository) FindJournalById(id int) (models.JournalEntry, error) {
+	query := `SELECT id, reference_no, journal_type, description, reversal_of, created_at FROM journal_entry WHERE id = $1`
+	return ctx.findJournal(query, id)
+}
+
+// FindJournalByReferenceNo mencari jurnal beserta posting-nya berdasarkan reference number
+func (ctx ledgerRepository) FindJournalByReferenceNo(referenceNo string) (models.JournalEntry, error) {
+	query := `SELECT id, reference_no, journal_type, description, reversal_of, created_at FROM journal_entry WHERE reference_no = $1`
+	return ctx.findJournal(query, referenceNo)
+}
+
+func (ctx ledgerRepository) findJournal(query string, arg interface{}) (models.JournalEntry, error) {
+	var journal models.JournalEntry
+	var reversalOf sql.NullInt64
+
+	err := ctx.RepoDB.DB.QueryRow(query, arg).Scan(
+		&journal.ID,
+		&journal.ReferenceNo,
+		&journal.JournalType,
+		&journal.Description,
+		&reversalOf,
+		&journal.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return journal, errors.New("Journal not found")
+		}
+		return journal, err
+	}
+	journal.ReversalOf = int(reversalOf.Int64)
+
+	rows, err := ctx.RepoDB.DB.Query(`SELECT `+defineColumnPosting+` FROM journal_posting WHERE journal_id = $1 ORDER BY id`, journal.ID)
+	if err != nil {
+		return journal, err
+	}
+	defer rows.Close()
+
+	journal.Postings, err = postingDto(rows)
+	if err != nil {
+		return journal, err
+	}
+
+	return journal, nil
+}
+
+// GetLedgerBalance menghitung saldo sebuah akun ledger dari seluruh posting
+func (ctx ledgerRepository) GetLedgerBalance(ledgerCode string) (models.LedgerBalanceResponse, error) {
+	var result = models.LedgerBalanceResponse{
+		LedgerCode:    ledgerCode,
+		NormalBalance: "C", // akun nasabah adalah liabilitas
+	}
+
+	var normalBalance string
+	err := ctx.RepoDB.DB.QueryRow(`SELECT normal_balance FROM ledger_account WHERE code = $1`, ledgerCode).Scan(&normalBalance)
+	if err != nil && err != sql.ErrNoRows {
+		return result, err
+	}
+	if normalBalance != "" {
+		result.NormalBalance = normalBalance
+	}
+
+	query := `SELECT
+				COALESCE(SUM(CASE WHEN direction = 'D' THEN amount ELSE 0 END), 0),
+				COALESCE(SUM(CASE WHEN direction = 'C' THEN amount ELSE 0 END), 0)
+			  FROM journal_posting WHERE ledger_code = $1`
+
+	err = ctx.RepoDB.DB.QueryRow(query, ledgerCode).Scan(&result.TotalDebit, &result.TotalCredit)
+	if err != nil {
+		return result, err
+	}
+
+	if result.NormalBalance == "D" {
+		result.Balance = result.TotalDebit.Sub(result.TotalCredit)
+	} else {
+		result.Balance = result.TotalCredit.Sub(result.TotalDebit)
+	}
+
+	return result, nil
+}
+
+// GetUnbalancedJournals mendapatkan jurnal yang melanggar invariant debit = kredit,
+// satu baris per mata uang yang tidak seimbang
+func (ctx ledgerRepository) GetUnbalancedJournals() ([]models.UnbalancedJournal, error) {
+	var result []models.UnbalancedJournal
+
+	query := `SELECT j.id, j.reference_no, COALESCE(p.currency, 'IDR') AS currency,
+				COALESCE(SUM(CASE WHEN p.direction = 'D' THEN p.amount ELSE 0 END), 0) AS total_debit,
+				COALESCE(SUM(CASE WHEN p.direction = 'C' THEN p.amount ELSE 0 END), 0) AS total_credit
+			  FROM journal_entry j
+			  LEFT JOIN journal_posting p ON p.journal_id = j.id
+			  GROUP BY j.id, j.reference_no, p.currency
+			  HAVING COALESCE(SUM(CASE WHEN p.direction = 'D' THEN p.amount ELSE -p.amount END), 0) <> 0
+			  	  OR COUNT(p.id) < 2
+			  ORDER BY j.id`
+
+	rows, err := ctx.RepoDB.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var val models.UnbalancedJournal
+		var currency string
+		if err := rows.Scan(&val.JournalID, &val.ReferenceNo, &currency, &val.TotalDebit, &val.TotalCredit); err != nil {
+			return result, err
+		}
+		val.TotalDebit.Currency = currency
+		val.TotalCredit.Currency = currency
+		result = append(result, val)
+	}
+
+	return result, rows.Err()
+}
+
+// GetLedgerMismatches mendapatkan akun nasabah yang saldonya tidak sama dengan saldo ledger
+func (ctx ledgerRepository) GetLedgerMismatches() ([]models.LedgerMismatch, error) {
+	var result []models.LedgerMismatch
+
+	query := `SELECT a.account_number, a.balance, COALESCE(l.ledger_balance, 0)
+			  FROM account a
+			  LEFT JOIN (
+				  SELECT ledger_code, SUM(CASE WHEN direction = 'C' THEN amount ELSE -amount END) AS ledger_balance
+				  FROM journal_posting
+				  GROUP BY ledger_code
+			  ) l ON l.ledger_code = a.account_number
+			  WHERE a.deleted_at IS NULL
+			    AND a.balance <> COALESCE(l.ledger_balance, 0)
+			  ORDER BY a.account_number`
+
+	rows, err := ctx.RepoDB.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var val models.LedgerMismatch
+		if err := rows.Scan(&val.AccountNumber, &val.AccountBalance, &val.LedgerBalance); err != nil {
+			return result, err
+		}
+		result = append(result, val)
+	}
+
+	return result, rows.Err()
+}
+
+// postingDto helper untuk mapping rows ke struct
+func postingDto(rows *sql.Rows) ([]models.Posting, error) {
+	var result []models.Posting
+
+	for rows.Next() {
+		var val models.Posting
+		var currency string
+		err := rows.Scan(
+			&val.ID,
+			&val.JournalID,
+			&val.LedgerCode,
+			&val.Direction,
+			&val.Amount,
+			&currency,
+			&val.CreatedAt,
+		)
+		if err != nil {
+			return result, err
+		}
+		val.Amount.Currency = currency
+		result = append(result, val)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,130 @@
+package limitRepository
+
+import (
+	"database/sql"
+	"errors"
+	"sample/models"
+	"sample/repositories"
+	"time"
+)
+
+var defineColumn = `tier, transaction_type, max_single_amount, max_daily_count, max_daily_amount, max_monthly_amount`
+
+type limitRepository struct {
+	RepoDB repositories.Repository
+}
+
+// NewLimitRepository
+func NewLimitRepository(repoDB repositories.Repository) limitRepository {
+	return limitRepository{
+		RepoDB: repoDB,
+	}
+}
+
+// EnsureDefaultTransactionLimits membuat limit default per tier jika belum ada,
+// limit yang sudah diubah di database tidak ditimpa
+func (ctx limitRepository) EnsureDefaultTransactionLimits() error {
+	query := `INSERT INTO transaction_limit (` + defineColumn + `, created_at, updated_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+			  ON CONFLICT (tier, transaction_type) DO NOTHING`
+
+	now := time.Now()
+	for _, l := range models.DefaultTransactionLimits {
+		if _, err := ctx.RepoDB.DB.Exec(query, l.Tier, l.TransactionType, l.MaxSingleAmount,
+			l.MaxDailyCount, l.MaxDailyAmount, l.MaxMonthlyAmount, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FindTransactionLimit mencari aturan limit untuk tier dan jenis transaksi
+func (ctx limitRepository) FindTransactionLimit(tier, transactionType string) (models.TransactionLimit, error) {
+	var limit models.TransactionLimit
+
+	query := `SELECT ` + defineColumn + ` FROM transaction_limit WHERE tier = $1 AND transaction_type = $2`
+
+	err := ctx.RepoDB.DB.QueryRow(query, tier, transactionType).Scan(
+		&limit.Tier,
+		&limit.TransactionType,
+		&limit.MaxSingleAmount,
+		&limit.MaxDailyCount,
+		&limit.MaxDailyAmount,
+		&limit.MaxMonthlyAmount,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return limit, errors.New("Transaction limit not found")
+		}
+		return limit, err
+	}
+
+	return limit, nil
+}
+
+// GetTransactionLimitsByTier mendapatkan semua aturan limit sebuah tier
+func (ctx limitRepository) GetTransactionLimitsByTier(tier string) ([]models.TransactionLimit, error) {
+	var result []models.TransactionLimit
+
+	query := `SELECT ` + defineColumn + ` FROM transaction_limit WHERE tier = $1 ORDER BY transaction_type`
+
+	rows, err := ctx.RepoDB.DB.Query(query, tier)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var val models.TransactionLimit
+		err := rows.Scan(
+			&val.Tier,
+			&val.TransactionType,
+			&val.MaxSingleAmount,
+			&val.MaxDailyCount,
+			&val.MaxDailyAmount,
+			&val.MaxMonthlyAmount,
+		)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, val)
+	}
+
+	return result, rows.Err()
+}
+
+// GetLimitUsage menghitung pemakaian limit akun hari ini dan bulan ini berdasarkan jenis jurnal.
+// direction 'C' untuk setoran, 'D' untuk tarik tunai dan transfer keluar. Baris reversal tidak dihitung
+func (ctx limitRepository) GetLimitUsage(accountID int, transactionType, direction string, now time.Time, tx *sql.Tx) (models.LimitUsage, error) {
+	var (
+		usage models.LimitUsage
+		err   error
+	)
+
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	query := `SELECT COUNT(*) FILTER (WHERE t.transaction_time >= $4),
+					 COALESCE(SUM(t.amount) FILTER (WHERE t.transaction_time >= $4), 0),
+					 COUNT(*),
+					 COALESCE(SUM(t.amount), 0)
+			  FROM transaction t
+			  JOIN journal_entry j ON j.id = t.journal_id
+			  WHERE t.account_id = $1 AND j.journal_type = $2 AND t.transaction_type = $3
+				AND t.reversal_of IS NULL AND t.transaction_time >= $5`
+
+	args := []interface{}{accountID, transactionType, direction, startOfDay, startOfMonth}
+
+	if tx != nil {
+		err = tx.QueryRow(query, args...).Scan(&usage.DailyCount, &usage.DailyAmount, &usage.MonthlyCount, &usage.MonthlyAmount)
+	} else {
+		err = ctx.RepoDB.DB.QueryRow(query, args...).Scan(&usage.DailyCount, &usage.DailyAmount, &usage.MonthlyCount, &usage.MonthlyAmount)
+	}
+
+	if err != nil {
+		return usage, err
+	}
+
+	return usage, nil
+}
@@ -0,0 +1,439 @@
+package memoryRepository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sample/constans"
+	"sample/helpers"
+	"sample/models"
+	"sort"
+	"time"
+)
+
+type accountRepository struct {
+	Store *Store
+}
+
+// NewAccountRepository implementasi in-memory repositories.AccountRepository
+func NewAccountRepository(store *Store) accountRepository {
+	return accountRepository{
+		Store: store,
+	}
+}
+
+// findRow akun aktif berdasarkan id, dipanggil saat mu sudah dipegang
+func (ctx accountRepository) findRow(id int) (*accountRow, error) {
+	row, ok := ctx.Store.accounts[id]
+	if !ok || row.deletedAt != nil {
+		return nil, errors.New("Account not found")
+	}
+	return row, nil
+}
+
+// findRowByNumber akun aktif berdasarkan nomor akun, dipanggil saat mu sudah dipegang
+func (ctx accountRepository) findRowByNumber(accountNumber string) (*accountRow, error) {
+	for _, row := range ctx.Store.accounts {
+		if row.deletedAt == nil && row.account.AccountNumber == accountNumber {
+			return row, nil
+		}
+	}
+	return nil, errors.New("Account not found")
+}
+
+// FindAccountById mencari berdasarkan id
+func (ctx accountRepository) FindAccountById(id int) (models.Account, error) {
+	ctx.Store.mu.Lock()
+	defer ctx.Store.mu.Unlock()
+
+	row, err := ctx.findRow(id)
+	if err != nil {
+		return models.Account{}, err
+	}
+	return row.account, nil
+}
+
+// FindAccountByNumber mencari berdasarkan nomor akun
+func (ctx accountRepository) FindAccountByNumber(accountNumber string) (models.Account, error) {
+	ctx.Store.mu.Lock()
+	defer ctx.Store.mu.Unlock()
+
+	row, err := ctx.findRowByNumber(accountNumber)
+	if err != nil {
+		return models.Account{}, err
+	}
+	return row.account, nil
+}
+
+// IsAccountExistsByNumber cek apakah akun ada
+func (ctx accountRepository) IsAccountExistsByNumber(accountNumber string) (models.Account, bool) {
+	account, err := ctx.FindAccountByNumber(accountNumber)
+	return account, err == nil
+}
+
+// AddAccount membuat akun baru
+func (ctx accountRepository) AddAccount(account models.Account) (int, error) {
+	return ctx.addAccount(nil, account)
+}
+
+// AddAccountWithTx membuat akun baru dalam transaksi
+func (ctx accountRepository) AddAccountWithTx(tx *sql.Tx, account models.Account) (int, error) {
+	return ctx.addAccount(tx, account)
+}
+
+func (ctx accountRepository) addAccount(tx *sql.Tx, account models.Account) (int, error) {
+	ctx.Store.mu.Lock()
+	defer ctx.Store.mu.Unlock()
+
+	// account_number unik termasuk akun yang sudah di-soft delete
+	for _, row := range ctx.Store.accounts {
+		if row.account.AccountNumber == account.AccountNumber {
+			return 0, fmt.Errorf("Account number %s already exists", account.AccountNumber)
+		}
+	}
+
+	now := time.Now()
+	ctx.Store.lastAccountID++
+	id := ctx.Store.lastAccountID
+
+	account.ID = id
+	account.Balance = models.NewMoney(account.Balance.Amount, constans.DEFAULT_CURRENCY)
+	account.HeldBalance = models.NewMoney(0, constans.DEFAULT_CURRENCY)
+	account.AccountStatus = "ACTIVE"
+	account.FailedPINAttempts = 0
+	account.CreatedAt = now
+	account.UpdatedAt = now
+	if account.AccountTier == "" {
+		account.AccountTier = constans.ACCOUNT_TIER_BASIC
+	}
+
+	ctx.Store.accounts[id] = &accountRow{account: account}
+	ctx.Store.addUndo(tx, func() { delete(ctx.Store.accounts, id) })
+
+	return id, nil
+}
+
+// UpdateAccount update data akun
+func (ctx accountRepository) UpdateAccount(account models.Account) (int, error) {
+	ctx.Store.mu.Lock()
+	defer ctx.Store.mu.Unlock()
+
+	row, err := ctx.findRow(account.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	row.account.AccountName = account.AccountName
+	row.account.PhoneNumber = account.PhoneNumber
+	row.account.Email = account.Email
+	row.account.UpdatedAt = time.Now()
+
+	return row.account.ID, nil
+}
+
+// UpdatePIN update PIN akun
+func (ctx accountRepository) UpdatePIN(accountNumber string, newPIN string) error {
+	return ctx.updatePIN(nil, accountNumber, newPIN)
+}
+
+// UpdatePINWithTx update PIN dalam transaksi
+func (ctx accountRepository) UpdatePINWithTx(tx *sql.Tx, accountNumber string, newPIN string) error {
+	return ctx.updatePIN(tx, accountNumber, newPIN)
+}
+
+// updatePIN set PIN baru, reset failed attempts, kunci sementara dan buka blokir
+func (ctx accountRepository) updatePIN(tx *sql.Tx, accountNumber string, newPIN string) error {
+	ctx.Store.mu.Lock()
+	defer ctx.Store.mu.Unlock()
+
+	row, err := ctx.findRowByNumber(accountNumber)
+	if err != nil {
+		return err
+	}
+
+	previous := row.account
+	ctx.Store.addUndo(tx, func() {
+		row.account.PIN = previous.PIN
+		row.account.FailedPINAttempts = previous.FailedPINAttempts
+		row.account.PINLockoutCount = previous.PINLockoutCount
+		row.account.LockedUntil = previous.LockedUntil
+		row.account.AccountStatus = previous.AccountStatus
+		row.account.UpdatedAt = previous.UpdatedAt
+	})
+
+	row.account.PIN = newPIN
+	row.account.FailedPINAttempts = 0
+	row.account.PINLockoutCount = 0
+	row.account.LockedUntil = nil
+	row.account.AccountStatus = constans.ACCOUNT_STATUS_ACTIVE
+	row.account.UpdatedAt = time.Now()
+
+	return nil
+}
+
+// ResetFailedPINAttempts reset failed PIN attempts, jumlah kunci sementara dan buka blokir
+func (ctx accountRepository) ResetFailedPINAttempts(accountNumber string) error {
+	ctx.Store.mu.Lock()
+	defer ctx.Store.mu.Unlock()
+
+	row, err := ctx.findRowByNumber(accountNumber)
+	if err != nil {
+		return err
+	}
+
+	row.account.FailedPINAttempts = 0
+	row.account.PINLockoutCount = 0
+	row.account.LockedUntil = nil
+	row.account.AccountStatus = constans.ACCOUNT_STATUS_ACTIVE
+	row.account.UpdatedAt = time.Now()
+
+	return nil
+}
+
+// FindAccountByNumberForUpdate sama dengan FindAccountByNumber, tx sudah berjalan serial
+func (ctx accountRepository) FindAccountByNumberForUpdate(accountNumber string, tx *sql.Tx) (models.Account, error) {
+	return ctx.FindAccountByNumber(accountNumber)
+}
+
+// UpdatePINLockStateWithTx simpan status blokir, failed attempts, jumlah kunci sementara dan locked_until
+func (ctx accountRepository) UpdatePINLockStateWithTx(tx *sql.Tx, account models.Account) error {
+	ctx.Store.mu.Lock()
+	defer ctx.Store.mu.Unlock()
+
+	row, err := ctx.findRowByNumber(account.AccountNumber)
+	if err != nil {
+		return err
+	}
+
+	previous := row.account
+	ctx.Store.addUndo(tx, func() {
+		row.account.AccountStatus = previous.AccountStatus
+		row.account.FailedPINAttempts = previous.FailedPINAttempts
+		row.account.PINLockoutCount = previous.PINLockoutCount
+		row.account.LockedUntil = previous.LockedUntil
+		row.account.UpdatedAt = previous.UpdatedAt
+	})
+
+	row.account.AccountStatus = account.AccountStatus
+	row.account.FailedPINAttempts = account.FailedPINAttempts
+	row.account.PINLockoutCount = account.PINLockoutCount
+	row.account.LockedUntil = account.LockedUntil
+	row.account.UpdatedAt = time.Now()
+
+	return nil
+}
+
+// AddPINLockEvent mencatat riwayat kunci / buka kunci PIN
+func (ctx accountRepository) AddPINLockEvent(event models.PINLockEvent, tx *sql.Tx) (int, error) {
+	ctx.Store.mu.Lock()
+	defer ctx.Store.mu.Unlock()
+
+	event.ID = len(ctx.Store.pinLockEvents) + 1
+	event.CreatedAt = time.Now()
+
+	ctx.Store.pinLockEvents = append(ctx.Store.pinLockEvents, event)
+	ctx.Store.addUndo(tx, func() { ctx.Store.pinLockEvents = ctx.Store.pinLockEvents[:event.ID-1] })
+
+	return event.ID, nil
+}
+
+// GetPINLockEvents riwayat kunci PIN akun, terbaru lebih dulu
+func (ctx accountRepository) GetPINLockEvents(accountNumber string) ([]models.PINLockEvent, error) {
+	var result []models.PINLockEvent
+
+	ctx.Store.mu.Lock()
+	defer ctx.Store.mu.Unlock()
+
+	for i := len(ctx.Store.pinLockEvents) - 1; i >= 0; i-- {
+		if ctx.Store.pinLockEvents[i].AccountNumber == accountNumber {
+			result = append(result, ctx.Store.pinLockEvents[i])
+		}
+	}
+
+	return result, nil
+}
+
+// IncrementDecrementLastBalance update saldo akun dengan operator (+/-) dan return last balance
+func (ctx accountRepository) IncrementDecrementLastBalance(accountID int, amount models.Money, debitCreditOperator string, updatedAt string, tx *sql.Tx) (lastBalance models.Money, err error) {
+	delta, at, err := signedDelta(amount, debitCreditOperator, updatedAt)
+	if err != nil {
+		return lastBalance, err
+	}
+
+	ctx.Store.mu.Lock()
+	defer ctx.Store.mu.Unlock()
+
+	row, err := ctx.findRow(accountID)
+	if err != nil {
+		return lastBalance, err
+	}
+
+	ctx.Store.addUndo(tx, func() { row.account.Balance.Amount -= delta })
+	row.account.Balance.Amount += delta
+	row.account.UpdatedAt = at
+
+	return row.account.Balance, nil
+}
+
+// IncrementDecrementHeldBalance update total hold akun dengan operator (+/-) dan return saldo tersedia
+func (ctx accountRepository) IncrementDecrementHeldBalance(accountID int, amount models.Money, debitCreditOperator string, updatedAt string, tx *sql.Tx) (availableBalance models.Money, err error) {
+	delta, at, err := signedDelta(amount, debitCreditOperator, updatedAt)
+	if err != nil {
+		return availableBalance, err
+	}
+
+	ctx.Store.mu.Lock()
+	defer ctx.Store.mu.Unlock()
+
+	row, err := ctx.findRow(accountID)
+	if err != nil {
+		return availableBalance, err
+	}
+
+	ctx.Store.addUndo(tx, func() { row.account.HeldBalance.Amount -= delta })
+	row.account.HeldBalance.Amount += delta
+	row.account.UpdatedAt = at
+
+	return row.account.Balance.Sub(row.account.HeldBalance), nil
+}
+
+// GetAvailableBalance saldo tersedia (balance - held_balance)
+func (ctx accountRepository) GetAvailableBalance(accountID int, tx *sql.Tx) (models.Money, error) {
+	ctx.Store.mu.Lock()
+	defer ctx.Store.mu.Unlock()
+
+	row, err := ctx.findRow(accountID)
+	if err != nil {
+		return models.Money{}, err
+	}
+
+	return row.account.Balance.Sub(row.account.HeldBalance), nil
+}
+
+// GetAccountPockets mendapatkan pocket valas rekening (mata uang selain mata uang utama)
+func (ctx accountRepository) GetAccountPockets(accountID int) ([]models.AccountPocket, error) {
+	var result []models.AccountPocket
+
+	ctx.Store.mu.Lock()
+	defer ctx.Store.mu.Unlock()
+
+	for key, pocket := range ctx.Store.pockets {
+		if key.accountID != accountID {
+			continue
+		}
+
+		balance := models.NewMoney(pocket.balance, key.currency)
+		result = append(result, models.AccountPocket{
+			Currency:         key.currency,
+			Balance:          balance,
+			AvailableBalance: balance,
+			UpdatedAt:        pocket.updatedAt,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Currency < result[j].Currency })
+
+	return result, nil
+}
+
+// IncrementDecrementPocketBalance update saldo pocket valas sesuai mata uang amount dengan operator (+/-)
+// dan return saldo terakhir. Pocket dibuat otomatis, saldo negatif harus dicek pemanggil di dalam tx
+func (ctx accountRepository) IncrementDecrementPocketBalance(accountID int, amount models.Money, debitCreditOperator string, updatedAt string, tx *sql.Tx) (lastBalance models.Money, err error) {
+	delta, at, err := signedDelta(amount, debitCreditOperator, updatedAt)
+	if err != nil {
+		return lastBalance, err
+	}
+
+	if amount.CurrencyCode() == constans.DEFAULT_CURRENCY {
+		return lastBalance, errors.New("Main currency balance must be updated through IncrementDecrementLastBalance")
+	}
+
+	ctx.Store.mu.Lock()
+	defer ctx.Store.mu.Unlock()
+
+	// Foreign key account_pocket.account_id
+	if _, ok := ctx.Store.accounts[accountID]; !ok {
+		return lastBalance, errors.New("Account not found")
+	}
+
+	key := pocketKey{accountID: accountID, currency: amount.CurrencyCode()}
+	pocket, ok := ctx.Store.pockets[key]
+	if !ok {
+		pocket = &pocketRow{createdAt: at}
+		ctx.Store.pockets[key] = pocket
+		ctx.Store.addUndo(tx, func() { delete(ctx.Store.pockets, key) })
+	} else {
+		ctx.Store.addUndo(tx, func() { pocket.balance -= delta })
+	}
+
+	pocket.balance += delta
+	pocket.updatedAt = at
+
+	return models.NewMoney(pocket.balance, key.currency), nil
+}
+
+// RemoveAccount soft delete akun
+func (ctx accountRepository) RemoveAccount(id int) error {
+	ctx.Store.mu.Lock()
+	defer ctx.Store.mu.Unlock()
+
+	row, ok := ctx.Store.accounts[id]
+	if !ok {
+		return errors.New("Account not found")
+	}
+
+	now := time.Now()
+	row.deletedAt = &now
+
+	return nil
+}
+
+// GetAccountList mendapatkan list semua akun, terbaru lebih dulu
+func (ctx accountRepository) GetAccountList() ([]models.Account, error) {
+	var result []models.Account
+
+	ctx.Store.mu.Lock()
+	defer ctx.Store.mu.Unlock()
+
+	for _, row := range ctx.Store.accounts {
+		if row.deletedAt == nil {
+			result = append(result, row.account)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.After(result[j].CreatedAt)
+		}
+		return result[i].ID > result[j].ID
+	})
+
+	return result, nil
+}
+
+// VerifyPIN verifikasi PIN akun terhadap hash bcrypt yang tersimpan
+func (ctx accountRepository) VerifyPIN(accountNumber string, pin string) (bool, error) {
+	account, err := ctx.FindAccountByNumber(accountNumber)
+	if err != nil {
+		return false, err
+	}
+
+	return helpers.CheckPINHash(pin, account.PIN), nil
+}
+
+// signedDelta nominal bertanda sesuai operator dan waktu update dari format LAYOUT_TIMESTAMP
+func signedDelta(amount models.Money, debitCreditOperator string, updatedAt string) (int64, time.Time, error) {
+	if debitCreditOperator != "+" && debitCreditOperator != "-" {
+		return 0, time.Time{}, errors.New("Invalid operator. Must be '+' or '-'")
+	}
+
+	at, err := time.ParseInLocation(constans.LAYOUT_TIMESTAMP, updatedAt, time.Local)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	if debitCreditOperator == "-" {
+		return -amount.Amount, at, nil
+	}
+	return amount.Amount, at, nil
+}
@@ -0,0 +1,267 @@
+package memoryRepository
+
+import (
+	"database/sql"
+	"errors"
+	"sample/constans"
+	"sample/models"
+	"sample/repositories"
+	"sample/utils"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	_ repositories.AccountRepository     = accountRepository{}
+	_ repositories.TransactionRepository = transactionRepository{}
+)
+
+func idr(amount int64) models.Money {
+	return models.NewMoney(amount, constans.DEFAULT_CURRENCY)
+}
+
+func now() string {
+	return time.Now().Format(constans.LAYOUT_TIMESTAMP)
+}
+
+func newAccount(t *testing.T, accountRepo accountRepository, accountNumber string, balance int64) models.Account {
+	t.Helper()
+
+	id, err := accountRepo.AddAccount(models.Account{AccountNumber: accountNumber, AccountName: "Test " + accountNumber, Balance: idr(balance)})
+	if err != nil {
+		t.Fatalf("AddAccount: %v", err)
+	}
+
+	account, err := accountRepo.FindAccountById(id)
+	if err != nil {
+		t.Fatalf("FindAccountById: %v", err)
+	}
+	return account
+}
+
+func TestDBTransactionCommit(t *testing.T) {
+	store := NewStore()
+	accountRepo := NewAccountRepository(store)
+	transactionRepo := NewTransactionRepository(store)
+	account := newAccount(t, accountRepo, "1000000001", 50000)
+
+	err := utils.DBTransaction(store.DB(), func(tx *sql.Tx) error {
+		if _, err := accountRepo.IncrementDecrementLastBalance(account.ID, idr(20000), "+", now(), tx); err != nil {
+			return err
+		}
+		if _, err := accountRepo.IncrementDecrementPocketBalance(account.ID, models.NewMoney(500, "USD"), "+", now(), tx); err != nil {
+			return err
+		}
+		_, err := transactionRepo.AddTransaction(models.Transaction{AccountID: account.ID, AccountNumber: account.AccountNumber,
+			TransactionType: "C", Amount: idr(20000), TransactionTime: time.Now()}, tx)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("DBTransaction: %v", err)
+	}
+
+	account, _ = accountRepo.FindAccountById(account.ID)
+	if account.Balance.Cmp(idr(70000)) != 0 {
+		t.Errorf("balance = %s, want 700.00", account.Balance)
+	}
+
+	pockets, _ := accountRepo.GetAccountPockets(account.ID)
+	if len(pockets) != 1 || pockets[0].Balance.Amount != 500 {
+		t.Errorf("pockets = %+v, want USD 5.00", pockets)
+	}
+
+	transactions, total, _ := transactionRepo.GetTransactionHistory(account.AccountNumber, "", "", "", 0, 0)
+	if total != 1 || transactions[0].Status != constans.TRANSACTION_STATUS_SUCCESS {
+		t.Errorf("history = %+v, want one SUCCESS row", transactions)
+	}
+}
+
+func TestDBTransactionRollback(t *testing.T) {
+	store := NewStore()
+	accountRepo := NewAccountRepository(store)
+	transactionRepo := NewTransactionRepository(store)
+	account := newAccount(t, accountRepo, "1000000001", 50000)
+	errAbort := errors.New("abort")
+
+	err := utils.DBTransaction(store.DB(), func(tx *sql.Tx) error {
+		accountRepo.IncrementDecrementLastBalance(account.ID, idr(20000), "-", now(), tx)
+		accountRepo.IncrementDecrementHeldBalance(account.ID, idr(10000), "+", now(), tx)
+		accountRepo.IncrementDecrementPocketBalance(account.ID, models.NewMoney(500, "USD"), "+", now(), tx)
+		accountRepo.UpdatePINWithTx(tx, account.AccountNumber, "changed")
+		accountRepo.AddAccountWithTx(tx, models.Account{AccountNumber: "1000000002", AccountName: "Rolled Back"})
+		transactionRepo.AddTransaction(models.Transaction{AccountID: account.ID, AccountNumber: account.AccountNumber,
+			TransactionType: "D", Amount: idr(20000), TransactionTime: time.Now()}, tx)
+
+		// Penulisan tanpa tx tetap permanen walaupun tx di-rollback
+		accountRepo.UpdateAccount(models.Account{ID: account.ID, AccountName: "Renamed"})
+		return errAbort
+	})
+	if err != errAbort {
+		t.Fatalf("DBTransaction error = %v, want %v", err, errAbort)
+	}
+
+	after, _ := accountRepo.FindAccountById(account.ID)
+	if after.Balance.Cmp(idr(50000)) != 0 || !after.HeldBalance.IsZero() {
+		t.Errorf("balance = %s held = %s, want 500.00 and 0", after.Balance, after.HeldBalance)
+	}
+	if after.PIN != account.PIN {
+		t.Errorf("PIN = %q, want rollback to %q", after.PIN, account.PIN)
+	}
+	if after.AccountName != "Renamed" {
+		t.Errorf("account name = %q, want autocommit write to survive", after.AccountName)
+	}
+
+	if pockets, _ := accountRepo.GetAccountPockets(account.ID); len(pockets) != 0 {
+		t.Errorf("pockets = %+v, want none", pockets)
+	}
+	if _, exists := accountRepo.IsAccountExistsByNumber("1000000002"); exists {
+		t.Error("account added in rolled back tx still exists")
+	}
+	if _, total, _ := transactionRepo.GetTransactionHistory("", "", "", "", 0, 0); total != 0 {
+		t.Errorf("transactions = %d, want 0", total)
+	}
+}
+
+func TestDBTransactionSerialized(t *testing.T) {
+	store := NewStore()
+	db := store.DB()
+	accountRepo := NewAccountRepository(store)
+	account := newAccount(t, accountRepo, "1000000001", 0)
+
+	// Read-modify-write di dalam tx: tanpa serialisasi sebagian update akan hilang
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := utils.DBTransaction(db, func(tx *sql.Tx) error {
+				current, err := accountRepo.GetAvailableBalance(account.ID, tx)
+				if err != nil {
+					return err
+				}
+				time.Sleep(time.Millisecond)
+				if _, err := accountRepo.IncrementDecrementLastBalance(account.ID, current, "-", now(), tx); err != nil {
+					return err
+				}
+				_, err = accountRepo.IncrementDecrementLastBalance(account.ID, current.Add(idr(100)), "+", now(), tx)
+				return err
+			})
+			if err != nil {
+				t.Errorf("DBTransaction: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	account, _ = accountRepo.FindAccountById(account.ID)
+	if account.Balance.Cmp(idr(2000)) != 0 {
+		t.Errorf("balance = %s, want 20.00", account.Balance)
+	}
+}
+
+func TestDBReadSnapshotAndSQL(t *testing.T) {
+	store := NewStore()
+	db := store.DB()
+
+	err := utils.DBReadSnapshot(db, func(tx *sql.Tx) error {
+		_, err := tx.Exec("SELECT 1")
+		return err
+	})
+	if err == nil {
+		t.Fatal("SQL inside snapshot should fail")
+	}
+
+	// Snapshot selalu rollback, tx berikutnya tidak boleh tertahan
+	if err := utils.DBTransaction(db, func(tx *sql.Tx) error { return nil }); err != nil {
+		t.Fatalf("DBTransaction after snapshot: %v", err)
+	}
+}
+
+func TestPINLockState(t *testing.T) {
+	store := NewStore()
+	db := store.DB()
+	accountRepo := NewAccountRepository(store)
+	account := newAccount(t, accountRepo, "1000000001", 0)
+
+	lockedUntil := time.Now().Add(15 * time.Minute)
+	lock := func(tx *sql.Tx) error {
+		locked, err := accountRepo.FindAccountByNumberForUpdate(account.AccountNumber, tx)
+		if err != nil {
+			return err
+		}
+		locked.PINLockoutCount = 1
+		locked.LockedUntil = &lockedUntil
+		if err := accountRepo.UpdatePINLockStateWithTx(tx, locked); err != nil {
+			return err
+		}
+		_, err = accountRepo.AddPINLockEvent(models.PINLockEvent{AccountNumber: account.AccountNumber,
+			EventType: constans.PIN_LOCK_EVENT_TEMPORARY_LOCK, LockoutCount: 1, LockedUntil: &lockedUntil, Actor: constans.PIN_LOCK_ACTOR_SYSTEM}, tx)
+		return err
+	}
+
+	// Rollback membatalkan state kunci dan event
+	err := utils.DBTransaction(db, func(tx *sql.Tx) error {
+		if err := lock(tx); err != nil {
+			return err
+		}
+		return errors.New("rollback")
+	})
+	if err == nil {
+		t.Fatal("DBTransaction should fail")
+	}
+	account, _ = accountRepo.FindAccountById(account.ID)
+	events, _ := accountRepo.GetPINLockEvents(account.AccountNumber)
+	if account.LockedUntil != nil || account.PINLockoutCount != 0 || len(events) != 0 {
+		t.Errorf("after rollback locked_until = %v lockout_count = %d events = %d, want nil, 0, 0", account.LockedUntil, account.PINLockoutCount, len(events))
+	}
+
+	if err := utils.DBTransaction(db, lock); err != nil {
+		t.Fatalf("DBTransaction: %v", err)
+	}
+	account, _ = accountRepo.FindAccountById(account.ID)
+	events, _ = accountRepo.GetPINLockEvents(account.AccountNumber)
+	if account.LockedUntil == nil || account.PINLockoutCount != 1 || len(events) != 1 || events[0].EventType != constans.PIN_LOCK_EVENT_TEMPORARY_LOCK {
+		t.Errorf("after commit account = %+v events = %+v", account, events)
+	}
+
+	if err := accountRepo.ResetFailedPINAttempts(account.AccountNumber); err != nil {
+		t.Fatalf("ResetFailedPINAttempts: %v", err)
+	}
+	account, _ = accountRepo.FindAccountById(account.ID)
+	if account.AccountStatus != constans.ACCOUNT_STATUS_ACTIVE || account.PINLockoutCount != 0 || account.LockedUntil != nil {
+		t.Errorf("status = %s lockout_count = %d locked_until = %v, want ACTIVE, 0, nil", account.AccountStatus, account.PINLockoutCount, account.LockedUntil)
+	}
+}
+
+func TestTransactionHistoryPagination(t *testing.T) {
+	store := NewStore()
+	transactionRepo := NewTransactionRepository(store)
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.Local)
+
+	for i := 0; i < 5; i++ {
+		transactionRepo.AddTransaction(models.Transaction{AccountNumber: "1000000001", TransactionType: "C",
+			Amount: idr(int64(i+1) * 10000), TransactionTime: start.AddDate(0, 0, i)}, nil)
+	}
+	transactionRepo.AddTransaction(models.Transaction{AccountNumber: "1000000002", TransactionType: "C",
+		Amount: idr(10000), TransactionTime: start}, nil)
+
+	page, total, _ := transactionRepo.GetTransactionHistory("1000000001", "2026-01-02", "2026-01-05", "", 2, 2)
+	if total != 4 {
+		t.Fatalf("total = %d, want 4", total)
+	}
+	if len(page) != 2 || page[0].Amount.Cmp(idr(30000)) != 0 || page[1].Amount.Cmp(idr(20000)) != 0 {
+		t.Errorf("page 2 = %+v, want 300.00 then 200.00", page)
+	}
+
+	list, err := transactionRepo.DataGetTransactionListByIndex(models.RequestTransactionHistoryList{
+		ColumnOrder: "amount", AscDesc: "asc", PageNumber: 1, PageSize: 3,
+	})
+	if err != nil || len(list) != 3 || list[2].Amount.Cmp(idr(20000)) != 0 {
+		t.Errorf("list = %+v, %v", list, err)
+	}
+
+	if _, err := transactionRepo.DataGetTransactionListByIndex(models.RequestTransactionHistoryList{ColumnOrder: "pin", AscDesc: "ASC"}); err == nil {
+		t.Error("unknown sort column should fail")
+	}
+}
@@ -0,0 +1,165 @@
+package memoryRepository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sample/models"
+	"sync"
+	"time"
+)
+
+var errSQLNotSupported = errors.New("memoryRepository: SQL is not supported, use the in-memory repositories")
+
+// accountRow satu baris tabel account, deletedAt terisi jika akun di-soft delete
+type accountRow struct {
+	account   models.Account
+	deletedAt *time.Time
+}
+
+// pocketKey kunci unik account_pocket (account_id, currency)
+type pocketKey struct {
+	accountID int
+	currency  string
+}
+
+// pocketRow satu baris tabel account_pocket
+type pocketRow struct {
+	balance   int64
+	createdAt time.Time
+	updatedAt time.Time
+}
+
+// Store penyimpanan in-memory bersama untuk repository account dan transaction.
+// DB() menghasilkan *sql.DB yang bisa dipakai utils.DBTransaction / DBReadSnapshot:
+// transaksi dijalankan satu per satu, perubahan di dalam tx dicatat di undo log
+// dan dibatalkan saat rollback.
+//
+// Karena hanya ada satu tx aktif, setiap *sql.Tx yang diberikan ke repository dianggap
+// tx yang sedang berjalan. Penulisan tanpa tx langsung permanen (autocommit) dan
+// pembacaan di luar tx bisa melihat perubahan tx yang belum di-commit.
+// DBTransaction bersarang di goroutine yang sama akan deadlock
+type Store struct {
+	mu     sync.Mutex
+	txLock chan struct{}
+	undo   []func()
+
+	accounts      map[int]*accountRow
+	pockets       map[pocketKey]*pocketRow
+	transactions  map[int]*models.Transaction
+	pinLockEvents []models.PINLockEvent
+
+	lastAccountID     int
+	lastTransactionID int
+}
+
+// NewStore
+func NewStore() *Store {
+	return &Store{
+		txLock:       make(chan struct{}, 1),
+		accounts:     map[int]*accountRow{},
+		pockets:      map[pocketKey]*pocketRow{},
+		transactions: map[int]*models.Transaction{},
+	}
+}
+
+// DB koneksi *sql.DB untuk UsecaseService.RepoDB. Hanya mendukung Begin/Commit/Rollback,
+// query SQL langsung selalu error
+func (s *Store) DB() *sql.DB {
+	return sql.OpenDB(connector{store: s})
+}
+
+// addUndo mencatat langkah pembatalan jika penulisan terjadi di dalam tx.
+// Dipanggil saat s.mu sudah dipegang
+func (s *Store) addUndo(tx *sql.Tx, fn func()) {
+	if tx != nil {
+		s.undo = append(s.undo, fn)
+	}
+}
+
+// begin menunggu giliran tx lalu memulai undo log baru
+func (s *Store) begin(ctx context.Context) error {
+	select {
+	case s.txLock <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	s.mu.Lock()
+	s.undo = nil
+	s.mu.Unlock()
+	return nil
+}
+
+// finish menutup tx aktif, undo log dijalankan mundur jika rollback
+func (s *Store) finish(rollback bool) {
+	s.mu.Lock()
+	if rollback {
+		for i := len(s.undo) - 1; i >= 0; i-- {
+			s.undo[i]()
+		}
+	}
+	s.undo = nil
+	s.mu.Unlock()
+
+	<-s.txLock
+}
+
+// ============== database/sql driver ==============
+
+type connector struct {
+	store *Store
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{store: c.store}, nil
+}
+
+func (c connector) Driver() driver.Driver {
+	return memoryDriver{}
+}
+
+type memoryDriver struct{}
+
+func (memoryDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("memoryRepository: open through Store.DB")
+}
+
+type conn struct {
+	store *Store
+}
+
+func (c *conn) Prepare(string) (driver.Stmt, error) {
+	return nil, errSQLNotSupported
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx isolation level dan read only diterima apa adanya, tx sudah berjalan serial
+func (c *conn) BeginTx(ctx context.Context, _ driver.TxOptions) (driver.Tx, error) {
+	if err := c.store.begin(ctx); err != nil {
+		return nil, err
+	}
+	return &memoryTx{store: c.store}, nil
+}
+
+type memoryTx struct {
+	store *Store
+}
+
+func (t *memoryTx) Commit() error {
+	t.store.finish(false)
+	return nil
+}
+
+func (t *memoryTx) Rollback() error {
+	t.store.finish(true)
+	return nil
+}
@@ -0,0 +1,348 @@
+package memoryRepository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sample/constans"
+	"sample/models"
+	"sort"
+	"strings"
+	"time"
+)
+
+type transactionRepository struct {
+	Store *Store
+}
+
+// NewTransactionRepository implementasi in-memory repositories.TransactionRepository
+func NewTransactionRepository(store *Store) transactionRepository {
+	return transactionRepository{
+		Store: store,
+	}
+}
+
+// AddTransaction mencatat transaksi baru, ikut dalam tx jika diberikan
+func (ctx transactionRepository) AddTransaction(transaction models.Transaction, tx *sql.Tx) (int, error) {
+	ctx.Store.mu.Lock()
+	defer ctx.Store.mu.Unlock()
+
+	ctx.Store.lastTransactionID++
+	id := ctx.Store.lastTransactionID
+
+	currency := transaction.Amount.CurrencyCode()
+	transaction.ID = id
+	transaction.Amount.Currency = currency
+	transaction.Fee.Currency = currency
+	transaction.CreatedAt = time.Now()
+	if transaction.Status == "" {
+		transaction.Status = constans.TRANSACTION_STATUS_SUCCESS
+	}
+
+	ctx.Store.transactions[id] = &transaction
+	ctx.Store.addUndo(tx, func() { delete(ctx.Store.transactions, id) })
+
+	return id, nil
+}
+
+// FindTransactionById mencari transaksi berdasarkan ID
+func (ctx transactionRepository) FindTransactionById(id int) (models.Transaction, error) {
+	ctx.Store.mu.Lock()
+	defer ctx.Store.mu.Unlock()
+
+	transaction, ok := ctx.Store.transactions[id]
+	if !ok {
+		return models.Transaction{}, errors.New("Transaction not found")
+	}
+	return *transaction, nil
+}
+
+// FindTransactionsByJournalId mendapatkan semua baris transaksi dari satu jurnal, urut id
+func (ctx transactionRepository) FindTransactionsByJournalId(journalID int, tx *sql.Tx) ([]models.Transaction, error) {
+	return ctx.selectTransactions(func(transaction models.Transaction) bool {
+		return transaction.JournalID == journalID
+	}, func(a, b models.Transaction) bool {
+		return a.ID < b.ID
+	}), nil
+}
+
+// UpdateTransactionStatus mengubah status transaksi sesuai state machine
+func (ctx transactionRepository) UpdateTransactionStatus(id int, fromStatus, toStatus string, tx *sql.Tx) error {
+	if !models.CanTransitionTransactionStatus(fromStatus, toStatus) {
+		return fmt.Errorf("Illegal transaction status transition %s -> %s", fromStatus, toStatus)
+	}
+
+	ctx.Store.mu.Lock()
+	defer ctx.Store.mu.Unlock()
+
+	transaction, ok := ctx.Store.transactions[id]
+	if !ok || transaction.Status != fromStatus {
+		return fmt.Errorf("Transaction %d is no longer %s", id, fromStatus)
+	}
+
+	transaction.Status = toStatus
+	ctx.Store.addUndo(tx, func() { transaction.Status = fromStatus })
+
+	return nil
+}
+
+// GetReversedAmount total nominal yang sudah di-reversal untuk sebuah transaksi
+func (ctx transactionRepository) GetReversedAmount(transactionID int, tx *sql.Tx) (models.Money, error) {
+	reversed := models.NewMoney(0, constans.DEFAULT_CURRENCY)
+
+	for _, transaction := range ctx.selectTransactions(func(transaction models.Transaction) bool {
+		return transaction.ReversalOf == transactionID
+	}, nil) {
+		reversed.Amount += transaction.Amount.Amount
+	}
+
+	return reversed, nil
+}
+
+// GetTransactionHistory mendapatkan riwayat transaksi, terbaru lebih dulu. limit 0 tanpa pagination
+func (ctx transactionRepository) GetTransactionHistory(accountNumber string, startDate, endDate, status string, limit, page int) ([]models.Transaction, int, error) {
+	transactions := ctx.selectTransactions(historyFilter(accountNumber, startDate, endDate, status), newestFirst)
+	totalRecords := len(transactions)
+
+	if limit > 0 {
+		if page <= 0 {
+			page = 1
+		}
+		transactions = paginate(transactions, limit, page)
+	}
+
+	return transactions, totalRecords, nil
+}
+
+// StreamTransactionHistory filter yang sama dengan GetTransactionHistory, diurutkan dari yang terlama.
+// fn dipanggil di luar lock sehingga boleh memanggil repository lain
+func (ctx transactionRepository) StreamTransactionHistory(accountNumber, startDate, endDate, status string, tx *sql.Tx, fn func(models.Transaction) error) error {
+	transactions := ctx.selectTransactions(historyFilter(accountNumber, startDate, endDate, status), func(a, b models.Transaction) bool {
+		return newestFirst(b, a)
+	})
+
+	for _, transaction := range transactions {
+		if err := fn(transaction); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetBalanceBefore saldo rekening untuk satu mata uang sebelum startDate, dihitung mundur dari
+// saldo saat ini dikurangi semua mutasi sejak startDate
+func (ctx transactionRepository) GetBalanceBefore(accountNumber, currency, startDate string, tx *sql.Tx) (models.Money, error) {
+	balance := models.NewMoney(0, currency)
+
+	ctx.Store.mu.Lock()
+	for id, row := range ctx.Store.accounts {
+		if row.deletedAt != nil || row.account.AccountNumber != accountNumber {
+			continue
+		}
+
+		if currency == constans.DEFAULT_CURRENCY {
+			balance.Amount = row.account.Balance.Amount
+		} else if pocket, ok := ctx.Store.pockets[pocketKey{accountID: id, currency: currency}]; ok {
+			balance.Amount = pocket.balance
+		}
+	}
+	ctx.Store.mu.Unlock()
+
+	movements := ctx.selectTransactions(func(transaction models.Transaction) bool {
+		return transaction.AccountNumber == accountNumber &&
+			transaction.Amount.CurrencyCode() == currency &&
+			transactionDate(transaction) >= startDate
+	}, nil)
+
+	for _, transaction := range movements {
+		balance = balance.Sub(transaction.BalanceEffect())
+	}
+
+	return balance, nil
+}
+
+// DataCountAndSumTransactionListByIndex - Count dan sum untuk transaction list
+func (ctx transactionRepository) DataCountAndSumTransactionListByIndex(countOnly bool, filter models.RequestTransactionHistoryList) (models.ResultDataTableTransactionCountAndSummaries, error) {
+	var result models.ResultDataTableTransactionCountAndSummaries
+
+	transactions := ctx.selectTransactions(listFilter(filter), nil)
+	result.Count = int64(len(transactions))
+
+	if !countOnly {
+		result.SumariesDebit = models.NewMoney(0, constans.DEFAULT_CURRENCY)
+		result.SumariesCredit = models.NewMoney(0, constans.DEFAULT_CURRENCY)
+
+		for _, transaction := range transactions {
+			switch transaction.TransactionType {
+			case "D":
+				result.SumariesDebit.Amount += transaction.Amount.Amount
+			case "C":
+				result.SumariesCredit.Amount += transaction.Amount.Amount
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// DataGetTransactionListByIndex - Get transaction list dengan filter
+func (ctx transactionRepository) DataGetTransactionListByIndex(filter models.RequestTransactionHistoryList) ([]models.Transaction, error) {
+	less := newestFirst
+
+	if filter.ColumnOrder != "" && filter.AscDesc != "" {
+		compare, ok := transactionColumns[filter.ColumnOrder]
+		if !ok {
+			return nil, fmt.Errorf("column \"%s\" does not exist", filter.ColumnOrder)
+		}
+
+		switch strings.ToUpper(filter.AscDesc) {
+		case "ASC":
+			less = func(a, b models.Transaction) bool { return compare(a, b) < 0 }
+		case "DESC":
+			less = func(a, b models.Transaction) bool { return compare(a, b) > 0 }
+		default:
+			return nil, fmt.Errorf("invalid sort direction \"%s\"", filter.AscDesc)
+		}
+	}
+
+	transactions := ctx.selectTransactions(listFilter(filter), less)
+
+	if filter.PageNumber > 0 && filter.PageSize > 0 {
+		transactions = paginate(transactions, filter.PageSize, filter.PageNumber)
+	}
+
+	return transactions, nil
+}
+
+// selectTransactions salinan transaksi yang lolos filter, diurutkan dengan less jika diberikan
+func (ctx transactionRepository) selectTransactions(match func(models.Transaction) bool, less func(a, b models.Transaction) bool) []models.Transaction {
+	var result []models.Transaction
+
+	ctx.Store.mu.Lock()
+	for _, transaction := range ctx.Store.transactions {
+		if match(*transaction) {
+			result = append(result, *transaction)
+		}
+	}
+	ctx.Store.mu.Unlock()
+
+	if less == nil {
+		less = func(a, b models.Transaction) bool { return a.ID < b.ID }
+	}
+	sort.SliceStable(result, func(i, j int) bool { return less(result[i], result[j]) })
+
+	return result
+}
+
+// historyFilter filter riwayat transaksi, parameter kosong berarti tanpa filter
+func historyFilter(accountNumber, startDate, endDate, status string) func(models.Transaction) bool {
+	return func(transaction models.Transaction) bool {
+		date := transactionDate(transaction)
+
+		return (accountNumber == "" || transaction.AccountNumber == accountNumber) &&
+			(startDate == "" || date >= startDate) &&
+			(endDate == "" || date <= endDate) &&
+			(status == "" || transaction.Status == status)
+	}
+}
+
+// listFilter filter datatable transaksi, rentang tanggal hanya jika keduanya diisi
+func listFilter(filter models.RequestTransactionHistoryList) func(models.Transaction) bool {
+	search := strings.ToLower(filter.SearchValue)
+
+	return func(transaction models.Transaction) bool {
+		if filter.StartDate != "" && filter.EndDate != "" {
+			date := transactionDate(transaction)
+			if date < filter.StartDate || date > filter.EndDate {
+				return false
+			}
+		}
+
+		if search != "" {
+			found := false
+			for _, value := range []string{transaction.AccountNumber, transaction.AccountName, transaction.SourceNumber, transaction.BeneficiaryNumber} {
+				if strings.Contains(strings.ToLower(value), search) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+
+		return (filter.AccountNumber == "" || transaction.AccountNumber == filter.AccountNumber) &&
+			(filter.Status == "" || transaction.Status == filter.Status)
+	}
+}
+
+// transactionDate DATE(transaction_time) dalam format LAYOUT_DATE
+func transactionDate(transaction models.Transaction) string {
+	return transaction.TransactionTime.In(time.Local).Format(constans.LAYOUT_DATE)
+}
+
+// newestFirst ORDER BY transaction_time DESC, id DESC
+func newestFirst(a, b models.Transaction) bool {
+	if !a.TransactionTime.Equal(b.TransactionTime) {
+		return a.TransactionTime.After(b.TransactionTime)
+	}
+	return a.ID > b.ID
+}
+
+// paginate LIMIT / OFFSET
+func paginate(transactions []models.Transaction, limit, page int) []models.Transaction {
+	offset := (page - 1) * limit
+	if offset >= len(transactions) {
+		return nil
+	}
+
+	end := offset + limit
+	if end > len(transactions) {
+		end = len(transactions)
+	}
+	return transactions[offset:end]
+}
+
+// transactionColumns pembanding per kolom yang boleh dipakai column_order_name
+var transactionColumns = map[string]func(a, b models.Transaction) int{
+	"id":                 func(a, b models.Transaction) int { return compareInt(int64(a.ID), int64(b.ID)) },
+	"account_id":         func(a, b models.Transaction) int { return compareInt(int64(a.AccountID), int64(b.AccountID)) },
+	"account_number":     func(a, b models.Transaction) int { return strings.Compare(a.AccountNumber, b.AccountNumber) },
+	"account_name":       func(a, b models.Transaction) int { return strings.Compare(a.AccountName, b.AccountName) },
+	"source_number":      func(a, b models.Transaction) int { return strings.Compare(a.SourceNumber, b.SourceNumber) },
+	"beneficiary_number": func(a, b models.Transaction) int { return strings.Compare(a.BeneficiaryNumber, b.BeneficiaryNumber) },
+	"transaction_type":   func(a, b models.Transaction) int { return strings.Compare(a.TransactionType, b.TransactionType) },
+	"amount":             func(a, b models.Transaction) int { return compareInt(a.Amount.Amount, b.Amount.Amount) },
+	"fee":                func(a, b models.Transaction) int { return compareInt(a.Fee.Amount, b.Fee.Amount) },
+	"currency": func(a, b models.Transaction) int {
+		return strings.Compare(a.Amount.CurrencyCode(), b.Amount.CurrencyCode())
+	},
+	"status":           func(a, b models.Transaction) int { return strings.Compare(a.Status, b.Status) },
+	"journal_id":       func(a, b models.Transaction) int { return compareInt(int64(a.JournalID), int64(b.JournalID)) },
+	"reversal_of":      func(a, b models.Transaction) int { return compareInt(int64(a.ReversalOf), int64(b.ReversalOf)) },
+	"transaction_time": func(a, b models.Transaction) int { return compareTime(a.TransactionTime, b.TransactionTime) },
+	"created_at":       func(a, b models.Transaction) int { return compareTime(a.CreatedAt, b.CreatedAt) },
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareTime(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	default:
+		return 0
+	}
+}
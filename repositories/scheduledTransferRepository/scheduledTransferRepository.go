@@ -0,0 +1,373 @@
+package scheduledTransferRepository
+
+import (
+	"database/sql"
+	"errors"
+	"sample/constans"
+	"sample/models"
+	"sample/repositories"
+	"time"
+)
+
+var defineColumn = `id, account_id, from_account_number, to_account_number, amount, frequency, description,
+					start_date, end_date, next_run_at, retry_at, retry_count, status, last_run_at,
+					locked_by, locked_until, created_at, updated_at`
+
+var defineColumnExecution = `id, schedule_id, scheduled_for, attempt, status, reference_no, message, executed_at`
+
+type scheduledTransferRepository struct {
+	RepoDB repositories.Repository
+}
+
+// NewScheduledTransferRepository
+func NewScheduledTransferRepository(repoDB repositories.Repository) scheduledTransferRepository {
+	return scheduledTransferRepository{
+		RepoDB: repoDB,
+	}
+}
+
+// AddScheduledTransfer membuat jadwal transfer baru
+func (ctx scheduledTransferRepository) AddScheduledTransfer(schedule models.ScheduledTransfer) (int, error) {
+	var ID int
+
+	query := `INSERT INTO scheduled_transfer (
+				account_id, from_account_number, to_account_number, amount, frequency, description,
+				start_date, end_date, next_run_at, retry_count, status, created_at, updated_at
+		) VALUES (
+				$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+		) RETURNING id`
+
+	now := time.Now()
+	err := ctx.RepoDB.DB.QueryRow(query,
+		schedule.AccountID,
+		schedule.FromAccountNumber,
+		schedule.ToAccountNumber,
+		schedule.Amount,
+		schedule.Frequency,
+		schedule.Description,
+		schedule.StartDate,
+		schedule.EndDate,
+		schedule.NextRunAt,
+		0,
+		constans.SCHEDULE_STATUS_ACTIVE,
+		now,
+		now,
+	).Scan(&ID)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return ID, nil
+}
+
+// FindScheduledTransferById mencari jadwal transfer berdasarkan ID
+func (ctx scheduledTransferRepository) FindScheduledTransferById(id int) (models.ScheduledTransfer, error) {
+	query := `SELECT ` + defineColumn + ` FROM scheduled_transfer WHERE id = $1 AND deleted_at IS NULL`
+
+	rows, err := ctx.RepoDB.DB.Query(query, id)
+	if err != nil {
+		return models.ScheduledTransfer{}, err
+	}
+	defer rows.Close()
+
+	result, err := scheduledTransferDto(rows)
+	if err != nil {
+		return models.ScheduledTransfer{}, err
+	}
+
+	if len(result) == 0 {
+		return models.ScheduledTransfer{}, errors.New("Scheduled transfer not found")
+	}
+
+	return result[0], nil
+}
+
+// GetScheduledTransfersByAccount mendapatkan semua jadwal transfer milik rekening
+func (ctx scheduledTransferRepository) GetScheduledTransfersByAccount(accountNumber string) ([]models.ScheduledTransfer, error) {
+	query := `SELECT ` + defineColumn + ` FROM scheduled_transfer
+			  WHERE from_account_number = $1 AND deleted_at IS NULL
+			  ORDER BY created_at DESC`
+
+	rows, err := ctx.RepoDB.DB.Query(query, accountNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scheduledTransferDto(rows)
+}
+
+// UpdateScheduledTransfer update nominal, tanggal akhir, status dan jadwal berikutnya.
+// Hanya berhasil jika occurrence, retry dan status belum diubah worker sejak previous dibaca
+// dan tidak ada lease aktif, sehingga update tidak pernah memundurkan jadwal yang sudah dieksekusi
+func (ctx scheduledTransferRepository) UpdateScheduledTransfer(schedule models.ScheduledTransfer, previous models.ScheduledTransfer) error {
+	now := time.Now()
+
+	query := `UPDATE scheduled_transfer
+			  SET amount = $2,
+			      end_date = $3,
+			      status = $4,
+			      next_run_at = $5,
+			      retry_at = $6,
+			      retry_count = $7,
+			      updated_at = $8
+			  WHERE id = $1 AND deleted_at IS NULL
+			    AND next_run_at = $9 AND retry_count = $10 AND status = $11
+			    AND (locked_until IS NULL OR locked_until < $8)`
+
+	result, err := ctx.RepoDB.DB.Exec(query,
+		schedule.ID,
+		schedule.Amount,
+		schedule.EndDate,
+		schedule.Status,
+		schedule.NextRunAt,
+		schedule.RetryAt,
+		schedule.RetryCount,
+		now,
+		previous.NextRunAt,
+		previous.RetryCount,
+		previous.Status,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("Scheduled transfer was modified or is being processed")
+	}
+
+	return nil
+}
+
+// RemoveScheduledTransfer membatalkan jadwal (soft delete)
+func (ctx scheduledTransferRepository) RemoveScheduledTransfer(id int) error {
+	now := time.Now()
+	result, err := ctx.RepoDB.DB.Exec(
+		"UPDATE scheduled_transfer SET status = $1, updated_at = $2, deleted_at = $2 WHERE id = $3 AND deleted_at IS NULL",
+		constans.SCHEDULE_STATUS_CANCELLED,
+		now,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("Scheduled transfer not found")
+	}
+
+	return nil
+}
+
+// ClaimDueScheduledTransfers mengambil jadwal yang jatuh tempo dan menguncinya (lease)
+// untuk worker ini. FOR UPDATE SKIP LOCKED memastikan satu jadwal hanya diambil
+// satu instance walaupun beberapa instance berjalan bersamaan
+func (ctx scheduledTransferRepository) ClaimDueScheduledTransfers(workerID string, limit int, lease time.Duration) ([]models.ScheduledTransfer, error) {
+	now := time.Now()
+
+	query := `UPDATE scheduled_transfer
+			  SET locked_by = $1, locked_until = $2
+			  WHERE id IN (
+				  SELECT id FROM scheduled_transfer
+				  WHERE status = $3 AND deleted_at IS NULL
+				    AND COALESCE(retry_at, next_run_at) <= $4
+				    AND (locked_until IS NULL OR locked_until < $4)
+				  ORDER BY COALESCE(retry_at, next_run_at)
+				  LIMIT $5
+				  FOR UPDATE SKIP LOCKED
+			  )
+			  RETURNING ` + defineColumn
+
+	rows, err := ctx.RepoDB.DB.Query(query, workerID, now.Add(lease), constans.SCHEDULE_STATUS_ACTIVE, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scheduledTransferDto(rows)
+}
+
+// CompleteScheduledRunWithTx menyimpan hasil eksekusi (jadwal berikutnya, retry, status)
+// dan melepas lease. Hanya berhasil jika occurrence belum berubah dan lease masih milik
+// worker ini, sehingga satu occurrence tidak pernah dieksekusi dua kali
+func (ctx scheduledTransferRepository) CompleteScheduledRunWithTx(tx *sql.Tx, schedule models.ScheduledTransfer, expectedRunAt time.Time) error {
+	var (
+		result sql.Result
+		err    error
+	)
+
+	query := `UPDATE scheduled_transfer
+			  SET next_run_at = $2,
+			      retry_at = $3,
+			      retry_count = $4,
+			      status = $5,
+			      last_run_at = $6,
+			      locked_by = NULL,
+			      locked_until = NULL,
+			      updated_at = $7
+			  WHERE id = $1 AND next_run_at = $8 AND locked_by = $9
+			    AND status = $10 AND deleted_at IS NULL`
+
+	args := []interface{}{
+		schedule.ID,
+		schedule.NextRunAt,
+		schedule.RetryAt,
+		schedule.RetryCount,
+		schedule.Status,
+		schedule.LastRunAt,
+		time.Now(),
+		expectedRunAt,
+		schedule.LockedBy,
+		constans.SCHEDULE_STATUS_ACTIVE,
+	}
+
+	if tx != nil {
+		result, err = tx.Exec(query, args...)
+	} else {
+		result, err = ctx.RepoDB.DB.Exec(query, args...)
+	}
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("Scheduled transfer was modified or claimed by another worker")
+	}
+
+	return nil
+}
+
+// ReleaseScheduledTransfer melepas lease tanpa mengubah jadwal (dipakai saat error sistem)
+func (ctx scheduledTransferRepository) ReleaseScheduledTransfer(id int, workerID string) error {
+	_, err := ctx.RepoDB.DB.Exec(
+		"UPDATE scheduled_transfer SET locked_by = NULL, locked_until = NULL WHERE id = $1 AND locked_by = $2",
+		id,
+		workerID,
+	)
+	return err
+}
+
+// AddScheduledTransferExecution mencatat hasil eksekusi, ikut dalam tx jika diberikan
+func (ctx scheduledTransferRepository) AddScheduledTransferExecution(execution models.ScheduledTransferExecution, tx *sql.Tx) (int, error) {
+	var (
+		ID  int
+		err error
+	)
+
+	query := `INSERT INTO scheduled_transfer_execution (
+				schedule_id, scheduled_for, attempt, status, reference_no, message, executed_at
+		) VALUES (
+				$1, $2, $3, $4, $5, $6, $7
+		) RETURNING id`
+
+	args := []interface{}{
+		execution.ScheduleID,
+		execution.ScheduledFor,
+		execution.Attempt,
+		execution.Status,
+		execution.ReferenceNo,
+		execution.Message,
+		execution.ExecutedAt,
+	}
+
+	if tx != nil {
+		err = tx.QueryRow(query, args...).Scan(&ID)
+	} else {
+		err = ctx.RepoDB.DB.QueryRow(query, args...).Scan(&ID)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return ID, nil
+}
+
+// GetScheduledTransferExecutions mendapatkan riwayat eksekusi sebuah jadwal
+func (ctx scheduledTransferRepository) GetScheduledTransferExecutions(scheduleID int) ([]models.ScheduledTransferExecution, error) {
+	var result []models.ScheduledTransferExecution
+
+	query := `SELECT ` + defineColumnExecution + ` FROM scheduled_transfer_execution
+			  WHERE schedule_id = $1 ORDER BY executed_at DESC`
+
+	rows, err := ctx.RepoDB.DB.Query(query, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var val models.ScheduledTransferExecution
+		err := rows.Scan(
+			&val.ID,
+			&val.ScheduleID,
+			&val.ScheduledFor,
+			&val.Attempt,
+			&val.Status,
+			&val.ReferenceNo,
+			&val.Message,
+			&val.ExecutedAt,
+		)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, val)
+	}
+
+	return result, rows.Err()
+}
+
+// scheduledTransferDto helper untuk mapping rows ke struct
+func scheduledTransferDto(rows *sql.Rows) ([]models.ScheduledTransfer, error) {
+	var result []models.ScheduledTransfer
+
+	for rows.Next() {
+		var val models.ScheduledTransfer
+		var lockedBy sql.NullString
+
+		err := rows.Scan(
+			&val.ID,
+			&val.AccountID,
+			&val.FromAccountNumber,
+			&val.ToAccountNumber,
+			&val.Amount,
+			&val.Frequency,
+			&val.Description,
+			&val.StartDate,
+			&val.EndDate,
+			&val.NextRunAt,
+			&val.RetryAt,
+			&val.RetryCount,
+			&val.Status,
+			&val.LastRunAt,
+			&lockedBy,
+			&val.LockedUntil,
+			&val.CreatedAt,
+			&val.UpdatedAt,
+		)
+		if err != nil {
+			return result, err
+		}
+
+		val.LockedBy = lockedBy.String
+		result = append(result, val)
+	}
+
+	return result, rows.Err()
+}
@@ -0,0 +1,728 @@
+// Package tracingRepository membungkus repository asli: setiap method dicatat sebagai child span
+// dari Context (biasanya span request HTTP) lalu diteruskan apa adanya ke Next.
+// Dipasang per request lewat services.UsecaseService.WithContext
+package tracingRepository
+
+import (
+	"context"
+	"database/sql"
+	"sample/models"
+	"sample/repositories"
+	"sample/tracing"
+	"time"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+)
+
+type accountRepository struct {
+	Next    repositories.AccountRepository
+	Context context.Context
+}
+
+// NewAccountRepository membungkus next, span repository menjadi child dari span di ctx
+func NewAccountRepository(ctx context.Context, next repositories.AccountRepository) repositories.AccountRepository {
+	if next == nil {
+		return nil
+	}
+	if traced, ok := next.(accountRepository); ok {
+		next = traced.Next
+	}
+	return accountRepository{Next: next, Context: ctx}
+}
+
+func (ctx accountRepository) FindAccountById(id int) (models.Account, error) {
+	_, span := tracing.Start(ctx.Context, "AccountRepository.FindAccountById", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.FindAccountById(id)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx accountRepository) FindAccountByNumber(accountNumber string) (models.Account, error) {
+	_, span := tracing.Start(ctx.Context, "AccountRepository.FindAccountByNumber", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.FindAccountByNumber(accountNumber)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx accountRepository) IsAccountExistsByNumber(accountNumber string) (models.Account, bool) {
+	_, span := tracing.Start(ctx.Context, "AccountRepository.IsAccountExistsByNumber", semconv.DBSystemPostgreSQL)
+	result, ok := ctx.Next.IsAccountExistsByNumber(accountNumber)
+	tracing.End(span, nil)
+	return result, ok
+}
+
+func (ctx accountRepository) AddAccount(account models.Account) (int, error) {
+	_, span := tracing.Start(ctx.Context, "AccountRepository.AddAccount", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.AddAccount(account)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx accountRepository) AddAccountWithTx(tx *sql.Tx, account models.Account) (int, error) {
+	_, span := tracing.Start(ctx.Context, "AccountRepository.AddAccountWithTx", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.AddAccountWithTx(tx, account)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx accountRepository) UpdateAccount(account models.Account) (int, error) {
+	_, span := tracing.Start(ctx.Context, "AccountRepository.UpdateAccount", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.UpdateAccount(account)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx accountRepository) UpdatePIN(accountNumber string, newPIN string) error {
+	_, span := tracing.Start(ctx.Context, "AccountRepository.UpdatePIN", semconv.DBSystemPostgreSQL)
+	err := ctx.Next.UpdatePIN(accountNumber, newPIN)
+	tracing.End(span, err)
+	return err
+}
+
+func (ctx accountRepository) UpdatePINWithTx(tx *sql.Tx, accountNumber string, newPIN string) error {
+	_, span := tracing.Start(ctx.Context, "AccountRepository.UpdatePINWithTx", semconv.DBSystemPostgreSQL)
+	err := ctx.Next.UpdatePINWithTx(tx, accountNumber, newPIN)
+	tracing.End(span, err)
+	return err
+}
+
+func (ctx accountRepository) ResetFailedPINAttempts(accountNumber string) error {
+	_, span := tracing.Start(ctx.Context, "AccountRepository.ResetFailedPINAttempts", semconv.DBSystemPostgreSQL)
+	err := ctx.Next.ResetFailedPINAttempts(accountNumber)
+	tracing.End(span, err)
+	return err
+}
+
+func (ctx accountRepository) FindAccountByNumberForUpdate(accountNumber string, tx *sql.Tx) (models.Account, error) {
+	_, span := tracing.Start(ctx.Context, "AccountRepository.FindAccountByNumberForUpdate", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.FindAccountByNumberForUpdate(accountNumber, tx)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx accountRepository) UpdatePINLockStateWithTx(tx *sql.Tx, account models.Account) error {
+	_, span := tracing.Start(ctx.Context, "AccountRepository.UpdatePINLockStateWithTx", semconv.DBSystemPostgreSQL)
+	err := ctx.Next.UpdatePINLockStateWithTx(tx, account)
+	tracing.End(span, err)
+	return err
+}
+
+func (ctx accountRepository) AddPINLockEvent(event models.PINLockEvent, tx *sql.Tx) (int, error) {
+	_, span := tracing.Start(ctx.Context, "AccountRepository.AddPINLockEvent", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.AddPINLockEvent(event, tx)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx accountRepository) GetPINLockEvents(accountNumber string) ([]models.PINLockEvent, error) {
+	_, span := tracing.Start(ctx.Context, "AccountRepository.GetPINLockEvents", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.GetPINLockEvents(accountNumber)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx accountRepository) IncrementDecrementLastBalance(accountID int, amount models.Money, debitCreditOperator string, updatedAt string, tx *sql.Tx) (models.Money, error) {
+	_, span := tracing.Start(ctx.Context, "AccountRepository.IncrementDecrementLastBalance", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.IncrementDecrementLastBalance(accountID, amount, debitCreditOperator, updatedAt, tx)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx accountRepository) IncrementDecrementHeldBalance(accountID int, amount models.Money, debitCreditOperator string, updatedAt string, tx *sql.Tx) (models.Money, error) {
+	_, span := tracing.Start(ctx.Context, "AccountRepository.IncrementDecrementHeldBalance", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.IncrementDecrementHeldBalance(accountID, amount, debitCreditOperator, updatedAt, tx)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx accountRepository) GetAvailableBalance(accountID int, tx *sql.Tx) (models.Money, error) {
+	_, span := tracing.Start(ctx.Context, "AccountRepository.GetAvailableBalance", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.GetAvailableBalance(accountID, tx)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx accountRepository) GetAccountPockets(accountID int) ([]models.AccountPocket, error) {
+	_, span := tracing.Start(ctx.Context, "AccountRepository.GetAccountPockets", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.GetAccountPockets(accountID)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx accountRepository) IncrementDecrementPocketBalance(accountID int, amount models.Money, debitCreditOperator string, updatedAt string, tx *sql.Tx) (models.Money, error) {
+	_, span := tracing.Start(ctx.Context, "AccountRepository.IncrementDecrementPocketBalance", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.IncrementDecrementPocketBalance(accountID, amount, debitCreditOperator, updatedAt, tx)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx accountRepository) RemoveAccount(id int) error {
+	_, span := tracing.Start(ctx.Context, "AccountRepository.RemoveAccount", semconv.DBSystemPostgreSQL)
+	err := ctx.Next.RemoveAccount(id)
+	tracing.End(span, err)
+	return err
+}
+
+func (ctx accountRepository) GetAccountList() ([]models.Account, error) {
+	_, span := tracing.Start(ctx.Context, "AccountRepository.GetAccountList", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.GetAccountList()
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx accountRepository) VerifyPIN(accountNumber string, pin string) (bool, error) {
+	_, span := tracing.Start(ctx.Context, "AccountRepository.VerifyPIN", semconv.DBSystemPostgreSQL)
+	ok, err := ctx.Next.VerifyPIN(accountNumber, pin)
+	tracing.End(span, err)
+	return ok, err
+}
+
+type transactionRepository struct {
+	Next    repositories.TransactionRepository
+	Context context.Context
+}
+
+// NewTransactionRepository membungkus next, span repository menjadi child dari span di ctx
+func NewTransactionRepository(ctx context.Context, next repositories.TransactionRepository) repositories.TransactionRepository {
+	if next == nil {
+		return nil
+	}
+	if traced, ok := next.(transactionRepository); ok {
+		next = traced.Next
+	}
+	return transactionRepository{Next: next, Context: ctx}
+}
+
+func (ctx transactionRepository) AddTransaction(transaction models.Transaction, tx *sql.Tx) (int, error) {
+	_, span := tracing.Start(ctx.Context, "TransactionRepository.AddTransaction", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.AddTransaction(transaction, tx)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx transactionRepository) FindTransactionById(id int) (models.Transaction, error) {
+	_, span := tracing.Start(ctx.Context, "TransactionRepository.FindTransactionById", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.FindTransactionById(id)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx transactionRepository) FindTransactionsByJournalId(journalID int, tx *sql.Tx) ([]models.Transaction, error) {
+	_, span := tracing.Start(ctx.Context, "TransactionRepository.FindTransactionsByJournalId", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.FindTransactionsByJournalId(journalID, tx)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx transactionRepository) GetReversedAmount(transactionID int, tx *sql.Tx) (models.Money, error) {
+	_, span := tracing.Start(ctx.Context, "TransactionRepository.GetReversedAmount", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.GetReversedAmount(transactionID, tx)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx transactionRepository) UpdateTransactionStatus(id int, fromStatus, toStatus string, tx *sql.Tx) error {
+	_, span := tracing.Start(ctx.Context, "TransactionRepository.UpdateTransactionStatus", semconv.DBSystemPostgreSQL)
+	err := ctx.Next.UpdateTransactionStatus(id, fromStatus, toStatus, tx)
+	tracing.End(span, err)
+	return err
+}
+
+func (ctx transactionRepository) GetTransactionHistory(accountNumber string, startDate, endDate, status string, limit, page int) ([]models.Transaction, int, error) {
+	_, span := tracing.Start(ctx.Context, "TransactionRepository.GetTransactionHistory", semconv.DBSystemPostgreSQL)
+	result, result1, err := ctx.Next.GetTransactionHistory(accountNumber, startDate, endDate, status, limit, page)
+	tracing.End(span, err)
+	return result, result1, err
+}
+
+func (ctx transactionRepository) StreamTransactionHistory(accountNumber, startDate, endDate, status string, tx *sql.Tx, fn func(models.Transaction) error) error {
+	_, span := tracing.Start(ctx.Context, "TransactionRepository.StreamTransactionHistory", semconv.DBSystemPostgreSQL)
+	err := ctx.Next.StreamTransactionHistory(accountNumber, startDate, endDate, status, tx, fn)
+	tracing.End(span, err)
+	return err
+}
+
+func (ctx transactionRepository) GetBalanceBefore(accountNumber, currency, startDate string, tx *sql.Tx) (models.Money, error) {
+	_, span := tracing.Start(ctx.Context, "TransactionRepository.GetBalanceBefore", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.GetBalanceBefore(accountNumber, currency, startDate, tx)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx transactionRepository) DataCountAndSumTransactionListByIndex(countOnly bool, filter models.RequestTransactionHistoryList) (models.ResultDataTableTransactionCountAndSummaries, error) {
+	_, span := tracing.Start(ctx.Context, "TransactionRepository.DataCountAndSumTransactionListByIndex", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.DataCountAndSumTransactionListByIndex(countOnly, filter)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx transactionRepository) DataGetTransactionListByIndex(filter models.RequestTransactionHistoryList) ([]models.Transaction, error) {
+	_, span := tracing.Start(ctx.Context, "TransactionRepository.DataGetTransactionListByIndex", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.DataGetTransactionListByIndex(filter)
+	tracing.End(span, err)
+	return result, err
+}
+
+type ledgerRepository struct {
+	Next    repositories.LedgerRepository
+	Context context.Context
+}
+
+// NewLedgerRepository membungkus next, span repository menjadi child dari span di ctx
+func NewLedgerRepository(ctx context.Context, next repositories.LedgerRepository) repositories.LedgerRepository {
+	if next == nil {
+		return nil
+	}
+	if traced, ok := next.(ledgerRepository); ok {
+		next = traced.Next
+	}
+	return ledgerRepository{Next: next, Context: ctx}
+}
+
+func (ctx ledgerRepository) EnsureSystemLedgerAccounts() error {
+	_, span := tracing.Start(ctx.Context, "LedgerRepository.EnsureSystemLedgerAccounts", semconv.DBSystemPostgreSQL)
+	err := ctx.Next.EnsureSystemLedgerAccounts()
+	tracing.End(span, err)
+	return err
+}
+
+func (ctx ledgerRepository) PostJournal(journal models.JournalEntry, tx *sql.Tx) (int, error) {
+	_, span := tracing.Start(ctx.Context, "LedgerRepository.PostJournal", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.PostJournal(journal, tx)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx ledgerRepository) FindJournalById(id int) (models.JournalEntry, error) {
+	_, span := tracing.Start(ctx.Context, "LedgerRepository.FindJournalById", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.FindJournalById(id)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx ledgerRepository) FindJournalByReferenceNo(referenceNo string) (models.JournalEntry, error) {
+	_, span := tracing.Start(ctx.Context, "LedgerRepository.FindJournalByReferenceNo", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.FindJournalByReferenceNo(referenceNo)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx ledgerRepository) GetLedgerBalance(ledgerCode string) (models.LedgerBalanceResponse, error) {
+	_, span := tracing.Start(ctx.Context, "LedgerRepository.GetLedgerBalance", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.GetLedgerBalance(ledgerCode)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx ledgerRepository) GetUnbalancedJournals() ([]models.UnbalancedJournal, error) {
+	_, span := tracing.Start(ctx.Context, "LedgerRepository.GetUnbalancedJournals", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.GetUnbalancedJournals()
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx ledgerRepository) GetLedgerMismatches() ([]models.LedgerMismatch, error) {
+	_, span := tracing.Start(ctx.Context, "LedgerRepository.GetLedgerMismatches", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.GetLedgerMismatches()
+	tracing.End(span, err)
+	return result, err
+}
+
+type scheduledTransferRepository struct {
+	Next    repositories.ScheduledTransferRepository
+	Context context.Context
+}
+
+// NewScheduledTransferRepository membungkus next, span repository menjadi child dari span di ctx
+func NewScheduledTransferRepository(ctx context.Context, next repositories.ScheduledTransferRepository) repositories.ScheduledTransferRepository {
+	if next == nil {
+		return nil
+	}
+	if traced, ok := next.(scheduledTransferRepository); ok {
+		next = traced.Next
+	}
+	return scheduledTransferRepository{Next: next, Context: ctx}
+}
+
+func (ctx scheduledTransferRepository) AddScheduledTransfer(schedule models.ScheduledTransfer) (int, error) {
+	_, span := tracing.Start(ctx.Context, "ScheduledTransferRepository.AddScheduledTransfer", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.AddScheduledTransfer(schedule)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx scheduledTransferRepository) FindScheduledTransferById(id int) (models.ScheduledTransfer, error) {
+	_, span := tracing.Start(ctx.Context, "ScheduledTransferRepository.FindScheduledTransferById", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.FindScheduledTransferById(id)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx scheduledTransferRepository) GetScheduledTransfersByAccount(accountNumber string) ([]models.ScheduledTransfer, error) {
+	_, span := tracing.Start(ctx.Context, "ScheduledTransferRepository.GetScheduledTransfersByAccount", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.GetScheduledTransfersByAccount(accountNumber)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx scheduledTransferRepository) UpdateScheduledTransfer(schedule models.ScheduledTransfer, previous models.ScheduledTransfer) error {
+	_, span := tracing.Start(ctx.Context, "ScheduledTransferRepository.UpdateScheduledTransfer", semconv.DBSystemPostgreSQL)
+	err := ctx.Next.UpdateScheduledTransfer(schedule, previous)
+	tracing.End(span, err)
+	return err
+}
+
+func (ctx scheduledTransferRepository) RemoveScheduledTransfer(id int) error {
+	_, span := tracing.Start(ctx.Context, "ScheduledTransferRepository.RemoveScheduledTransfer", semconv.DBSystemPostgreSQL)
+	err := ctx.Next.RemoveScheduledTransfer(id)
+	tracing.End(span, err)
+	return err
+}
+
+func (ctx scheduledTransferRepository) ClaimDueScheduledTransfers(workerID string, limit int, lease time.Duration) ([]models.ScheduledTransfer, error) {
+	_, span := tracing.Start(ctx.Context, "ScheduledTransferRepository.ClaimDueScheduledTransfers", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.ClaimDueScheduledTransfers(workerID, limit, lease)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx scheduledTransferRepository) CompleteScheduledRunWithTx(tx *sql.Tx, schedule models.ScheduledTransfer, expectedRunAt time.Time) error {
+	_, span := tracing.Start(ctx.Context, "ScheduledTransferRepository.CompleteScheduledRunWithTx", semconv.DBSystemPostgreSQL)
+	err := ctx.Next.CompleteScheduledRunWithTx(tx, schedule, expectedRunAt)
+	tracing.End(span, err)
+	return err
+}
+
+func (ctx scheduledTransferRepository) ReleaseScheduledTransfer(id int, workerID string) error {
+	_, span := tracing.Start(ctx.Context, "ScheduledTransferRepository.ReleaseScheduledTransfer", semconv.DBSystemPostgreSQL)
+	err := ctx.Next.ReleaseScheduledTransfer(id, workerID)
+	tracing.End(span, err)
+	return err
+}
+
+func (ctx scheduledTransferRepository) AddScheduledTransferExecution(execution models.ScheduledTransferExecution, tx *sql.Tx) (int, error) {
+	_, span := tracing.Start(ctx.Context, "ScheduledTransferRepository.AddScheduledTransferExecution", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.AddScheduledTransferExecution(execution, tx)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx scheduledTransferRepository) GetScheduledTransferExecutions(scheduleID int) ([]models.ScheduledTransferExecution, error) {
+	_, span := tracing.Start(ctx.Context, "ScheduledTransferRepository.GetScheduledTransferExecutions", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.GetScheduledTransferExecutions(scheduleID)
+	tracing.End(span, err)
+	return result, err
+}
+
+type limitRepository struct {
+	Next    repositories.LimitRepository
+	Context context.Context
+}
+
+// NewLimitRepository membungkus next, span repository menjadi child dari span di ctx
+func NewLimitRepository(ctx context.Context, next repositories.LimitRepository) repositories.LimitRepository {
+	if next == nil {
+		return nil
+	}
+	if traced, ok := next.(limitRepository); ok {
+		next = traced.Next
+	}
+	return limitRepository{Next: next, Context: ctx}
+}
+
+func (ctx limitRepository) EnsureDefaultTransactionLimits() error {
+	_, span := tracing.Start(ctx.Context, "LimitRepository.EnsureDefaultTransactionLimits", semconv.DBSystemPostgreSQL)
+	err := ctx.Next.EnsureDefaultTransactionLimits()
+	tracing.End(span, err)
+	return err
+}
+
+func (ctx limitRepository) FindTransactionLimit(tier, transactionType string) (models.TransactionLimit, error) {
+	_, span := tracing.Start(ctx.Context, "LimitRepository.FindTransactionLimit", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.FindTransactionLimit(tier, transactionType)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx limitRepository) GetTransactionLimitsByTier(tier string) ([]models.TransactionLimit, error) {
+	_, span := tracing.Start(ctx.Context, "LimitRepository.GetTransactionLimitsByTier", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.GetTransactionLimitsByTier(tier)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx limitRepository) GetLimitUsage(accountID int, transactionType, direction string, now time.Time, tx *sql.Tx) (models.LimitUsage, error) {
+	_, span := tracing.Start(ctx.Context, "LimitRepository.GetLimitUsage", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.GetLimitUsage(accountID, transactionType, direction, now, tx)
+	tracing.End(span, err)
+	return result, err
+}
+
+type feeRepository struct {
+	Next    repositories.FeeRepository
+	Context context.Context
+}
+
+// NewFeeRepository membungkus next, span repository menjadi child dari span di ctx
+func NewFeeRepository(ctx context.Context, next repositories.FeeRepository) repositories.FeeRepository {
+	if next == nil {
+		return nil
+	}
+	if traced, ok := next.(feeRepository); ok {
+		next = traced.Next
+	}
+	return feeRepository{Next: next, Context: ctx}
+}
+
+func (ctx feeRepository) EnsureDefaultFeeRules() error {
+	_, span := tracing.Start(ctx.Context, "FeeRepository.EnsureDefaultFeeRules", semconv.DBSystemPostgreSQL)
+	err := ctx.Next.EnsureDefaultFeeRules()
+	tracing.End(span, err)
+	return err
+}
+
+func (ctx feeRepository) FindFeeRule(transactionType string) (models.FeeRule, error) {
+	_, span := tracing.Start(ctx.Context, "FeeRepository.FindFeeRule", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.FindFeeRule(transactionType)
+	tracing.End(span, err)
+	return result, err
+}
+
+type holdRepository struct {
+	Next    repositories.HoldRepository
+	Context context.Context
+}
+
+// NewHoldRepository membungkus next, span repository menjadi child dari span di ctx
+func NewHoldRepository(ctx context.Context, next repositories.HoldRepository) repositories.HoldRepository {
+	if next == nil {
+		return nil
+	}
+	if traced, ok := next.(holdRepository); ok {
+		next = traced.Next
+	}
+	return holdRepository{Next: next, Context: ctx}
+}
+
+func (ctx holdRepository) AddHold(hold models.Hold, tx *sql.Tx) (int, error) {
+	_, span := tracing.Start(ctx.Context, "HoldRepository.AddHold", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.AddHold(hold, tx)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx holdRepository) FindHoldById(id int) (models.Hold, error) {
+	_, span := tracing.Start(ctx.Context, "HoldRepository.FindHoldById", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.FindHoldById(id)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx holdRepository) FindHoldByIdForUpdate(id int, tx *sql.Tx) (models.Hold, error) {
+	_, span := tracing.Start(ctx.Context, "HoldRepository.FindHoldByIdForUpdate", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.FindHoldByIdForUpdate(id, tx)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx holdRepository) GetHoldsByAccount(accountNumber string) ([]models.Hold, error) {
+	_, span := tracing.Start(ctx.Context, "HoldRepository.GetHoldsByAccount", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.GetHoldsByAccount(accountNumber)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx holdRepository) CloseHoldWithTx(tx *sql.Tx, hold models.Hold) error {
+	_, span := tracing.Start(ctx.Context, "HoldRepository.CloseHoldWithTx", semconv.DBSystemPostgreSQL)
+	err := ctx.Next.CloseHoldWithTx(tx, hold)
+	tracing.End(span, err)
+	return err
+}
+
+func (ctx holdRepository) GetExpiredHoldIDs(now time.Time, limit int) ([]int, error) {
+	_, span := tracing.Start(ctx.Context, "HoldRepository.GetExpiredHoldIDs", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.GetExpiredHoldIDs(now, limit)
+	tracing.End(span, err)
+	return result, err
+}
+
+type fxRepository struct {
+	Next    repositories.FxRepository
+	Context context.Context
+}
+
+// NewFxRepository membungkus next, span repository menjadi child dari span di ctx
+func NewFxRepository(ctx context.Context, next repositories.FxRepository) repositories.FxRepository {
+	if next == nil {
+		return nil
+	}
+	if traced, ok := next.(fxRepository); ok {
+		next = traced.Next
+	}
+	return fxRepository{Next: next, Context: ctx}
+}
+
+func (ctx fxRepository) AddFxRate(rate models.FxRate) (int, error) {
+	_, span := tracing.Start(ctx.Context, "FxRepository.AddFxRate", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.AddFxRate(rate)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx fxRepository) FindEffectiveFxRate(fromCurrency, toCurrency string, at time.Time) (models.FxRate, error) {
+	_, span := tracing.Start(ctx.Context, "FxRepository.FindEffectiveFxRate", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.FindEffectiveFxRate(fromCurrency, toCurrency, at)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx fxRepository) GetFxRateList(baseCurrency, quoteCurrency string) ([]models.FxRate, error) {
+	_, span := tracing.Start(ctx.Context, "FxRepository.GetFxRateList", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.GetFxRateList(baseCurrency, quoteCurrency)
+	tracing.End(span, err)
+	return result, err
+}
+
+type webhookRepository struct {
+	Next    repositories.WebhookRepository
+	Context context.Context
+}
+
+// NewWebhookRepository membungkus next, span repository menjadi child dari span di ctx
+func NewWebhookRepository(ctx context.Context, next repositories.WebhookRepository) repositories.WebhookRepository {
+	if next == nil {
+		return nil
+	}
+	if traced, ok := next.(webhookRepository); ok {
+		next = traced.Next
+	}
+	return webhookRepository{Next: next, Context: ctx}
+}
+
+func (ctx webhookRepository) AddWebhookSubscription(subscription models.WebhookSubscription) (int, error) {
+	_, span := tracing.Start(ctx.Context, "WebhookRepository.AddWebhookSubscription", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.AddWebhookSubscription(subscription)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx webhookRepository) FindWebhookSubscriptionById(id int) (models.WebhookSubscription, error) {
+	_, span := tracing.Start(ctx.Context, "WebhookRepository.FindWebhookSubscriptionById", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.FindWebhookSubscriptionById(id)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx webhookRepository) GetWebhookSubscriptions() ([]models.WebhookSubscription, error) {
+	_, span := tracing.Start(ctx.Context, "WebhookRepository.GetWebhookSubscriptions", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.GetWebhookSubscriptions()
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx webhookRepository) UpdateWebhookSubscription(subscription models.WebhookSubscription) error {
+	_, span := tracing.Start(ctx.Context, "WebhookRepository.UpdateWebhookSubscription", semconv.DBSystemPostgreSQL)
+	err := ctx.Next.UpdateWebhookSubscription(subscription)
+	tracing.End(span, err)
+	return err
+}
+
+func (ctx webhookRepository) AddOutboxEvent(event models.WebhookEvent, tx *sql.Tx) (int, error) {
+	_, span := tracing.Start(ctx.Context, "WebhookRepository.AddOutboxEvent", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.AddOutboxEvent(event, tx)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx webhookRepository) DispatchOutboxEvents(limit int) (int, error) {
+	_, span := tracing.Start(ctx.Context, "WebhookRepository.DispatchOutboxEvents", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.DispatchOutboxEvents(limit)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx webhookRepository) ClaimDueWebhookDeliveries(workerID string, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	_, span := tracing.Start(ctx.Context, "WebhookRepository.ClaimDueWebhookDeliveries", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.ClaimDueWebhookDeliveries(workerID, limit, lease)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx webhookRepository) CompleteWebhookDeliveryWithTx(tx *sql.Tx, delivery models.WebhookDelivery) error {
+	_, span := tracing.Start(ctx.Context, "WebhookRepository.CompleteWebhookDeliveryWithTx", semconv.DBSystemPostgreSQL)
+	err := ctx.Next.CompleteWebhookDeliveryWithTx(tx, delivery)
+	tracing.End(span, err)
+	return err
+}
+
+func (ctx webhookRepository) AddWebhookDeliveryAttempt(attempt models.WebhookDeliveryAttempt, tx *sql.Tx) (int, error) {
+	_, span := tracing.Start(ctx.Context, "WebhookRepository.AddWebhookDeliveryAttempt", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.AddWebhookDeliveryAttempt(attempt, tx)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx webhookRepository) FindWebhookDeliveryById(id int) (models.WebhookDelivery, error) {
+	_, span := tracing.Start(ctx.Context, "WebhookRepository.FindWebhookDeliveryById", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.FindWebhookDeliveryById(id)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx webhookRepository) GetWebhookDeliveries(filter models.RequestWebhookDeliveryList) ([]models.WebhookDelivery, error) {
+	_, span := tracing.Start(ctx.Context, "WebhookRepository.GetWebhookDeliveries", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.GetWebhookDeliveries(filter)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx webhookRepository) GetWebhookDeliveryAttempts(deliveryID int) ([]models.WebhookDeliveryAttempt, error) {
+	_, span := tracing.Start(ctx.Context, "WebhookRepository.GetWebhookDeliveryAttempts", semconv.DBSystemPostgreSQL)
+	result, err := ctx.Next.GetWebhookDeliveryAttempts(deliveryID)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx webhookRepository) RedeliverWebhookDelivery(id int) error {
+	_, span := tracing.Start(ctx.Context, "WebhookRepository.RedeliverWebhookDelivery", semconv.DBSystemPostgreSQL)
+	err := ctx.Next.RedeliverWebhookDelivery(id)
+	tracing.End(span, err)
+	return err
+}
+
+type auditRepository struct {
+	Next    repositories.AuditRepository
+	Context context.Context
+}
+
+// NewAuditRepository membungkus next, span repository menjadi child dari span di ctx
+func NewAuditRepository(ctx context.Context, next repositories.AuditRepository) repositories.AuditRepository {
+	if next == nil {
+		return nil
+	}
+	if traced, ok := next.(auditRepository); ok {
+		next = traced.Next
+	}
+	return auditRepository{Next: next, Context: ctx}
+}
+
+func (ctx auditRepository) EnsureAuditIndexes() error {
+	_, span := tracing.Start(ctx.Context, "AuditRepository.EnsureAuditIndexes", semconv.DBSystemMongoDB)
+	err := ctx.Next.EnsureAuditIndexes()
+	tracing.End(span, err)
+	return err
+}
+
+func (ctx auditRepository) AddAuditLog(auditLog models.AuditLog) (string, error) {
+	_, span := tracing.Start(ctx.Context, "AuditRepository.AddAuditLog", semconv.DBSystemMongoDB)
+	result, err := ctx.Next.AddAuditLog(auditLog)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (ctx auditRepository) GetAuditLogs(filter models.RequestAuditLogList) ([]models.AuditLog, error) {
+	_, span := tracing.Start(ctx.Context, "AuditRepository.GetAuditLogs", semconv.DBSystemMongoDB)
+	result, err := ctx.Next.GetAuditLogs(filter)
+	tracing.End(span, err)
+	return result, err
+}
@@ -3,6 +3,8 @@ package transactionRepository
 import (
 	"database/sql"
 	"errors"
+	"fmt"
+	"sample/constans"
 	"sample/helpers"
 	"sample/models"
 	"sample/repositories"
@@ -10,7 +12,7 @@ import (
 )
 
 var defineColumn = `id, account_id, account_number, account_name, source_number, 
-					beneficiary_number, transaction_type, amount, transaction_time, created_at`
+					beneficiary_number, transaction_type, amount, fee, currency, status, journal_id, reversal_of, transaction_time, created_at`
 
 type transactionRepository struct {
 	RepoDB repositories.Repository
@@ -23,21 +25,24 @@ func NewTransactionRepository(repoDB repositories.Repository) transactionReposit
 	}
 }
 
-// AddTransaction mencatat transaksi baru
-func (ctx transactionRepository) AddTransaction(transaction models.Transaction) (int, error) {
-	var ID int
+// AddTransaction mencatat transaksi baru, ikut dalam tx jika diberikan
+func (ctx transactionRepository) AddTransaction(transaction models.Transaction, tx *sql.Tx) (int, error) {
+	var (
+		ID   int
+		args []interface{}
+		err  error
+	)
 
 	query := `INSERT INTO transaction (
 				account_id, account_number, account_name, 
 				source_number, beneficiary_number,
-				transaction_type, amount, transaction_time, created_at
+				transaction_type, amount, fee, currency, status, journal_id, reversal_of, transaction_time, created_at
 		) VALUES (
-				$1, $2, $3, $4, $5, $6, $7, $8, $9
+				$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
 		) RETURNING id`
 
 	now := time.Now()
-	err := ctx.RepoDB.DB.QueryRow(
-		query,
+	args = append(args,
 		transaction.AccountID,
 		transaction.AccountNumber,
 		transaction.AccountName,
@@ -45,9 +50,20 @@ func (ctx transactionRepository) AddTransaction(transaction models.Transaction)
 		helpers.NullString(transaction.BeneficiaryNumber),
 		transaction.TransactionType,
 		transaction.Amount,
+		transaction.Fee,
+		transaction.Amount.CurrencyCode(),
+		transactionStatus(transaction),
+		helpers.NullInt(transaction.JournalID),
+		helpers.NullInt(transaction.ReversalOf),
 		transaction.TransactionTime,
 		now,
-	).Scan(&ID)
+	)
+
+	if tx != nil {
+		err = tx.QueryRow(query, args...).Scan(&ID)
+	} else {
+		err = ctx.RepoDB.DB.QueryRow(query, args...).Scan(&ID)
+	}
 
 	if err != nil {
 		return 0, err
@@ -58,13 +74,113 @@ func (ctx transactionRepository) AddTransaction(transaction models.Transaction)
 
 // FindTransactionById mencari transaksi berdasarkan ID
 func (ctx transactionRepository) FindTransactionById(id int) (models.Transaction, error) {
-	var transaction models.Transaction
-
 	var query = `SELECT ` + defineColumn + ` FROM transaction WHERE id = $1 AND deleted_at IS NULL`
+	return scanTransaction(ctx.RepoDB.DB.QueryRow(query, id))
+}
+
+// FindTransactionsByJournalId mendapatkan semua baris transaksi dari satu jurnal
+// (transfer menghasilkan dua baris: debit pengirim dan kredit penerima)
+func (ctx transactionRepository) FindTransactionsByJournalId(journalID int, tx *sql.Tx) ([]models.Transaction, error) {
+	var query = `SELECT ` + defineColumn + ` FROM transaction WHERE journal_id = $1 AND deleted_at IS NULL ORDER BY id FOR UPDATE`
+
+	rows, err := tx.Query(query, journalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return transactionDto(rows)
+}
+
+// UpdateTransactionStatus mengubah status transaksi sesuai state machine.
+// Guard status asal di WHERE memastikan transisi paralel tidak saling menimpa
+func (ctx transactionRepository) UpdateTransactionStatus(id int, fromStatus, toStatus string, tx *sql.Tx) error {
+	var (
+		result sql.Result
+		err    error
+	)
+
+	if !models.CanTransitionTransactionStatus(fromStatus, toStatus) {
+		return fmt.Errorf("Illegal transaction status transition %s -> %s", fromStatus, toStatus)
+	}
+
+	query := `UPDATE transaction SET status = $1 WHERE id = $2 AND status = $3 AND deleted_at IS NULL`
+
+	if tx != nil {
+		result, err = tx.Exec(query, toStatus, id, fromStatus)
+	} else {
+		result, err = ctx.RepoDB.DB.Exec(query, toStatus, id, fromStatus)
+	}
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
 
+	if rowsAffected == 0 {
+		return fmt.Errorf("Transaction %d is no longer %s", id, fromStatus)
+	}
+
+	return nil
+}
+
+// GetReversedAmount total nominal yang sudah di-reversal untuk sebuah transaksi, ikut dalam tx jika diberikan
+func (ctx transactionRepository) GetReversedAmount(transactionID int, tx *sql.Tx) (models.Money, error) {
+	var reversed models.Money
+
+	var err error
+
+	query := `SELECT COALESCE(SUM(amount), 0) FROM transaction WHERE reversal_of = $1 AND deleted_at IS NULL`
+
+	if tx != nil {
+		err = tx.QueryRow(query, transactionID).Scan(&reversed)
+	} else {
+		err = ctx.RepoDB.DB.QueryRow(query, transactionID).Scan(&reversed)
+	}
+	if err != nil {
+		return reversed, err
+	}
+
+	return reversed, nil
+}
+
+// transactionStatus status baris baru, default SUCCESS karena transaksi sinkron langsung final
+func transactionStatus(transaction models.Transaction) string {
+	if transaction.Status == "" {
+		return constans.TRANSACTION_STATUS_SUCCESS
+	}
+	return transaction.Status
+}
+
+// rowScanner *sql.Row atau *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTransaction helper untuk mapping satu row ke struct
+func scanTransaction(row *sql.Row) (models.Transaction, error) {
+	transaction, err := scanTransactionFields(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return transaction, errors.New("Transaction not found")
+		}
+		return transaction, err
+	}
+
+	return transaction, nil
+}
+
+// scanTransactionFields mapping kolom defineColumn ke struct
+func scanTransactionFields(row rowScanner) (models.Transaction, error) {
+	var transaction models.Transaction
 	var sourceNumber, beneficiaryNumber sql.NullString
+	var currency string
+	var journalID, reversalOf sql.NullInt64
 
-	err := ctx.RepoDB.DB.QueryRow(query, id).Scan(
+	err := row.Scan(
 		&transaction.ID,
 		&transaction.AccountID,
 		&transaction.AccountNumber,
@@ -73,90 +189,67 @@ func (ctx transactionRepository) FindTransactionById(id int) (models.Transaction
 		&beneficiaryNumber,
 		&transaction.TransactionType,
 		&transaction.Amount,
+		&transaction.Fee,
+		&currency,
+		&transaction.Status,
+		&journalID,
+		&reversalOf,
 		&transaction.TransactionTime,
 		&transaction.CreatedAt,
 	)
-
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return transaction, errors.New("Transaction not found")
-		}
 		return transaction, err
 	}
 
 	transaction.SourceNumber = sourceNumber.String
 	transaction.BeneficiaryNumber = beneficiaryNumber.String
+	transaction.JournalID = int(journalID.Int64)
+	transaction.ReversalOf = int(reversalOf.Int64)
+	transaction.Amount.Currency = currency
+	transaction.Fee.Currency = currency
 
 	return transaction, nil
 }
 
-// GetTransactionHistory mendapatkan riwayat transaksi
-func (ctx transactionRepository) GetTransactionHistory(accountNumber string, startDate, endDate string, limit, page int) ([]models.Transaction, int, error) {
-	var totalRecords int
-
-	// Base query dan parameter
-	var baseQuery string
+// transactionHistoryFilter klausa FROM/WHERE riwayat transaksi, dipakai bersama
+// oleh GetTransactionHistory dan StreamTransactionHistory
+func transactionHistoryFilter(accountNumber, startDate, endDate, status string) (string, []interface{}) {
 	var params []interface{}
-	paramCount := 1
+	baseQuery := `FROM transaction WHERE deleted_at IS NULL`
+
+	addFilter := func(clause string, value interface{}) {
+		params = append(params, value)
+		baseQuery += fmt.Sprintf(clause, len(params))
+	}
 
 	// Filter berdasarkan account number
 	if accountNumber != "" {
-		baseQuery = `FROM transaction WHERE account_number = $1 AND deleted_at IS NULL`
-		params = append(params, accountNumber)
-	} else {
-		baseQuery = `FROM transaction WHERE deleted_at IS NULL`
-		paramCount = 0
+		addFilter(` AND account_number = $%d`, accountNumber)
 	}
 
 	// Filter berdasarkan tanggal
-	if startDate != "" && endDate != "" {
-		if baseQuery == `FROM transaction WHERE deleted_at IS NULL` {
-			baseQuery += ` AND DATE(transaction_time) BETWEEN $1 AND $2`
-			params = append(params, startDate, endDate)
-			paramCount = 2
-		} else {
-			baseQuery += ` AND DATE(transaction_time) BETWEEN $2 AND $3`
-			params = append(params, startDate, endDate)
-			paramCount = 3
-		}
-	} else if startDate != "" || endDate != "" {
-		if startDate != "" {
-			if baseQuery == `FROM transaction WHERE deleted_at IS NULL` {
-				baseQuery += ` AND DATE(transaction_time) >= $1`
-				params = append(params, startDate)
-				paramCount = 1
-			} else {
-				baseQuery += ` AND DATE(transaction_time) >= $2`
-				params = append(params, startDate)
-				paramCount = 2
-			}
-		}
-		if endDate != "" {
-			if baseQuery == `FROM transaction WHERE deleted_at IS NULL` {
-				if startDate != "" {
-					baseQuery += ` AND DATE(transaction_time) <= $2`
-				} else {
-					baseQuery += ` AND DATE(transaction_time) <= $1`
-				}
-				params = append(params, endDate)
-				if startDate != "" {
-					paramCount = 2
-				} else {
-					paramCount = 1
-				}
-			} else {
-				if startDate != "" {
-					baseQuery += ` AND DATE(transaction_time) <= $3`
-					paramCount = 3
-				} else {
-					baseQuery += ` AND DATE(transaction_time) <= $2`
-					paramCount = 2
-				}
-				params = append(params, endDate)
-			}
-		}
+	if startDate != "" {
+		addFilter(` AND DATE(transaction_time) >= $%d`, startDate)
+	}
+	if endDate != "" {
+		addFilter(` AND DATE(transaction_time) <= $%d`, endDate)
+	}
+
+	// Filter berdasarkan status
+	if status != "" {
+		addFilter(` AND status = $%d`, status)
 	}
 
+	return baseQuery, params
+}
+
+// GetTransactionHistory mendapatkan riwayat transaksi
+func (ctx transactionRepository) GetTransactionHistory(accountNumber string, startDate, endDate, status string, limit, page int) ([]models.Transaction, int, error) {
+	var totalRecords int
+
+	baseQuery, params := transactionHistoryFilter(accountNumber, startDate, endDate, status)
+	paramCount := len(params)
+
 	// Count total records
 	countQuery := `SELECT COUNT(*) ` + baseQuery
 	err := ctx.RepoDB.DB.QueryRow(countQuery, params...).Scan(&totalRecords)
@@ -175,19 +268,8 @@ func (ctx transactionRepository) GetTransactionHistory(accountNumber string, sta
 		offset := (page - 1) * limit
 
 		// Tambahkan parameter untuk limit dan offset
-		if paramCount == 0 {
-			dataQuery += ` LIMIT $1 OFFSET $2`
-			params = append(params, limit, offset)
-		} else if paramCount == 1 {
-			dataQuery += ` LIMIT $2 OFFSET $3`
-			params = append(params, limit, offset)
-		} else if paramCount == 2 {
-			dataQuery += ` LIMIT $3 OFFSET $4`
-			params = append(params, limit, offset)
-		} else if paramCount == 3 {
-			dataQuery += ` LIMIT $4 OFFSET $5`
-			params = append(params, limit, offset)
-		}
+		dataQuery += fmt.Sprintf(` LIMIT $%d OFFSET $%d`, paramCount+1, paramCount+2)
+		params = append(params, limit, offset)
 	}
 	// Jika limit = 0, tidak ada LIMIT dan OFFSET (ambil semua data)
 
@@ -205,6 +287,85 @@ func (ctx transactionRepository) GetTransactionHistory(accountNumber string, sta
 	return transactions, totalRecords, nil
 }
 
+// StreamTransactionHistory filter yang sama dengan GetTransactionHistory, diurutkan dari yang terlama
+// dan dikirim satu per satu ke fn sehingga periode panjang tidak dimuat sekaligus ke memori.
+// Berhenti pada error pertama dari fn, ikut dalam tx jika diberikan
+func (ctx transactionRepository) StreamTransactionHistory(accountNumber, startDate, endDate, status string, tx *sql.Tx, fn func(models.Transaction) error) error {
+	var (
+		rows *sql.Rows
+		err  error
+	)
+
+	baseQuery, params := transactionHistoryFilter(accountNumber, startDate, endDate, status)
+	dataQuery := `SELECT ` + defineColumn + ` ` + baseQuery + ` ORDER BY transaction_time ASC, id ASC`
+
+	if tx != nil {
+		rows, err = tx.Query(dataQuery, params...)
+	} else {
+		rows, err = ctx.RepoDB.DB.Query(dataQuery, params...)
+	}
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		transaction, err := scanTransactionFields(rows)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(transaction); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// GetBalanceBefore saldo rekening untuk satu mata uang sebelum startDate, dihitung mundur dari
+// saldo saat ini dikurangi semua mutasi sejak startDate. Jalankan dalam tx snapshot yang sama
+// dengan StreamTransactionHistory agar saldo awal dan mutasi konsisten
+func (ctx transactionRepository) GetBalanceBefore(accountNumber, currency, startDate string, tx *sql.Tx) (models.Money, error) {
+	var err error
+
+	balance := models.NewMoney(0, currency)
+
+	currentBalance := `SELECT balance FROM account WHERE account_number = $1 AND deleted_at IS NULL`
+	if currency != constans.DEFAULT_CURRENCY {
+		currentBalance = `SELECT p.balance FROM account_pocket p
+						  JOIN account a ON a.id = p.account_id
+						  WHERE a.account_number = $1 AND p.currency = $2`
+	}
+
+	// Debit mengurangi amount + fee, kredit menambah amount - fee (sama dengan Transaction.BalanceEffect)
+	query := `SELECT COALESCE((` + currentBalance + `), 0) - COALESCE((
+				SELECT SUM(CASE WHEN transaction_type = 'D' THEN -(amount + COALESCE(fee, 0)) ELSE amount - COALESCE(fee, 0) END)
+				FROM transaction
+				WHERE account_number = $1 AND currency = $2 AND DATE(transaction_time) >= $3
+				  AND status IN ($4, $5) AND deleted_at IS NULL
+			  ), 0)`
+
+	args := []interface{}{
+		accountNumber,
+		currency,
+		startDate,
+		constans.TRANSACTION_STATUS_SUCCESS,
+		constans.TRANSACTION_STATUS_REVERSED,
+	}
+
+	if tx != nil {
+		err = tx.QueryRow(query, args...).Scan(&balance)
+	} else {
+		err = ctx.RepoDB.DB.QueryRow(query, args...).Scan(&balance)
+	}
+	if err != nil {
+		return balance, err
+	}
+
+	return balance, nil
+}
+
 // DataCountAndSumTransactionListByIndex - Count dan sum untuk transaction list
 func (ctx transactionRepository) DataCountAndSumTransactionListByIndex(countOnly bool, filter models.RequestTransactionHistoryList) (models.ResultDataTableTransactionCountAndSummaries, error) {
 	var (
@@ -235,6 +396,12 @@ func (ctx transactionRepository) DataCountAndSumTransactionListByIndex(countOnly
 		args = append(args, filter.AccountNumber)
 	}
 
+	// Filter by status
+	if filter.Status != "" {
+		query += ` AND status = ?`
+		args = append(args, filter.Status)
+	}
+
 	// Filter by search value (searching in account_number, account_name, source_number, beneficiary_number)
 	if filter.SearchValue != "" {
 		query += ` AND (account_number ILIKE '%' || ? || '%' OR account_name ILIKE '%' || ? || '%' OR source_number ILIKE '%' || ? || '%' OR beneficiary_number ILIKE '%' || ? || '%')`
@@ -261,9 +428,7 @@ func (ctx transactionRepository) DataGetTransactionListByIndex(filter models.Req
 	)
 
 	query = `
-		SELECT id, account_id, account_number, account_name, 
-			   source_number, beneficiary_number, 
-			   transaction_type, amount, transaction_time, created_at
+		SELECT ` + defineColumn + `
 		FROM transaction
 		WHERE deleted_at IS NULL
 	`
@@ -280,6 +445,12 @@ func (ctx transactionRepository) DataGetTransactionListByIndex(filter models.Req
 		args = append(args, filter.AccountNumber)
 	}
 
+	// Filter by status
+	if filter.Status != "" {
+		query += ` AND status = ?`
+		args = append(args, filter.Status)
+	}
+
 	// Filter by search value
 	if filter.SearchValue != "" {
 		query += ` AND (account_number ILIKE '%' || ? || '%' OR account_name ILIKE '%' || ? || '%' OR source_number ILIKE '%' || ? || '%' OR beneficiary_number ILIKE '%' || ? || '%')`
@@ -321,28 +492,11 @@ func transactionDto(rows *sql.Rows) ([]models.Transaction, error) {
 	var result []models.Transaction
 
 	for rows.Next() {
-		var val models.Transaction
-		var sourceNumber, beneficiaryNumber sql.NullString
-
-		err := rows.Scan(
-			&val.ID,
-			&val.AccountID,
-			&val.AccountNumber,
-			&val.AccountName,
-			&sourceNumber,
-			&beneficiaryNumber,
-			&val.TransactionType,
-			&val.Amount,
-			&val.TransactionTime,
-			&val.CreatedAt,
-		)
+		val, err := scanTransactionFields(rows)
 		if err != nil {
 			return result, err
 		}
 
-		val.SourceNumber = sourceNumber.String
-		val.BeneficiaryNumber = beneficiaryNumber.String
-
 		result = append(result, val)
 	}
 
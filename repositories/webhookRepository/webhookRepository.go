@@ -0,0 +1,488 @@
+package webhookRepository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sample/constans"
+	"sample/models"
+	"sample/repositories"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	subscriptionColumn = `id, partner_name, url, secret, event_types, is_active, created_at, updated_at`
+
+	deliveryColumn = `d.id, d.outbox_id, d.subscription_id, o.event_id, o.event_type, d.status, d.attempts,
+					  d.next_attempt_at, d.last_status_code, d.last_error, d.delivered_at, d.locked_by,
+					  d.created_at, d.updated_at, s.url, s.secret, s.is_active, o.payload`
+)
+
+type webhookRepository struct {
+	RepoDB repositories.Repository
+}
+
+// NewWebhookRepository
+func NewWebhookRepository(repoDB repositories.Repository) webhookRepository {
+	return webhookRepository{
+		RepoDB: repoDB,
+	}
+}
+
+// AddWebhookSubscription mendaftarkan URL partner
+func (ctx webhookRepository) AddWebhookSubscription(subscription models.WebhookSubscription) (int, error) {
+	var ID int
+
+	query := `INSERT INTO webhook_subscription (partner_name, url, secret, event_types, is_active, created_at, updated_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $6) RETURNING id`
+
+	err := ctx.RepoDB.DB.QueryRow(query,
+		subscription.PartnerName,
+		subscription.URL,
+		subscription.Secret,
+		pq.Array(subscription.EventTypes),
+		subscription.IsActive,
+		time.Now(),
+	).Scan(&ID)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return ID, nil
+}
+
+// FindWebhookSubscriptionById mencari subscription berdasarkan ID
+func (ctx webhookRepository) FindWebhookSubscriptionById(id int) (models.WebhookSubscription, error) {
+	query := `SELECT ` + subscriptionColumn + ` FROM webhook_subscription WHERE id = $1`
+
+	subscription, err := scanSubscription(ctx.RepoDB.DB.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return subscription, errors.New("Webhook subscription not found")
+		}
+		return subscription, err
+	}
+
+	return subscription, nil
+}
+
+// GetWebhookSubscriptions semua subscription, terbaru dulu
+func (ctx webhookRepository) GetWebhookSubscriptions() ([]models.WebhookSubscription, error) {
+	var result []models.WebhookSubscription
+
+	query := `SELECT ` + subscriptionColumn + ` FROM webhook_subscription ORDER BY id DESC`
+
+	rows, err := ctx.RepoDB.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		val, err := scanSubscription(rows)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, val)
+	}
+
+	return result, rows.Err()
+}
+
+// UpdateWebhookSubscription mengubah URL, jenis event dan status aktif
+func (ctx webhookRepository) UpdateWebhookSubscription(subscription models.WebhookSubscription) error {
+	query := `UPDATE webhook_subscription SET url = $1, event_types = $2, is_active = $3, updated_at = $4 WHERE id = $5`
+
+	result, err := ctx.RepoDB.DB.Exec(query,
+		subscription.URL,
+		pq.Array(subscription.EventTypes),
+		subscription.IsActive,
+		time.Now(),
+		subscription.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("Webhook subscription not found")
+	}
+
+	return nil
+}
+
+// AddOutboxEvent menulis event ke outbox. Harus ikut dalam tx perubahan datanya
+// agar event hanya terkirim jika perubahan tersebut benar-benar di-commit
+func (ctx webhookRepository) AddOutboxEvent(event models.WebhookEvent, tx *sql.Tx) (int, error) {
+	var (
+		ID  int
+		err error
+	)
+
+	query := `INSERT INTO webhook_outbox (event_id, event_type, payload, created_at)
+			  VALUES ($1, $2, $3, $4) RETURNING id`
+
+	args := []interface{}{
+		event.EventID,
+		event.EventType,
+		string(event.Payload),
+		event.CreatedAt,
+	}
+
+	if tx != nil {
+		err = tx.QueryRow(query, args...).Scan(&ID)
+	} else {
+		err = ctx.RepoDB.DB.QueryRow(query, args...).Scan(&ID)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return ID, nil
+}
+
+// DispatchOutboxEvents membuat baris delivery untuk setiap subscription aktif yang berlangganan
+// event outbox yang belum diproses, lalu menandai event tersebut. Satu statement sehingga atomik
+// dan aman dijalankan paralel (SKIP LOCKED)
+func (ctx webhookRepository) DispatchOutboxEvents(limit int) (int, error) {
+	var dispatched int
+
+	query := `WITH events AS (
+				  SELECT id, event_type FROM webhook_outbox
+				  WHERE dispatched_at IS NULL
+				  ORDER BY id
+				  LIMIT $1
+				  FOR UPDATE SKIP LOCKED
+			  ), fanout AS (
+				  INSERT INTO webhook_delivery (outbox_id, subscription_id, status, attempts, next_attempt_at, created_at, updated_at)
+				  SELECT e.id, s.id, $2, 0, $3, $3, $3
+				  FROM events e
+				  JOIN webhook_subscription s ON s.is_active AND e.event_type = ANY(s.event_types)
+				  ON CONFLICT (outbox_id, subscription_id) DO NOTHING
+			  ), marked AS (
+				  UPDATE webhook_outbox SET dispatched_at = $3
+				  WHERE id IN (SELECT id FROM events)
+				  RETURNING id
+			  )
+			  SELECT COUNT(1) FROM marked`
+
+	err := ctx.RepoDB.DB.QueryRow(query, limit, constans.WEBHOOK_DELIVERY_STATUS_PENDING, time.Now()).Scan(&dispatched)
+	if err != nil {
+		return 0, err
+	}
+
+	return dispatched, nil
+}
+
+// ClaimDueWebhookDeliveries mengambil delivery PENDING yang sudah jatuh tempo dan mengunci
+// dengan lease agar tidak dikirim dua instance sekaligus
+func (ctx webhookRepository) ClaimDueWebhookDeliveries(workerID string, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	now := time.Now()
+
+	query := `UPDATE webhook_delivery d
+			  SET locked_by = $1, locked_until = $2
+			  FROM webhook_outbox o, webhook_subscription s
+			  WHERE o.id = d.outbox_id AND s.id = d.subscription_id
+			    AND d.id IN (
+				  SELECT id FROM webhook_delivery
+				  WHERE status = $3 AND next_attempt_at <= $4
+				    AND (locked_until IS NULL OR locked_until < $4)
+				  ORDER BY next_attempt_at
+				  LIMIT $5
+				  FOR UPDATE SKIP LOCKED
+			    )
+			  RETURNING ` + deliveryColumn
+
+	rows, err := ctx.RepoDB.DB.Query(query, workerID, now.Add(lease), constans.WEBHOOK_DELIVERY_STATUS_PENDING, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return deliveryDto(rows)
+}
+
+// CompleteWebhookDeliveryWithTx menyimpan hasil percobaan (status, retry berikutnya) dan melepas lease.
+// Hanya berhasil jika lease masih milik worker ini
+func (ctx webhookRepository) CompleteWebhookDeliveryWithTx(tx *sql.Tx, delivery models.WebhookDelivery) error {
+	var (
+		result sql.Result
+		err    error
+	)
+
+	query := `UPDATE webhook_delivery
+			  SET status = $2,
+			      attempts = $3,
+			      next_attempt_at = $4,
+			      last_status_code = $5,
+			      last_error = $6,
+			      delivered_at = $7,
+			      locked_by = NULL,
+			      locked_until = NULL,
+			      updated_at = $8
+			  WHERE id = $1 AND locked_by = $9`
+
+	args := []interface{}{
+		delivery.ID,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.LastStatusCode,
+		delivery.LastError,
+		delivery.DeliveredAt,
+		time.Now(),
+		delivery.LockedBy,
+	}
+
+	if tx != nil {
+		result, err = tx.Exec(query, args...)
+	} else {
+		result, err = ctx.RepoDB.DB.Exec(query, args...)
+	}
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("Webhook delivery lease was lost")
+	}
+
+	return nil
+}
+
+// AddWebhookDeliveryAttempt mencatat log satu percobaan pengiriman
+func (ctx webhookRepository) AddWebhookDeliveryAttempt(attempt models.WebhookDeliveryAttempt, tx *sql.Tx) (int, error) {
+	var (
+		ID  int
+		err error
+	)
+
+	query := `INSERT INTO webhook_delivery_attempt (delivery_id, attempt, status_code, response_body, error, duration_ms, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+
+	args := []interface{}{
+		attempt.DeliveryID,
+		attempt.Attempt,
+		attempt.StatusCode,
+		attempt.ResponseBody,
+		attempt.Error,
+		attempt.DurationMs,
+		time.Now(),
+	}
+
+	if tx != nil {
+		err = tx.QueryRow(query, args...).Scan(&ID)
+	} else {
+		err = ctx.RepoDB.DB.QueryRow(query, args...).Scan(&ID)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return ID, nil
+}
+
+// FindWebhookDeliveryById detail delivery beserta payload event
+func (ctx webhookRepository) FindWebhookDeliveryById(id int) (models.WebhookDelivery, error) {
+	query := `SELECT ` + deliveryColumn + `
+			  FROM webhook_delivery d
+			  JOIN webhook_outbox o ON o.id = d.outbox_id
+			  JOIN webhook_subscription s ON s.id = d.subscription_id
+			  WHERE d.id = $1`
+
+	rows, err := ctx.RepoDB.DB.Query(query, id)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	defer rows.Close()
+
+	deliveries, err := deliveryDto(rows)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	if len(deliveries) == 0 {
+		return models.WebhookDelivery{}, errors.New("Webhook delivery not found")
+	}
+
+	return deliveries[0], nil
+}
+
+// GetWebhookDeliveries log pengiriman dengan filter subscription, status dan jenis event
+func (ctx webhookRepository) GetWebhookDeliveries(filter models.RequestWebhookDeliveryList) ([]models.WebhookDelivery, error) {
+	var args []interface{}
+
+	query := `SELECT ` + deliveryColumn + `
+			  FROM webhook_delivery d
+			  JOIN webhook_outbox o ON o.id = d.outbox_id
+			  JOIN webhook_subscription s ON s.id = d.subscription_id
+			  WHERE 1 = 1`
+
+	if filter.SubscriptionID > 0 {
+		args = append(args, filter.SubscriptionID)
+		query += fmt.Sprintf(` AND d.subscription_id = $%d`, len(args))
+	}
+
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		query += fmt.Sprintf(` AND d.status = $%d`, len(args))
+	}
+
+	if filter.EventType != "" {
+		args = append(args, filter.EventType)
+		query += fmt.Sprintf(` AND o.event_type = $%d`, len(args))
+	}
+
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(` ORDER BY d.id DESC LIMIT $%d`, len(args))
+
+	rows, err := ctx.RepoDB.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return deliveryDto(rows)
+}
+
+// GetWebhookDeliveryAttempts log percobaan pengiriman sebuah delivery
+func (ctx webhookRepository) GetWebhookDeliveryAttempts(deliveryID int) ([]models.WebhookDeliveryAttempt, error) {
+	var result []models.WebhookDeliveryAttempt
+
+	query := `SELECT id, delivery_id, attempt, status_code, response_body, error, duration_ms, created_at
+			  FROM webhook_delivery_attempt WHERE delivery_id = $1 ORDER BY id`
+
+	rows, err := ctx.RepoDB.DB.Query(query, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var val models.WebhookDeliveryAttempt
+		err := rows.Scan(
+			&val.ID,
+			&val.DeliveryID,
+			&val.Attempt,
+			&val.StatusCode,
+			&val.ResponseBody,
+			&val.Error,
+			&val.DurationMs,
+			&val.CreatedAt,
+		)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, val)
+	}
+
+	return result, rows.Err()
+}
+
+// RedeliverWebhookDelivery menjadwalkan ulang delivery (manual) untuk segera dikirim,
+// jumlah percobaan di-reset. Ditolak jika delivery sedang dikirim worker
+func (ctx webhookRepository) RedeliverWebhookDelivery(id int) error {
+	now := time.Now()
+
+	query := `UPDATE webhook_delivery
+			  SET status = $1, attempts = 0, next_attempt_at = $2, delivered_at = NULL, updated_at = $2
+			  WHERE id = $3 AND (locked_until IS NULL OR locked_until < $2)`
+
+	result, err := ctx.RepoDB.DB.Exec(query, constans.WEBHOOK_DELIVERY_STATUS_PENDING, now, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("Webhook delivery not found or is being delivered")
+	}
+
+	return nil
+}
+
+// rowScanner *sql.Row atau *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanSubscription helper untuk mapping satu row ke struct
+func scanSubscription(row rowScanner) (models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+
+	err := row.Scan(
+		&subscription.ID,
+		&subscription.PartnerName,
+		&subscription.URL,
+		&subscription.Secret,
+		pq.Array(&subscription.EventTypes),
+		&subscription.IsActive,
+		&subscription.CreatedAt,
+		&subscription.UpdatedAt,
+	)
+
+	return subscription, err
+}
+
+// deliveryDto helper untuk mapping rows (deliveryColumn) ke struct
+func deliveryDto(rows *sql.Rows) ([]models.WebhookDelivery, error) {
+	var result []models.WebhookDelivery
+
+	for rows.Next() {
+		var val models.WebhookDelivery
+		var lastStatusCode sql.NullInt64
+		var lastError, lockedBy sql.NullString
+		var payload []byte
+
+		err := rows.Scan(
+			&val.ID,
+			&val.OutboxID,
+			&val.SubscriptionID,
+			&val.EventID,
+			&val.EventType,
+			&val.Status,
+			&val.Attempts,
+			&val.NextAttemptAt,
+			&lastStatusCode,
+			&lastError,
+			&val.DeliveredAt,
+			&lockedBy,
+			&val.CreatedAt,
+			&val.UpdatedAt,
+			&val.URL,
+			&val.Secret,
+			&val.IsActive,
+			&payload,
+		)
+		if err != nil {
+			return result, err
+		}
+
+		val.LastStatusCode = int(lastStatusCode.Int64)
+		val.LastError = lastError.String
+		val.LockedBy = lockedBy.String
+		val.Payload = payload
+		result = append(result, val)
+	}
+
+	return result, rows.Err()
+}
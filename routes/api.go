@@ -2,20 +2,67 @@ package routes
 
 import (
 	"net/http"
-	"sample/config"
+	"sample/constans"
+	"sample/metrics"
+	"sample/middlewares"
 	"sample/services"
 	"sample/services/accountService"
+	"sample/services/auditService"
+	"sample/services/authService"
+	"sample/services/fxService"
+	"sample/services/healthService"
+	"sample/services/holdService"
+	"sample/services/ledgerService"
+	"sample/services/limitService"
+	"sample/services/pinLockoutService"
+	"sample/services/reversalService"
+	"sample/services/scheduledTransferService"
+	"sample/services/statementService"
 	"sample/services/transactionHistoryService"
 	"sample/services/transactionService"
+	"sample/services/webhookService"
 
 	"github.com/labstack/echo"
 	"github.com/labstack/echo/middleware"
 )
 
 // RoutesApi
-func RoutesApi(e echo.Echo, usecaseSvc services.UsecaseService) {
+func RoutesApi(e *echo.Echo, usecaseSvc services.UsecaseService) {
+
+	// Audit trail MongoDB untuk setiap endpoint yang mengubah data
+	audit := func(action string) echo.MiddlewareFunc {
+		return middlewares.Audit(usecaseSvc.AuditRepo, action)
+	}
+
+	// ============================================
+	// Health Check (liveness & readiness probe)
+	// ============================================
+	healthSvc := healthService.NewHealthService(usecaseSvc)
+	healthGroup := e.Group("/health")
+
+	healthGroup.GET("/live", healthSvc.Live)   // Proses berjalan
+	healthGroup.GET("/ready", healthSvc.Ready) // Postgres, Redis dan MongoDB bisa dijangkau
+
+	e.GET("/metrics", echo.WrapHandler(metrics.Handler())) // Scrape Prometheus
+
+	// IP client untuk rate limit dan audit, X-Forwarded-For hanya dari TRUSTED_PROXIES
+	e.Use(middlewares.ClientIP(usecaseSvc.Config.TrustedProxies))
 
 	public := e.Group("/public")
+	public.Use(middlewares.RateLimit("public", usecaseSvc.Config.RateLimit.Public(), middlewares.RateLimitByIP)) // Batas request per IP
+
+	// ============================================
+	// Auth Service (PIN Login)
+	// ============================================
+	authSvc := authService.NewAuthService(usecaseSvc)
+	authGroup := public.Group("/auth")
+
+	// Selain batas per IP dibatasi juga per rekening agar tebakan PIN tidak bisa diperbanyak dengan berganti IP
+	loginLimit := middlewares.RateLimit("login", usecaseSvc.Config.RateLimit.Login(), middlewares.RateLimitByRequestAccount)
+	refreshLimit := middlewares.RateLimit("login", usecaseSvc.Config.RateLimit.Login(), middlewares.RateLimitByRefreshToken(usecaseSvc.Config.JWT))
+
+	authGroup.POST("/login", authSvc.Login, loginLimit)            // Login dengan PIN, terbitkan access & refresh token
+	authGroup.POST("/refresh", authSvc.RefreshToken, refreshLimit) // Tukar refresh token dengan token baru
 
 	// ============================================
 	// Account Service (PostgreSQL)
@@ -23,50 +70,145 @@ func RoutesApi(e echo.Echo, usecaseSvc services.UsecaseService) {
 	accountSvc := accountService.NewAccountService(usecaseSvc)
 	accountGroup := public.Group("/account")
 
+	accountGroup.POST("/create", accountSvc.CreateAccount, audit(constans.AUDIT_ACTION_ACCOUNT_CREATE)) // Buat akun baru
+
+	// PIN Management, selain batas per IP dibatasi juga per account_number agar tidak bisa diakali dengan berganti IP
+	pinResetLimit := middlewares.RateLimit("pin-reset", usecaseSvc.Config.RateLimit.PINReset(), middlewares.RateLimitByRequestAccount)
+
+	accountGroup.POST("/forgot-pin", accountSvc.ForgotPIN, pinResetLimit, audit(constans.AUDIT_ACTION_PIN_FORGOT)) // Lupa PIN - Generate reset token
+	accountGroup.POST("/reset-pin", accountSvc.ResetPIN, pinResetLimit, audit(constans.AUDIT_ACTION_PIN_RESET))    // Reset PIN dengan token
+
+	// ============================================
+	// Private Routes (Authenticated)
+	// Subject (account number) diambil dari claims access token
+	// ============================================
+	private := e.Group("/private")
+	private.Use(middlewares.JWT(usecaseSvc.Config.JWT))
+	private.Use(middlewares.RateLimit("private", usecaseSvc.Config.RateLimit.Private(), middlewares.RateLimitByAccount)) // Batas request per nasabah
+	private.Use(middleware.CORSWithConfig(middleware.CORSConfig{
+		AllowCredentials: true,
+		AllowOrigins:     []string{"*"},
+		AllowMethods:     []string{http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete},
+	}))
+
 	// Account Management
-	accountGroup.POST("/create", accountSvc.CreateAccount) // Buat akun baru
-	accountGroup.POST("/list", accountSvc.GetAccountList)  // List semua akun
-	accountGroup.POST("/get", accountSvc.GetAccountByID)   // Get akun by ID
-	accountGroup.POST("/update", accountSvc.UpdateAccount) // Update data akun
-	accountGroup.POST("/delete", accountSvc.DeleteAccount) // Hapus akun
+	privateAccountGroup := private.Group("/account")
+
+	privateAccountGroup.POST("/list", accountSvc.GetAccountList)                                               // List akun milik nasabah
+	privateAccountGroup.POST("/get", accountSvc.GetAccountByID)                                                // Get akun by ID
+	privateAccountGroup.POST("/update", accountSvc.UpdateAccount, audit(constans.AUDIT_ACTION_ACCOUNT_UPDATE)) // Update data akun
+	privateAccountGroup.POST("/delete", accountSvc.DeleteAccount, audit(constans.AUDIT_ACTION_ACCOUNT_DELETE)) // Hapus akun
 
-	accountGroup.POST("/balance-inquiry", accountSvc.GetBalanceInquiry)
+	privateAccountGroup.POST("/balance-inquiry", accountSvc.GetBalanceInquiry)
+	privateAccountGroup.POST("/change-pin", accountSvc.ChangePIN, audit(constans.AUDIT_ACTION_PIN_CHANGE)) // Ubah PIN
 
-	// PIN Management
-	accountGroup.POST("/change-pin", accountSvc.ChangePIN) // Ubah PIN
-	accountGroup.POST("/forgot-pin", accountSvc.ForgotPIN) // Lupa PIN - Generate reset token
-	accountGroup.POST("/reset-pin", accountSvc.ResetPIN)   // Reset PIN dengan token
+	pinLockoutSvc := pinLockoutService.NewPINLockoutService(usecaseSvc)
+	privateAccountGroup.POST("/lock-history", pinLockoutSvc.GetMyPINLockHistory) // Status & riwayat kunci PIN
+
+	// Limit transaksi per tier akun
+	limitSvc := limitService.NewLimitService(usecaseSvc)
+	privateAccountGroup.POST("/limit-usage", limitSvc.GetLimitUsage) // Sisa limit harian & bulanan
+
+	fxSvc := fxService.NewFxService(usecaseSvc)
+	privateAccountGroup.POST("/pockets", fxSvc.GetPocketBalances) // Saldo per mata uang
 
 	// ============================================
 	// Transaction Service
 	// ============================================
 	transactionSvc := transactionService.NewTransactionService(usecaseSvc)
 	transactionHistorySvc := transactionHistoryService.NewTransactionHistoryService(usecaseSvc)
-	transactionGroup := public.Group("/transaction")
+	statementSvc := statementService.NewStatementService(usecaseSvc)
+	transactionGroup := private.Group("/transaction")
+	transactionGroup.Use(middlewares.RateLimit("transaction", usecaseSvc.Config.RateLimit.Transaction(), middlewares.RateLimitByAccount)) // Batas tambahan transaksi per nasabah
 
-	// Basic Transactions
-	transactionGroup.POST("/deposit", transactionSvc.Deposit)   // Setor tunai
-	transactionGroup.POST("/withdraw", transactionSvc.Withdraw) // Tarik tunai
-	transactionGroup.POST("/transfer", transactionSvc.Transfer) // Transfer antar akun
+	// Basic Transactions (mendukung header Idempotency-Key)
+	transactionGroup.POST("/deposit", transactionSvc.Deposit, audit(constans.AUDIT_ACTION_DEPOSIT), middlewares.Idempotency())                        // Setor tunai
+	transactionGroup.POST("/withdraw", transactionSvc.Withdraw, audit(constans.AUDIT_ACTION_WITHDRAW), middlewares.Idempotency())                     // Tarik tunai
+	transactionGroup.POST("/transfer-inquiry", transactionSvc.TransferInquiry)                                                                        // Cek penerima & biaya sebelum transfer
+	transactionGroup.POST("/transfer", transactionSvc.Transfer, audit(constans.AUDIT_ACTION_TRANSFER), middlewares.Idempotency())                     // Transfer antar akun (opsional inquiry_id)
+	transactionGroup.POST("/confirm", transactionSvc.ConfirmTransaction, audit(constans.AUDIT_ACTION_TRANSACTION_CONFIRM), middlewares.Idempotency()) // Konfirmasi OTP withdraw / transfer nominal besar
 
 	// Transaction History
 	transactionGroup.POST("/history-v2", transactionHistorySvc.TransactionHistoryListV2) // Riwayat transaksi
 	transactionGroup.POST("/history", transactionSvc.GetTransactionHistory)              // Riwayat transaksi
 	transactionGroup.POST("/detail", transactionSvc.GetTransactionDetail)                // Detail transaksi
+	transactionGroup.POST("/status", transactionSvc.GetTransactionStatus)                // Cek status transaksi
+	transactionGroup.POST("/statement", statementSvc.DownloadStatement)                  // Unduh mutasi rekening (CSV/PDF)
+
+	// Scheduled Transfer (standing order, dieksekusi worker)
+	scheduledTransferSvc := scheduledTransferService.NewScheduledTransferService(usecaseSvc)
+	scheduledTransferGroup := private.Group("/scheduled-transfer")
+
+	scheduledTransferGroup.POST("/create", scheduledTransferSvc.CreateScheduledTransfer, audit(constans.AUDIT_ACTION_SCHEDULED_TRANSFER_CREATE)) // Buat jadwal transfer
+	scheduledTransferGroup.POST("/list", scheduledTransferSvc.GetScheduledTransferList)                                                          // List jadwal milik nasabah
+	scheduledTransferGroup.POST("/get", scheduledTransferSvc.GetScheduledTransferDetail)                                                         // Detail jadwal dan riwayat eksekusi
+	scheduledTransferGroup.POST("/update", scheduledTransferSvc.UpdateScheduledTransfer, audit(constans.AUDIT_ACTION_SCHEDULED_TRANSFER_UPDATE)) // Ubah nominal/end date, pause/resume
+	scheduledTransferGroup.POST("/cancel", scheduledTransferSvc.CancelScheduledTransfer, audit(constans.AUDIT_ACTION_SCHEDULED_TRANSFER_CANCEL)) // Batalkan jadwal
+
+	// Authorization Hold (reservasi dana, capture atau release)
+	holdSvc := holdService.NewHoldService(usecaseSvc)
+	holdGroup := private.Group("/hold")
+
+	holdGroup.POST("/create", holdSvc.CreateHold, audit(constans.AUDIT_ACTION_HOLD_CREATE))                               // Reservasi dana tanpa memindahkan
+	holdGroup.POST("/capture", holdSvc.CaptureHold, audit(constans.AUDIT_ACTION_HOLD_CAPTURE), middlewares.Idempotency()) // Capture penuh / sebagian
+	holdGroup.POST("/release", holdSvc.ReleaseHold, audit(constans.AUDIT_ACTION_HOLD_RELEASE))                            // Batalkan hold
+	holdGroup.POST("/list", holdSvc.GetHoldList)                                                                          // List hold milik nasabah / merchant
+
+	// Valas (konversi antar pocket mata uang)
+	fxGroup := private.Group("/fx")
+
+	fxGroup.POST("/quote", fxSvc.GetFxQuote)                                                                    // Simulasi kurs & hasil konversi
+	fxGroup.POST("/convert", fxSvc.Convert, audit(constans.AUDIT_ACTION_FX_CONVERT), middlewares.Idempotency()) // Konversi antar pocket
 
 	// ============================================
-	// Private Routes (Authenticated)
+	// Admin Routes (Support staff, header X-Admin-Key)
 	// ============================================
-	private := e.Group("/private")
-	private.Use(middleware.JWT([]byte(config.GetEnv("JWT_KEY"))))
-	private.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowCredentials: true,
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete},
-	}))
+	admin := e.Group("/admin")
+	admin.Use(middlewares.AdminAuth(usecaseSvc.Config.AdminAPIKey))
+	admin.Use(middlewares.RateLimit("admin", usecaseSvc.Config.RateLimit.Admin(), middlewares.RateLimitByClient)) // Batas request per API client (X-Client-Id)
+
+	// Blokir PIN (kunci sementara & blokir permanen). Unblock di /admin, bukan /private:
+	// JWT /private milik nasabah dan tidak boleh membuka blokir akun lain
+	adminAccountGroup := admin.Group("/account")
+
+	adminAccountGroup.POST("/unblock", pinLockoutSvc.UnblockAccount, audit(constans.AUDIT_ACTION_ACCOUNT_UNBLOCK)) // Buka blokir PIN dengan alasan
+	adminAccountGroup.POST("/lock-history", pinLockoutSvc.GetPINLockHistory)                                       // Riwayat kunci PIN per akun
+
+	// Ledger Service (Double-entry)
+	ledgerSvc := ledgerService.NewLedgerService(usecaseSvc)
+	ledgerGroup := admin.Group("/ledger")
+
+	ledgerGroup.POST("/balance", ledgerSvc.GetLedgerBalance) // Saldo dari posting ledger
+	ledgerGroup.POST("/journal", ledgerSvc.GetJournalDetail) // Detail jurnal dan posting
+	ledgerGroup.POST("/verify", ledgerSvc.VerifyLedger)      // Cek invariant debit = kredit
+
+	// Reversal & Refund
+	reversalSvc := reversalService.NewReversalService(usecaseSvc)
+	adminTransactionGroup := admin.Group("/transaction")
+
+	adminTransactionGroup.POST("/reversal", reversalSvc.ReverseTransaction, audit(constans.AUDIT_ACTION_REVERSAL), middlewares.Idempotency()) // Reversal penuh / refund sebagian
+
+	// Kurs valas
+	fxRateGroup := admin.Group("/fx/rate")
+
+	fxRateGroup.POST("/set", fxSvc.SetFxRate, audit(constans.AUDIT_ACTION_FX_RATE_SET)) // Tambah kurs dengan effective_at
+	fxRateGroup.POST("/list", fxSvc.GetFxRateList)                                      // Riwayat kurs
+
+	// Webhook partner
+	webhookSvc := webhookService.NewWebhookService(usecaseSvc)
+	webhookGroup := admin.Group("/webhook")
+
+	webhookGroup.POST("/subscription/create", webhookSvc.CreateSubscription, audit(constans.AUDIT_ACTION_WEBHOOK_SUBSCRIPTION_CREATE)) // Daftarkan URL & jenis event
+	webhookGroup.POST("/subscription/list", webhookSvc.GetSubscriptionList)                                                            // List subscription
+	webhookGroup.POST("/subscription/update", webhookSvc.UpdateSubscription, audit(constans.AUDIT_ACTION_WEBHOOK_SUBSCRIPTION_UPDATE)) // Ubah URL/event, aktif/nonaktif
+	webhookGroup.POST("/delivery/list", webhookSvc.GetDeliveryList)                                                                    // Log pengiriman
+	webhookGroup.POST("/delivery/detail", webhookSvc.GetDeliveryDetail)                                                                // Detail & log percobaan
+	webhookGroup.POST("/delivery/redeliver", webhookSvc.Redeliver, audit(constans.AUDIT_ACTION_WEBHOOK_REDELIVER))                     // Kirim ulang manual
+
+	// Audit trail
+	auditSvc := auditService.NewAuditService(usecaseSvc)
+	auditGroup := admin.Group("/audit")
 
-	// Private routes can be added here for admin/authenticated users
-	// privateAccountGroup := private.Group("/account")
-	// privateAccountGroup.GET("/admin/list", accountSvc.GetAccountList)
+	auditGroup.POST("/list", auditSvc.GetAuditLogList) // Filter account, actor, action, rentang waktu
 
 }
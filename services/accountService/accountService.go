@@ -1,14 +1,20 @@
 package accountService
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"net/http"
 	"sample/config"
 	"sample/constans"
 	"sample/helpers"
+	"sample/metrics"
 	"sample/models"
+	"sample/notification"
 	"sample/services"
+	"sample/services/pinLockoutService"
+	"sample/services/webhookService"
+	"sample/tracing"
 	"sample/utils"
 	"time"
 
@@ -28,6 +34,8 @@ func NewAccountService(service services.UsecaseService) accountService {
 
 // CreateAccount membuat akun baru
 func (svc accountService) CreateAccount(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
 	var (
 		result        models.Response
 		serviceName   = "CreateAccount"
@@ -69,7 +77,7 @@ func (svc accountService) CreateAccount(ctx echo.Context) error {
 		return ctx.JSON(http.StatusInternalServerError, result)
 	}
 
-	if request.InitialDeposit < 0 {
+	if request.InitialDeposit.IsNegative() {
 		utils.LogError(serviceName, constans.EMPTY_VALUE, "CreateAccount.ValidateInitialDeposit",
 			fmt.Errorf("Initial deposit cant negative"))
 		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Initial deposit cant negative", nil)
@@ -99,17 +107,34 @@ func (svc accountService) CreateAccount(ctx echo.Context) error {
 		AccountName:   request.AccountName,
 		Balance:       request.InitialDeposit,
 		PIN:           hashedPIN,
+		PhoneNumber:   request.PhoneNumber,
+		Email:         request.Email,
 		CreatedAt:     time.Now(),
 		UpdatedAt:     time.Now(),
 	}
 
 	err = utils.DBTransaction(svc.Service.RepoDB, func(tx *sql.Tx) error {
-		id, err := svc.Service.AccountRepo.AddAccount(account)
+		id, err := svc.Service.AccountRepo.AddAccountWithTx(tx, account)
 		if err != nil {
 			return err
 		}
 		accountID = id
-		return nil
+
+		if !account.Balance.IsPositive() {
+			return nil
+		}
+
+		// Jurnal saldo awal: Debit CASH_VAULT, Credit rekening nasabah
+		_, err = svc.Service.LedgerRepo.PostJournal(models.JournalEntry{
+			ReferenceNo: utils.GenerateReferenceNo(),
+			JournalType: constans.JOURNAL_TYPE_OPENING,
+			Description: "Setoran Awal " + account.AccountNumber,
+			Postings: []models.Posting{
+				{LedgerCode: constans.LEDGER_CASH_VAULT, Direction: "D", Amount: account.Balance},
+				{LedgerCode: account.AccountNumber, Direction: "C", Amount: account.Balance},
+			},
+		}, tx)
+		return err
 	})
 
 	if err != nil {
@@ -126,15 +151,21 @@ func (svc accountService) CreateAccount(ctx echo.Context) error {
 		Balance:       account.Balance,
 		AccountName:   account.AccountName,
 		AccountStatus: "ACTIVE",
+		AccountTier:   constans.ACCOUNT_TIER_BASIC,
 		CreatedAt:     account.CreatedAt.Format(time.RFC3339),
 	}
 
+	helpers.AuditAccount(ctx, account.AccountNumber)
+	helpers.AuditAfter(ctx, response)
+
 	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Account created successfully", response)
 	return ctx.JSON(http.StatusOK, result)
 }
 
 // ChangePIN ubah PIN akun dengan satu DBTransaction
 func (svc accountService) ChangePIN(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
 	var (
 		result      models.Response
 		serviceName = "AccountService"
@@ -148,6 +179,9 @@ func (svc accountService) ChangePIN(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, result)
 	}
 
+	// Subject selalu dari token, bukan dari body request
+	request.AccountNumber = helpers.GetAccountNumber(ctx)
+
 	utils.LogInfo(serviceName, request.AccountNumber, "ChangePIN", "Request received")
 
 	// Validasi format PIN baru
@@ -173,49 +207,24 @@ func (svc accountService) ChangePIN(ctx echo.Context) error {
 		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Account not found", nil)
 		return ctx.JSON(http.StatusNotFound, result)
 	}
+	helpers.AuditBefore(ctx, account.AuditSnapshot())
 
-	// Cek status akun
-	if account.AccountStatus == "BLOCKED_PIN" {
-		utils.LogError(serviceName, request.AccountNumber, "ChangePIN.CheckAccountStatus",
-			fmt.Errorf("Account is blocked"))
-		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Account is blocked due to multiple failed PIN attempts. Please use Forgot PIN", nil)
-		return ctx.JSON(http.StatusForbidden, result)
+	// Cek blokir dan verifikasi PIN lama sesuai kebijakan lockout
+	if pinErr := pinLockoutService.NewPINLockoutService(svc.Service).VerifyPIN(account, request.OldPIN); pinErr != nil {
+		utils.LogError(serviceName, request.AccountNumber, "ChangePIN.VerifyPIN", pinErr)
+		result = helpers.ResponseJSON(false, pinErr.Code, pinErr.Message, nil)
+		return ctx.JSON(pinErr.StatusCode, result)
 	}
 
-	var failedAttempts int
-	err = utils.DBTransaction(svc.Service.RepoDB, func(tx *sql.Tx) error {
-		// Panggil repository function yang menangani semua operasi dalam satu transaksi
-		failedAttempts, err = svc.Service.AccountRepo.ChangePINWithTx(
-			tx,
-			request.AccountNumber,
-			request.OldPIN,
-			request.NewPIN,
-			account.PIN,
-		)
-		return err
-	})
-
+	hashedPIN, err := helpers.HashPIN(request.NewPIN)
 	if err != nil {
-		// Cek jika error adalah PIN verification failed
-		if err.Error() == "PIN_VERIFICATION_FAILED" {
-			remainingAttempts := 3 - failedAttempts
-
-			utils.LogError(serviceName, request.AccountNumber, "ChangePIN.VerifyPIN",
-				fmt.Errorf("Invalid old PIN. Remaining attempts: %d", remainingAttempts))
-
-			if remainingAttempts <= 0 {
-				result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE,
-					"Account blocked due to multiple failed PIN attempts", nil)
-				return ctx.JSON(http.StatusForbidden, result)
-			}
-
-			result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE,
-				fmt.Sprintf("Invalid old PIN. %d attempt(s) remaining", remainingAttempts), nil)
-			return ctx.JSON(http.StatusUnauthorized, result)
-		}
+		utils.LogError(serviceName, request.AccountNumber, "ChangePIN.HashPIN", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to process new PIN", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
 
-		// Error lainnya
-		utils.LogError(serviceName, request.AccountNumber, "ChangePIN.Transaction", err)
+	if err := svc.Service.AccountRepo.UpdatePIN(request.AccountNumber, hashedPIN); err != nil {
+		utils.LogError(serviceName, request.AccountNumber, "ChangePIN.UpdatePIN", err)
 		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, err.Error(), nil)
 		return ctx.JSON(http.StatusInternalServerError, result)
 	}
@@ -227,19 +236,22 @@ func (svc accountService) ChangePIN(ctx echo.Context) error {
 		AccountNumber: request.AccountNumber,
 		ChangedAt:     time.Now(),
 	}
+	helpers.AuditAfter(ctx, response)
 
 	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "PIN changed successfully", response)
 	return ctx.JSON(http.StatusOK, result)
 }
 
-// ForgotPIN Inquiry
+// ForgotPIN Inquiry: kirim kode reset PIN ke kontak terdaftar. Response selalu sama
+// agar tidak bisa dipakai menebak nomor akun yang terdaftar
 func (svc accountService) ForgotPIN(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
 	var (
 		result models.Response
 		// request    models.RequestForgotPIN
 		request    = new(models.RequestForgotPIN)
-		resetToken = helpers.GenerateResetToken()
-		expiryTime = time.Now().Add(5 * time.Minute)
+		expiryTime = time.Now().Add(svc.Service.Config.PINReset.CodeTTL())
 		response   models.ForgotPINResponse
 	)
 
@@ -250,54 +262,132 @@ func (svc accountService) ForgotPIN(ctx echo.Context) error {
 	}
 
 	helpers.LOG("INFO ForgotPIN - Request received", request.AccountNumber, false)
+	helpers.AuditAccount(ctx, request.AccountNumber)
 
-	account, err := svc.Service.AccountRepo.FindAccountByNumber(request.AccountNumber)
+	// Kode dibuat dan di-hash sebelum cek akun supaya waktu response tidak membedakan
+	resetCode, err := helpers.GenerateOTP()
+	if err != nil {
+		helpers.LOG("ERROR ForgotPIN - Failed to generate reset code", err.Error(), false)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE,
+			"Failed to generate reset code. Please try again", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+	hashedCode, err := helpers.HashPIN(resetCode)
+	if err != nil {
+		helpers.LOG("ERROR ForgotPIN - Failed to hash reset code", err.Error(), false)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE,
+			"Failed to generate reset code. Please try again", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	// Cek akun, reservasi kuota dan pengiriman kode berjalan di background, sehingga response
+	// tidak menunggu Redis maupun SMS/SMTP dan waktunya sama untuk akun terdaftar maupun tidak
+	go svc.issueResetCode(tracing.Detach(svc.Service.Context), request.AccountNumber, resetCode, hashedCode, expiryTime)
+
+	response = models.ForgotPINResponse{
+		AccountNumber: request.AccountNumber,
+		ExpiresAt:     expiryTime,
+	}
+
+	// Kode reset tidak ikut dicatat di audit trail
+	helpers.AuditAfter(ctx, map[string]interface{}{
+		"account_number": request.AccountNumber,
+		"expires_at":     expiryTime,
+	})
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE,
+		"If the account is registered, a reset code has been sent to its registered contact", response)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// issueResetCode cari akun, simpan hash kode ke Redis lalu kirim kode ke nasabah. Kode tidak
+// diterbitkan jika jatah kode / percobaan akun dalam jendela cooldown sudah habis. Berjalan di
+// background dengan ctx yang lepas dari request, kegagalan hanya dicatat di log karena response
+// forgot PIN tidak boleh membedakan hasilnya
+func (svc accountService) issueResetCode(ctx context.Context, accountNumber, resetCode, hashedCode string, expiryTime time.Time) {
+	svc.Service.Context = ctx
+
+	account, err := svc.Service.AccountRepo.FindAccountByNumber(accountNumber)
 	if err != nil {
 		helpers.LOG("ERROR ForgotPIN - Account not found", map[string]interface{}{
 			"error":          err.Error(),
-			"account_number": request.AccountNumber,
+			"account_number": accountNumber,
 		}, false)
-		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Account not found", nil)
-		return ctx.JSON(http.StatusNotFound, result)
+		return
 	}
 
-	//set expiry time
+	policy := svc.Service.Config.PINReset
 
-	err = config.SetResetToken(resetToken, account.AccountNumber, expiryTime)
+	allowed, retryAfter, err := config.ReserveResetCode(svc.Service.Context, account.AccountNumber,
+		policy.MaxCodes, policy.MaxAttempts, policy.Cooldown())
 	if err != nil {
-		helpers.LOG("ERROR ForgotPIN - Failed to store reset token", map[string]interface{}{
+		helpers.LOG("ERROR ForgotPIN - Failed to reserve reset code", map[string]interface{}{
 			"error":          err.Error(),
 			"account_number": account.AccountNumber,
 		}, false)
-		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE,
-			"Failed to generate reset token. Please try again", nil)
-		return ctx.JSON(http.StatusInternalServerError, result)
+		return
+	}
+	if !allowed {
+		helpers.LOG("ERROR ForgotPIN - Reset code limit reached", map[string]interface{}{
+			"account_number": account.AccountNumber,
+			"retry_after":    retryAfter.String(),
+		}, false)
+		return
 	}
 
-	helpers.LOG("SUCCESS ForgotPIN - Reset token generated", map[string]interface{}{
-		"account_number": account.AccountNumber,
-		"expires_at":     expiryTime.Format(constans.LAYOUT_TIMESTAMP),
-	}, false)
+	if err := config.SetResetToken(svc.Service.Context, account.AccountNumber, hashedCode, expiryTime); err != nil {
+		helpers.LOG("ERROR ForgotPIN - Failed to store reset code", map[string]interface{}{
+			"error":          err.Error(),
+			"account_number": account.AccountNumber,
+		}, false)
+		return
+	}
+	metrics.ResetTokensIssuedTotal.Inc()
 
-	response = models.ForgotPINResponse{
+	if svc.Service.Notifier == nil {
+		helpers.LOG("ERROR ForgotPIN - Notification channel is not configured", account.AccountNumber, false)
+		metrics.ResetTokenDeliveryFailuresTotal.Inc()
+		return
+	}
+
+	err = notification.Send(svc.Service.Context, svc.Service.Notifier, models.NotificationMessage{
 		AccountNumber: account.AccountNumber,
-		ResetToken:    resetToken,
-		ExpiresAt:     expiryTime,
+		PhoneNumber:   account.PhoneNumber,
+		Email:         account.Email,
+		Subject:       "PIN reset code",
+		Body: fmt.Sprintf("Your PIN reset code is %s. It expires in %d minutes. Never share this code with anyone.",
+			resetCode, svc.Service.Config.PINReset.CodeTTLMinutes),
+	})
+	if err != nil {
+		helpers.LOG("ERROR ForgotPIN - Failed to deliver reset code", map[string]interface{}{
+			"error":          err.Error(),
+			"account_number": account.AccountNumber,
+			"channel":        svc.Service.Notifier.Name(),
+		}, false)
+		metrics.ResetTokenDeliveryFailuresTotal.Inc()
+		return
 	}
 
-	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE,
-		"Reset token generated successfully. Please use this token within 5 minutes", response)
-	return ctx.JSON(http.StatusOK, result)
+	helpers.LOG("SUCCESS ForgotPIN - Reset code sent", map[string]interface{}{
+		"account_number": account.AccountNumber,
+		"channel":        svc.Service.Notifier.Name(),
+		"expires_at":     expiryTime.Format(constans.LAYOUT_TIMESTAMP),
+	}, false)
 }
 
-// ResetPIN reset PIN dengan token dari Redis (Forgot PIN Confirm)
+// ResetPIN reset PIN dengan kode dari forgot PIN (Forgot PIN Confirm). Setiap percobaan dihitung
+// per akun di Redis lintas kode yang diterbitkan. Kode dihapus setelah dipakai atau setelah
+// PIN_RESET_MAX_ATTEMPTS salah, lalu kode baru baru bisa diminta setelah PIN_RESET_COOLDOWN_MINUTES
 func (svc accountService) ResetPIN(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
 	var (
 		result      models.Response
 		serviceName = "AccountService"
 		// request     models.RequestResetPIN
-		request  = new(models.RequestResetPIN)
-		response models.ResetPINResponse
+		request     = new(models.RequestResetPIN)
+		response    models.ResetPINResponse
+		maxAttempts = svc.Service.Config.PINReset.MaxAttempts
 	)
 
 	if err := helpers.BindValidateStruct(ctx, request); err != nil {
@@ -306,11 +396,13 @@ func (svc accountService) ResetPIN(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, result)
 	}
 
-	utils.LogInfo(serviceName, constans.EMPTY_VALUE, "ResetPIN", "Request received")
+	accountNumber := request.AccountNumber
+	utils.LogInfo(serviceName, accountNumber, "ResetPIN", "Request received")
+	helpers.AuditAccount(ctx, accountNumber)
 
 	// Validasi format PIN baru
 	if !helpers.IsNumeric(request.NewPIN) {
-		utils.LogError(serviceName, constans.EMPTY_VALUE, "ResetPIN.ValidatePINNumeric",
+		utils.LogError(serviceName, accountNumber, "ResetPIN.ValidatePINNumeric",
 			fmt.Errorf("PIN must be numeric"))
 		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "PIN must be numeric", nil)
 		return ctx.JSON(http.StatusBadRequest, result)
@@ -318,26 +410,66 @@ func (svc accountService) ResetPIN(ctx echo.Context) error {
 
 	// Validasi PIN confirmation
 	if request.NewPIN != request.ConfirmNewPIN {
-		utils.LogError(serviceName, constans.EMPTY_VALUE, "ResetPIN.ValidatePINMatch",
+		utils.LogError(serviceName, accountNumber, "ResetPIN.ValidatePINMatch",
 			fmt.Errorf("New PIN and Confirm PIN do not match"))
 		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "New PIN and Confirm PIN do not match", nil)
 		return ctx.JSON(http.StatusBadRequest, result)
 	}
 
-	// Dapatkan account_number dari token
-	accountNumber, err := config.GetAccountNumberByToken(request.ResetToken)
+	// Catat percobaan lalu verifikasi kode
+	hashedCode, attempts, err := config.HitResetToken(svc.Service.Context, accountNumber, svc.Service.Config.PINReset.Cooldown())
 	if err != nil {
 		if helpers.Contains(err.Error(), "expired or not found") {
-			utils.LogError(serviceName, constans.EMPTY_VALUE, "ResetPIN.GetAccountNumberByToken", err)
+			utils.LogError(serviceName, accountNumber, "ResetPIN.HitResetToken", err)
+			result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE,
+				"Reset code has expired or is invalid. Please request a new code", nil)
+			return ctx.JSON(http.StatusBadRequest, result)
+		}
+		utils.LogError(serviceName, accountNumber, "ResetPIN.HitResetToken", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE,
+			"Failed to verify reset code", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	if attempts > maxAttempts || !helpers.CheckPINHashContext(svc.Service.Context, request.ResetCode, hashedCode) {
+		utils.LogError(serviceName, accountNumber, "ResetPIN.CheckResetCode",
+			fmt.Errorf("Invalid reset code, attempt %d of %d", attempts, maxAttempts))
+
+		if attempts >= maxAttempts {
+			if _, err := config.DeleteResetToken(svc.Service.Context, accountNumber); err != nil {
+				utils.LogError(serviceName, accountNumber, "ResetPIN.DeleteResetToken", err)
+			}
 			result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE,
-				"Reset token has expired or is invalid. Please request a new token", nil)
+				fmt.Sprintf("Too many invalid reset code attempts. Please request a new code after %d minute(s)",
+					svc.Service.Config.PINReset.CooldownMinutes), nil)
 			return ctx.JSON(http.StatusBadRequest, result)
 		}
-		utils.LogError(serviceName, constans.EMPTY_VALUE, "ResetPIN.GetAccountNumberByToken", err)
+
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE,
+			fmt.Sprintf("Invalid reset code. %d attempt(s) remaining", maxAttempts-attempts), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	// Kode sekali pakai: hanya request yang berhasil menghapus kode yang boleh lanjut
+	deleted, err := config.DeleteResetToken(svc.Service.Context, accountNumber)
+	if err != nil {
+		utils.LogError(serviceName, accountNumber, "ResetPIN.DeleteResetToken", err)
 		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE,
-			"Failed to verify reset token", nil)
+			"Failed to verify reset code", nil)
 		return ctx.JSON(http.StatusInternalServerError, result)
 	}
+	if !deleted {
+		utils.LogError(serviceName, accountNumber, "ResetPIN.DeleteResetToken",
+			fmt.Errorf("Reset code already used"))
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE,
+			"Reset code has expired or is invalid. Please request a new code", nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	// Kode benar, jatah kode dan percobaan akun dikembalikan
+	if err := config.ClearResetGuard(svc.Service.Context, accountNumber); err != nil {
+		utils.LogError(serviceName, accountNumber, "ResetPIN.ClearResetGuard", err)
+	}
 
 	// Verifikasi account masih exists
 	account, err := svc.Service.AccountRepo.FindAccountByNumber(accountNumber)
@@ -346,6 +478,7 @@ func (svc accountService) ResetPIN(ctx echo.Context) error {
 		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Account not found", nil)
 		return ctx.JSON(http.StatusNotFound, result)
 	}
+	helpers.AuditBefore(ctx, account.AuditSnapshot())
 
 	// Hash PIN baru
 	hashedPIN, err := helpers.HashPIN(request.NewPIN)
@@ -356,7 +489,27 @@ func (svc accountService) ResetPIN(ctx echo.Context) error {
 	}
 
 	err = utils.DBTransaction(svc.Service.RepoDB, func(tx *sql.Tx) error {
-		return svc.Service.AccountRepo.UpdatePINWithTx(tx, accountNumber, hashedPIN)
+		if err := svc.Service.AccountRepo.UpdatePINWithTx(tx, accountNumber, hashedPIN); err != nil {
+			return err
+		}
+
+		// Reset PIN juga membuka blokir / kunci sementara, dicatat di riwayat kunci PIN
+		if pinLockoutService.IsLocked(account, time.Now()) {
+			_, err := svc.Service.AccountRepo.AddPINLockEvent(models.PINLockEvent{
+				AccountNumber:  accountNumber,
+				EventType:      constans.PIN_LOCK_EVENT_PIN_RESET,
+				FailedAttempts: account.FailedPINAttempts,
+				LockoutCount:   account.PINLockoutCount,
+				LockedUntil:    account.LockedUntil,
+				Actor:          accountNumber,
+				Reason:         "PIN reset with forgot PIN code",
+			}, tx)
+			if err != nil {
+				return err
+			}
+		}
+
+		return webhookService.NewWebhookService(svc.Service).PublishPINReset(tx, accountNumber)
 	})
 
 	if err != nil {
@@ -366,25 +519,22 @@ func (svc accountService) ResetPIN(ctx echo.Context) error {
 		return ctx.JSON(http.StatusInternalServerError, result)
 	}
 
-	// Hapus token dari Redis setelah berhasil digunakan
-	if err := config.DeleteResetToken(request.ResetToken); err != nil {
-		utils.LogError(serviceName, accountNumber, "ResetPIN.DeleteResetToken", err)
-		// Tidak perlu gagalkan request, token akan expire sendiri
-	}
-
 	utils.LogInfo(serviceName, accountNumber, "ResetPIN.Success", "PIN reset successfully")
 
 	response = models.ResetPINResponse{
 		AccountNumber: account.AccountNumber,
 		ResetAt:       time.Now(),
 	}
+	helpers.AuditAfter(ctx, response)
 
 	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "PIN reset successfully", response)
 	return ctx.JSON(http.StatusOK, result)
 }
 
-// GetAccountList mendapatkan list semua akun
+// GetAccountList mendapatkan list akun milik nasabah pada token
 func (svc accountService) GetAccountList(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
 	var (
 		result           models.Response
 		serviceName      = "AccountService"
@@ -393,11 +543,11 @@ func (svc accountService) GetAccountList(ctx echo.Context) error {
 
 	utils.LogInfo(serviceName, constans.EMPTY_VALUE, "GetAccountList", "Request received")
 
-	accounts, err := svc.Service.AccountRepo.GetAccountList()
-	if err != nil {
-		utils.LogError(serviceName, constans.EMPTY_VALUE, "GetAccountList.GetAccountList", err)
-		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, err.Error(), nil)
-		return ctx.JSON(http.StatusInternalServerError, result)
+	// Nasabah hanya boleh melihat akun miliknya sendiri
+	var accounts []models.Account
+	account, err := svc.Service.AccountRepo.FindAccountByNumber(helpers.GetAccountNumber(ctx))
+	if err == nil {
+		accounts = append(accounts, account)
 	}
 
 	if len(accounts) == 0 {
@@ -413,6 +563,7 @@ func (svc accountService) GetAccountList(ctx echo.Context) error {
 			AccountName:   acc.AccountName,
 			Balance:       acc.Balance,
 			AccountStatus: acc.AccountStatus,
+			AccountTier:   acc.AccountTier,
 			CreatedAt:     acc.CreatedAt.Format(time.RFC3339),
 		})
 	}
@@ -426,6 +577,8 @@ func (svc accountService) GetAccountList(ctx echo.Context) error {
 
 // GetAccountByID mendapatkan detail akun berdasarkan ID
 func (svc accountService) GetAccountByID(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
 	var (
 		result      models.Response
 		serviceName = "AccountService"
@@ -449,12 +602,20 @@ func (svc accountService) GetAccountByID(ctx echo.Context) error {
 		return ctx.JSON(http.StatusNotFound, result)
 	}
 
+	if account.AccountNumber != helpers.GetAccountNumber(ctx) {
+		utils.LogError(serviceName, fmt.Sprintf("%d", request.ID), "GetAccountByID.CheckOwnership",
+			fmt.Errorf("Account does not belong to token subject"))
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Account not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+
 	response = models.AccountResponse{
 		ID:            account.ID,
 		AccountNumber: account.AccountNumber,
 		AccountName:   account.AccountName,
 		Balance:       account.Balance,
 		AccountStatus: account.AccountStatus,
+		AccountTier:   account.AccountTier,
 		CreatedAt:     account.CreatedAt.Format(time.RFC3339),
 	}
 
@@ -467,6 +628,8 @@ func (svc accountService) GetAccountByID(ctx echo.Context) error {
 
 // GetBalanceInquiry mendapatkan detail akun berdasarkan nomor rekening
 func (svc accountService) GetBalanceInquiry(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
 	var (
 		result      models.Response
 		serviceName = "AccountService"
@@ -480,6 +643,9 @@ func (svc accountService) GetBalanceInquiry(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, result)
 	}
 
+	// Subject selalu dari token, bukan dari body request
+	request.AccountNumber = helpers.GetAccountNumber(ctx)
+
 	utils.LogInfo(serviceName, request.AccountNumber, "GetBalanceInquiry", "Request received")
 
 	account, err := svc.Service.AccountRepo.FindAccountByNumber(request.AccountNumber)
@@ -490,11 +656,14 @@ func (svc accountService) GetBalanceInquiry(ctx echo.Context) error {
 	}
 
 	response = models.BalanceInquiryResponse{
-		ID:            account.ID,
-		AccountNumber: account.AccountNumber,
-		AccountName:   account.AccountName,
-		Balance:       account.Balance,
-		AccountStatus: account.AccountStatus,
+		ID:               account.ID,
+		AccountNumber:    account.AccountNumber,
+		AccountName:      account.AccountName,
+		Balance:          account.Balance,
+		HeldBalance:      account.HeldBalance,
+		AvailableBalance: account.AvailableBalance(),
+		Currency:         account.Balance.CurrencyCode(),
+		AccountStatus:    account.AccountStatus,
 	}
 
 	utils.LogInfo(serviceName, account.AccountNumber, "GetBalanceInquiry.Success",
@@ -506,6 +675,8 @@ func (svc accountService) GetBalanceInquiry(ctx echo.Context) error {
 
 // UpdateAccount update data akun
 func (svc accountService) UpdateAccount(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
 	var (
 		result      models.Response
 		serviceName = "AccountService"
@@ -523,16 +694,34 @@ func (svc accountService) UpdateAccount(ctx echo.Context) error {
 		fmt.Sprintf("Request: %+v", request))
 
 	// Cek apakah akun exists
-	_, err := svc.Service.AccountRepo.FindAccountById(request.ID)
+	existing, err := svc.Service.AccountRepo.FindAccountById(request.ID)
 	if err != nil {
 		utils.LogError(serviceName, fmt.Sprintf("%d", request.ID), "UpdateAccount.FindAccountById", err)
 		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, err.Error(), nil)
 		return ctx.JSON(http.StatusNotFound, result)
 	}
 
+	if existing.AccountNumber != helpers.GetAccountNumber(ctx) {
+		utils.LogError(serviceName, fmt.Sprintf("%d", request.ID), "UpdateAccount.CheckOwnership",
+			fmt.Errorf("Account does not belong to token subject"))
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Account not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+
+	helpers.AuditBefore(ctx, existing.AuditSnapshot())
+
+	// Kontak yang tidak dikirim tetap memakai nilai lama
 	account = models.Account{
 		ID:          request.ID,
 		AccountName: request.AccountName,
+		PhoneNumber: existing.PhoneNumber,
+		Email:       existing.Email,
+	}
+	if request.PhoneNumber != "" {
+		account.PhoneNumber = request.PhoneNumber
+	}
+	if request.Email != "" {
+		account.Email = request.Email
 	}
 
 	var accountID int
@@ -554,12 +743,19 @@ func (svc accountService) UpdateAccount(ctx echo.Context) error {
 
 	utils.LogInfo(serviceName, fmt.Sprintf("%d", accountID), "UpdateAccount.Success", "Account updated successfully")
 
+	existing.AccountName = account.AccountName
+	existing.PhoneNumber = account.PhoneNumber
+	existing.Email = account.Email
+	helpers.AuditAfter(ctx, existing.AuditSnapshot())
+
 	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Account updated successfully", accountID)
 	return ctx.JSON(http.StatusOK, result)
 }
 
 // DeleteAccount delete akun
 func (svc accountService) DeleteAccount(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
 	var (
 		result      models.Response
 		serviceName = "AccountService"
@@ -583,14 +779,39 @@ func (svc accountService) DeleteAccount(ctx echo.Context) error {
 		return ctx.JSON(http.StatusNotFound, result)
 	}
 
+	if account.AccountNumber != helpers.GetAccountNumber(ctx) {
+		utils.LogError(serviceName, fmt.Sprintf("%d", request.ID), "DeleteAccount.CheckOwnership",
+			fmt.Errorf("Account does not belong to token subject"))
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Account not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+	helpers.AuditBefore(ctx, account.AuditSnapshot())
+
 	// Validasi balance harus 0
-	if account.Balance > 0 {
+	if account.Balance.IsPositive() {
 		utils.LogError(serviceName, account.AccountNumber, "DeleteAccount.ValidateBalance",
-			fmt.Errorf("Cannot delete account with remaining balance: %.2f", account.Balance))
+			fmt.Errorf("Cannot delete account with remaining balance: %s", account.Balance))
 		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Cannot delete account with remaining balance", nil)
 		return ctx.JSON(http.StatusBadRequest, result)
 	}
 
+	// Pocket valas juga harus kosong
+	pockets, err := svc.Service.AccountRepo.GetAccountPockets(account.ID)
+	if err != nil {
+		utils.LogError(serviceName, account.AccountNumber, "DeleteAccount.GetAccountPockets", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to check pocket balances", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	for _, pocket := range pockets {
+		if pocket.Balance.IsPositive() {
+			utils.LogError(serviceName, account.AccountNumber, "DeleteAccount.ValidatePocketBalance",
+				fmt.Errorf("Cannot delete account with remaining %s balance: %s", pocket.Currency, pocket.Balance))
+			result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Cannot delete account with remaining "+pocket.Currency+" balance", nil)
+			return ctx.JSON(http.StatusBadRequest, result)
+		}
+	}
+
 	err = utils.DBTransaction(svc.Service.RepoDB, func(tx *sql.Tx) error {
 		return svc.Service.AccountRepo.RemoveAccount(request.ID)
 	})
@@ -0,0 +1,86 @@
+package auditService
+
+import (
+	"net/http"
+	"sample/constans"
+	"sample/helpers"
+	"sample/models"
+	"sample/services"
+	"sample/utils"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+type auditService struct {
+	Service services.UsecaseService
+}
+
+// NewAuditService
+func NewAuditService(service services.UsecaseService) auditService {
+	return auditService{
+		Service: service,
+	}
+}
+
+// GetAuditLogList query audit trail per account, actor, action dan rentang waktu (admin)
+func (svc auditService) GetAuditLogList(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result      models.Response
+		serviceName = "AuditService.GetAuditLogList"
+		request     = new(models.RequestAuditLogList)
+		err         error
+	)
+
+	if err := helpers.BindValidateStruct(ctx, request); err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "BindValidateStruct", err)
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	if request.StartTime != "" {
+		request.StartAt, err = time.ParseInLocation(constans.LAYOUT_TIMESTAMP, request.StartTime, time.Local)
+		if err != nil {
+			utils.LogError(serviceName, constans.EMPTY_VALUE, "ParseStartTime", err)
+			result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Invalid start_time format. Use YYYY-MM-DD HH:MM:SS", nil)
+			return ctx.JSON(http.StatusBadRequest, result)
+		}
+	}
+
+	if request.EndTime != "" {
+		request.EndAt, err = time.ParseInLocation(constans.LAYOUT_TIMESTAMP, request.EndTime, time.Local)
+		if err != nil {
+			utils.LogError(serviceName, constans.EMPTY_VALUE, "ParseEndTime", err)
+			result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Invalid end_time format. Use YYYY-MM-DD HH:MM:SS", nil)
+			return ctx.JSON(http.StatusBadRequest, result)
+		}
+	}
+
+	if !request.StartAt.IsZero() && !request.EndAt.IsZero() && request.EndAt.Before(request.StartAt) {
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "end_time must not be before start_time", nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	if request.Page <= 0 {
+		request.Page = 1
+	}
+	if request.Limit <= 0 {
+		request.Limit = 50
+	}
+
+	auditLogs, err := svc.Service.AuditRepo.GetAuditLogs(*request)
+	if err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "GetAuditLogs", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	if auditLogs == nil {
+		auditLogs = []models.AuditLog{}
+	}
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Audit logs retrieved successfully", auditLogs)
+	return ctx.JSON(http.StatusOK, result)
+}
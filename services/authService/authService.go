@@ -0,0 +1,163 @@
+package authService
+
+import (
+	"net/http"
+	"sample/config"
+	"sample/constans"
+	"sample/helpers"
+	"sample/models"
+	"sample/services"
+	"sample/services/pinLockoutService"
+	"sample/utils"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+type authService struct {
+	Service services.UsecaseService
+}
+
+// NewAuthService
+func NewAuthService(service services.UsecaseService) authService {
+	return authService{
+		Service: service,
+	}
+}
+
+// Login verifikasi PIN nasabah lalu menerbitkan access token dan refresh token
+func (svc authService) Login(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result      models.Response
+		serviceName = "AuthService"
+		request     = new(models.RequestLogin)
+	)
+
+	if err := helpers.BindValidateStruct(ctx, request); err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "Login.BindValidateStruct", err)
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	utils.LogInfo(serviceName, request.AccountNumber, "Login", "Request received")
+
+	account, err := svc.Service.AccountRepo.FindAccountByNumber(request.AccountNumber)
+	if err != nil {
+		utils.LogError(serviceName, request.AccountNumber, "Login.FindAccountByNumber", err)
+		result = helpers.ResponseJSON(false, constans.UNAUTHORIZED_CODE, "Invalid account number or PIN", nil)
+		return ctx.JSON(http.StatusUnauthorized, result)
+	}
+
+	// Cek blokir dan verifikasi PIN sesuai kebijakan lockout
+	if pinErr := pinLockoutService.NewPINLockoutService(svc.Service).VerifyPIN(account, request.PIN); pinErr != nil {
+		utils.LogError(serviceName, request.AccountNumber, "Login.VerifyPIN", pinErr)
+		result = helpers.ResponseJSON(false, pinErr.Code, pinErr.Message, nil)
+		return ctx.JSON(pinErr.StatusCode, result)
+	}
+
+	response, err := svc.issueTokens(account.AccountNumber)
+	if err != nil {
+		utils.LogError(serviceName, account.AccountNumber, "Login.IssueTokens", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to issue token. Please try again", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	utils.LogInfo(serviceName, account.AccountNumber, "Login.Success", "Token issued")
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Login successful", response)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// RefreshToken menukar refresh token yang masih aktif dengan pasangan token baru.
+// Refresh token hanya berlaku sekali (rotasi)
+func (svc authService) RefreshToken(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result      models.Response
+		serviceName = "AuthService"
+		request     = new(models.RequestRefreshToken)
+	)
+
+	if err := helpers.BindValidateStruct(ctx, request); err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "RefreshToken.BindValidateStruct", err)
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	claims, err := helpers.ParseToken(svc.Service.Config.JWT, request.RefreshToken)
+	if err != nil || claims.TokenType != constans.TOKEN_TYPE_REFRESH {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "RefreshToken.ParseToken", err)
+		result = helpers.ResponseJSON(false, constans.UNAUTHORIZED_CODE, "Invalid or expired refresh token", nil)
+		return ctx.JSON(http.StatusUnauthorized, result)
+	}
+
+	utils.LogInfo(serviceName, claims.AccountNumber, "RefreshToken", "Request received")
+
+	accountNumber, err := config.ConsumeRefreshToken(svc.Service.Context, claims.Id)
+	if err != nil || accountNumber != claims.AccountNumber {
+		utils.LogError(serviceName, claims.AccountNumber, "RefreshToken.ConsumeRefreshToken", err)
+		result = helpers.ResponseJSON(false, constans.UNAUTHORIZED_CODE, "Invalid or expired refresh token", nil)
+		return ctx.JSON(http.StatusUnauthorized, result)
+	}
+
+	account, err := svc.Service.AccountRepo.FindAccountByNumber(accountNumber)
+	if err != nil {
+		utils.LogError(serviceName, accountNumber, "RefreshToken.FindAccountByNumber", err)
+		result = helpers.ResponseJSON(false, constans.UNAUTHORIZED_CODE, "Invalid or expired refresh token", nil)
+		return ctx.JSON(http.StatusUnauthorized, result)
+	}
+
+	// Akun terkunci (permanen maupun sementara) tidak bisa memperpanjang sesi
+	if pinErr := pinLockoutService.CheckLock(account, time.Now()); pinErr != nil {
+		utils.LogError(serviceName, accountNumber, "RefreshToken.CheckLock", pinErr)
+		result = helpers.ResponseJSON(false, pinErr.Code, pinErr.Message, nil)
+		return ctx.JSON(pinErr.StatusCode, result)
+	}
+
+	response, err := svc.issueTokens(account.AccountNumber)
+	if err != nil {
+		utils.LogError(serviceName, account.AccountNumber, "RefreshToken.IssueTokens", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to issue token. Please try again", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	utils.LogInfo(serviceName, account.AccountNumber, "RefreshToken.Success", "Token refreshed")
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Token refreshed successfully", response)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// issueTokens membuat access token dan refresh token lalu mendaftarkan refresh token di Redis
+func (svc authService) issueTokens(accountNumber string) (models.LoginResponse, error) {
+	var (
+		jwtConfig  = svc.Service.Config.JWT
+		accessTTL  = jwtConfig.AccessTTL()
+		refreshTTL = jwtConfig.RefreshTTL()
+	)
+
+	accessToken, _, _, err := helpers.GenerateToken(jwtConfig, accountNumber, constans.ROLE_CUSTOMER, constans.TOKEN_TYPE_ACCESS, accessTTL)
+	if err != nil {
+		return models.LoginResponse{}, err
+	}
+
+	refreshToken, refreshID, _, err := helpers.GenerateToken(jwtConfig, accountNumber, constans.ROLE_CUSTOMER, constans.TOKEN_TYPE_REFRESH, refreshTTL)
+	if err != nil {
+		return models.LoginResponse{}, err
+	}
+
+	if err := config.SetRefreshToken(svc.Service.Context, refreshID, accountNumber, refreshTTL); err != nil {
+		return models.LoginResponse{}, err
+	}
+
+	return models.LoginResponse{
+		AccountNumber:    accountNumber,
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		TokenType:        constans.AUTH_SCHEME,
+		ExpiresIn:        int64(accessTTL.Seconds()),
+		RefreshExpiresIn: int64(refreshTTL.Seconds()),
+	}, nil
+}
@@ -0,0 +1,47 @@
+package feeService
+
+import (
+	"database/sql"
+	"sample/models"
+	"sample/services"
+	"time"
+)
+
+type feeService struct {
+	Service services.UsecaseService
+}
+
+// NewFeeService
+func NewFeeService(service services.UsecaseService) feeService {
+	return feeService{
+		Service: service,
+	}
+}
+
+// CalculateFee menghitung biaya transaksi sesuai skema biaya dan sisa kuota gratis bulan ini.
+// tx opsional, dipakai saat dipanggil di dalam DBTransaction agar kuota dihitung setelah row akun terkunci
+func (svc feeService) CalculateFee(account models.Account, transactionType string, amount models.Money, tx *sql.Tx) (models.Money, error) {
+	zero := models.NewMoney(0, amount.CurrencyCode())
+
+	rule, err := svc.Service.FeeRepo.FindFeeRule(transactionType)
+	if err == sql.ErrNoRows {
+		return zero, nil
+	}
+	if err != nil {
+		return zero, err
+	}
+
+	if rule.FreeQuotaMonthly > 0 {
+		// Sisi debit rekening: tarik tunai dan transfer keluar
+		usage, err := svc.Service.LimitRepo.GetLimitUsage(account.ID, transactionType, "D", time.Now(), tx)
+		if err != nil {
+			return zero, err
+		}
+
+		if usage.MonthlyCount < rule.FreeQuotaMonthly {
+			return zero, nil
+		}
+	}
+
+	return rule.Calculate(amount), nil
+}
@@ -0,0 +1,385 @@
+package fxService
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sample/constans"
+	"sample/helpers"
+	"sample/models"
+	"sample/services"
+	"sample/services/pinLockoutService"
+	"sample/services/webhookService"
+	"sample/utils"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+type fxService struct {
+	Service services.UsecaseService
+}
+
+// NewFxService
+func NewFxService(service services.UsecaseService) fxService {
+	return fxService{
+		Service: service,
+	}
+}
+
+// SetFxRate menambah kurs baru (admin). Kurs berlaku mulai effective_at dan
+// menggantikan kurs sebelumnya untuk pasangan yang sama, riwayat kurs tidak diubah
+func (svc fxService) SetFxRate(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result      models.Response
+		serviceName = "FxService.SetFxRate"
+		request     = new(models.RequestSetFxRate)
+		effectiveAt = time.Now()
+	)
+
+	if err := helpers.BindValidateStruct(ctx, request); err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "BindValidateStruct", err)
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	pair := request.BaseCurrency + "/" + request.QuoteCurrency
+
+	if request.EffectiveAt != "" {
+		parsed, err := time.ParseInLocation(constans.LAYOUT_TIMESTAMP, request.EffectiveAt, time.Local)
+		if err != nil {
+			utils.LogError(serviceName, pair, "ParseEffectiveAt", err)
+			result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Invalid effective_at format. Use YYYY-MM-DD HH:MM:SS", nil)
+			return ctx.JSON(http.StatusBadRequest, result)
+		}
+		effectiveAt = parsed
+	}
+
+	utils.LogInfo(serviceName, pair, "Request received",
+		fmt.Sprintf("Rate: %s, Spread: %d bps, Effective at: %s", request.Rate, request.SpreadBps, effectiveAt.Format(constans.LAYOUT_TIMESTAMP)))
+
+	rate := models.FxRate{
+		BaseCurrency:  request.BaseCurrency,
+		QuoteCurrency: request.QuoteCurrency,
+		Rate:          request.Rate,
+		SpreadBps:     request.SpreadBps,
+		EffectiveAt:   effectiveAt,
+	}
+
+	id, err := svc.Service.FxRepo.AddFxRate(rate)
+	if err != nil {
+		utils.LogError(serviceName, pair, "AddFxRate", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to save exchange rate", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+	rate.ID = id
+
+	utils.LogInfo(serviceName, pair, "Success", fmt.Sprintf("Rate ID: %d", id))
+	helpers.AuditAfter(ctx, rate)
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Exchange rate saved successfully", rate)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// GetFxRateList riwayat kurs (admin), terbaru di atas
+func (svc fxService) GetFxRateList(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result      models.Response
+		serviceName = "FxService.GetFxRateList"
+		request     = new(models.RequestFxRateList)
+	)
+
+	if err := helpers.BindValidateStruct(ctx, request); err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "BindValidateStruct", err)
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	rates, err := svc.Service.FxRepo.GetFxRateList(request.BaseCurrency, request.QuoteCurrency)
+	if err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "GetFxRateList", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to get exchange rates", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	if rates == nil {
+		rates = []models.FxRate{}
+	}
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Exchange rates retrieved successfully", rates)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// GetFxQuote simulasi konversi dengan kurs yang berlaku saat ini (tanpa posting)
+func (svc fxService) GetFxQuote(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result      models.Response
+		serviceName = "FxService.GetFxQuote"
+		request     = new(models.RequestFxQuote)
+	)
+
+	if err := helpers.BindValidateStruct(ctx, request); err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "BindValidateStruct", err)
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	source := models.NewMoney(request.Amount.Amount, request.FromCurrency)
+
+	quote, err := svc.GetQuote(request.FromCurrency, request.ToCurrency)
+	if err != nil {
+		utils.LogError(serviceName, helpers.GetAccountNumber(ctx), "GetQuote", err)
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Exchange rate not available", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+
+	response := models.FxQuoteResponse{
+		Quote:        quote,
+		SourceAmount: source,
+		TargetAmount: quote.Convert(source),
+	}
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Exchange rate quoted successfully", response)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// Convert memindahkan saldo antar pocket mata uang milik nasabah dengan kurs quote (setelah spread)
+func (svc fxService) Convert(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result      models.Response
+		serviceName = "FxService.Convert"
+		request     = new(models.RequestFxConvert)
+		updatedAt   = time.Now().Format(constans.LAYOUT_TIMESTAMP)
+		referenceNo = utils.GenerateReferenceNo()
+		conversion  models.FxConversion
+	)
+
+	if err := helpers.BindValidateStruct(ctx, request); err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "BindValidateStruct", err)
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	// Subject selalu dari token, bukan dari body request
+	request.AccountNumber = helpers.GetAccountNumber(ctx)
+	source := models.NewMoney(request.Amount.Amount, request.FromCurrency)
+
+	utils.LogInfo(serviceName, request.AccountNumber, "Request received",
+		fmt.Sprintf("From: %s %s, To: %s", source.CurrencyCode(), source, request.ToCurrency))
+
+	account, err := svc.Service.AccountRepo.FindAccountByNumber(request.AccountNumber)
+	if err != nil {
+		utils.LogError(serviceName, request.AccountNumber, "FindAccountByNumber", err)
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Account not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+
+	// Cek blokir dan verifikasi PIN sesuai kebijakan lockout
+	if pinErr := pinLockoutService.NewPINLockoutService(svc.Service).VerifyPIN(account, request.PIN); pinErr != nil {
+		utils.LogError(serviceName, request.AccountNumber, "VerifyPIN", pinErr)
+		result = helpers.ResponseJSON(false, pinErr.Code, pinErr.Message, nil)
+		return ctx.JSON(pinErr.StatusCode, result)
+	}
+
+	quote, err := svc.GetQuote(request.FromCurrency, request.ToCurrency)
+	if err != nil {
+		utils.LogError(serviceName, request.AccountNumber, "GetQuote", err)
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Exchange rate not available", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+
+	target := quote.Convert(source)
+	if !target.IsPositive() {
+		utils.LogError(serviceName, request.AccountNumber, "ValidateTargetAmount",
+			fmt.Errorf("Converted amount is zero for %s %s", source.CurrencyCode(), source))
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Amount is too small to convert", nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	err = utils.DBTransaction(svc.Service.RepoDB, func(tx *sql.Tx) error {
+		var err error
+		conversion, err = svc.ConvertWithTx(tx, account, quote, source, target, referenceNo, updatedAt)
+		return err
+	})
+
+	if err != nil {
+		if txErr, ok := err.(*utils.TransactionError); ok {
+			utils.LogError(serviceName, request.AccountNumber, "DBTransaction.Rejected", fmt.Errorf("%s", txErr.Message))
+			result = helpers.ResponseJSON(false, txErr.Code, txErr.Message, nil)
+			return ctx.JSON(http.StatusBadRequest, result)
+		}
+
+		utils.LogError(serviceName, request.AccountNumber, "DBTransaction", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Transaction failed: "+err.Error(), nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	utils.LogInfo(serviceName, request.AccountNumber, "Success",
+		fmt.Sprintf("%s %s -> %s %s at %s, Ref: %s", source.CurrencyCode(), source, target.CurrencyCode(), target,
+			quote.QuotedRate, referenceNo))
+
+	helpers.AuditBalanceChange(ctx, referenceNo,
+		models.AuditBalanceChange{AccountNumber: account.AccountNumber, Before: conversion.SourceBalance.Add(source), After: conversion.SourceBalance},
+		models.AuditBalanceChange{AccountNumber: account.AccountNumber, Before: conversion.TargetBalance.Sub(target), After: conversion.TargetBalance},
+	)
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Conversion successful", conversion)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// GetPocketBalances saldo rekening per mata uang (pocket utama dan pocket valas)
+func (svc fxService) GetPocketBalances(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result        models.Response
+		serviceName   = "FxService.GetPocketBalances"
+		accountNumber = helpers.GetAccountNumber(ctx)
+	)
+
+	account, err := svc.Service.AccountRepo.FindAccountByNumber(accountNumber)
+	if err != nil {
+		utils.LogError(serviceName, accountNumber, "FindAccountByNumber", err)
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Account not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+
+	pockets, err := svc.Service.AccountRepo.GetAccountPockets(account.ID)
+	if err != nil {
+		utils.LogError(serviceName, accountNumber, "GetAccountPockets", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to get pocket balances", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	response := models.PocketBalanceResponse{
+		AccountNumber: account.AccountNumber,
+		Pockets: append([]models.AccountPocket{{
+			Currency:         account.Balance.CurrencyCode(),
+			Balance:          account.Balance,
+			AvailableBalance: account.AvailableBalance(),
+			UpdatedAt:        account.UpdatedAt,
+		}}, pockets...),
+		CheckedAt: time.Now(),
+	}
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Pocket balances retrieved successfully", response)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// GetQuote kurs konversi yang berlaku saat ini untuk pasangan mata uang
+func (svc fxService) GetQuote(fromCurrency, toCurrency string) (models.FxQuote, error) {
+	rate, err := svc.Service.FxRepo.FindEffectiveFxRate(fromCurrency, toCurrency, time.Now())
+	if err != nil {
+		return models.FxQuote{}, err
+	}
+
+	return models.NewFxQuote(rate, fromCurrency, toCurrency), nil
+}
+
+// ConvertWithTx mendebit pocket source dan mengkredit pocket target di dalam tx pemanggil.
+// Jurnal FX_CONVERSION seimbang per mata uang lewat akun posisi valas, dan dicatat sebagai
+// dua transaksi (debit dan kredit) dengan journal_id yang sama
+func (svc fxService) ConvertWithTx(tx *sql.Tx, account models.Account, quote models.FxQuote, source, target models.Money,
+	referenceNo, updatedAt string) (models.FxConversion, error) {
+	var conversion models.FxConversion
+
+	sourceBalance, err := svc.updatePocket(tx, account, source, "-", updatedAt)
+	if err != nil {
+		return conversion, err
+	}
+
+	// Pocket utama dicek terhadap saldo tersedia (dikurangi hold aktif)
+	available := sourceBalance
+	if source.CurrencyCode() == constans.DEFAULT_CURRENCY {
+		available, err = svc.Service.AccountRepo.GetAvailableBalance(account.ID, tx)
+		if err != nil {
+			return conversion, err
+		}
+	}
+
+	if available.IsNegative() {
+		return conversion, &utils.TransactionError{
+			Code:    constans.ACCOUNT_BALANCE_BELOW_MINIMUM_CODE,
+			Message: "Insufficient " + source.CurrencyCode() + " balance",
+		}
+	}
+
+	targetBalance, err := svc.updatePocket(tx, account, target, "+", updatedAt)
+	if err != nil {
+		return conversion, err
+	}
+
+	journalID, err := svc.Service.LedgerRepo.PostJournal(models.JournalEntry{
+		ReferenceNo: referenceNo,
+		JournalType: constans.JOURNAL_TYPE_FX_CONVERSION,
+		Description: fmt.Sprintf("Konversi %s %s ke %s @ %s", account.AccountNumber, source.CurrencyCode(), target.CurrencyCode(), quote.QuotedRate),
+		Postings: []models.Posting{
+			{LedgerCode: models.PocketLedgerCode(account.AccountNumber, source.CurrencyCode()), Direction: "D", Amount: source},
+			{LedgerCode: models.FxPositionLedgerCode(source.CurrencyCode()), Direction: "C", Amount: source},
+			{LedgerCode: models.FxPositionLedgerCode(target.CurrencyCode()), Direction: "D", Amount: target},
+			{LedgerCode: models.PocketLedgerCode(account.AccountNumber, target.CurrencyCode()), Direction: "C", Amount: target},
+		},
+	}, tx)
+	if err != nil {
+		return conversion, err
+	}
+
+	transactionTime := time.Now()
+	webhookSvc := webhookService.NewWebhookService(svc.Service)
+
+	for _, leg := range []struct {
+		transactionType string
+		amount          models.Money
+	}{{"D", source}, {"C", target}} {
+		transaction := models.Transaction{
+			AccountID:         account.ID,
+			AccountNumber:     account.AccountNumber,
+			AccountName:       account.AccountName,
+			TransactionType:   leg.transactionType,
+			Amount:            leg.amount,
+			Fee:               models.NewMoney(0, leg.amount.CurrencyCode()),
+			JournalID:         journalID,
+			TransactionTime:   transactionTime,
+			SourceNumber:      account.AccountNumber,
+			BeneficiaryNumber: account.AccountNumber,
+		}
+
+		transaction.ID, err = svc.Service.TransactionRepo.AddTransaction(transaction, tx)
+		if err != nil {
+			return conversion, err
+		}
+
+		if err = webhookSvc.PublishTransactionCreated(tx, transaction); err != nil {
+			return conversion, err
+		}
+	}
+
+	conversion = models.FxConversion{
+		ReferenceNo:     referenceNo,
+		Quote:           quote,
+		SourceAmount:    source,
+		TargetAmount:    target,
+		SourceBalance:   sourceBalance,
+		TargetBalance:   targetBalance,
+		TransactionDate: updatedAt,
+	}
+
+	return conversion, nil
+}
+
+// updatePocket update saldo sesuai mata uang amount: mata uang utama di account.balance,
+// mata uang lain di account_pocket
+func (svc fxService) updatePocket(tx *sql.Tx, account models.Account, amount models.Money, operator, updatedAt string) (models.Money, error) {
+	if amount.CurrencyCode() == constans.DEFAULT_CURRENCY {
+		return svc.Service.AccountRepo.IncrementDecrementLastBalance(account.ID, amount, operator, updatedAt, tx)
+	}
+	return svc.Service.AccountRepo.IncrementDecrementPocketBalance(account.ID, amount, operator, updatedAt, tx)
+}
@@ -0,0 +1,137 @@
+package healthService
+
+import (
+	"context"
+	"net/http"
+	"sample/config"
+	"sample/constans"
+	"sample/helpers"
+	"sample/models"
+	"sample/services"
+	"sample/utils"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+// draining bernilai 1 setelah SIGTERM diterima, readiness langsung DOWN
+// agar load balancer berhenti mengirim request baru
+var draining int32
+
+// MarkDraining menandai instance sedang shutdown
+func MarkDraining() {
+	atomic.StoreInt32(&draining, 1)
+}
+
+type healthService struct {
+	Service services.UsecaseService
+}
+
+// NewHealthService
+func NewHealthService(service services.UsecaseService) healthService {
+	return healthService{
+		Service: service,
+	}
+}
+
+// Live liveness probe, hanya memastikan proses masih melayani HTTP
+func (svc healthService) Live(ctx echo.Context) error {
+	result := helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Service is alive", models.HealthResponse{Status: constans.HEALTH_STATUS_UP})
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// Ready readiness probe, cek Postgres, Redis dan MongoDB. 503 jika salah satu DOWN atau sedang shutdown
+func (svc healthService) Ready(ctx echo.Context) error {
+	var (
+		result      models.Response
+		serviceName = "HealthService"
+	)
+
+	response := models.HealthResponse{
+		Status: constans.HEALTH_STATUS_UP,
+		Checks: svc.CheckDependencies(ctx.Request().Context()),
+	}
+
+	for _, check := range response.Checks {
+		if check.Status != constans.HEALTH_STATUS_UP {
+			response.Status = constans.HEALTH_STATUS_DOWN
+		}
+	}
+
+	if atomic.LoadInt32(&draining) == 1 {
+		response.Status = constans.HEALTH_STATUS_DOWN
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Service is shutting down", response)
+		return ctx.JSON(http.StatusServiceUnavailable, result)
+	}
+
+	if response.Status != constans.HEALTH_STATUS_UP {
+		utils.LogInfo(serviceName, constans.EMPTY_VALUE, "Ready", "Service is not ready")
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Service is not ready", response)
+		return ctx.JSON(http.StatusServiceUnavailable, result)
+	}
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Service is ready", response)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// CheckDependencies cek semua dependency secara paralel, masing-masing dibatasi HealthCheckTimeout
+func (svc healthService) CheckDependencies(parent context.Context) []models.HealthCheck {
+	checks := []struct {
+		name string
+		ping func(context.Context) error
+	}{
+		{"postgres", svc.Service.HealthRepo.PingDB},
+		{"redis", config.PingRedis},
+		{"mongo", svc.Service.HealthRepo.PingMongo},
+	}
+
+	results := make([]models.HealthCheck, len(checks))
+	timeout := svc.Service.Config.HealthCheckTimeout()
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, name string, ping func(context.Context) error) {
+			defer wg.Done()
+			results[i] = runCheck(parent, name, timeout, ping)
+		}(i, check.name, check.ping)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// runCheck menjalankan satu ping. Hasil dikembalikan paling lambat saat timeout walaupun
+// driver tidak menghormati ctx, goroutine ping dibiarkan selesai sendiri
+func runCheck(parent context.Context, name string, timeout time.Duration, ping func(context.Context) error) models.HealthCheck {
+	c, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- ping(c)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-c.Done():
+		err = c.Err()
+	}
+
+	check := models.HealthCheck{
+		Name:      name,
+		Status:    constans.HEALTH_STATUS_UP,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		utils.LogError("HealthService", constans.EMPTY_VALUE, "Ping."+name, err)
+		check.Status = constans.HEALTH_STATUS_DOWN
+		check.Error = err.Error()
+	}
+
+	return check
+}
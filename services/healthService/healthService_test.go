@@ -0,0 +1,77 @@
+package healthService
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sample/config"
+	"sample/constans"
+	"sample/models"
+	"sample/services"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+type healthRepo struct {
+	dbErr    error
+	mongoErr error
+}
+
+func (r healthRepo) PingDB(ctx context.Context) error    { return r.dbErr }
+func (r healthRepo) PingMongo(ctx context.Context) error { return r.mongoErr }
+
+func TestRunCheckTimeout(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+
+	start := time.Now()
+	check := runCheck(context.Background(), "slow", 50*time.Millisecond, func(context.Context) error {
+		<-block // driver yang tidak menghormati ctx
+		return nil
+	})
+
+	if check.Status != constans.HEALTH_STATUS_DOWN || check.Error != context.DeadlineExceeded.Error() {
+		t.Errorf("check = %+v, want DOWN with deadline exceeded", check)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("runCheck took %s, want about 50ms", elapsed)
+	}
+}
+
+func TestReady(t *testing.T) {
+	cfg := config.Default()
+	cfg.HealthCheckTimeoutSeconds = 1
+	svc := NewHealthService(services.UsecaseService{
+		HealthRepo: healthRepo{mongoErr: errors.New("no reachable servers")},
+		Config:     cfg,
+	})
+
+	rec := httptest.NewRecorder()
+	svc.Ready(echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/health/ready", nil), rec))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", rec.Code)
+	}
+
+	var body struct {
+		Result models.HealthResponse `json:"result"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+
+	// Redis pool tidak diinisialisasi di test, jadi ikut DOWN
+	want := map[string]string{"postgres": constans.HEALTH_STATUS_UP, "redis": constans.HEALTH_STATUS_DOWN, "mongo": constans.HEALTH_STATUS_DOWN}
+	for _, check := range body.Result.Checks {
+		if check.Status != want[check.Name] {
+			t.Errorf("%s = %s, want %s", check.Name, check.Status, want[check.Name])
+		}
+	}
+	if len(body.Result.Checks) != len(want) || body.Result.Status != constans.HEALTH_STATUS_DOWN {
+		t.Errorf("result = %+v", body.Result)
+	}
+}
@@ -0,0 +1,500 @@
+package holdService
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sample/constans"
+	"sample/helpers"
+	"sample/models"
+	"sample/services"
+	"sample/services/limitService"
+	"sample/services/pinLockoutService"
+	"sample/services/webhookService"
+	"sample/utils"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+type holdService struct {
+	Service services.UsecaseService
+}
+
+// NewHoldService
+func NewHoldService(service services.UsecaseService) holdService {
+	return holdService{
+		Service: service,
+	}
+}
+
+// CreateHold mereservasi dana nasabah tanpa memindahkannya. Saldo buku tetap,
+// saldo tersedia berkurang sampai hold di-capture, di-release atau kedaluwarsa
+func (svc holdService) CreateHold(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result           models.Response
+		serviceName      = "HoldService.CreateHold"
+		request          = new(models.RequestCreateHold)
+		updatedAt        = time.Now().Format(constans.LAYOUT_TIMESTAMP)
+		holdID           int
+		availableBalance models.Money
+	)
+
+	if err := helpers.BindValidateStruct(ctx, request); err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "BindValidateStruct", err)
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	// Subject selalu dari token, bukan dari body request
+	request.AccountNumber = helpers.GetAccountNumber(ctx)
+
+	utils.LogInfo(serviceName, request.AccountNumber, "Request received",
+		fmt.Sprintf("Beneficiary: %s, Amount: %s, Expires in: %d minute(s)",
+			request.BeneficiaryNumber, request.Amount, request.ExpiresInMinutes))
+
+	if request.AccountNumber == request.BeneficiaryNumber {
+		utils.LogError(serviceName, request.AccountNumber, "ValidateSameAccount",
+			fmt.Errorf("Cannot hold funds for same account"))
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Cannot hold funds for same account", nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	account, err := svc.Service.AccountRepo.FindAccountByNumber(request.AccountNumber)
+	if err != nil {
+		utils.LogError(serviceName, request.AccountNumber, "FindAccountByNumber", err)
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Account not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+
+	// Cek blokir dan verifikasi PIN sesuai kebijakan lockout
+	if pinErr := pinLockoutService.NewPINLockoutService(svc.Service).VerifyPIN(account, request.PIN); pinErr != nil {
+		utils.LogError(serviceName, request.AccountNumber, "VerifyPIN", pinErr)
+		result = helpers.ResponseJSON(false, pinErr.Code, pinErr.Message, nil)
+		return ctx.JSON(pinErr.StatusCode, result)
+	}
+
+	if _, err := svc.Service.AccountRepo.FindAccountByNumber(request.BeneficiaryNumber); err != nil {
+		utils.LogError(serviceName, request.AccountNumber, "FindBeneficiaryAccount", err,
+			fmt.Sprintf("Beneficiary account: %s", request.BeneficiaryNumber))
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Beneficiary account not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+
+	if account.AvailableBalance().LessThan(request.Amount) {
+		utils.LogError(serviceName, request.AccountNumber, "CheckBalance",
+			fmt.Errorf("Insufficient balance. Available: %s, Requested: %s", account.AvailableBalance(), request.Amount))
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Insufficient balance", nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	err = utils.DBTransaction(svc.Service.RepoDB, func(tx *sql.Tx) error {
+		// Update held_balance mengunci row akun, sehingga hold paralel dicek berurutan
+		availableBalance, err = svc.Service.AccountRepo.IncrementDecrementHeldBalance(account.ID, request.Amount, "+", updatedAt, tx)
+		if err != nil {
+			return err
+		}
+
+		if availableBalance.IsNegative() {
+			return &utils.TransactionError{
+				Code:    constans.ACCOUNT_BALANCE_BELOW_MINIMUM_CODE,
+				Message: "Insufficient available balance",
+			}
+		}
+
+		holdID, err = svc.Service.HoldRepo.AddHold(models.Hold{
+			AccountID:         account.ID,
+			AccountNumber:     account.AccountNumber,
+			BeneficiaryNumber: request.BeneficiaryNumber,
+			Amount:            request.Amount,
+			Description:       request.Description,
+			ExpiresAt:         time.Now().Add(time.Duration(request.ExpiresInMinutes) * time.Minute),
+		}, tx)
+		return err
+	})
+
+	if err != nil {
+		return holdErrorResponse(ctx, serviceName, request.AccountNumber, err)
+	}
+
+	hold, err := svc.Service.HoldRepo.FindHoldById(holdID)
+	if err != nil {
+		utils.LogError(serviceName, request.AccountNumber, "FindHoldById", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to load hold", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	utils.LogInfo(serviceName, request.AccountNumber, "Success",
+		fmt.Sprintf("Hold ID: %d, Amount: %s, Available: %s, Expires at: %s",
+			hold.ID, hold.Amount, availableBalance, hold.ExpiresAt.Format(constans.LAYOUT_TIMESTAMP)))
+
+	response := models.HoldResponse{
+		Hold:             hold,
+		AvailableBalance: availableBalance,
+	}
+	helpers.AuditAfter(ctx, response)
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Hold created successfully", response)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// CaptureHold mengubah hold menjadi transfer ke rekening merchant (jurnal TRANSFER).
+// Capture boleh sebagian, sisa hold otomatis di-release. Hold tanpa beneficiary ditolak:
+// capture oleh pemilik sama dengan tarik tunai tanpa PIN, biaya dan OTP, gunakan withdraw
+func (svc holdService) CaptureHold(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result          models.Response
+		serviceName     = "HoldService.CaptureHold"
+		request         = new(models.RequestCaptureHold)
+		transactionTime = time.Now()
+		updatedAt       = transactionTime.Format(constans.LAYOUT_TIMESTAMP)
+		referenceNo     = utils.GenerateReferenceNo()
+		balanceBefore   models.Money
+		balanceAfter    models.Money
+		available       models.Money
+		captured        models.Hold
+	)
+
+	if err := helpers.BindValidateStruct(ctx, request); err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "BindValidateStruct", err)
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	hold, ok := svc.findControlledHold(ctx, serviceName, request.ID)
+	if !ok {
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Hold not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+	helpers.AuditAccount(ctx, hold.AccountNumber)
+
+	if hold.BeneficiaryNumber == "" {
+		utils.LogError(serviceName, hold.AccountNumber, "ValidateBeneficiary",
+			fmt.Errorf("Hold %d has no beneficiary, self-capture is not allowed", hold.ID))
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE,
+			"Hold without beneficiary cannot be captured. Release it and use withdraw instead", nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	captureAmount := hold.Amount
+	if request.Amount.IsPositive() {
+		captureAmount = request.Amount
+	}
+
+	utils.LogInfo(serviceName, hold.AccountNumber, "Request received",
+		fmt.Sprintf("Hold ID: %d, Hold amount: %s, Capture amount: %s", hold.ID, hold.Amount, captureAmount))
+
+	if hold.Amount.LessThan(captureAmount) {
+		utils.LogError(serviceName, hold.AccountNumber, "ValidateCaptureAmount",
+			fmt.Errorf("Capture amount %s exceeds hold amount %s", captureAmount, hold.Amount))
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Capture amount exceeds hold amount "+hold.Amount.String(), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	account, err := svc.Service.AccountRepo.FindAccountById(hold.AccountID)
+	if err != nil {
+		utils.LogError(serviceName, hold.AccountNumber, "FindAccountById", err)
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Account not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+
+	beneficiary, err := svc.Service.AccountRepo.FindAccountByNumber(hold.BeneficiaryNumber)
+	if err != nil {
+		utils.LogError(serviceName, hold.AccountNumber, "FindBeneficiaryAccount", err)
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Beneficiary account not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+
+	err = utils.DBTransaction(svc.Service.RepoDB, func(tx *sql.Tx) error {
+		locked, err := svc.Service.HoldRepo.FindHoldByIdForUpdate(hold.ID, tx)
+		if err != nil {
+			return err
+		}
+
+		if locked.Status != constans.HOLD_STATUS_ACTIVE {
+			return &utils.TransactionError{
+				Code:    constans.VALIDATE_ERROR_CODE,
+				Message: "Hold is already " + locked.Status,
+			}
+		}
+
+		if !transactionTime.Before(locked.ExpiresAt) {
+			return &utils.TransactionError{
+				Code:    constans.VALIDATE_ERROR_CODE,
+				Message: "Hold has expired",
+			}
+		}
+
+		// Seluruh hold dilepas, lalu nominal capture didebit dari saldo buku
+		if _, err := svc.Service.AccountRepo.IncrementDecrementHeldBalance(account.ID, locked.Amount, "-", updatedAt, tx); err != nil {
+			return err
+		}
+
+		balanceAfter, err = svc.Service.AccountRepo.IncrementDecrementLastBalance(account.ID, captureAmount, "-", updatedAt, tx)
+		if err != nil {
+			return err
+		}
+		balanceBefore = balanceAfter.Add(captureAmount)
+
+		if err := limitService.NewLimitService(svc.Service).CheckTransactionLimit(account, constans.JOURNAL_TYPE_TRANSFER, captureAmount, tx); err != nil {
+			return err
+		}
+
+		available, err = svc.Service.AccountRepo.GetAvailableBalance(account.ID, tx)
+		if err != nil {
+			return err
+		}
+
+		if available.IsNegative() {
+			return &utils.TransactionError{
+				Code:    constans.ACCOUNT_BALANCE_BELOW_MINIMUM_CODE,
+				Message: "Account balance below minimum",
+			}
+		}
+
+		journalID, err := svc.Service.LedgerRepo.PostJournal(models.JournalEntry{
+			ReferenceNo: referenceNo,
+			JournalType: constans.JOURNAL_TYPE_TRANSFER,
+			Description: fmt.Sprintf("Capture hold #%d %s ke %s", locked.ID, account.AccountNumber, beneficiary.AccountNumber),
+			Postings: []models.Posting{
+				{LedgerCode: account.AccountNumber, Direction: "D", Amount: captureAmount},
+				{LedgerCode: beneficiary.AccountNumber, Direction: "C", Amount: captureAmount},
+			},
+		}, tx)
+		if err != nil {
+			return err
+		}
+
+		webhookSvc := webhookService.NewWebhookService(svc.Service)
+
+		debitTransaction := models.Transaction{
+			AccountID:         account.ID,
+			AccountNumber:     account.AccountNumber,
+			AccountName:       account.AccountName,
+			TransactionType:   "D",
+			Amount:            captureAmount,
+			JournalID:         journalID,
+			TransactionTime:   transactionTime,
+			SourceNumber:      account.AccountNumber,
+			BeneficiaryNumber: beneficiary.AccountNumber,
+		}
+
+		debitTransaction.ID, err = svc.Service.TransactionRepo.AddTransaction(debitTransaction, tx)
+		if err != nil {
+			return err
+		}
+
+		if err := webhookSvc.PublishTransactionCreated(tx, debitTransaction); err != nil {
+			return err
+		}
+
+		if _, err := svc.Service.AccountRepo.IncrementDecrementLastBalance(beneficiary.ID, captureAmount, "+", updatedAt, tx); err != nil {
+			return err
+		}
+
+		creditTransaction := models.Transaction{
+			AccountID:         beneficiary.ID,
+			AccountNumber:     beneficiary.AccountNumber,
+			AccountName:       beneficiary.AccountName,
+			TransactionType:   "C",
+			Amount:            captureAmount,
+			JournalID:         journalID,
+			TransactionTime:   transactionTime,
+			SourceNumber:      account.AccountNumber,
+			BeneficiaryNumber: beneficiary.AccountNumber,
+		}
+
+		creditTransaction.ID, err = svc.Service.TransactionRepo.AddTransaction(creditTransaction, tx)
+		if err != nil {
+			return err
+		}
+
+		if err := webhookSvc.PublishTransactionCreated(tx, creditTransaction); err != nil {
+			return err
+		}
+
+		captured = locked
+		captured.Status = constans.HOLD_STATUS_CAPTURED
+		captured.CapturedAmount = captureAmount
+		captured.ReferenceNo = referenceNo
+		captured.ClosedAt = &transactionTime
+
+		return svc.Service.HoldRepo.CloseHoldWithTx(tx, captured)
+	})
+
+	if err != nil {
+		return holdErrorResponse(ctx, serviceName, hold.AccountNumber, err)
+	}
+
+	releasedAmount := captured.Amount.Sub(captureAmount)
+
+	utils.LogInfo(serviceName, hold.AccountNumber, "Success",
+		fmt.Sprintf("Hold ID: %d, Captured: %s, Released: %s, Balance: %s->%s, Ref: %s",
+			captured.ID, captureAmount, releasedAmount, balanceBefore, balanceAfter, referenceNo))
+
+	response := models.HoldCaptureResponse{
+		Hold:             captured,
+		ReferenceNo:      referenceNo,
+		CapturedAmount:   captureAmount,
+		ReleasedAmount:   releasedAmount,
+		BalanceBefore:    balanceBefore,
+		BalanceAfter:     balanceAfter,
+		AvailableBalance: available,
+		Currency:         balanceAfter.CurrencyCode(),
+		TransactionDate:  updatedAt,
+	}
+
+	helpers.AuditBalanceChange(ctx, referenceNo, models.AuditBalanceChange{
+		AccountNumber: hold.AccountNumber,
+		Before:        balanceBefore,
+		After:         balanceAfter,
+	})
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Hold captured successfully", response)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// ReleaseHold membatalkan hold tanpa memindahkan dana, saldo tersedia kembali penuh
+func (svc holdService) ReleaseHold(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result      models.Response
+		serviceName = "HoldService.ReleaseHold"
+		request     = new(models.RequestHoldByID)
+		released    models.Hold
+		available   models.Money
+	)
+
+	if err := helpers.BindValidateStruct(ctx, request); err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "BindValidateStruct", err)
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	hold, ok := svc.findControlledHold(ctx, serviceName, request.ID)
+	if !ok {
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Hold not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+	helpers.AuditAccount(ctx, hold.AccountNumber)
+	helpers.AuditBefore(ctx, hold)
+
+	err := utils.DBTransaction(svc.Service.RepoDB, func(tx *sql.Tx) error {
+		var err error
+		released, available, err = svc.ReleaseHoldWithTx(tx, hold.ID, constans.HOLD_STATUS_RELEASED)
+		return err
+	})
+
+	if err != nil {
+		return holdErrorResponse(ctx, serviceName, hold.AccountNumber, err)
+	}
+
+	utils.LogInfo(serviceName, hold.AccountNumber, "Success",
+		fmt.Sprintf("Hold ID: %d, Released: %s, Available: %s", released.ID, released.Amount, available))
+
+	response := models.HoldResponse{
+		Hold:             released,
+		AvailableBalance: available,
+	}
+	helpers.AuditAfter(ctx, response)
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Hold released successfully", response)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// GetHoldList daftar hold milik nasabah dan hold yang ditujukan ke rekening nasabah (merchant)
+func (svc holdService) GetHoldList(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result        models.Response
+		serviceName   = "HoldService.GetHoldList"
+		accountNumber = helpers.GetAccountNumber(ctx)
+	)
+
+	utils.LogInfo(serviceName, accountNumber, "Request received")
+
+	holds, err := svc.Service.HoldRepo.GetHoldsByAccount(accountNumber)
+	if err != nil {
+		utils.LogError(serviceName, accountNumber, "GetHoldsByAccount", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to get holds", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	if holds == nil {
+		holds = []models.Hold{}
+	}
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Holds retrieved successfully", holds)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// ReleaseHoldWithTx menutup hold ACTIVE tanpa capture (RELEASED atau EXPIRED)
+// dan mengembalikan nominalnya ke saldo tersedia. Dipakai endpoint release dan worker expiry
+func (svc holdService) ReleaseHoldWithTx(tx *sql.Tx, holdID int, status string) (models.Hold, models.Money, error) {
+	var available models.Money
+
+	hold, err := svc.Service.HoldRepo.FindHoldByIdForUpdate(holdID, tx)
+	if err != nil {
+		return hold, available, err
+	}
+
+	if hold.Status != constans.HOLD_STATUS_ACTIVE {
+		return hold, available, &utils.TransactionError{
+			Code:    constans.VALIDATE_ERROR_CODE,
+			Message: "Hold is already " + hold.Status,
+		}
+	}
+
+	now := time.Now()
+	available, err = svc.Service.AccountRepo.IncrementDecrementHeldBalance(hold.AccountID, hold.Amount, "-", now.Format(constans.LAYOUT_TIMESTAMP), tx)
+	if err != nil {
+		return hold, available, err
+	}
+
+	hold.Status = status
+	hold.ClosedAt = &now
+
+	return hold, available, svc.Service.HoldRepo.CloseHoldWithTx(tx, hold)
+}
+
+// findControlledHold mencari hold yang boleh di-capture/release oleh pemilik token:
+// merchant (beneficiary) jika ada, selain itu pemilik rekening
+func (svc holdService) findControlledHold(ctx echo.Context, serviceName string, id int) (models.Hold, bool) {
+	hold, err := svc.Service.HoldRepo.FindHoldById(id)
+	if err != nil {
+		utils.LogError(serviceName, fmt.Sprintf("%d", id), "FindHoldById", err)
+		return models.Hold{}, false
+	}
+
+	controller := hold.AccountNumber
+	if hold.BeneficiaryNumber != "" {
+		controller = hold.BeneficiaryNumber
+	}
+
+	if controller != helpers.GetAccountNumber(ctx) {
+		utils.LogError(serviceName, fmt.Sprintf("%d", id), "CheckOwnership",
+			fmt.Errorf("Hold is not controlled by token subject"))
+		return models.Hold{}, false
+	}
+
+	return hold, true
+}
+
+// holdErrorResponse error bisnis dari DBTransaction menjadi 400, selain itu 500
+func holdErrorResponse(ctx echo.Context, serviceName, accountNumber string, err error) error {
+	if txErr, ok := err.(*utils.TransactionError); ok {
+		utils.LogError(serviceName, accountNumber, "DBTransaction.Rejected", fmt.Errorf("%s", txErr.Message))
+		result := helpers.ResponseJSON(false, txErr.Code, txErr.Message, nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	utils.LogError(serviceName, accountNumber, "DBTransaction", err)
+	result := helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Transaction failed: "+err.Error(), nil)
+	return ctx.JSON(http.StatusInternalServerError, result)
+}
@@ -0,0 +1,91 @@
+package holdService
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sample/constans"
+	"sample/models"
+	"sample/services"
+	"sample/utils"
+	"time"
+)
+
+type holdExpiryWorker struct {
+	Service   services.UsecaseService
+	Interval  time.Duration
+	BatchSize int
+}
+
+// NewHoldExpiryWorker interval dari service.Config (HOLD_EXPIRY_INTERVAL_SECONDS)
+func NewHoldExpiryWorker(service services.UsecaseService) holdExpiryWorker {
+	return holdExpiryWorker{
+		Service:   service,
+		Interval:  service.Config.HoldExpiryInterval(),
+		BatchSize: 100,
+	}
+}
+
+// Start menjalankan worker sampai ctx selesai. Aman dijalankan di banyak instance:
+// setiap hold dikunci (FOR UPDATE) dan hanya hold yang masih ACTIVE yang di-release
+func (w holdExpiryWorker) Start(ctx context.Context) {
+	serviceName := "HoldExpiryWorker.Start"
+	utils.LogInfo(serviceName, constans.EMPTY_VALUE, "Started", fmt.Sprintf("Interval: %s", w.Interval))
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		w.ReleaseExpiredHolds()
+
+		select {
+		case <-ctx.Done():
+			utils.LogInfo(serviceName, constans.EMPTY_VALUE, "Stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ReleaseExpiredHolds me-release semua hold ACTIVE yang sudah melewati expires_at
+func (w holdExpiryWorker) ReleaseExpiredHolds() {
+	serviceName := "HoldExpiryWorker.ReleaseExpiredHolds"
+	svc := NewHoldService(w.Service)
+
+	for {
+		ids, err := w.Service.HoldRepo.GetExpiredHoldIDs(time.Now(), w.BatchSize)
+		if err != nil {
+			utils.LogError(serviceName, constans.EMPTY_VALUE, "GetExpiredHoldIDs", err)
+			return
+		}
+
+		expired := 0
+		for _, id := range ids {
+			var hold models.Hold
+
+			err := utils.DBTransaction(w.Service.RepoDB, func(tx *sql.Tx) error {
+				var err error
+				hold, _, err = svc.ReleaseHoldWithTx(tx, id, constans.HOLD_STATUS_EXPIRED)
+				return err
+			})
+
+			if err != nil {
+				// Hold sudah di-capture/release oleh request lain atau instance lain
+				if _, ok := err.(*utils.TransactionError); ok {
+					continue
+				}
+				utils.LogError(serviceName, fmt.Sprintf("%d", id), "ReleaseHoldWithTx", err)
+				continue
+			}
+
+			expired++
+			utils.LogInfo(serviceName, hold.AccountNumber, "Expired",
+				fmt.Sprintf("Hold ID: %d, Amount: %s", hold.ID, hold.Amount))
+		}
+
+		// Berhenti jika batch tidak penuh atau tidak ada progres (hindari loop pada error sistem)
+		if len(ids) < w.BatchSize || expired == 0 {
+			return
+		}
+	}
+}
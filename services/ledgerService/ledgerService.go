@@ -0,0 +1,132 @@
+package ledgerService
+
+import (
+	"fmt"
+	"net/http"
+	"sample/constans"
+	"sample/helpers"
+	"sample/models"
+	"sample/services"
+	"sample/utils"
+
+	"github.com/labstack/echo"
+)
+
+type ledgerService struct {
+	Service services.UsecaseService
+}
+
+// NewLedgerService
+func NewLedgerService(service services.UsecaseService) ledgerService {
+	return ledgerService{
+		Service: service,
+	}
+}
+
+// GetLedgerBalance mendapatkan saldo akun yang diturunkan dari posting ledger
+func (svc ledgerService) GetLedgerBalance(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result      models.Response
+		serviceName = "LedgerService"
+		request     = new(models.RequestLedgerBalance)
+	)
+
+	if err := helpers.BindValidateStruct(ctx, request); err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "GetLedgerBalance.BindValidateStruct", err)
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	utils.LogInfo(serviceName, request.LedgerCode, "GetLedgerBalance", "Request received")
+
+	response, err := svc.Service.LedgerRepo.GetLedgerBalance(request.LedgerCode)
+	if err != nil {
+		utils.LogError(serviceName, request.LedgerCode, "GetLedgerBalance.GetLedgerBalance", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Ledger balance retrieved successfully", response)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// GetJournalDetail mendapatkan jurnal beserta posting berdasarkan ID atau reference number
+func (svc ledgerService) GetJournalDetail(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result      models.Response
+		serviceName = "LedgerService"
+		request     = new(models.RequestJournalDetail)
+		journal     models.JournalEntry
+		err         error
+	)
+
+	if err := helpers.BindValidateStruct(ctx, request); err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "GetJournalDetail.BindValidateStruct", err)
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	if request.JournalID <= 0 && request.ReferenceNo == "" {
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "journal_id or reference_no is required", nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	if request.JournalID > 0 {
+		journal, err = svc.Service.LedgerRepo.FindJournalById(request.JournalID)
+	} else {
+		journal, err = svc.Service.LedgerRepo.FindJournalByReferenceNo(request.ReferenceNo)
+	}
+
+	if err != nil {
+		utils.LogError(serviceName, request.ReferenceNo, "GetJournalDetail.FindJournal", err)
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Journal not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Journal retrieved successfully", journal)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// VerifyLedger memeriksa invariant ledger: semua jurnal seimbang dan saldo akun sama dengan saldo ledger
+func (svc ledgerService) VerifyLedger(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result      models.Response
+		serviceName = "LedgerService"
+		referenceNo = utils.GenerateShortReferenceNo()
+		response    models.LedgerVerifyResponse
+	)
+
+	utils.LogInfo(serviceName, referenceNo, "VerifyLedger", "Request received")
+
+	unbalanced, err := svc.Service.LedgerRepo.GetUnbalancedJournals()
+	if err != nil {
+		utils.LogError(serviceName, referenceNo, "VerifyLedger.GetUnbalancedJournals", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	mismatches, err := svc.Service.LedgerRepo.GetLedgerMismatches()
+	if err != nil {
+		utils.LogError(serviceName, referenceNo, "VerifyLedger.GetLedgerMismatches", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	response = models.LedgerVerifyResponse{
+		Balanced:           len(unbalanced) == 0 && len(mismatches) == 0,
+		UnbalancedJournals: unbalanced,
+		Mismatches:         mismatches,
+	}
+
+	utils.LogInfo(serviceName, referenceNo, "VerifyLedger.Success",
+		fmt.Sprintf("Unbalanced journals: %d, Mismatched accounts: %d", len(unbalanced), len(mismatches)))
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Ledger verified", response)
+	return ctx.JSON(http.StatusOK, result)
+}
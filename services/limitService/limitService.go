@@ -0,0 +1,174 @@
+package limitService
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sample/constans"
+	"sample/helpers"
+	"sample/models"
+	"sample/services"
+	"sample/utils"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+type limitService struct {
+	Service services.UsecaseService
+}
+
+// NewLimitService
+func NewLimitService(service services.UsecaseService) limitService {
+	return limitService{
+		Service: service,
+	}
+}
+
+// CheckTransactionLimit memastikan transaksi tidak melewati limit tier akun.
+// Dipanggil di dalam DBTransaction setelah saldo akun di-update, sehingga row akun
+// sudah terkunci dan transaksi paralel pada akun yang sama dicek berurutan
+func (svc limitService) CheckTransactionLimit(account models.Account, transactionType string, amount models.Money, tx *sql.Tx) error {
+	limit, err := svc.Service.LimitRepo.FindTransactionLimit(tierOf(account), transactionType)
+	if err != nil {
+		return err
+	}
+
+	if limit.MaxSingleAmount.IsPositive() && limit.MaxSingleAmount.LessThan(amount) {
+		return limitExceeded(fmt.Sprintf("Amount exceeds maximum %s of %s per transaction", transactionType, limit.MaxSingleAmount))
+	}
+
+	usage, err := svc.Service.LimitRepo.GetLimitUsage(account.ID, transactionType, directionOf(transactionType), time.Now(), tx)
+	if err != nil {
+		return err
+	}
+
+	// Usage dibaca di dalam tx yang sama, tapi baris transaksi ini belum ditulis
+	if limit.MaxDailyCount > 0 && usage.DailyCount >= limit.MaxDailyCount {
+		return limitExceeded(fmt.Sprintf("Daily %s count limit of %d reached", transactionType, limit.MaxDailyCount))
+	}
+
+	if limit.MaxDailyAmount.IsPositive() && limit.MaxDailyAmount.LessThan(usage.DailyAmount.Add(amount)) {
+		return limitExceeded(fmt.Sprintf("Daily %s amount limit exceeded. Remaining: %s",
+			transactionType, remainingMoney(limit.MaxDailyAmount, usage.DailyAmount)))
+	}
+
+	if limit.MaxMonthlyAmount.IsPositive() && limit.MaxMonthlyAmount.LessThan(usage.MonthlyAmount.Add(amount)) {
+		return limitExceeded(fmt.Sprintf("Monthly %s amount limit exceeded. Remaining: %s",
+			transactionType, remainingMoney(limit.MaxMonthlyAmount, usage.MonthlyAmount)))
+	}
+
+	return nil
+}
+
+// GetLimitUsage sisa limit harian dan bulanan akun untuk setiap jenis transaksi
+func (svc limitService) GetLimitUsage(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result        models.Response
+		serviceName   = "LimitService.GetLimitUsage"
+		accountNumber = helpers.GetAccountNumber(ctx)
+		now           = time.Now()
+		details       = []models.LimitUsageDetail{}
+	)
+
+	utils.LogInfo(serviceName, accountNumber, "Request received")
+
+	account, err := svc.Service.AccountRepo.FindAccountByNumber(accountNumber)
+	if err != nil {
+		utils.LogError(serviceName, accountNumber, "FindAccountByNumber", err)
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Account not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+
+	limits, err := svc.Service.LimitRepo.GetTransactionLimitsByTier(tierOf(account))
+	if err != nil {
+		utils.LogError(serviceName, accountNumber, "GetTransactionLimitsByTier", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to get transaction limits", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	for _, limit := range limits {
+		usage, err := svc.Service.LimitRepo.GetLimitUsage(account.ID, limit.TransactionType, directionOf(limit.TransactionType), now, nil)
+		if err != nil {
+			utils.LogError(serviceName, accountNumber, "GetLimitUsage", err)
+			result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to get limit usage", nil)
+			return ctx.JSON(http.StatusInternalServerError, result)
+		}
+
+		detail := models.LimitUsageDetail{
+			TransactionType:    limit.TransactionType,
+			MaxSingleAmount:    limit.MaxSingleAmount,
+			DailyCountLimit:    limit.MaxDailyCount,
+			DailyCountUsed:     usage.DailyCount,
+			DailyAmountLimit:   limit.MaxDailyAmount,
+			DailyAmountUsed:    usage.DailyAmount,
+			MonthlyAmountLimit: limit.MaxMonthlyAmount,
+			MonthlyAmountUsed:  usage.MonthlyAmount,
+		}
+
+		if limit.MaxDailyCount > 0 {
+			remaining := limit.MaxDailyCount - usage.DailyCount
+			if remaining < 0 {
+				remaining = 0
+			}
+			detail.DailyCountRemaining = &remaining
+		}
+
+		if limit.MaxDailyAmount.IsPositive() {
+			remaining := remainingMoney(limit.MaxDailyAmount, usage.DailyAmount)
+			detail.DailyAmountRemaining = &remaining
+		}
+
+		if limit.MaxMonthlyAmount.IsPositive() {
+			remaining := remainingMoney(limit.MaxMonthlyAmount, usage.MonthlyAmount)
+			detail.MonthlyAmountRemaining = &remaining
+		}
+
+		details = append(details, detail)
+	}
+
+	response := models.LimitUsageResponse{
+		AccountNumber: account.AccountNumber,
+		AccountTier:   tierOf(account),
+		Currency:      account.Balance.CurrencyCode(),
+		Limits:        details,
+		CheckedAt:     now,
+	}
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Limit usage retrieved successfully", response)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// tierOf tier akun, akun lama tanpa tier diperlakukan sebagai BASIC
+func tierOf(account models.Account) string {
+	if account.AccountTier == "" {
+		return constans.ACCOUNT_TIER_BASIC
+	}
+	return account.AccountTier
+}
+
+// directionOf sisi rekening nasabah yang dihitung untuk limit: setoran masuk (C), lainnya keluar (D)
+func directionOf(transactionType string) string {
+	if transactionType == constans.JOURNAL_TYPE_DEPOSIT {
+		return "C"
+	}
+	return "D"
+}
+
+// remainingMoney sisa limit, tidak pernah negatif
+func remainingMoney(limit, used models.Money) models.Money {
+	remaining := limit.Sub(used)
+	if remaining.IsNegative() {
+		return models.NewMoney(0, limit.CurrencyCode())
+	}
+	return remaining
+}
+
+func limitExceeded(message string) error {
+	return &utils.TransactionError{
+		Code:    constans.TRANSACTION_LIMIT_EXCEEDED_CODE,
+		Message: message,
+	}
+}
@@ -0,0 +1,356 @@
+package pinLockoutService
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"net/http"
+	"sample/constans"
+	"sample/helpers"
+	"sample/metrics"
+	"sample/models"
+	"sample/services"
+	"sample/services/webhookService"
+	"sample/utils"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+type pinLockoutService struct {
+	Service services.UsecaseService
+}
+
+// NewPINLockoutService kebijakan dari service.Config.PINLockout (PIN_*)
+func NewPINLockoutService(service services.UsecaseService) pinLockoutService {
+	return pinLockoutService{
+		Service: service,
+	}
+}
+
+// PINError verifikasi PIN yang ditolak beserta status HTTP dan response code-nya
+type PINError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *PINError) Error() string {
+	return e.Message
+}
+
+// IsLocked akun diblokir permanen atau masih dalam masa kunci sementara
+func IsLocked(account models.Account, now time.Time) bool {
+	return CheckLock(account, now) != nil
+}
+
+// CheckLock menolak akun yang diblokir permanen (BLOCKED_PIN) atau masih terkunci sementara.
+// Kunci sementara berakhir sendiri saat locked_until lewat
+func CheckLock(account models.Account, now time.Time) *PINError {
+	if account.AccountStatus == constans.ACCOUNT_STATUS_BLOCKED_PIN {
+		return &PINError{
+			StatusCode: http.StatusForbidden,
+			Code:       constans.FORBIDDEN_CODE,
+			Message:    "Account is blocked due to multiple failed PIN attempts. Please reset your PIN",
+		}
+	}
+
+	if account.LockedUntil != nil && now.Before(*account.LockedUntil) {
+		minutes := int(math.Ceil(account.LockedUntil.Sub(now).Minutes()))
+		return &PINError{
+			StatusCode: http.StatusForbidden,
+			Code:       constans.FORBIDDEN_CODE,
+			Message:    fmt.Sprintf("Account is temporarily locked due to multiple failed PIN attempts. Please try again in %d minute(s)", minutes),
+		}
+	}
+
+	return nil
+}
+
+// VerifyPIN satu-satunya jalur verifikasi PIN nasabah: tolak akun terkunci, cocokkan hash,
+// reset counter jika benar, catat percobaan gagal dan terapkan kunci jika salah
+func (svc pinLockoutService) VerifyPIN(account models.Account, pin string) *PINError {
+	// Snapshot sudah terkunci: tolak tanpa membuka transaksi, status pastinya dicek ulang di VerifyCredential
+	if pinErr := CheckLock(account, time.Now()); pinErr != nil {
+		return pinErr
+	}
+
+	return svc.VerifyCredential(account, "PIN", func(current models.Account) bool {
+		if helpers.CheckPINHashContext(svc.Service.Context, pin, current.PIN) {
+			return true
+		}
+		metrics.FailedPINAttemptsTotal.Inc()
+		return false
+	})
+}
+
+// VerifyCredential cek kunci lalu jalankan match (PIN atau OTP transaksi) di bawah row lock akun.
+// Percobaan paralel untuk akun yang sama berjalan serial, sehingga tidak ada credential yang
+// dicocokkan saat akun terkunci, setiap kegagalan dihitung ke counter yang sama, dan reset
+// counter hanya terjadi pada row yang tidak terkunci. credential dipakai di pesan error
+func (svc pinLockoutService) VerifyCredential(account models.Account, credential string, match func(current models.Account) bool) *PINError {
+	serviceName := "PINLockoutService.VerifyCredential"
+	now := time.Now()
+	policy := svc.Service.Config.PINLockout
+
+	var (
+		current models.Account
+		event   *models.PINLockEvent
+		locked  *PINError
+		matched bool
+	)
+	err := utils.DBTransaction(svc.Service.RepoDB, func(tx *sql.Tx) error {
+		var err error
+		current, err = svc.Service.AccountRepo.FindAccountByNumberForUpdate(account.AccountNumber, tx)
+		if err != nil {
+			return err
+		}
+
+		if locked = CheckLock(current, now); locked != nil {
+			return nil
+		}
+
+		if matched = match(current); matched {
+			if current.FailedPINAttempts == 0 && current.PINLockoutCount == 0 && current.LockedUntil == nil {
+				return nil
+			}
+			current.FailedPINAttempts = 0
+			current.PINLockoutCount = 0
+			current.LockedUntil = nil
+			return svc.Service.AccountRepo.UpdatePINLockStateWithTx(tx, current)
+		}
+
+		current, event = applyFailure(policy, current, now)
+		if err := svc.Service.AccountRepo.UpdatePINLockStateWithTx(tx, current); err != nil {
+			return err
+		}
+
+		if event != nil {
+			if _, err := svc.Service.AccountRepo.AddPINLockEvent(*event, tx); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		utils.LogError(serviceName, account.AccountNumber, "DBTransaction", err)
+		return &PINError{
+			StatusCode: http.StatusInternalServerError,
+			Code:       constans.SYSTEM_ERROR_CODE,
+			Message:    "Failed to verify " + credential,
+		}
+	}
+
+	if locked != nil {
+		return locked
+	}
+	if matched {
+		return nil
+	}
+
+	if event != nil {
+		utils.LogInfo(serviceName, account.AccountNumber, event.EventType,
+			fmt.Sprintf("Lockout Count: %d, Reason: %s", event.LockoutCount, event.Reason))
+
+		if event.EventType == constans.PIN_LOCK_EVENT_PERMANENT_BLOCK {
+			webhookService.NewWebhookService(svc.Service).PublishAccountBlocked(account.AccountNumber, event.FailedAttempts)
+		} else {
+			metrics.AccountsTemporarilyLockedTotal.Inc()
+		}
+	}
+
+	if pinErr := CheckLock(current, now); pinErr != nil {
+		return pinErr
+	}
+
+	return &PINError{
+		StatusCode: http.StatusUnauthorized,
+		Code:       constans.UNAUTHORIZED_CODE,
+		Message:    fmt.Sprintf("Invalid %s. %d attempt(s) remaining", credential, policy.MaxAttempts-current.FailedPINAttempts),
+	}
+}
+
+// applyFailure state akun setelah satu PIN salah. Setiap MaxAttempts kegagalan menghasilkan kunci
+// sementara yang makin lama, kunci setelah MaxTemporaryLockouts menjadi blokir permanen
+func applyFailure(policy models.PINLockout, account models.Account, now time.Time) (models.Account, *models.PINLockEvent) {
+	account.FailedPINAttempts++
+	if account.FailedPINAttempts < policy.MaxAttempts {
+		return account, nil
+	}
+
+	account.PINLockoutCount++
+	event := &models.PINLockEvent{
+		AccountNumber:  account.AccountNumber,
+		FailedAttempts: account.FailedPINAttempts,
+		LockoutCount:   account.PINLockoutCount,
+		Actor:          constans.PIN_LOCK_ACTOR_SYSTEM,
+	}
+
+	if account.PINLockoutCount > policy.MaxTemporaryLockouts {
+		account.AccountStatus = constans.ACCOUNT_STATUS_BLOCKED_PIN
+		account.LockedUntil = nil
+		event.EventType = constans.PIN_LOCK_EVENT_PERMANENT_BLOCK
+		event.Reason = fmt.Sprintf("%d failed PIN attempts after %d temporary lockout(s)", account.FailedPINAttempts, policy.MaxTemporaryLockouts)
+		return account, event
+	}
+
+	// Percobaan dihitung ulang dari nol setelah kunci sementara berakhir
+	lockedUntil := now.Add(policy.LockoutDuration(account.PINLockoutCount))
+	account.FailedPINAttempts = 0
+	account.LockedUntil = &lockedUntil
+	event.EventType = constans.PIN_LOCK_EVENT_TEMPORARY_LOCK
+	event.LockedUntil = &lockedUntil
+	event.Reason = fmt.Sprintf("%d failed PIN attempts", event.FailedAttempts)
+
+	return account, event
+}
+
+// UnblockAccount membuka blokir permanen maupun kunci sementara akun (admin)
+func (svc pinLockoutService) UnblockAccount(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result      models.Response
+		serviceName = "PINLockoutService.UnblockAccount"
+		request     = new(models.RequestUnblockAccount)
+		before      models.Account
+		unblocked   models.Account
+	)
+
+	if err := helpers.BindValidateStruct(ctx, request); err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "BindValidateStruct", err)
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	utils.LogInfo(serviceName, request.AccountNumber, "UnblockAccount", "Request received")
+	helpers.AuditAccount(ctx, request.AccountNumber)
+
+	now := time.Now()
+	err := utils.DBTransaction(svc.Service.RepoDB, func(tx *sql.Tx) error {
+		var err error
+		before, err = svc.Service.AccountRepo.FindAccountByNumberForUpdate(request.AccountNumber, tx)
+		if err != nil {
+			return err
+		}
+
+		if !IsLocked(before, now) {
+			return &utils.TransactionError{
+				Code:    constans.VALIDATE_ERROR_CODE,
+				Message: "Account is not locked",
+			}
+		}
+
+		unblocked = before
+		unblocked.AccountStatus = constans.ACCOUNT_STATUS_ACTIVE
+		unblocked.FailedPINAttempts = 0
+		unblocked.PINLockoutCount = 0
+		unblocked.LockedUntil = nil
+		if err := svc.Service.AccountRepo.UpdatePINLockStateWithTx(tx, unblocked); err != nil {
+			return err
+		}
+
+		_, err = svc.Service.AccountRepo.AddPINLockEvent(models.PINLockEvent{
+			AccountNumber:  before.AccountNumber,
+			EventType:      constans.PIN_LOCK_EVENT_ADMIN_UNBLOCK,
+			FailedAttempts: before.FailedPINAttempts,
+			LockoutCount:   before.PINLockoutCount,
+			LockedUntil:    before.LockedUntil,
+			Actor:          constans.ROLE_ADMIN,
+			Reason:         request.Reason,
+		}, tx)
+		return err
+	})
+
+	if err != nil {
+		if txErr, ok := err.(*utils.TransactionError); ok {
+			utils.LogError(serviceName, request.AccountNumber, "CheckLock", txErr)
+			result = helpers.ResponseJSON(false, txErr.Code, txErr.Message, nil)
+			return ctx.JSON(http.StatusBadRequest, result)
+		}
+		if err.Error() == "Account not found" {
+			utils.LogError(serviceName, request.AccountNumber, "FindAccountByNumberForUpdate", err)
+			result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Account not found", nil)
+			return ctx.JSON(http.StatusNotFound, result)
+		}
+
+		utils.LogError(serviceName, request.AccountNumber, "DBTransaction", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to unblock account", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	utils.LogInfo(serviceName, request.AccountNumber, "UnblockAccount.Success", "Reason: "+request.Reason)
+
+	response := models.UnblockAccountResponse{
+		AccountNumber: unblocked.AccountNumber,
+		AccountStatus: unblocked.AccountStatus,
+		UnblockedAt:   now,
+	}
+	helpers.AuditBefore(ctx, before.AuditSnapshot())
+	helpers.AuditAfter(ctx, unblocked.AuditSnapshot())
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Account unblocked successfully", response)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// GetPINLockHistory riwayat kunci PIN akun mana pun (admin)
+func (svc pinLockoutService) GetPINLockHistory(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	request := new(models.RequestPINLockHistory)
+	if err := helpers.BindValidateStruct(ctx, request); err != nil {
+		utils.LogError("PINLockoutService.GetPINLockHistory", constans.EMPTY_VALUE, "BindValidateStruct", err)
+		result := helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	return svc.pinLockHistory(ctx, request.AccountNumber)
+}
+
+// GetMyPINLockHistory riwayat kunci PIN akun milik nasabah pada token
+func (svc pinLockoutService) GetMyPINLockHistory(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	return svc.pinLockHistory(ctx, helpers.GetAccountNumber(ctx))
+}
+
+func (svc pinLockoutService) pinLockHistory(ctx echo.Context, accountNumber string) error {
+	var (
+		result      models.Response
+		serviceName = "PINLockoutService.GetPINLockHistory"
+	)
+
+	utils.LogInfo(serviceName, accountNumber, "GetPINLockHistory", "Request received")
+
+	account, err := svc.Service.AccountRepo.FindAccountByNumber(accountNumber)
+	if err != nil {
+		utils.LogError(serviceName, accountNumber, "FindAccountByNumber", err)
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Account not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+
+	events, err := svc.Service.AccountRepo.GetPINLockEvents(accountNumber)
+	if err != nil {
+		utils.LogError(serviceName, accountNumber, "GetPINLockEvents", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to get PIN lock history", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+	if events == nil {
+		events = []models.PINLockEvent{}
+	}
+
+	response := models.PINLockHistoryResponse{
+		AccountNumber:     account.AccountNumber,
+		AccountStatus:     account.AccountStatus,
+		FailedPINAttempts: account.FailedPINAttempts,
+		PINLockoutCount:   account.PINLockoutCount,
+		Events:            events,
+	}
+	if IsLocked(account, time.Now()) {
+		response.LockedUntil = account.LockedUntil
+	}
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "PIN lock history retrieved successfully", response)
+	return ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,171 @@
+package pinLockoutService
+
+import (
+	"net/http"
+	"sample/config"
+	"sample/constans"
+	"sample/helpers"
+	"sample/models"
+	"sample/repositories"
+	"sample/repositories/memoryRepository"
+	"sample/services"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestApplyFailureProgression(t *testing.T) {
+	policy := config.Default().PINLockout
+	now := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	account := models.Account{AccountNumber: "1000000001", AccountStatus: constans.ACCOUNT_STATUS_ACTIVE}
+
+	// Kunci sementara 15, 30, 60 menit lalu blokir permanen
+	for i, want := range []time.Duration{15 * time.Minute, 30 * time.Minute, 60 * time.Minute} {
+		var event *models.PINLockEvent
+		for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
+			account, event = applyFailure(policy, account, now)
+			if attempt < policy.MaxAttempts && event != nil {
+				t.Fatalf("lockout %d attempt %d: unexpected event %+v", i+1, attempt, event)
+			}
+		}
+
+		if event == nil || event.EventType != constans.PIN_LOCK_EVENT_TEMPORARY_LOCK || event.LockoutCount != i+1 {
+			t.Fatalf("lockout %d: event = %+v, want TEMPORARY_LOCK", i+1, event)
+		}
+		if account.LockedUntil == nil || account.LockedUntil.Sub(now) != want || account.FailedPINAttempts != 0 {
+			t.Errorf("lockout %d: locked_until = %v failed = %d, want now+%s and 0", i+1, account.LockedUntil, account.FailedPINAttempts, want)
+		}
+
+		if pinErr := CheckLock(account, now); pinErr == nil || pinErr.StatusCode != http.StatusForbidden {
+			t.Errorf("lockout %d: CheckLock during lock = %v, want 403", i+1, pinErr)
+		}
+		if pinErr := CheckLock(account, account.LockedUntil.Add(time.Second)); pinErr != nil {
+			t.Errorf("lockout %d: CheckLock after expiry = %v, want nil", i+1, pinErr)
+		}
+	}
+
+	var event *models.PINLockEvent
+	for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
+		account, event = applyFailure(policy, account, now)
+	}
+	if event == nil || event.EventType != constans.PIN_LOCK_EVENT_PERMANENT_BLOCK || account.AccountStatus != constans.ACCOUNT_STATUS_BLOCKED_PIN {
+		t.Fatalf("event = %+v status = %s, want PERMANENT_BLOCK and BLOCKED_PIN", event, account.AccountStatus)
+	}
+	if CheckLock(account, now.Add(24*time.Hour)) == nil {
+		t.Error("permanent block should not expire")
+	}
+}
+
+// Tanpa kunci sementara, kegagalan ke-MaxAttempts langsung blokir permanen
+func TestApplyFailureWithoutTemporaryLockouts(t *testing.T) {
+	policy := config.Default().PINLockout
+	policy.MaxTemporaryLockouts = 0
+
+	account := models.Account{AccountNumber: "1000000001", AccountStatus: constans.ACCOUNT_STATUS_ACTIVE}
+	var event *models.PINLockEvent
+	for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
+		account, event = applyFailure(policy, account, time.Now())
+	}
+
+	if event == nil || event.EventType != constans.PIN_LOCK_EVENT_PERMANENT_BLOCK || account.LockedUntil != nil {
+		t.Errorf("event = %+v locked_until = %v, want PERMANENT_BLOCK without locked_until", event, account.LockedUntil)
+	}
+}
+
+// newTestLockout service di atas memoryRepository dengan satu akun ber-PIN 123456
+func newTestLockout(t *testing.T) (pinLockoutService, repositories.AccountRepository, models.Account) {
+	t.Helper()
+
+	hashedPIN, err := helpers.HashPIN("123456")
+	if err != nil {
+		t.Fatalf("HashPIN: %v", err)
+	}
+
+	store := memoryRepository.NewStore()
+	accountRepo := memoryRepository.NewAccountRepository(store)
+	id, err := accountRepo.AddAccount(models.Account{
+		AccountNumber: "1000000001",
+		AccountName:   "Nasabah 1000000001",
+		Balance:       models.NewMoney(0, constans.DEFAULT_CURRENCY),
+		PIN:           hashedPIN,
+	})
+	if err != nil {
+		t.Fatalf("AddAccount: %v", err)
+	}
+	account, _ := accountRepo.FindAccountById(id)
+
+	svc := NewPINLockoutService(services.NewUsecaseService(store.DB(),
+		accountRepo,
+		nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+		config.Default(),
+	))
+	return svc, accountRepo, account
+}
+
+// Snapshot yang dimuat sebelum akun terkunci tidak boleh lolos dengan PIN benar
+// dan tidak boleh membuka kunci
+func TestVerifyPINRechecksLockedRow(t *testing.T) {
+	svc, accountRepo, snapshot := newTestLockout(t)
+
+	lockedUntil := time.Now().Add(15 * time.Minute)
+	locked := snapshot
+	locked.PINLockoutCount = 1
+	locked.LockedUntil = &lockedUntil
+	if err := accountRepo.UpdatePINLockStateWithTx(nil, locked); err != nil {
+		t.Fatalf("UpdatePINLockStateWithTx: %v", err)
+	}
+
+	if pinErr := svc.VerifyPIN(snapshot, "123456"); pinErr == nil || pinErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("VerifyPIN on stale snapshot = %v, want 403", pinErr)
+	}
+
+	account, _ := accountRepo.FindAccountByNumber("1000000001")
+	if account.LockedUntil == nil || account.PINLockoutCount != 1 {
+		t.Errorf("lock state = %v/%d, want lock kept", account.LockedUntil, account.PINLockoutCount)
+	}
+}
+
+// Tebakan paralel berjalan serial: setelah MaxAttempts gagal akun terkunci dan PIN benar
+// di burst yang sama ikut ditolak
+func TestVerifyPINConcurrentGuesses(t *testing.T) {
+	svc, accountRepo, snapshot := newTestLockout(t)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		statuses = map[int]int{}
+	)
+	guesses := []string{"000001", "000002", "000003", "000004", "000005", "000006", "000007", "000008"}
+	for _, pin := range guesses {
+		wg.Add(1)
+		go func(pin string) {
+			defer wg.Done()
+			status := http.StatusOK
+			if pinErr := svc.VerifyPIN(snapshot, pin); pinErr != nil {
+				status = pinErr.StatusCode
+			}
+			mu.Lock()
+			statuses[status]++
+			mu.Unlock()
+		}(pin)
+	}
+	wg.Wait()
+
+	// MaxAttempts-1 ditolak 401, sisanya 403 karena akun sudah terkunci
+	if statuses[http.StatusUnauthorized] != 2 || statuses[http.StatusForbidden] != len(guesses)-2 {
+		t.Errorf("statuses = %v, want 2x401 and %dx403", statuses, len(guesses)-2)
+	}
+
+	if pinErr := svc.VerifyPIN(snapshot, "123456"); pinErr == nil || pinErr.StatusCode != http.StatusForbidden {
+		t.Errorf("correct PIN after burst = %v, want 403", pinErr)
+	}
+
+	account, _ := accountRepo.FindAccountByNumber("1000000001")
+	if account.PINLockoutCount != 1 || account.LockedUntil == nil {
+		t.Errorf("lock state = %v/%d, want one temporary lock", account.LockedUntil, account.PINLockoutCount)
+	}
+	events, _ := accountRepo.GetPINLockEvents("1000000001")
+	if len(events) != 1 {
+		t.Errorf("lock events = %d, want 1", len(events))
+	}
+}
@@ -0,0 +1,320 @@
+package reversalService
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sample/constans"
+	"sample/helpers"
+	"sample/models"
+	"sample/services"
+	"sample/services/webhookService"
+	"sample/utils"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+type reversalService struct {
+	Service services.UsecaseService
+}
+
+// NewReversalService
+func NewReversalService(service services.UsecaseService) reversalService {
+	return reversalService{
+		Service: service,
+	}
+}
+
+// ReverseTransaction membatalkan transaksi yang sudah diposting dengan jurnal kompensasi.
+// Amount kosong berarti reversal penuh atas sisa nominal, amount diisi berarti refund sebagian.
+// Total reversal tidak boleh melebihi nominal transaksi asal
+func (svc reversalService) ReverseTransaction(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result      models.Response
+		serviceName = "ReversalService.ReverseTransaction"
+		request     = new(models.RequestReversal)
+
+		transactionTime = time.Now()
+		updatedAt       = transactionTime.Format(constans.LAYOUT_TIMESTAMP)
+		referenceNo     = utils.GenerateReferenceNo()
+
+		amount, totalReversed, remaining models.Money
+		feeRefunded                      models.Money
+		entries                          []models.ReversalEntry
+		response                         models.ReversalResponse
+	)
+
+	if err := helpers.BindValidateStruct(ctx, request); err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "BindValidateStruct", err)
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	utils.LogInfo(serviceName, referenceNo, "Request received",
+		fmt.Sprintf("TransactionID: %d, Amount: %s, Reason: %s", request.TransactionID, request.Amount, request.Reason))
+
+	original, err := svc.Service.TransactionRepo.FindTransactionById(request.TransactionID)
+	if err != nil {
+		utils.LogError(serviceName, referenceNo, "FindTransactionById", err)
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Transaction not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+
+	if original.ReversalOf != 0 {
+		utils.LogError(serviceName, referenceNo, "ValidateOriginal",
+			fmt.Errorf("Transaction %d is a reversal of %d", original.ID, original.ReversalOf))
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "A reversal transaction cannot be reversed", nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	if original.Status != constans.TRANSACTION_STATUS_SUCCESS {
+		utils.LogError(serviceName, referenceNo, "ValidateOriginal",
+			fmt.Errorf("Transaction %d status is %s", original.ID, original.Status))
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Only successful transactions can be reversed. Current status: "+original.Status, nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	if original.JournalID == 0 {
+		utils.LogError(serviceName, referenceNo, "ValidateOriginal",
+			fmt.Errorf("Transaction %d has no ledger journal", original.ID))
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Transaction has no ledger journal and cannot be reversed", nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	journal, err := svc.Service.LedgerRepo.FindJournalById(original.JournalID)
+	if err != nil {
+		utils.LogError(serviceName, referenceNo, "FindJournalById", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to load original journal", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	// Reversal mengembalikan saldo utama, konversi valas tidak bisa dibalik dengan kurs lama
+	if journal.JournalType == constans.JOURNAL_TYPE_FX_CONVERSION {
+		utils.LogError(serviceName, referenceNo, "ValidateJournalType",
+			fmt.Errorf("Journal %d is %s", journal.ID, journal.JournalType))
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Currency conversion cannot be reversed", nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	err = utils.DBTransaction(svc.Service.RepoDB, func(tx *sql.Tx) error {
+		// Kunci semua baris jurnal asal urut id (transfer punya baris pengirim dan penerima),
+		// lalu ambil baris target dari hasilnya. Reversal yang dimulai dari baris debit maupun
+		// kredit transfer yang sama mengunci dengan urutan sama sehingga tidak deadlock
+		rows, err := svc.Service.TransactionRepo.FindTransactionsByJournalId(original.JournalID, tx)
+		if err != nil {
+			return err
+		}
+
+		var locked models.Transaction
+		for _, row := range rows {
+			if row.ID == original.ID {
+				locked = row
+			}
+		}
+		if locked.ID == 0 {
+			return fmt.Errorf("transaction %d not found in journal %d", original.ID, original.JournalID)
+		}
+
+		if locked.Status != constans.TRANSACTION_STATUS_SUCCESS {
+			return &utils.TransactionError{
+				Code:    constans.REVERSAL_LIMIT_EXCEEDED_CODE,
+				Message: "Transaction is already " + locked.Status,
+			}
+		}
+
+		reversed, err := svc.Service.TransactionRepo.GetReversedAmount(locked.ID, tx)
+		if err != nil {
+			return err
+		}
+
+		remaining = locked.Amount.Sub(reversed)
+		if !remaining.IsPositive() {
+			return &utils.TransactionError{
+				Code:    constans.REVERSAL_LIMIT_EXCEEDED_CODE,
+				Message: "Transaction has already been fully reversed",
+			}
+		}
+
+		amount = request.Amount
+		if amount.IsZero() {
+			amount = remaining
+		}
+
+		if remaining.LessThan(amount) {
+			return &utils.TransactionError{
+				Code:    constans.REVERSAL_LIMIT_EXCEEDED_CODE,
+				Message: fmt.Sprintf("Reversal amount exceeds remaining reversible amount %s", remaining),
+			}
+		}
+
+		// Biaya transaksi asal (FEE_INCOME) dikembalikan saat reversal penuh, termasuk refund
+		// sebagian yang menghabiskan sisa nominal. Refund sebagian lainnya tidak mengembalikan biaya
+		fullReversal := remaining.Cmp(amount) == 0
+
+		var postings []models.Posting
+		for _, row := range rows {
+			// Arah kebalikan dari transaksi asal
+			operator, direction := "+", "C"
+			if row.TransactionType == "C" {
+				operator, direction = "-", "D"
+			}
+
+			lastBalance, err := svc.Service.AccountRepo.IncrementDecrementLastBalance(row.AccountID, amount, operator, updatedAt, tx)
+			if err != nil {
+				return err
+			}
+
+			if lastBalance.IsNegative() {
+				return &utils.TransactionError{
+					Code:    constans.ACCOUNT_BALANCE_BELOW_MINIMUM_CODE,
+					Message: fmt.Sprintf("Account %s balance below minimum", row.AccountNumber),
+				}
+			}
+
+			balanceBefore := lastBalance.Add(amount)
+			if operator == "+" {
+				balanceBefore = lastBalance.Sub(amount)
+			}
+
+			postings = append(postings, models.Posting{LedgerCode: row.AccountNumber, Direction: direction, Amount: amount})
+
+			var fee models.Money
+			if fullReversal && row.Fee.IsPositive() {
+				fee = row.Fee
+				lastBalance, err = svc.Service.AccountRepo.IncrementDecrementLastBalance(row.AccountID, fee, "+", updatedAt, tx)
+				if err != nil {
+					return err
+				}
+
+				postings = append(postings,
+					models.Posting{LedgerCode: constans.LEDGER_FEE_INCOME, Direction: "D", Amount: fee},
+					models.Posting{LedgerCode: row.AccountNumber, Direction: "C", Amount: fee},
+				)
+				if feeRefunded.IsZero() {
+					feeRefunded = fee
+				} else {
+					feeRefunded = feeRefunded.Add(fee)
+				}
+			}
+
+			entries = append(entries, models.ReversalEntry{
+				ReversalOf:      row.ID,
+				AccountNumber:   row.AccountNumber,
+				TransactionType: direction,
+				Amount:          amount,
+				FeeRefunded:     fee,
+				BalanceBefore:   balanceBefore,
+				BalanceAfter:    lastBalance,
+			})
+		}
+
+		// Setor/tarik tunai hanya punya satu baris nasabah, lawannya CASH_VAULT
+		if len(rows) == 1 {
+			postings = append(postings, models.Posting{LedgerCode: constans.LEDGER_CASH_VAULT, Direction: rows[0].TransactionType, Amount: amount})
+		}
+
+		journalID, err := svc.Service.LedgerRepo.PostJournal(models.JournalEntry{
+			ReferenceNo: referenceNo,
+			JournalType: constans.JOURNAL_TYPE_REVERSAL,
+			Description: fmt.Sprintf("Reversal %s: %s", journal.ReferenceNo, request.Reason),
+			ReversalOf:  journal.ID,
+			Postings:    postings,
+		}, tx)
+		if err != nil {
+			return err
+		}
+
+		webhookSvc := webhookService.NewWebhookService(svc.Service)
+
+		for i, row := range rows {
+			transaction := models.Transaction{
+				AccountID:         row.AccountID,
+				AccountNumber:     row.AccountNumber,
+				AccountName:       row.AccountName,
+				SourceNumber:      row.BeneficiaryNumber,
+				BeneficiaryNumber: row.SourceNumber,
+				TransactionType:   entries[i].TransactionType,
+				Amount:            amount,
+				JournalID:         journalID,
+				ReversalOf:        row.ID,
+				TransactionTime:   transactionTime,
+			}
+
+			// Biaya yang dikembalikan dicatat sebagai fee negatif pada baris reversal, sehingga
+			// BalanceEffect, saldo berjalan mutasi rekening dan GetBalanceBefore ikut menghitungnya
+			if entries[i].FeeRefunded.IsPositive() {
+				transaction.Fee = models.NewMoney(0, entries[i].FeeRefunded.CurrencyCode()).Sub(entries[i].FeeRefunded)
+			}
+
+			transaction.ID, err = svc.Service.TransactionRepo.AddTransaction(transaction, tx)
+			if err != nil {
+				return err
+			}
+			entries[i].TransactionID = transaction.ID
+
+			if err := webhookSvc.PublishTransactionCreated(tx, transaction); err != nil {
+				return err
+			}
+		}
+
+		totalReversed = reversed.Add(amount)
+		remaining = remaining.Sub(amount)
+
+		// Reversal penuh: semua baris jurnal asal menjadi REVERSED, refund sebagian tetap SUCCESS
+		if remaining.IsZero() {
+			for _, row := range rows {
+				if err := svc.Service.TransactionRepo.UpdateTransactionStatus(row.ID, row.Status, constans.TRANSACTION_STATUS_REVERSED, tx); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		if txErr, ok := err.(*utils.TransactionError); ok {
+			utils.LogError(serviceName, referenceNo, "DBTransaction", fmt.Errorf("%s", txErr.Message))
+			result = helpers.ResponseJSON(false, txErr.Code, txErr.Message, nil)
+			if txErr.Code == constans.REVERSAL_LIMIT_EXCEEDED_CODE {
+				return ctx.JSON(http.StatusConflict, result)
+			}
+			return ctx.JSON(http.StatusBadRequest, result)
+		}
+
+		utils.LogError(serviceName, referenceNo, "DBTransaction", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Reversal failed: "+err.Error(), nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	utils.LogInfo(serviceName, referenceNo, "Success",
+		fmt.Sprintf("Original: %s, Amount: %s, Fee Refunded: %s, Total Reversed: %s, Remaining: %s",
+			journal.ReferenceNo, amount, feeRefunded, totalReversed, remaining))
+
+	response = models.ReversalResponse{
+		ReferenceNo:         referenceNo,
+		ReversalOf:          original.ID,
+		OriginalReferenceNo: journal.ReferenceNo,
+		JournalType:         journal.JournalType,
+		Amount:              amount,
+		TotalReversed:       totalReversed,
+		RemainingAmount:     remaining,
+		FeeRefunded:         feeRefunded,
+		Currency:            amount.CurrencyCode(),
+		Reason:              request.Reason,
+		Entries:             entries,
+		TransactionDate:     updatedAt,
+	}
+
+	changes := make([]models.AuditBalanceChange, len(entries))
+	for i, entry := range entries {
+		changes[i] = models.AuditBalanceChange{AccountNumber: entry.AccountNumber, Before: entry.BalanceBefore, After: entry.BalanceAfter}
+	}
+	helpers.AuditAccount(ctx, original.AccountNumber)
+	helpers.AuditBalanceChange(ctx, referenceNo, changes...)
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Reversal successful", response)
+	return ctx.JSON(http.StatusOK, result)
+}
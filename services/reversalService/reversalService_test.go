@@ -0,0 +1,174 @@
+package reversalService
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sample/config"
+	"sample/constans"
+	"sample/helpers"
+	"sample/models"
+	"sample/repositories"
+	"sample/repositories/memoryRepository"
+	"sample/services"
+	"sample/services/statementService"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/labstack/echo"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+type testValidator struct {
+	validator *validator.Validate
+}
+
+func (v testValidator) Validate(i interface{}) error {
+	return v.validator.Struct(i)
+}
+
+// ledgerRepo menyimpan jurnal agar reversal bisa membaca jurnal asal
+type ledgerRepo struct {
+	repositories.LedgerRepository
+	mu       sync.Mutex
+	journals []models.JournalEntry
+}
+
+func (r *ledgerRepo) PostJournal(journal models.JournalEntry, tx *sql.Tx) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	journal.ID = len(r.journals) + 1
+	r.journals = append(r.journals, journal)
+	return journal.ID, nil
+}
+
+func (r *ledgerRepo) FindJournalById(id int) (models.JournalEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id < 1 || id > len(r.journals) {
+		return models.JournalEntry{}, sql.ErrNoRows
+	}
+	return r.journals[id-1], nil
+}
+
+// webhookRepo outbox yang selalu berhasil
+type webhookRepo struct {
+	repositories.WebhookRepository
+}
+
+func (webhookRepo) AddOutboxEvent(event models.WebhookEvent, tx *sql.Tx) (int, error) {
+	return 1, nil
+}
+
+// Reversal penuh atas penarikan berbiaya: saldo akhir mutasi rekening harus sama dengan
+// saldo rekening, termasuk biaya yang dikembalikan
+func TestFullReversalStatementMatchesBalance(t *testing.T) {
+	store := memoryRepository.NewStore()
+	accountRepo := memoryRepository.NewAccountRepository(store)
+	transactionRepo := memoryRepository.NewTransactionRepository(store)
+	ledger := &ledgerRepo{}
+
+	service := services.NewUsecaseService(store.DB(),
+		accountRepo,
+		transactionRepo,
+		ledger,
+		nil, nil, nil, nil, nil,
+		webhookRepo{},
+		nil, nil,
+		config.Default(),
+	)
+
+	e := echo.New()
+	validate := validator.New()
+	locale := id.New()
+	trans, _ := ut.New(locale, locale).GetTranslator("id")
+	helpers.RegisterMoneyValidation(validate, trans)
+	e.Validator = testValidator{validator: validate}
+
+	// Penarikan 40.000 dengan biaya 2.500 dari saldo 100.000
+	accountID, err := accountRepo.AddAccount(models.Account{
+		AccountNumber: "1000000001",
+		AccountName:   "Nasabah 1000000001",
+		Balance:       models.MustParseMoney("57500", constans.DEFAULT_CURRENCY),
+	})
+	if err != nil {
+		t.Fatalf("AddAccount: %v", err)
+	}
+
+	amount := models.MustParseMoney("40000", constans.DEFAULT_CURRENCY)
+	fee := models.MustParseMoney("2500", constans.DEFAULT_CURRENCY)
+	journalID, _ := ledger.PostJournal(models.JournalEntry{
+		ReferenceNo: "WD-1",
+		JournalType: constans.JOURNAL_TYPE_WITHDRAW,
+		Postings: []models.Posting{
+			{LedgerCode: "1000000001", Direction: "D", Amount: amount.Add(fee)},
+			{LedgerCode: constans.LEDGER_CASH_VAULT, Direction: "C", Amount: amount},
+			{LedgerCode: constans.LEDGER_FEE_INCOME, Direction: "C", Amount: fee},
+		},
+	}, nil)
+	transactionID, err := transactionRepo.AddTransaction(models.Transaction{
+		AccountID:       accountID,
+		AccountNumber:   "1000000001",
+		TransactionType: "D",
+		Amount:          amount,
+		Fee:             fee,
+		JournalID:       journalID,
+		TransactionTime: time.Now(),
+	}, nil)
+	if err != nil {
+		t.Fatalf("AddTransaction: %v", err)
+	}
+
+	call := func(handler echo.HandlerFunc, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		ctx := e.NewContext(req, rec)
+		ctx.Set(constans.CONTEXT_ACCOUNT_NUMBER, "1000000001")
+		if err := handler(ctx); err != nil {
+			t.Fatalf("handler error: %v", err)
+		}
+		return rec
+	}
+
+	rec := call(NewReversalService(service).ReverseTransaction, fmt.Sprintf(`{"transaction_id":%d,"reason":"customer dispute"}`, transactionID))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("reversal status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	account, _ := accountRepo.FindAccountByNumber("1000000001")
+	if account.Balance.String() != "100000.00" {
+		t.Fatalf("balance after reversal = %s, want 100000.00", account.Balance)
+	}
+
+	today := time.Now().Format(constans.LAYOUT_DATE)
+	rec = call(statementService.NewStatementService(service).DownloadStatement,
+		fmt.Sprintf(`{"start_date":"%s","end_date":"%s","format":"csv"}`, today, today))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("statement status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	values := map[string]string{}
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		if fields := strings.Split(scanner.Text(), ","); len(fields) == 2 {
+			values[fields[0]] = fields[1]
+		}
+	}
+
+	if values["Opening Balance"] != "100000.00" || values["Closing Balance"] != account.Balance.String() {
+		t.Errorf("opening/closing = %s/%s, want 100000.00/%s", values["Opening Balance"], values["Closing Balance"], account.Balance)
+	}
+	if values["Total Fee"] != "0.00" {
+		t.Errorf("total fee = %s, want 0.00", values["Total Fee"])
+	}
+}
@@ -0,0 +1,407 @@
+package scheduledTransferService
+
+import (
+	"fmt"
+	"net/http"
+	"sample/constans"
+	"sample/helpers"
+	"sample/models"
+	"sample/services"
+	"sample/services/pinLockoutService"
+	"sample/utils"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+type scheduledTransferService struct {
+	Service services.UsecaseService
+}
+
+// NewScheduledTransferService
+func NewScheduledTransferService(service services.UsecaseService) scheduledTransferService {
+	return scheduledTransferService{
+		Service: service,
+	}
+}
+
+// CreateScheduledTransfer membuat transfer terjadwal (sekali jalan atau berulang).
+// PIN diverifikasi saat pembuatan, eksekusi berikutnya dijalankan worker tanpa PIN.
+// Nominal di atas batas OTP step-up ditolak karena worker tidak bisa meminta OTP
+func (svc scheduledTransferService) CreateScheduledTransfer(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result      models.Response
+		serviceName = "ScheduledTransferService.CreateScheduledTransfer"
+		request     = new(models.RequestCreateScheduledTransfer)
+	)
+
+	if err := helpers.BindValidateStruct(ctx, request); err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "BindValidateStruct", err)
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	// Subject selalu dari token, bukan dari body request
+	request.FromAccountNumber = helpers.GetAccountNumber(ctx)
+
+	utils.LogInfo(serviceName, request.FromAccountNumber, "Request received",
+		fmt.Sprintf("To: %s, Amount: %s, Frequency: %s, Start: %s, End: %s",
+			request.ToAccountNumber, request.Amount, request.Frequency, request.StartDate, request.EndDate))
+
+	if request.FromAccountNumber == request.ToAccountNumber {
+		utils.LogError(serviceName, request.FromAccountNumber, "ValidateSameAccount",
+			fmt.Errorf("Cannot transfer to same account"))
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Cannot transfer to same account", nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	if svc.Service.Config.StepUp.Required(request.Amount) {
+		return stepUpRequiredResponse(ctx, serviceName, request.FromAccountNumber, request.Amount)
+	}
+
+	startDate, endDate, err := parseScheduleDates(request.Frequency, request.StartDate, request.EndDate)
+	if err != nil {
+		utils.LogError(serviceName, request.FromAccountNumber, "ParseScheduleDates", err)
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	fromAccount, err := svc.Service.AccountRepo.FindAccountByNumber(request.FromAccountNumber)
+	if err != nil {
+		utils.LogError(serviceName, request.FromAccountNumber, "FindSourceAccount", err)
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Source account not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+
+	// Cek blokir dan verifikasi PIN sesuai kebijakan lockout
+	if pinErr := pinLockoutService.NewPINLockoutService(svc.Service).VerifyPIN(fromAccount, request.PIN); pinErr != nil {
+		utils.LogError(serviceName, request.FromAccountNumber, "VerifyPIN", pinErr)
+		result = helpers.ResponseJSON(false, pinErr.Code, pinErr.Message, nil)
+		return ctx.JSON(pinErr.StatusCode, result)
+	}
+
+	if _, err := svc.Service.AccountRepo.FindAccountByNumber(request.ToAccountNumber); err != nil {
+		utils.LogError(serviceName, request.FromAccountNumber, "FindBeneficiaryAccount", err,
+			fmt.Sprintf("Beneficiary account: %s", request.ToAccountNumber))
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Beneficiary account not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+
+	id, err := svc.Service.ScheduledTransferRepo.AddScheduledTransfer(models.ScheduledTransfer{
+		AccountID:         fromAccount.ID,
+		FromAccountNumber: fromAccount.AccountNumber,
+		ToAccountNumber:   request.ToAccountNumber,
+		Amount:            request.Amount,
+		Frequency:         request.Frequency,
+		Description:       request.Description,
+		StartDate:         startDate,
+		EndDate:           endDate,
+		NextRunAt:         startDate,
+	})
+	if err != nil {
+		utils.LogError(serviceName, request.FromAccountNumber, "AddScheduledTransfer", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to create scheduled transfer", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	schedule, err := svc.Service.ScheduledTransferRepo.FindScheduledTransferById(id)
+	if err != nil {
+		utils.LogError(serviceName, request.FromAccountNumber, "FindScheduledTransferById", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to load scheduled transfer", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	utils.LogInfo(serviceName, request.FromAccountNumber, "Success",
+		fmt.Sprintf("Schedule ID: %d, Next run: %s", schedule.ID, schedule.NextRunAt.Format(constans.LAYOUT_DATE)))
+	helpers.AuditAfter(ctx, schedule)
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Scheduled transfer created successfully", schedule)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// GetScheduledTransferList daftar transfer terjadwal milik nasabah
+func (svc scheduledTransferService) GetScheduledTransferList(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result        models.Response
+		serviceName   = "ScheduledTransferService.GetScheduledTransferList"
+		accountNumber = helpers.GetAccountNumber(ctx)
+	)
+
+	utils.LogInfo(serviceName, accountNumber, "Request received")
+
+	schedules, err := svc.Service.ScheduledTransferRepo.GetScheduledTransfersByAccount(accountNumber)
+	if err != nil {
+		utils.LogError(serviceName, accountNumber, "GetScheduledTransfersByAccount", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to get scheduled transfers", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	if schedules == nil {
+		schedules = []models.ScheduledTransfer{}
+	}
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Scheduled transfers retrieved successfully", schedules)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// GetScheduledTransferDetail detail jadwal beserta riwayat eksekusinya
+func (svc scheduledTransferService) GetScheduledTransferDetail(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result      models.Response
+		serviceName = "ScheduledTransferService.GetScheduledTransferDetail"
+		request     = new(models.RequestScheduledTransferByID)
+	)
+
+	if err := helpers.BindValidateStruct(ctx, request); err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "BindValidateStruct", err)
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	schedule, ok := svc.findOwnedSchedule(ctx, serviceName, request.ID)
+	if !ok {
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Scheduled transfer not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+
+	executions, err := svc.Service.ScheduledTransferRepo.GetScheduledTransferExecutions(schedule.ID)
+	if err != nil {
+		utils.LogError(serviceName, schedule.FromAccountNumber, "GetScheduledTransferExecutions", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to get executions", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	if executions == nil {
+		executions = []models.ScheduledTransferExecution{}
+	}
+
+	response := models.ScheduledTransferDetailResponse{
+		Schedule:   schedule,
+		Executions: executions,
+	}
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Scheduled transfer retrieved successfully", response)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// UpdateScheduledTransfer ubah nominal, tanggal akhir, atau pause/resume jadwal.
+// Ubah nominal wajib PIN pemilik dan tetap tunduk pada batas OTP step-up seperti saat pembuatan.
+// Jadwal yang sedang dieksekusi worker atau berubah sejak dibaca ditolak 409
+func (svc scheduledTransferService) UpdateScheduledTransfer(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result      models.Response
+		serviceName = "ScheduledTransferService.UpdateScheduledTransfer"
+		request     = new(models.RequestUpdateScheduledTransfer)
+	)
+
+	if err := helpers.BindValidateStruct(ctx, request); err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "BindValidateStruct", err)
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	schedule, ok := svc.findOwnedSchedule(ctx, serviceName, request.ID)
+	if !ok {
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Scheduled transfer not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+
+	utils.LogInfo(serviceName, schedule.FromAccountNumber, "Request received",
+		fmt.Sprintf("Schedule ID: %d, Amount: %s, End: %s, Status: %s", request.ID, request.Amount, request.EndDate, request.Status))
+	helpers.AuditBefore(ctx, schedule)
+
+	if schedule.Status != constans.SCHEDULE_STATUS_ACTIVE && schedule.Status != constans.SCHEDULE_STATUS_PAUSED {
+		utils.LogError(serviceName, schedule.FromAccountNumber, "ValidateStatus",
+			fmt.Errorf("Schedule %d is %s", schedule.ID, schedule.Status))
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Scheduled transfer is already "+schedule.Status, nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	if schedule.LockedUntil != nil && time.Now().Before(*schedule.LockedUntil) {
+		utils.LogError(serviceName, schedule.FromAccountNumber, "ValidateLease",
+			fmt.Errorf("Schedule %d is leased by %s", schedule.ID, schedule.LockedBy))
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, scheduleBusyMessage, nil)
+		return ctx.JSON(http.StatusConflict, result)
+	}
+	previous := schedule
+
+	if !request.Amount.IsZero() && request.Amount.Cmp(schedule.Amount) != 0 {
+		if svc.Service.Config.StepUp.Required(request.Amount) {
+			return stepUpRequiredResponse(ctx, serviceName, schedule.FromAccountNumber, request.Amount)
+		}
+
+		if request.PIN == "" {
+			utils.LogError(serviceName, schedule.FromAccountNumber, "ValidatePIN", fmt.Errorf("PIN is required to change amount"))
+			result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "PIN is required to change amount", nil)
+			return ctx.JSON(http.StatusBadRequest, result)
+		}
+
+		account, err := svc.Service.AccountRepo.FindAccountByNumber(schedule.FromAccountNumber)
+		if err != nil {
+			utils.LogError(serviceName, schedule.FromAccountNumber, "FindSourceAccount", err)
+			result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Source account not found", nil)
+			return ctx.JSON(http.StatusNotFound, result)
+		}
+
+		// Cek blokir dan verifikasi PIN sesuai kebijakan lockout
+		if pinErr := pinLockoutService.NewPINLockoutService(svc.Service).VerifyPIN(account, request.PIN); pinErr != nil {
+			utils.LogError(serviceName, schedule.FromAccountNumber, "VerifyPIN", pinErr)
+			result = helpers.ResponseJSON(false, pinErr.Code, pinErr.Message, nil)
+			return ctx.JSON(pinErr.StatusCode, result)
+		}
+
+		schedule.Amount = request.Amount
+	}
+
+	if request.EndDate != "" {
+		if schedule.Frequency == constans.SCHEDULE_FREQUENCY_ONCE {
+			result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "End date is not allowed for one-off transfer", nil)
+			return ctx.JSON(http.StatusBadRequest, result)
+		}
+
+		endDate, err := time.ParseInLocation(constans.LAYOUT_DATE, request.EndDate, time.Local)
+		if err != nil {
+			result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Invalid end_date format. Use YYYY-MM-DD", nil)
+			return ctx.JSON(http.StatusBadRequest, result)
+		}
+
+		if endDate.Before(schedule.NextRunAt) {
+			result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE,
+				"End date must not be before next run date "+schedule.NextRunAt.Format(constans.LAYOUT_DATE), nil)
+			return ctx.JSON(http.StatusBadRequest, result)
+		}
+		schedule.EndDate = &endDate
+	}
+
+	if request.Status != "" && request.Status != schedule.Status {
+		// Resume: occurrence yang terlewat selama pause tidak dieksekusi
+		if request.Status == constans.SCHEDULE_STATUS_ACTIVE && schedule.Frequency != constans.SCHEDULE_FREQUENCY_ONCE {
+			schedule = skipMissedOccurrences(schedule, time.Now())
+			schedule.RetryAt = nil
+			schedule.RetryCount = 0
+		}
+		schedule.Status = request.Status
+	}
+
+	if err := svc.Service.ScheduledTransferRepo.UpdateScheduledTransfer(schedule, previous); err != nil {
+		utils.LogError(serviceName, schedule.FromAccountNumber, "UpdateScheduledTransfer", err)
+		if err.Error() == "Scheduled transfer was modified or is being processed" {
+			result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, scheduleBusyMessage, nil)
+			return ctx.JSON(http.StatusConflict, result)
+		}
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to update scheduled transfer", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	utils.LogInfo(serviceName, schedule.FromAccountNumber, "Success",
+		fmt.Sprintf("Schedule ID: %d, Status: %s", schedule.ID, schedule.Status))
+	helpers.AuditAfter(ctx, schedule)
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Scheduled transfer updated successfully", schedule)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// CancelScheduledTransfer membatalkan jadwal, eksekusi yang sudah terjadi tidak terpengaruh
+func (svc scheduledTransferService) CancelScheduledTransfer(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result      models.Response
+		serviceName = "ScheduledTransferService.CancelScheduledTransfer"
+		request     = new(models.RequestScheduledTransferByID)
+	)
+
+	if err := helpers.BindValidateStruct(ctx, request); err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "BindValidateStruct", err)
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	schedule, ok := svc.findOwnedSchedule(ctx, serviceName, request.ID)
+	if !ok {
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Scheduled transfer not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+	helpers.AuditBefore(ctx, schedule)
+
+	if err := svc.Service.ScheduledTransferRepo.RemoveScheduledTransfer(schedule.ID); err != nil {
+		utils.LogError(serviceName, schedule.FromAccountNumber, "RemoveScheduledTransfer", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to cancel scheduled transfer", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	utils.LogInfo(serviceName, schedule.FromAccountNumber, "Success", fmt.Sprintf("Schedule ID: %d cancelled", schedule.ID))
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Scheduled transfer cancelled successfully", nil)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// findOwnedSchedule mengambil jadwal dan memastikan milik subject token
+func (svc scheduledTransferService) findOwnedSchedule(ctx echo.Context, serviceName string, id int) (models.ScheduledTransfer, bool) {
+	schedule, err := svc.Service.ScheduledTransferRepo.FindScheduledTransferById(id)
+	if err != nil {
+		utils.LogError(serviceName, fmt.Sprintf("%d", id), "FindScheduledTransferById", err)
+		return models.ScheduledTransfer{}, false
+	}
+
+	if schedule.FromAccountNumber != helpers.GetAccountNumber(ctx) {
+		utils.LogError(serviceName, fmt.Sprintf("%d", id), "CheckOwnership",
+			fmt.Errorf("Scheduled transfer does not belong to token subject"))
+		return models.ScheduledTransfer{}, false
+	}
+
+	return schedule, true
+}
+
+// scheduleBusyMessage jadwal sedang dieksekusi worker atau berubah saat update berjalan
+const scheduleBusyMessage = "Scheduled transfer is being processed. Please try again shortly"
+
+// stepUpRequiredResponse tolak nominal yang butuh OTP, transfer sebesar itu harus lewat /transaction/transfer
+func stepUpRequiredResponse(ctx echo.Context, serviceName, accountNumber string, amount models.Money) error {
+	utils.LogError(serviceName, accountNumber, "CheckStepUpThreshold",
+		fmt.Errorf("Amount %s requires OTP confirmation", amount))
+	result := helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE,
+		"Amount requires OTP confirmation and cannot be scheduled. Use transfer instead", nil)
+	return ctx.JSON(http.StatusBadRequest, result)
+}
+
+// parseScheduleDates validasi start_date dan end_date sesuai frekuensi
+func parseScheduleDates(frequency, start, end string) (time.Time, *time.Time, error) {
+	startDate, err := time.ParseInLocation(constans.LAYOUT_DATE, start, time.Local)
+	if err != nil {
+		return time.Time{}, nil, fmt.Errorf("Invalid start_date format. Use YYYY-MM-DD")
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if startDate.Before(today) {
+		return time.Time{}, nil, fmt.Errorf("Start date must not be in the past")
+	}
+
+	if end == "" {
+		return startDate, nil, nil
+	}
+
+	if frequency == constans.SCHEDULE_FREQUENCY_ONCE {
+		return time.Time{}, nil, fmt.Errorf("End date is not allowed for one-off transfer")
+	}
+
+	endDate, err := time.ParseInLocation(constans.LAYOUT_DATE, end, time.Local)
+	if err != nil {
+		return time.Time{}, nil, fmt.Errorf("Invalid end_date format. Use YYYY-MM-DD")
+	}
+
+	if endDate.Before(startDate) {
+		return time.Time{}, nil, fmt.Errorf("End date must not be before start date")
+	}
+
+	return startDate, &endDate, nil
+}
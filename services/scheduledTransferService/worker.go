@@ -0,0 +1,302 @@
+package scheduledTransferService
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"sample/constans"
+	"sample/models"
+	"sample/notification"
+	"sample/services"
+	"sample/services/pinLockoutService"
+	"sample/services/transactionService"
+	"sample/tracing"
+	"sample/utils"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type scheduledTransferWorker struct {
+	Service       services.UsecaseService
+	WorkerID      string
+	Interval      time.Duration
+	Lease         time.Duration
+	RetryInterval time.Duration
+	MaxRetry      int
+	BatchSize     int
+}
+
+// NewScheduledTransferWorker konfigurasi dari service.Config.Scheduler (SCHEDULER_*)
+func NewScheduledTransferWorker(service services.UsecaseService) scheduledTransferWorker {
+	hostname, _ := os.Hostname()
+	cfg := service.Config.Scheduler
+
+	return scheduledTransferWorker{
+		Service:       service,
+		WorkerID:      fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		Interval:      time.Duration(cfg.IntervalSeconds) * time.Second,
+		Lease:         5 * time.Minute,
+		RetryInterval: time.Duration(cfg.RetryIntervalMinutes) * time.Minute,
+		MaxRetry:      cfg.MaxRetry,
+		BatchSize:     cfg.BatchSize,
+	}
+}
+
+// Start menjalankan worker sampai ctx selesai. Aman dijalankan di banyak instance:
+// jadwal di-claim dengan lease (FOR UPDATE SKIP LOCKED) dan hasil eksekusi disimpan
+// dengan guard next_run_at di transaksi yang sama dengan transfer
+func (w scheduledTransferWorker) Start(ctx context.Context) {
+	serviceName := "ScheduledTransferWorker.Start"
+	utils.LogInfo(serviceName, w.WorkerID, "Started", fmt.Sprintf("Interval: %s", w.Interval))
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		w.RunDueSchedules()
+
+		select {
+		case <-ctx.Done():
+			utils.LogInfo(serviceName, w.WorkerID, "Stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDueSchedules claim dan eksekusi semua jadwal yang jatuh tempo
+func (w scheduledTransferWorker) RunDueSchedules() {
+	serviceName := "ScheduledTransferWorker.RunDueSchedules"
+
+	for {
+		schedules, err := w.Service.ScheduledTransferRepo.ClaimDueScheduledTransfers(w.WorkerID, w.BatchSize, w.Lease)
+		if err != nil {
+			utils.LogError(serviceName, w.WorkerID, "ClaimDueScheduledTransfers", err)
+			return
+		}
+
+		for _, schedule := range schedules {
+			w.execute(schedule)
+		}
+
+		if len(schedules) < w.BatchSize {
+			return
+		}
+	}
+}
+
+// execute menjalankan satu occurrence melalui logic transfer yang sama dengan endpoint transfer
+func (w scheduledTransferWorker) execute(schedule models.ScheduledTransfer) {
+	var (
+		serviceName = "ScheduledTransferWorker.Execute"
+		runAt       = schedule.NextRunAt
+		attempt     = schedule.RetryCount + 1
+		now         = time.Now()
+	)
+
+	// Satu trace per occurrence, repository dan Redis di bawahnya menjadi child span
+	spanCtx, span := tracing.Start(context.Background(), serviceName, attribute.Int("schedule.id", schedule.ID))
+	defer span.End()
+	w.Service = w.Service.WithContext(spanCtx)
+
+	utils.LogInfo(serviceName, schedule.FromAccountNumber, "Executing",
+		fmt.Sprintf("Schedule ID: %d, Occurrence: %s, Attempt: %d", schedule.ID, runAt.Format(constans.LAYOUT_DATE), attempt))
+
+	fromAccount, err := w.Service.AccountRepo.FindAccountByNumber(schedule.FromAccountNumber)
+	if err != nil {
+		w.fail(schedule, runAt, attempt, "Source account not found")
+		return
+	}
+
+	if pinLockoutService.IsLocked(fromAccount, now) {
+		w.fail(schedule, runAt, attempt, "Source account is blocked")
+		return
+	}
+
+	// Jadwal lama yang dibuat sebelum batas OTP berlaku tidak dieksekusi tanpa OTP
+	if w.Service.Config.StepUp.Required(schedule.Amount) {
+		w.fail(schedule, runAt, attempt, "Amount requires OTP confirmation")
+		return
+	}
+
+	_, err = transactionService.NewTransactionService(w.Service).ProcessTransfer(fromAccount, schedule.ToAccountNumber, schedule.Amount, nil,
+		func(tx *sql.Tx, response models.TransferResponse) error {
+			_, err := w.Service.ScheduledTransferRepo.AddScheduledTransferExecution(models.ScheduledTransferExecution{
+				ScheduleID:   schedule.ID,
+				ScheduledFor: runAt,
+				Attempt:      attempt,
+				Status:       constans.EXECUTION_STATUS_SUCCESS,
+				ReferenceNo:  response.ReferenceNo,
+				Message:      "Transfer successful",
+				ExecutedAt:   now,
+			}, tx)
+			if err != nil {
+				return err
+			}
+
+			next := advanceSchedule(schedule, now)
+			if next.Status == constans.SCHEDULE_STATUS_FAILED {
+				next.Status = constans.SCHEDULE_STATUS_COMPLETED
+			}
+			return w.Service.ScheduledTransferRepo.CompleteScheduledRunWithTx(tx, next, runAt)
+		})
+
+	if err == nil {
+		utils.LogInfo(serviceName, schedule.FromAccountNumber, "Success", fmt.Sprintf("Schedule ID: %d", schedule.ID))
+		return
+	}
+
+	txErr, ok := err.(*utils.TransactionError)
+	if !ok {
+		// Error sistem: transfer sudah rollback, lepas lease agar dicoba lagi tick berikutnya
+		utils.LogError(serviceName, schedule.FromAccountNumber, "ProcessTransfer", err, fmt.Sprintf("Schedule ID: %d", schedule.ID))
+		if err := w.Service.ScheduledTransferRepo.ReleaseScheduledTransfer(schedule.ID, w.WorkerID); err != nil {
+			utils.LogError(serviceName, schedule.FromAccountNumber, "ReleaseScheduledTransfer", err)
+		}
+		return
+	}
+
+	insufficient := txErr == transactionService.ErrInsufficientBalance || txErr.Code == constans.ACCOUNT_BALANCE_BELOW_MINIMUM_CODE
+	if insufficient && schedule.RetryCount < w.MaxRetry {
+		retryAt := now.Add(w.RetryInterval)
+		schedule.RetryAt = &retryAt
+		schedule.RetryCount++
+		schedule.LastRunAt = &now
+
+		message := fmt.Sprintf("%s, retry %d of %d at %s", txErr.Message, schedule.RetryCount, w.MaxRetry,
+			retryAt.Format(constans.LAYOUT_TIMESTAMP))
+		w.record(schedule, runAt, attempt, constans.EXECUTION_STATUS_RETRY, message)
+		return
+	}
+
+	w.fail(schedule, runAt, attempt, txErr.Message)
+}
+
+// fail mencatat occurrence gagal, memberi notifikasi ke nasabah dan lanjut ke occurrence berikutnya
+func (w scheduledTransferWorker) fail(schedule models.ScheduledTransfer, runAt time.Time, attempt int, message string) {
+	next := advanceSchedule(schedule, time.Now())
+	if w.record(next, runAt, attempt, constans.EXECUTION_STATUS_FAILED, message) {
+		w.notifyScheduleFailure(schedule, runAt, message)
+	}
+}
+
+// record menyimpan hasil eksekusi dan state jadwal dalam satu transaksi
+func (w scheduledTransferWorker) record(schedule models.ScheduledTransfer, runAt time.Time, attempt int, status, message string) bool {
+	serviceName := "ScheduledTransferWorker.Record"
+	now := time.Now()
+	schedule.LastRunAt = &now
+
+	err := utils.DBTransaction(w.Service.RepoDB, func(tx *sql.Tx) error {
+		_, err := w.Service.ScheduledTransferRepo.AddScheduledTransferExecution(models.ScheduledTransferExecution{
+			ScheduleID:   schedule.ID,
+			ScheduledFor: runAt,
+			Attempt:      attempt,
+			Status:       status,
+			Message:      message,
+			ExecutedAt:   now,
+		}, tx)
+		if err != nil {
+			return err
+		}
+
+		return w.Service.ScheduledTransferRepo.CompleteScheduledRunWithTx(tx, schedule, runAt)
+	})
+	if err != nil {
+		utils.LogError(serviceName, schedule.FromAccountNumber, "DBTransaction", err, fmt.Sprintf("Schedule ID: %d", schedule.ID))
+		if err := w.Service.ScheduledTransferRepo.ReleaseScheduledTransfer(schedule.ID, w.WorkerID); err != nil {
+			utils.LogError(serviceName, schedule.FromAccountNumber, "ReleaseScheduledTransfer", err)
+		}
+		return false
+	}
+
+	utils.LogInfo(serviceName, schedule.FromAccountNumber, status,
+		fmt.Sprintf("Schedule ID: %d, Message: %s", schedule.ID, message))
+	return true
+}
+
+// notifyScheduleFailure notifikasi kegagalan transfer terjadwal ke nasabah lewat channel notifikasi.
+// Gagal kirim hanya dicatat di log, hasil eksekusi sudah tersimpan
+func (w scheduledTransferWorker) notifyScheduleFailure(schedule models.ScheduledTransfer, runAt time.Time, message string) {
+	serviceName := "ScheduledTransferWorker.Notify"
+
+	utils.LogInfo(serviceName, schedule.FromAccountNumber, "ScheduledTransferFailed",
+		fmt.Sprintf("Schedule ID: %d, Occurrence: %s, To: %s, Amount: %s, Reason: %s",
+			schedule.ID, runAt.Format(constans.LAYOUT_DATE), schedule.ToAccountNumber, schedule.Amount, message))
+
+	if w.Service.Notifier == nil {
+		return
+	}
+
+	account, err := w.Service.AccountRepo.FindAccountByNumber(schedule.FromAccountNumber)
+	if err != nil {
+		utils.LogError(serviceName, schedule.FromAccountNumber, "FindAccountByNumber", err)
+		return
+	}
+
+	err = notification.Send(w.Service.Context, w.Service.Notifier, models.NotificationMessage{
+		AccountNumber: account.AccountNumber,
+		PhoneNumber:   account.PhoneNumber,
+		Email:         account.Email,
+		Subject:       "Scheduled transfer failed",
+		Body: fmt.Sprintf("Your scheduled transfer of %s to %s on %s failed: %s.",
+			schedule.Amount, schedule.ToAccountNumber, runAt.Format(constans.LAYOUT_DATE), message),
+	})
+	if err != nil {
+		utils.LogError(serviceName, schedule.FromAccountNumber, "SendNotification", err, "Channel: "+w.Service.Notifier.Name())
+	}
+}
+
+// advanceSchedule pindah ke occurrence berikutnya dan reset retry.
+// ONCE menjadi FAILED (pemanggil mengganti COMPLETED jika sukses), berulang menjadi
+// COMPLETED jika occurrence berikutnya melewati end_date
+func advanceSchedule(schedule models.ScheduledTransfer, now time.Time) models.ScheduledTransfer {
+	schedule.RetryAt = nil
+	schedule.RetryCount = 0
+
+	if schedule.Frequency == constans.SCHEDULE_FREQUENCY_ONCE {
+		schedule.Status = constans.SCHEDULE_STATUS_FAILED
+		return schedule
+	}
+
+	next := skipMissedOccurrences(schedule, now)
+	if schedule.EndDate != nil && next.NextRunAt.After(*schedule.EndDate) {
+		schedule.Status = constans.SCHEDULE_STATUS_COMPLETED
+		return schedule
+	}
+
+	return next
+}
+
+// skipMissedOccurrences geser next_run_at ke occurrence pertama setelah now,
+// sehingga worker yang sempat mati tidak mengeksekusi occurrence terlewat sekaligus
+func skipMissedOccurrences(schedule models.ScheduledTransfer, now time.Time) models.ScheduledTransfer {
+	for !schedule.NextRunAt.After(now) {
+		schedule.NextRunAt = nextOccurrence(schedule.Frequency, schedule.StartDate, schedule.NextRunAt)
+	}
+	return schedule
+}
+
+// nextOccurrence occurrence setelah current. MONTHLY mengikuti tanggal start_date
+// dan dibatasi hari terakhir bulan (misal start 31 Jan -> 29 Feb -> 31 Mar)
+func nextOccurrence(frequency string, startDate, current time.Time) time.Time {
+	switch frequency {
+	case constans.SCHEDULE_FREQUENCY_DAILY:
+		return current.AddDate(0, 0, 1)
+	case constans.SCHEDULE_FREQUENCY_WEEKLY:
+		return current.AddDate(0, 0, 7)
+	case constans.SCHEDULE_FREQUENCY_MONTHLY:
+		firstOfNextMonth := time.Date(current.Year(), current.Month()+1, 1, 0, 0, 0, 0, current.Location())
+		lastDay := firstOfNextMonth.AddDate(0, 1, -1).Day()
+		day := startDate.Day()
+		if day > lastDay {
+			day = lastDay
+		}
+		return time.Date(firstOfNextMonth.Year(), firstOfNextMonth.Month(), day,
+			current.Hour(), current.Minute(), current.Second(), 0, current.Location())
+	}
+
+	// Frekuensi tidak dikenal, jangan sampai loop tanpa akhir
+	return current.AddDate(100, 0, 0)
+}
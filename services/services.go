@@ -1,23 +1,85 @@
 package services
 
 import (
+	"context"
 	"database/sql"
+	"sample/models"
+	"sample/notification"
 	"sample/repositories"
+	"sample/repositories/tracingRepository"
 )
 
 type UsecaseService struct {
 	RepoDB          *sql.DB
 	AccountRepo     repositories.AccountRepository
 	TransactionRepo repositories.TransactionRepository
+	LedgerRepo      repositories.LedgerRepository
+
+	ScheduledTransferRepo repositories.ScheduledTransferRepository
+	LimitRepo             repositories.LimitRepository
+	FeeRepo               repositories.FeeRepository
+	HoldRepo              repositories.HoldRepository
+	FxRepo                repositories.FxRepository
+	WebhookRepo           repositories.WebhookRepository
+	AuditRepo             repositories.AuditRepository
+	HealthRepo            repositories.HealthRepository
+
+	Config   models.Config
+	Notifier notification.Channel
+
+	// Context context request/job yang sedang diproses, parent span untuk Redis dan bcrypt
+	Context context.Context
 }
 
 func NewUsecaseService(repoDB *sql.DB,
 	AccountRepo repositories.AccountRepository,
 	TransactionRepo repositories.TransactionRepository,
+	LedgerRepo repositories.LedgerRepository,
+	ScheduledTransferRepo repositories.ScheduledTransferRepository,
+	LimitRepo repositories.LimitRepository,
+	FeeRepo repositories.FeeRepository,
+	HoldRepo repositories.HoldRepository,
+	FxRepo repositories.FxRepository,
+	WebhookRepo repositories.WebhookRepository,
+	AuditRepo repositories.AuditRepository,
+	HealthRepo repositories.HealthRepository,
+	Config models.Config,
 ) UsecaseService {
 	return UsecaseService{
 		RepoDB:          repoDB,
 		AccountRepo:     AccountRepo,
 		TransactionRepo: TransactionRepo,
+		LedgerRepo:      LedgerRepo,
+
+		ScheduledTransferRepo: ScheduledTransferRepo,
+		LimitRepo:             LimitRepo,
+		FeeRepo:               FeeRepo,
+		HoldRepo:              HoldRepo,
+		FxRepo:                FxRepo,
+		WebhookRepo:           WebhookRepo,
+		AuditRepo:             AuditRepo,
+		HealthRepo:            HealthRepo,
+
+		Config:   Config,
+		Notifier: notification.New(Config),
+
+		Context: context.Background(),
 	}
 }
+
+// WithContext salinan UsecaseService untuk satu request/job: setiap panggilan repository
+// dicatat sebagai child span dari span di ctx
+func (svc UsecaseService) WithContext(ctx context.Context) UsecaseService {
+	svc.Context = ctx
+	svc.AccountRepo = tracingRepository.NewAccountRepository(ctx, svc.AccountRepo)
+	svc.TransactionRepo = tracingRepository.NewTransactionRepository(ctx, svc.TransactionRepo)
+	svc.LedgerRepo = tracingRepository.NewLedgerRepository(ctx, svc.LedgerRepo)
+	svc.ScheduledTransferRepo = tracingRepository.NewScheduledTransferRepository(ctx, svc.ScheduledTransferRepo)
+	svc.LimitRepo = tracingRepository.NewLimitRepository(ctx, svc.LimitRepo)
+	svc.FeeRepo = tracingRepository.NewFeeRepository(ctx, svc.FeeRepo)
+	svc.HoldRepo = tracingRepository.NewHoldRepository(ctx, svc.HoldRepo)
+	svc.FxRepo = tracingRepository.NewFxRepository(ctx, svc.FxRepo)
+	svc.WebhookRepo = tracingRepository.NewWebhookRepository(ctx, svc.WebhookRepo)
+	svc.AuditRepo = tracingRepository.NewAuditRepository(ctx, svc.AuditRepo)
+	return svc
+}
@@ -0,0 +1,70 @@
+package statementService
+
+import (
+	"encoding/csv"
+	"io"
+	"sample/constans"
+	"sample/models"
+	"strconv"
+)
+
+// csvStatementWriter mutasi rekening dalam format CSV: blok identitas rekening,
+// tabel mutasi, lalu blok total dan saldo akhir
+type csvStatementWriter struct {
+	writer *csv.Writer
+}
+
+func newCSVStatementWriter(w io.Writer) *csvStatementWriter {
+	return &csvStatementWriter{
+		writer: csv.NewWriter(w),
+	}
+}
+
+func (w *csvStatementWriter) ContentType() string {
+	return "text/csv; charset=utf-8"
+}
+
+func (w *csvStatementWriter) FileExtension() string {
+	return "csv"
+}
+
+func (w *csvStatementWriter) WriteHeader(header models.StatementHeader) error {
+	return w.writer.WriteAll([][]string{
+		{"Account Number", header.AccountNumber},
+		{"Account Name", header.AccountName},
+		{"Currency", header.Currency},
+		{"Period", header.StartDate + " - " + header.EndDate},
+		{"Generated At", header.GeneratedAt.Format(constans.LAYOUT_TIMESTAMP)},
+		{"Opening Balance", header.OpeningBalance.String()},
+		{},
+		{"Transaction Time", "Transaction ID", "Description", "Debit", "Credit", "Fee", "Balance"},
+	})
+}
+
+func (w *csvStatementWriter) WriteLine(line models.StatementLine) error {
+	return w.writer.Write([]string{
+		line.TransactionTime.Format(constans.LAYOUT_TIMESTAMP),
+		strconv.Itoa(line.TransactionID),
+		line.Description,
+		line.Debit.String(),
+		line.Credit.String(),
+		line.Fee.String(),
+		line.Balance.String(),
+	})
+}
+
+func (w *csvStatementWriter) WriteSummary(summary models.StatementSummary) error {
+	return w.writer.WriteAll([][]string{
+		{},
+		{"Total Debit", summary.TotalDebit.String()},
+		{"Total Credit", summary.TotalCredit.String()},
+		{"Total Fee", summary.TotalFee.String()},
+		{"Closing Balance", summary.ClosingBalance.String()},
+	})
+}
+
+// Flush meneruskan buffer csv.Writer ke response
+func (w *csvStatementWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
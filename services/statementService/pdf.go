@@ -0,0 +1,204 @@
+package statementService
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sample/constans"
+	"sample/models"
+	"strings"
+)
+
+// Layout PDF: A4 portrait, font Courier (monospace) sehingga kolom cukup diratakan dengan spasi
+const (
+	pdfPageWidth    = 595
+	pdfPageHeight   = 842
+	pdfMargin       = 36
+	pdfFontSize     = 7.5
+	pdfLeading      = 10
+	pdfLinesPerPage = (pdfPageHeight - 2*pdfMargin) / pdfLeading
+
+	// Nomor objek tetap, objek halaman dimulai dari pdfFirstPageObject
+	pdfCatalogObject   = 1
+	pdfPagesObject     = 2
+	pdfFontObject      = 3
+	pdfFirstPageObject = 4
+
+	// Waktu, keterangan, debit, kredit, biaya, saldo (lebar total 113 karakter)
+	pdfColumnFormat = "%-19s %-28s %16s %16s %12s %17s"
+)
+
+// pdfStatementWriter menulis PDF secara streaming: setiap halaman yang penuh langsung dikirim,
+// hanya offset objek yang disimpan sampai xref ditulis di akhir dokumen
+type pdfStatementWriter struct {
+	w       io.Writer
+	written int64
+	offsets map[int]int64
+	pages   []int
+	lines   []string
+	header  []string
+	err     error
+}
+
+func newPDFStatementWriter(w io.Writer) *pdfStatementWriter {
+	return &pdfStatementWriter{
+		w:       w,
+		offsets: map[int]int64{},
+	}
+}
+
+func (w *pdfStatementWriter) ContentType() string {
+	return "application/pdf"
+}
+
+func (w *pdfStatementWriter) FileExtension() string {
+	return "pdf"
+}
+
+func (w *pdfStatementWriter) WriteHeader(header models.StatementHeader) error {
+	w.write("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	w.writeObject(pdfCatalogObject, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pdfPagesObject))
+	w.writeObject(pdfFontObject, "<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+
+	w.header = []string{
+		fmt.Sprintf(pdfColumnFormat, "Transaction Time", "Description", "Debit", "Credit", "Fee", "Balance"),
+		strings.Repeat("-", 113),
+	}
+
+	w.lines = append(w.lines,
+		"ACCOUNT STATEMENT",
+		"",
+		"Account Number  : "+header.AccountNumber,
+		"Account Name    : "+header.AccountName,
+		"Currency        : "+header.Currency,
+		"Period          : "+header.StartDate+" - "+header.EndDate,
+		"Generated At    : "+header.GeneratedAt.Format(constans.LAYOUT_TIMESTAMP),
+		"",
+		fmt.Sprintf(pdfColumnFormat, "", "OPENING BALANCE", "", "", "", header.OpeningBalance),
+	)
+	w.lines = append(w.lines, w.header...)
+
+	return w.err
+}
+
+func (w *pdfStatementWriter) WriteLine(line models.StatementLine) error {
+	description := fmt.Sprintf("#%d %s", line.TransactionID, line.Description)
+	if len(description) > 28 {
+		description = description[:28]
+	}
+
+	w.addLine(fmt.Sprintf(pdfColumnFormat,
+		line.TransactionTime.Format(constans.LAYOUT_TIMESTAMP),
+		description,
+		line.Debit,
+		line.Credit,
+		line.Fee,
+		line.Balance,
+	))
+
+	return w.err
+}
+
+func (w *pdfStatementWriter) WriteSummary(summary models.StatementSummary) error {
+	w.addLine(strings.Repeat("-", 113))
+	w.addLine(fmt.Sprintf(pdfColumnFormat, "", "TOTAL", summary.TotalDebit, summary.TotalCredit, summary.TotalFee, ""))
+	w.addLine(fmt.Sprintf(pdfColumnFormat, "", "CLOSING BALANCE", "", "", "", summary.ClosingBalance))
+	w.addLine("")
+	w.addLine(fmt.Sprintf("%d transaction(s)", summary.LineCount))
+
+	w.writePage()
+	w.writeTrailer()
+
+	return w.err
+}
+
+// Flush halaman penuh sudah langsung ditulis, sisa baris menunggu halaman berikutnya
+func (w *pdfStatementWriter) Flush() error {
+	return w.err
+}
+
+// addLine menambah baris ke halaman berjalan, halaman penuh langsung ditulis
+// dan halaman baru diawali header kolom
+func (w *pdfStatementWriter) addLine(line string) {
+	if len(w.lines) >= pdfLinesPerPage-2 {
+		w.writePage()
+		w.lines = append(w.lines, w.header...)
+	}
+	w.lines = append(w.lines, line)
+}
+
+// writePage menulis content stream dan objek halaman, ditutup dengan nomor halaman
+func (w *pdfStatementWriter) writePage() {
+	pageNumber := len(w.pages) + 1
+	contentObject := pdfFirstPageObject + 2*len(w.pages)
+	pageObject := contentObject + 1
+
+	var content bytes.Buffer
+	fmt.Fprintf(&content, "BT\n/F1 %g Tf\n%d TL\n%d %d Td\n", pdfFontSize, pdfLeading, pdfMargin, pdfPageHeight-pdfMargin)
+	for _, line := range w.lines {
+		fmt.Fprintf(&content, "(%s) Tj T*\n", pdfEscape(line))
+	}
+	fmt.Fprintf(&content, "ET\nBT\n/F1 %g Tf\n%d %d Td\n(Page %d) Tj\nET\n", pdfFontSize, pdfPageWidth-pdfMargin-40, pdfMargin/2, pageNumber)
+
+	w.writeObject(contentObject, fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	w.writeObject(pageObject, fmt.Sprintf(
+		"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>",
+		pdfPagesObject, pdfPageWidth, pdfPageHeight, pdfFontObject, contentObject))
+
+	w.pages = append(w.pages, pageObject)
+	w.lines = w.lines[:0]
+}
+
+// writeTrailer menulis daftar halaman, tabel xref dan trailer
+func (w *pdfStatementWriter) writeTrailer() {
+	kids := make([]string, len(w.pages))
+	for i, page := range w.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", page)
+	}
+	w.writeObject(pdfPagesObject, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(w.pages)))
+
+	size := pdfFirstPageObject + 2*len(w.pages)
+	xrefOffset := w.written
+
+	var xref bytes.Buffer
+	fmt.Fprintf(&xref, "xref\n0 %d\n0000000000 65535 f \n", size)
+	for object := 1; object < size; object++ {
+		fmt.Fprintf(&xref, "%010d 00000 n \n", w.offsets[object])
+	}
+	fmt.Fprintf(&xref, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", size, pdfCatalogObject, xrefOffset)
+
+	w.write(xref.String())
+}
+
+func (w *pdfStatementWriter) writeObject(number int, body string) {
+	w.offsets[number] = w.written
+	w.write(fmt.Sprintf("%d 0 obj\n%s\nendobj\n", number, body))
+}
+
+// write menyimpan error pertama, penulisan berikutnya diabaikan
+func (w *pdfStatementWriter) write(s string) {
+	if w.err != nil {
+		return
+	}
+
+	n, err := io.WriteString(w.w, s)
+	w.written += int64(n)
+	w.err = err
+}
+
+// pdfEscape escape karakter khusus string PDF, karakter di luar ASCII diganti '?'
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 32 || r > 126:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
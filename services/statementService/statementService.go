@@ -0,0 +1,245 @@
+package statementService
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sample/constans"
+	"sample/helpers"
+	"sample/models"
+	"sample/services"
+	"sample/utils"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+// flushEvery jumlah baris mutasi sebelum response di-flush ke client
+const flushEvery = 200
+
+type statementService struct {
+	Service services.UsecaseService
+}
+
+func NewStatementService(service services.UsecaseService) statementService {
+	return statementService{
+		Service: service,
+	}
+}
+
+// statementWriter format file mutasi rekening, setiap baris langsung ditulis ke response
+type statementWriter interface {
+	ContentType() string
+	FileExtension() string
+	WriteHeader(header models.StatementHeader) error
+	WriteLine(line models.StatementLine) error
+	WriteSummary(summary models.StatementSummary) error
+	Flush() error
+}
+
+// DownloadStatement mengunduh mutasi rekening satu periode (CSV/PDF) berisi saldo awal,
+// setiap transaksi dengan saldo berjalan, dan saldo akhir. Baris dibaca dan ditulis satu per satu
+// dari cursor database sehingga periode panjang tidak dimuat sekaligus ke memori
+func (svc statementService) DownloadStatement(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result      models.Response
+		serviceName = "StatementService.DownloadStatement"
+		request     = new(models.RequestStatement)
+		writer      statementWriter
+		started     bool
+	)
+
+	if err := helpers.BindValidateStruct(ctx, request); err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "DownloadStatement.BindValidateStruct", err)
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	// Subject selalu dari token, bukan dari body request
+	request.AccountNumber = helpers.GetAccountNumber(ctx)
+
+	if request.Currency == "" {
+		request.Currency = constans.DEFAULT_CURRENCY
+	}
+
+	startDate, err := time.Parse(constans.LAYOUT_DATE, request.StartDate)
+	if err != nil {
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Invalid start_date format, expected YYYY-MM-DD", nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	endDate, err := time.Parse(constans.LAYOUT_DATE, request.EndDate)
+	if err != nil {
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Invalid end_date format, expected YYYY-MM-DD", nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	if endDate.Before(startDate) {
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "end_date must not be before start_date", nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	utils.LogInfo(serviceName, request.AccountNumber, "DownloadStatement",
+		fmt.Sprintf("Period: %s - %s, Currency: %s, Format: %s", request.StartDate, request.EndDate, request.Currency, request.Format))
+
+	account, err := svc.Service.AccountRepo.FindAccountByNumber(request.AccountNumber)
+	if err != nil {
+		utils.LogError(serviceName, request.AccountNumber, "DownloadStatement.FindAccountByNumber", err)
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Account not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+
+	response := ctx.Response()
+	if request.Format == "pdf" {
+		writer = newPDFStatementWriter(response)
+	} else {
+		writer = newCSVStatementWriter(response)
+	}
+
+	header := models.StatementHeader{
+		AccountNumber: account.AccountNumber,
+		AccountName:   account.AccountName,
+		Currency:      request.Currency,
+		StartDate:     request.StartDate,
+		EndDate:       request.EndDate,
+		GeneratedAt:   time.Now(),
+	}
+
+	summary := models.StatementSummary{
+		TotalDebit:  models.NewMoney(0, request.Currency),
+		TotalCredit: models.NewMoney(0, request.Currency),
+		TotalFee:    models.NewMoney(0, request.Currency),
+	}
+
+	// Header HTTP baru dikirim saat baris pertama siap, sehingga error sebelum itu masih bisa dijawab JSON
+	begin := func() error {
+		if started {
+			return nil
+		}
+		started = true
+
+		filename := fmt.Sprintf("statement-%s-%s-%s.%s", account.AccountNumber, request.StartDate, request.EndDate, writer.FileExtension())
+		response.Header().Set(echo.HeaderContentType, writer.ContentType())
+		response.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+		response.WriteHeader(http.StatusOK)
+
+		return writer.WriteHeader(header)
+	}
+
+	// Saldo awal dan mutasi dibaca dari snapshot yang sama agar saldo berjalan konsisten
+	err = utils.DBReadSnapshot(svc.Service.RepoDB, func(tx *sql.Tx) error {
+		var err error
+
+		header.OpeningBalance, err = svc.Service.TransactionRepo.GetBalanceBefore(account.AccountNumber, request.Currency, request.StartDate, tx)
+		if err != nil {
+			return err
+		}
+
+		balance := header.OpeningBalance
+
+		err = svc.Service.TransactionRepo.StreamTransactionHistory(account.AccountNumber, request.StartDate, request.EndDate, constans.EMPTY_VALUE, tx,
+			func(transaction models.Transaction) error {
+				// Transaksi PENDING/FAILED tidak mengubah saldo, pocket lain punya mutasi sendiri
+				if !transaction.MovesFunds() || transaction.Amount.CurrencyCode() != request.Currency {
+					return nil
+				}
+
+				if err := begin(); err != nil {
+					return err
+				}
+
+				balance = balance.Add(transaction.BalanceEffect())
+				line := statementLine(transaction, balance)
+
+				summary.TotalDebit = summary.TotalDebit.Add(line.Debit)
+				summary.TotalCredit = summary.TotalCredit.Add(line.Credit)
+				summary.TotalFee = summary.TotalFee.Add(line.Fee)
+				summary.LineCount++
+
+				if err := writer.WriteLine(line); err != nil {
+					return err
+				}
+
+				if summary.LineCount%flushEvery == 0 {
+					if err := writer.Flush(); err != nil {
+						return err
+					}
+					response.Flush()
+				}
+
+				return nil
+			})
+		if err != nil {
+			return err
+		}
+
+		summary.ClosingBalance = balance
+		return nil
+	})
+
+	if err == nil {
+		if err = begin(); err == nil {
+			if err = writer.WriteSummary(summary); err == nil {
+				err = writer.Flush()
+			}
+		}
+	}
+
+	if err != nil {
+		utils.LogError(serviceName, account.AccountNumber, "DownloadStatement.Stream", err)
+
+		if !started {
+			result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to generate statement", nil)
+			return ctx.JSON(http.StatusInternalServerError, result)
+		}
+
+		// Response sudah terkirim sebagian, file terpotong tanpa saldo akhir
+		return nil
+	}
+
+	utils.LogInfo(serviceName, account.AccountNumber, "DownloadStatement.Success",
+		fmt.Sprintf("Lines: %d, Opening: %s, Closing: %s", summary.LineCount, header.OpeningBalance, summary.ClosingBalance))
+
+	return nil
+}
+
+// statementLine mapping transaksi ke baris mutasi
+func statementLine(transaction models.Transaction, balance models.Money) models.StatementLine {
+	line := models.StatementLine{
+		TransactionID:   transaction.ID,
+		TransactionTime: transaction.TransactionTime,
+		Description:     statementDescription(transaction),
+		Debit:           models.NewMoney(0, transaction.Amount.CurrencyCode()),
+		Credit:          models.NewMoney(0, transaction.Amount.CurrencyCode()),
+		Fee:             models.NewMoney(0, transaction.Amount.CurrencyCode()).Add(transaction.Fee),
+		Balance:         balance,
+	}
+
+	if transaction.TransactionType == "D" {
+		line.Debit = transaction.Amount
+	} else {
+		line.Credit = transaction.Amount
+	}
+
+	return line
+}
+
+// statementDescription keterangan mutasi dari data transaksi
+func statementDescription(transaction models.Transaction) string {
+	switch {
+	case transaction.ReversalOf != 0:
+		return fmt.Sprintf("Reversal of #%d", transaction.ReversalOf)
+	case transaction.SourceNumber == transaction.AccountNumber && transaction.BeneficiaryNumber == transaction.AccountNumber:
+		return "FX conversion"
+	case transaction.TransactionType == "D" && transaction.BeneficiaryNumber != "":
+		return "Transfer to " + transaction.BeneficiaryNumber
+	case transaction.TransactionType == "C" && transaction.SourceNumber != "":
+		return "Transfer from " + transaction.SourceNumber
+	case transaction.TransactionType == "D":
+		return "Debit"
+	default:
+		return "Credit"
+	}
+}
@@ -24,6 +24,8 @@ func NewTransactionHistoryService(service services.UsecaseService) transactionHi
 
 // TransactionHistoryListV2 mendapatkan list history transaksi dengan filter dan pagination
 func (svc transactionHistoryService) TransactionHistoryListV2(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
 	var (
 		request                 models.RequestTransactionHistoryList
 		responseTransactionList []models.ResponseTransactionHistoryListV2
@@ -44,6 +46,9 @@ func (svc transactionHistoryService) TransactionHistoryListV2(ctx echo.Context)
 		return ctx.JSON(http.StatusBadRequest, result)
 	}
 
+	// Subject selalu dari token, bukan dari body request
+	request.AccountNumber = helpers.GetAccountNumber(ctx)
+
 	accNo := request.AccountNumber
 	if accNo == "" {
 		accNo = "ALL_ACCOUNTS"
@@ -96,6 +101,7 @@ func (svc transactionHistoryService) TransactionHistoryListV2(ctx echo.Context)
 			// BeneficiaryNumber: tx.BeneficiaryNumber,
 			TransactionType: tx.TransactionType,
 			Amount:          tx.Amount,
+			Status:          tx.Status,
 			TransactionTime: tx.TransactionTime.Format(constans.LAYOUT_TIMESTAMP),
 			CreatedAt:       tx.CreatedAt.Format(constans.LAYOUT_TIMESTAMP),
 		})
@@ -0,0 +1,241 @@
+package transactionService
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sample/config"
+	"sample/constans"
+	"sample/helpers"
+	"sample/metrics"
+	"sample/models"
+	"sample/notification"
+	"sample/services/pinLockoutService"
+	"sample/utils"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+// startStepUp simpan challenge OTP untuk withdraw / transfer nominal besar lalu kirim OTP ke
+// kontak terdaftar. Response HTTP 202 berisi challenge_id untuk /transaction/confirm
+func (svc transactionService) startStepUp(ctx echo.Context, account models.Account, challenge models.StepUpChallenge, amount models.Money) error {
+	var (
+		result      models.Response
+		serviceName = "TransactionService.StepUp"
+		ttl         = svc.Service.Config.StepUp.CodeTTL()
+	)
+
+	if svc.Service.Notifier == nil {
+		utils.LogError(serviceName, account.AccountNumber, "CheckNotifier", fmt.Errorf("Notification channel is not configured"))
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to send OTP. Please try again", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	challengeID, err := helpers.GenerateSecureID()
+	if err != nil {
+		utils.LogError(serviceName, account.AccountNumber, "GenerateSecureID", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to start OTP verification", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	otp, err := helpers.GenerateOTP()
+	if err != nil {
+		utils.LogError(serviceName, account.AccountNumber, "GenerateOTP", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to start OTP verification", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	hashedOTP, err := helpers.HashPIN(otp)
+	if err != nil {
+		utils.LogError(serviceName, account.AccountNumber, "HashOTP", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to start OTP verification", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	challenge.ChallengeID = challengeID
+	challenge.AccountNumber = account.AccountNumber
+	challenge.ExpiresAt = time.Now().Add(ttl)
+	value, _ := json.Marshal(challenge)
+
+	if err := config.SetStepUpChallenge(svc.Service.Context, challengeID, hashedOTP, string(value), ttl); err != nil {
+		utils.LogError(serviceName, account.AccountNumber, "SetStepUpChallenge", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to start OTP verification", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	err = notification.Send(svc.Service.Context, svc.Service.Notifier, models.NotificationMessage{
+		AccountNumber: account.AccountNumber,
+		PhoneNumber:   account.PhoneNumber,
+		Email:         account.Email,
+		Subject:       "Transaction OTP",
+		Body: fmt.Sprintf("Your OTP to confirm %s of %s is %s. It expires in %d minute(s). Never share this code with anyone.",
+			strings.ToLower(challenge.Type), amount, otp, int(math.Ceil(ttl.Minutes()))),
+	})
+	if err != nil {
+		utils.LogError(serviceName, account.AccountNumber, "SendOTP", err, "Channel: "+svc.Service.Notifier.Name())
+		svc.discardStepUp(challengeID, account.AccountNumber)
+
+		if err == notification.ErrNoRecipient {
+			result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE,
+				"No registered contact to receive the OTP. Please update your phone number or email", nil)
+			return ctx.JSON(http.StatusBadRequest, result)
+		}
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to send OTP. Please try again", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	metrics.StepUpChallengesTotal.WithLabelValues("issued").Inc()
+	utils.LogInfo(serviceName, account.AccountNumber, "StepUp.Issued",
+		fmt.Sprintf("Challenge: %s, Type: %s, Amount: %s", challengeID, challenge.Type, amount))
+
+	response := models.StepUpChallengeResponse{
+		ChallengeID: challengeID,
+		Type:        challenge.Type,
+		Amount:      amount,
+		Channel:     svc.Service.Notifier.Name(),
+		ExpiresAt:   challenge.ExpiresAt,
+	}
+
+	result = helpers.ResponseJSON(true, constans.PENDING_CODE,
+		"OTP verification required. Submit the code sent to your registered contact to confirm the transaction", response)
+	return ctx.JSON(http.StatusAccepted, result)
+}
+
+// ConfirmTransaction tahap kedua withdraw / transfer nominal besar: verifikasi OTP challenge lalu
+// eksekusi transaksi yang tersimpan. OTP salah dihitung ke counter kunci PIN, challenge hangus
+// setelah PIN_MAX_ATTEMPTS salah atau saat akun terkunci
+func (svc transactionService) ConfirmTransaction(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result      models.Response
+		serviceName = "TransactionService.ConfirmTransaction"
+		request     = new(models.RequestConfirmTransaction)
+		challenge   models.StepUpChallenge
+		maxAttempts = svc.Service.Config.PINLockout.MaxAttempts
+	)
+
+	if err := helpers.BindValidateStruct(ctx, request); err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "ConfirmTransaction.BindValidateStruct", err)
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	// Subject selalu dari token, challenge milik nasabah lain dianggap tidak ada
+	accountNumber := helpers.GetAccountNumber(ctx)
+	utils.LogInfo(serviceName, accountNumber, "ConfirmTransaction", "Challenge: "+request.ChallengeID)
+
+	hashedOTP, value, attempts, err := config.HitStepUpChallenge(svc.Service.Context, request.ChallengeID)
+	if err != nil && !helpers.Contains(err.Error(), "expired or not found") {
+		utils.LogError(serviceName, accountNumber, "ConfirmTransaction.HitStepUpChallenge", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to verify OTP", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+	if err == nil {
+		err = json.Unmarshal([]byte(value), &challenge)
+	}
+	if err != nil || challenge.AccountNumber != accountNumber {
+		utils.LogError(serviceName, accountNumber, "ConfirmTransaction.FindChallenge", err)
+		metrics.StepUpChallengesTotal.WithLabelValues("expired").Inc()
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "OTP challenge expired or not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+
+	account, err := svc.Service.AccountRepo.FindAccountByNumber(accountNumber)
+	if err != nil {
+		utils.LogError(serviceName, accountNumber, "ConfirmTransaction.FindAccountByNumber", err)
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Account not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+
+	// Akun yang terkunci setelah challenge dibuat tidak bisa menyelesaikan transaksi
+	if pinErr := pinLockoutService.CheckLock(account, time.Now()); pinErr != nil {
+		utils.LogError(serviceName, accountNumber, "ConfirmTransaction.CheckLock", pinErr)
+		svc.discardStepUp(request.ChallengeID, accountNumber)
+		result = helpers.ResponseJSON(false, pinErr.Code, pinErr.Message, nil)
+		return ctx.JSON(pinErr.StatusCode, result)
+	}
+
+	pinErr := pinLockoutService.NewPINLockoutService(svc.Service).VerifyCredential(account, "OTP", func(models.Account) bool {
+		return attempts <= maxAttempts && helpers.CheckPINHashContext(svc.Service.Context, request.OTP, hashedOTP)
+	})
+	if pinErr != nil {
+		utils.LogError(serviceName, accountNumber, "ConfirmTransaction.CheckOTP",
+			fmt.Errorf("%s, attempt %d of %d", pinErr.Message, attempts, maxAttempts))
+		if pinErr.StatusCode == http.StatusInternalServerError {
+			result = helpers.ResponseJSON(false, pinErr.Code, pinErr.Message, nil)
+			return ctx.JSON(pinErr.StatusCode, result)
+		}
+		metrics.StepUpChallengesTotal.WithLabelValues("rejected").Inc()
+
+		if attempts >= maxAttempts || pinErr.StatusCode == http.StatusForbidden {
+			svc.discardStepUp(request.ChallengeID, accountNumber)
+			if pinErr.StatusCode != http.StatusForbidden {
+				pinErr = &pinLockoutService.PINError{
+					StatusCode: http.StatusBadRequest,
+					Code:       constans.VALIDATE_ERROR_CODE,
+					Message:    "Too many invalid OTP attempts. Please start the transaction again",
+				}
+			}
+		}
+
+		result = helpers.ResponseJSON(false, pinErr.Code, pinErr.Message, nil)
+		return ctx.JSON(pinErr.StatusCode, result)
+	}
+
+	// Challenge sekali pakai: hanya request yang berhasil menghapus challenge yang boleh lanjut
+	deleted, err := config.DeleteStepUpChallenge(svc.Service.Context, request.ChallengeID)
+	if err != nil {
+		utils.LogError(serviceName, accountNumber, "ConfirmTransaction.DeleteStepUpChallenge", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to verify OTP", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+	if !deleted {
+		utils.LogError(serviceName, accountNumber, "ConfirmTransaction.DeleteStepUpChallenge",
+			fmt.Errorf("Challenge already used"))
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "OTP challenge expired or not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+
+	metrics.StepUpChallengesTotal.WithLabelValues("confirmed").Inc()
+	utils.LogInfo(serviceName, accountNumber, "ConfirmTransaction.Verified",
+		fmt.Sprintf("Challenge: %s, Type: %s", challenge.ChallengeID, challenge.Type))
+
+	switch {
+	case challenge.Type == constans.STEP_UP_TYPE_WITHDRAW && challenge.Withdraw != nil:
+		request := *challenge.Withdraw
+		request.AccountNumber = accountNumber
+		return svc.executeWithdraw(ctx, account, request)
+
+	case challenge.Type == constans.STEP_UP_TYPE_TRANSFER && challenge.Transfer != nil:
+		request := *challenge.Transfer
+		request.FromAccountNumber = accountNumber
+
+		var quote *models.TransferInquiry
+		if request.InquiryID != "" {
+			inquiry, err := getTransferInquiry(svc.Service.Context, request.InquiryID)
+			if err != nil || inquiry.FromAccountNumber != accountNumber {
+				utils.LogError(serviceName, accountNumber, "ConfirmTransaction.GetTransferInquiry", err)
+				result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Transfer inquiry expired or not found", nil)
+				return ctx.JSON(http.StatusNotFound, result)
+			}
+			quote = &inquiry
+		}
+		return svc.executeTransfer(ctx, account, request, quote)
+	}
+
+	utils.LogError(serviceName, accountNumber, "ConfirmTransaction.UnknownType", fmt.Errorf("Unknown challenge type %q", challenge.Type))
+	result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to process transaction", nil)
+	return ctx.JSON(http.StatusInternalServerError, result)
+}
+
+// discardStepUp hapus challenge yang tidak boleh dipakai lagi, gagal hapus hanya dicatat
+// karena challenge tetap kedaluwarsa sendiri
+func (svc transactionService) discardStepUp(challengeID, accountNumber string) {
+	if _, err := config.DeleteStepUpChallenge(svc.Service.Context, challengeID); err != nil {
+		utils.LogError("TransactionService.StepUp", accountNumber, "DeleteStepUpChallenge", err)
+	}
+}
@@ -1,16 +1,24 @@
 package transactionService
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"math"
 	"net/http"
+	"sample/config"
 	"sample/constans"
 	"sample/helpers"
+	"sample/metrics"
 	"sample/models"
 	"sample/services"
+	"sample/services/feeService"
+	"sample/services/fxService"
+	"sample/services/limitService"
+	"sample/services/pinLockoutService"
+	"sample/services/webhookService"
 	"sample/utils"
-	"strconv"
 	"time"
 
 	"github.com/labstack/echo"
@@ -28,6 +36,8 @@ func NewTransactionService(service services.UsecaseService) transactionService {
 
 // Deposit menambah saldo
 func (svc transactionService) Deposit(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
 	var (
 		result      models.Response
 		serviceName = "TransactionService.Deposit"
@@ -37,7 +47,8 @@ func (svc transactionService) Deposit(ctx echo.Context) error {
 
 		transactionTime             = time.Now()
 		updatedAt                   = transactionTime.Format(constans.LAYOUT_TIMESTAMP)
-		balanceAfter, balanceBefore float64
+		referenceNo                 = utils.GenerateReferenceNo()
+		balanceAfter, balanceBefore models.Money
 
 		transaction models.Transaction
 		response    models.DepositResponse
@@ -49,8 +60,11 @@ func (svc transactionService) Deposit(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, result)
 	}
 
+	// Subject selalu dari token, bukan dari body request
+	request.AccountNumber = helpers.GetAccountNumber(ctx)
+
 	utils.LogInfo(serviceName, request.AccountNumber, "Deposit",
-		fmt.Sprintf("Request amount: %.2f", request.Amount))
+		fmt.Sprintf("Request amount: %s", request.Amount))
 
 	account, err := svc.Service.AccountRepo.FindAccountByNumber(request.AccountNumber)
 	if err != nil {
@@ -59,35 +73,13 @@ func (svc transactionService) Deposit(ctx echo.Context) error {
 		return ctx.JSON(http.StatusNotFound, result)
 	}
 
-	// Check account status
-	if account.AccountStatus == "BLOCKED_PIN" {
-		utils.LogError(serviceName, request.AccountNumber, "Deposit.CheckAccountStatus",
-			fmt.Errorf("Account is blocked"))
-		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Account is blocked. Please reset your PIN", nil)
-		return ctx.JSON(http.StatusForbidden, result)
-	}
-
-	// Verify PIN with failed attempts tracking
-	if !helpers.CheckPINHash(request.PIN, account.PIN) {
-		failedAttempts, _ := svc.Service.AccountRepo.IncrementFailedPINAttempts(request.AccountNumber)
-		remainingAttempts := 3 - failedAttempts
-
-		utils.LogError(serviceName, request.AccountNumber, "Deposit.VerifyPIN",
-			fmt.Errorf("Invalid PIN. Remaining attempts: %d", remainingAttempts))
-
-		if remainingAttempts <= 0 {
-			result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Account blocked due to multiple failed PIN attempts", nil)
-			return ctx.JSON(http.StatusForbidden, result)
-		}
-
-		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE,
-			"Invalid PIN. "+strconv.Itoa(remainingAttempts)+" attempt(s) remaining", nil)
-		return ctx.JSON(http.StatusUnauthorized, result)
+	// Cek blokir dan verifikasi PIN sesuai kebijakan lockout
+	if pinErr := pinLockoutService.NewPINLockoutService(svc.Service).VerifyPIN(account, request.PIN); pinErr != nil {
+		utils.LogError(serviceName, request.AccountNumber, "Deposit.VerifyPIN", pinErr)
+		result = helpers.ResponseJSON(false, pinErr.Code, pinErr.Message, nil)
+		return ctx.JSON(pinErr.StatusCode, result)
 	}
 
-	// Reset failed attempts on successful PIN
-	svc.Service.AccountRepo.ResetFailedPINAttempts(request.AccountNumber)
-
 	err = utils.DBTransaction(svc.Service.RepoDB, func(tx *sql.Tx) error {
 		lastBalance, err := svc.Service.AccountRepo.IncrementDecrementLastBalance(
 			account.ID,
@@ -102,18 +94,42 @@ func (svc transactionService) Deposit(ctx echo.Context) error {
 		balanceBefore = account.Balance
 		balanceAfter = lastBalance
 
+		if err := limitService.NewLimitService(svc.Service).CheckTransactionLimit(account, constans.JOURNAL_TYPE_DEPOSIT, request.Amount, tx); err != nil {
+			return err
+		}
+
+		// Jurnal: Debit CASH_VAULT, Credit rekening nasabah
+		journalID, err := svc.Service.LedgerRepo.PostJournal(models.JournalEntry{
+			ReferenceNo: referenceNo,
+			JournalType: constans.JOURNAL_TYPE_DEPOSIT,
+			Description: "Setoran Tunai " + account.AccountNumber,
+			Postings: []models.Posting{
+				{LedgerCode: constans.LEDGER_CASH_VAULT, Direction: "D", Amount: request.Amount},
+				{LedgerCode: account.AccountNumber, Direction: "C", Amount: request.Amount},
+			},
+		}, tx)
+		if err != nil {
+			return err
+		}
+
 		transaction = models.Transaction{
 			AccountID:         account.ID,
 			AccountNumber:     account.AccountNumber,
 			AccountName:       account.AccountName,
 			TransactionType:   "C",
 			Amount:            request.Amount,
+			JournalID:         journalID,
 			TransactionTime:   transactionTime,
 			SourceNumber:      account.AccountNumber,
 			BeneficiaryNumber: account.AccountNumber,
 		}
 
-		_, err = svc.Service.TransactionRepo.AddTransaction(transaction)
+		transaction.ID, err = svc.Service.TransactionRepo.AddTransaction(transaction, tx)
+		if err != nil {
+			return err
+		}
+
+		err = webhookService.NewWebhookService(svc.Service).PublishTransactionCreated(tx, transaction)
 		if err != nil {
 			return err
 		}
@@ -122,41 +138,53 @@ func (svc transactionService) Deposit(ctx echo.Context) error {
 	})
 
 	if err != nil {
+		if txErr, ok := err.(*utils.TransactionError); ok {
+			utils.LogError(serviceName, request.AccountNumber, "Deposit.LimitExceeded",
+				fmt.Errorf("%s", txErr.Message))
+			result = helpers.ResponseJSON(false, txErr.Code, txErr.Message, nil)
+			return ctx.JSON(http.StatusBadRequest, result)
+		}
+
 		utils.LogError(serviceName, request.AccountNumber, "Deposit.DBTransaction", err)
 		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Transaction failed: "+err.Error(), nil)
 		return ctx.JSON(http.StatusInternalServerError, result)
 	}
 
+	metrics.RecordTransaction(constans.JOURNAL_TYPE_DEPOSIT, request.Amount)
 	utils.LogInfo(serviceName, request.AccountNumber, "Deposit.Success",
-		fmt.Sprintf("Amount: %.2f, Balance Before: %.2f, Balance After: %.2f", request.Amount, balanceBefore, balanceAfter))
+		fmt.Sprintf("Amount: %s, Balance Before: %s, Balance After: %s", request.Amount, balanceBefore, balanceAfter))
 
 	response = models.DepositResponse{
+		ReferenceNo:     referenceNo,
 		AccountNumber:   account.AccountNumber,
 		AccountName:     account.AccountName,
 		BalanceBefore:   balanceBefore,
 		Amount:          request.Amount,
 		BalanceAfter:    balanceAfter,
+		Currency:        balanceAfter.CurrencyCode(),
 		TransactionDate: updatedAt,
 	}
 
+	helpers.AuditBalanceChange(ctx, referenceNo, models.AuditBalanceChange{
+		AccountNumber: account.AccountNumber,
+		Before:        balanceBefore,
+		After:         balanceAfter,
+	})
+
 	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Deposit successful", response)
 	return ctx.JSON(http.StatusOK, result)
 }
 
 // Withdraw menarik saldo
 func (svc transactionService) Withdraw(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
 	var (
 		result      models.Response
 		serviceName = "TransactionService.Withdraw"
 		// request         models.RequestWithdraw
-		request                     = new(models.RequestWithdraw)
-		account                     models.Account
-		transactionTime             = time.Now()
-		updatedAt                   = transactionTime.Format(constans.LAYOUT_TIMESTAMP)
-		balanceAfter, balanceBefore float64
-
-		transaction models.Transaction
-		response    models.WithdrawResponse
+		request = new(models.RequestWithdraw)
+		account models.Account
 	)
 
 	if err := helpers.BindValidateStruct(ctx, request); err != nil {
@@ -165,8 +193,11 @@ func (svc transactionService) Withdraw(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, result)
 	}
 
+	// Subject selalu dari token, bukan dari body request
+	request.AccountNumber = helpers.GetAccountNumber(ctx)
+
 	utils.LogInfo(serviceName, request.AccountNumber, "Withdraw",
-		fmt.Sprintf("Request amount: %.2f", request.Amount))
+		fmt.Sprintf("Request amount: %s", request.Amount))
 
 	account, err := svc.Service.AccountRepo.FindAccountByNumber(request.AccountNumber)
 	if err != nil {
@@ -175,43 +206,49 @@ func (svc transactionService) Withdraw(ctx echo.Context) error {
 		return ctx.JSON(http.StatusNotFound, result)
 	}
 
-	// Check account status
-	if account.AccountStatus == "BLOCKED_PIN" {
-		utils.LogError(serviceName, request.AccountNumber, "Withdraw.CheckAccountStatus",
-			fmt.Errorf("Account is blocked"))
-		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Account is blocked. Please reset your PIN", nil)
-		return ctx.JSON(http.StatusForbidden, result)
+	// Cek blokir dan verifikasi PIN sesuai kebijakan lockout
+	if pinErr := pinLockoutService.NewPINLockoutService(svc.Service).VerifyPIN(account, request.PIN); pinErr != nil {
+		utils.LogError(serviceName, request.AccountNumber, "Withdraw.VerifyPIN", pinErr)
+		result = helpers.ResponseJSON(false, pinErr.Code, pinErr.Message, nil)
+		return ctx.JSON(pinErr.StatusCode, result)
 	}
 
-	// Verify PIN with failed attempts tracking
-	if !helpers.CheckPINHash(request.PIN, account.PIN) {
-		failedAttempts, _ := svc.Service.AccountRepo.IncrementFailedPINAttempts(request.AccountNumber)
-		remainingAttempts := 3 - failedAttempts
-
-		utils.LogError(serviceName, request.AccountNumber, "Withdraw.VerifyPIN",
-			fmt.Errorf("Invalid PIN. Remaining attempts: %d", remainingAttempts))
+	// Nominal besar baru dieksekusi setelah OTP dikonfirmasi lewat /transaction/confirm
+	if svc.Service.Config.StepUp.Required(request.Amount) {
+		request.PIN = ""
+		return svc.startStepUp(ctx, account, models.StepUpChallenge{
+			Type:     constans.STEP_UP_TYPE_WITHDRAW,
+			Withdraw: request,
+		}, request.Amount)
+	}
 
-		if remainingAttempts <= 0 {
-			result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Account blocked due to multiple failed PIN attempts", nil)
-			return ctx.JSON(http.StatusForbidden, result)
-		}
+	return svc.executeWithdraw(ctx, account, *request)
+}
 
-		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE,
-			"Invalid PIN. "+strconv.Itoa(remainingAttempts)+" attempt(s) remaining", nil)
-		return ctx.JSON(http.StatusUnauthorized, result)
-	}
+// executeWithdraw posting penarikan dari akun yang sudah terotorisasi (PIN, dan OTP untuk nominal besar)
+func (svc transactionService) executeWithdraw(ctx echo.Context, account models.Account, request models.RequestWithdraw) error {
+	var (
+		result                      models.Response
+		serviceName                 = "TransactionService.Withdraw"
+		transactionTime             = time.Now()
+		updatedAt                   = transactionTime.Format(constans.LAYOUT_TIMESTAMP)
+		referenceNo                 = utils.GenerateReferenceNo()
+		balanceAfter, balanceBefore models.Money
+		fee                         models.Money
 
-	// Reset failed attempts on successful PIN
-	svc.Service.AccountRepo.ResetFailedPINAttempts(request.AccountNumber)
+		transaction models.Transaction
+		response    models.WithdrawResponse
+	)
 
-	if account.Balance < request.Amount {
+	// Dana yang sedang di-hold tidak bisa ditarik
+	if account.AvailableBalance().LessThan(request.Amount) {
 		utils.LogError(serviceName, request.AccountNumber, "Withdraw.CheckBalance",
-			fmt.Errorf("Insufficient balance. Current: %.2f, Requested: %.2f", account.Balance, request.Amount))
+			fmt.Errorf("Insufficient balance. Available: %s, Requested: %s", account.AvailableBalance(), request.Amount))
 		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Insufficient balance", nil)
 		return ctx.JSON(http.StatusBadRequest, result)
 	}
 
-	err = utils.DBTransaction(svc.Service.RepoDB, func(tx *sql.Tx) error {
+	err := utils.DBTransaction(svc.Service.RepoDB, func(tx *sql.Tx) error {
 		lastBalance, err := svc.Service.AccountRepo.IncrementDecrementLastBalance(
 			account.ID,
 			request.Amount,
@@ -223,27 +260,78 @@ func (svc transactionService) Withdraw(ctx echo.Context) error {
 			return err
 		}
 		balanceBefore = account.Balance
+
+		if err := limitService.NewLimitService(svc.Service).CheckTransactionLimit(account, constans.JOURNAL_TYPE_WITHDRAW, request.Amount, tx); err != nil {
+			return err
+		}
+
+		fee, err = feeService.NewFeeService(svc.Service).CalculateFee(account, constans.JOURNAL_TYPE_WITHDRAW, request.Amount, tx)
+		if err != nil {
+			return err
+		}
+
+		if fee.IsPositive() {
+			lastBalance, err = svc.Service.AccountRepo.IncrementDecrementLastBalance(account.ID, fee, "-", updatedAt, tx)
+			if err != nil {
+				return err
+			}
+		}
 		balanceAfter = lastBalance
 
-		if balanceAfter < 0 {
+		// Dicek terhadap saldo tersedia (saldo buku dikurangi hold aktif) setelah row akun terkunci
+		availableBalance, err := svc.Service.AccountRepo.GetAvailableBalance(account.ID, tx)
+		if err != nil {
+			return err
+		}
+
+		if availableBalance.IsNegative() {
 			return &utils.TransactionError{
 				Code:    constans.ACCOUNT_BALANCE_BELOW_MINIMUM_CODE,
 				Message: "Account balance below minimum",
 			}
 		}
 
+		// Jurnal: Debit rekening nasabah, Credit CASH_VAULT (+ biaya ke FEE_INCOME)
+		postings := []models.Posting{
+			{LedgerCode: account.AccountNumber, Direction: "D", Amount: request.Amount},
+			{LedgerCode: constans.LEDGER_CASH_VAULT, Direction: "C", Amount: request.Amount},
+		}
+		if fee.IsPositive() {
+			postings = append(postings,
+				models.Posting{LedgerCode: account.AccountNumber, Direction: "D", Amount: fee},
+				models.Posting{LedgerCode: constans.LEDGER_FEE_INCOME, Direction: "C", Amount: fee},
+			)
+		}
+
+		journalID, err := svc.Service.LedgerRepo.PostJournal(models.JournalEntry{
+			ReferenceNo: referenceNo,
+			JournalType: constans.JOURNAL_TYPE_WITHDRAW,
+			Description: "Penarikan Tunai " + account.AccountNumber,
+			Postings:    postings,
+		}, tx)
+		if err != nil {
+			return err
+		}
+
 		transaction = models.Transaction{
 			AccountID:         account.ID,
 			AccountNumber:     account.AccountNumber,
 			AccountName:       account.AccountName,
 			TransactionType:   "D",
 			Amount:            request.Amount,
+			Fee:               fee,
+			JournalID:         journalID,
 			TransactionTime:   transactionTime,
 			SourceNumber:      account.AccountNumber,
 			BeneficiaryNumber: account.AccountNumber,
 		}
 
-		_, err = svc.Service.TransactionRepo.AddTransaction(transaction)
+		transaction.ID, err = svc.Service.TransactionRepo.AddTransaction(transaction, tx)
+		if err != nil {
+			return err
+		}
+
+		err = webhookService.NewWebhookService(svc.Service).PublishTransactionCreated(tx, transaction)
 		if err != nil {
 			return err
 		}
@@ -259,6 +347,13 @@ func (svc transactionService) Withdraw(ctx echo.Context) error {
 				result = helpers.ResponseJSON(false, constans.ACCOUNT_BALANCE_BELOW_MINIMUM_CODE, txErr.Message, nil)
 				return ctx.JSON(http.StatusBadRequest, result)
 			}
+
+			if txErr.Code == constans.TRANSACTION_LIMIT_EXCEEDED_CODE {
+				utils.LogError(serviceName, request.AccountNumber, "Withdraw.LimitExceeded",
+					fmt.Errorf("%s", txErr.Message))
+				result = helpers.ResponseJSON(false, constans.TRANSACTION_LIMIT_EXCEEDED_CODE, txErr.Message, nil)
+				return ctx.JSON(http.StatusBadRequest, result)
+			}
 		}
 
 		utils.LogError(serviceName, request.AccountNumber, "Withdraw.DBTransaction", err)
@@ -266,35 +361,43 @@ func (svc transactionService) Withdraw(ctx echo.Context) error {
 		return ctx.JSON(http.StatusInternalServerError, result)
 	}
 
+	metrics.RecordTransaction(constans.JOURNAL_TYPE_WITHDRAW, request.Amount)
 	utils.LogInfo(serviceName, request.AccountNumber, "Withdraw.Success",
-		fmt.Sprintf("Amount: %.2f, Balance Before: %.2f, Balance After: %.2f", request.Amount, balanceBefore, balanceAfter))
+		fmt.Sprintf("Amount: %s, Fee: %s, Balance Before: %s, Balance After: %s", request.Amount, fee, balanceBefore, balanceAfter))
 
 	response = models.WithdrawResponse{
+		ReferenceNo:     referenceNo,
 		AccountNumber:   account.AccountNumber,
 		AccountName:     account.AccountName,
 		BalanceBefore:   balanceBefore,
 		Amount:          request.Amount,
+		Fee:             fee,
+		TotalDebit:      request.Amount.Add(fee),
 		BalanceAfter:    balanceAfter,
+		Currency:        balanceAfter.CurrencyCode(),
 		TransactionDate: updatedAt,
 	}
 
+	helpers.AuditBalanceChange(ctx, referenceNo, models.AuditBalanceChange{
+		AccountNumber: account.AccountNumber,
+		Before:        balanceBefore,
+		After:         balanceAfter,
+	})
+
 	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Withdraw successful", response)
 	return ctx.JSON(http.StatusOK, result)
 }
 
 // Transfer antar akun
 func (svc transactionService) Transfer(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
 	var (
 		result      models.Response
 		serviceName = "TransactionService.Transfer"
 		// request          models.RequestTransfer
-		request          = new(models.RequestTransfer)
-		fromBalanceAfter float64
-		toBalanceAfter   float64
-
-		debitTransaction  models.Transaction
-		creditTransaction models.Transaction
-		response          models.TransferResponse
+		request = new(models.RequestTransfer)
+		quote   *models.TransferInquiry
 	)
 
 	if err := helpers.BindValidateStruct(ctx, request); err != nil {
@@ -303,8 +406,40 @@ func (svc transactionService) Transfer(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, result)
 	}
 
+	// Subject selalu dari token, bukan dari body request
+	request.FromAccountNumber = helpers.GetAccountNumber(ctx)
+
 	utils.LogInfo(serviceName, request.FromAccountNumber, "Transfer",
-		fmt.Sprintf("To: %s, Amount: %.2f", request.ToAccountNumber, request.Amount))
+		fmt.Sprintf("To: %s, Amount: %s, Inquiry: %s, Currency: %s", request.ToAccountNumber, request.Amount, request.InquiryID, request.Currency))
+
+	// Dengan inquiry_id, penerima dan nominal diambil dari quote, bukan dari body
+	if request.InquiryID != "" {
+		inquiry, err := getTransferInquiry(svc.Service.Context, request.InquiryID)
+		if err != nil || inquiry.FromAccountNumber != request.FromAccountNumber {
+			utils.LogError(serviceName, request.FromAccountNumber, "Transfer.GetTransferInquiry", err)
+			result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Transfer inquiry expired or not found", nil)
+			return ctx.JSON(http.StatusNotFound, result)
+		}
+
+		if (request.ToAccountNumber != "" && request.ToAccountNumber != inquiry.ToAccountNumber) ||
+			(!request.Amount.IsZero() && request.Amount.Cmp(inquiry.Amount) != 0) {
+			utils.LogError(serviceName, request.FromAccountNumber, "Transfer.ValidateInquiry",
+				fmt.Errorf("Request does not match inquiry %s", request.InquiryID))
+			result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Beneficiary or amount does not match transfer inquiry", nil)
+			return ctx.JSON(http.StatusBadRequest, result)
+		}
+
+		request.ToAccountNumber = inquiry.ToAccountNumber
+		request.Amount = inquiry.Amount
+		quote = &inquiry
+	}
+
+	if request.ToAccountNumber == "" || request.Amount.IsZero() {
+		utils.LogError(serviceName, request.FromAccountNumber, "Transfer.ValidateRequest",
+			fmt.Errorf("beneficiary_number and amount are required without inquiry_id"))
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "beneficiary_number and amount are required without inquiry_id", nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
 
 	if request.FromAccountNumber == request.ToAccountNumber {
 		utils.LogError(serviceName, request.FromAccountNumber, "Transfer.ValidateSameAccount",
@@ -320,59 +455,289 @@ func (svc transactionService) Transfer(ctx echo.Context) error {
 		return ctx.JSON(http.StatusNotFound, result)
 	}
 
-	// Check account status
-	if fromAccount.AccountStatus == "BLOCKED_PIN" {
-		utils.LogError(serviceName, request.FromAccountNumber, "Transfer.CheckSourceAccountStatus",
-			fmt.Errorf("Source account is blocked"))
-		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Account is blocked. Please reset your PIN", nil)
-		return ctx.JSON(http.StatusForbidden, result)
+	// Cek blokir dan verifikasi PIN sesuai kebijakan lockout
+	if pinErr := pinLockoutService.NewPINLockoutService(svc.Service).VerifyPIN(fromAccount, request.PIN); pinErr != nil {
+		utils.LogError(serviceName, request.FromAccountNumber, "Transfer.VerifyPIN", pinErr)
+		result = helpers.ResponseJSON(false, pinErr.Code, pinErr.Message, nil)
+		return ctx.JSON(pinErr.StatusCode, result)
 	}
 
-	// Verify PIN with failed attempts tracking
-	if !helpers.CheckPINHash(request.PIN, fromAccount.PIN) {
-		failedAttempts, _ := svc.Service.AccountRepo.IncrementFailedPINAttempts(request.FromAccountNumber)
-		remainingAttempts := 3 - failedAttempts
+	// Nominal besar baru dieksekusi setelah OTP dikonfirmasi lewat /transaction/confirm.
+	// Quote inquiry belum diambil agar tetap bisa dipakai saat konfirmasi
+	if svc.Service.Config.StepUp.Required(request.Amount) {
+		request.PIN = ""
+		return svc.startStepUp(ctx, fromAccount, models.StepUpChallenge{
+			Type:     constans.STEP_UP_TYPE_TRANSFER,
+			Transfer: request,
+		}, request.Amount)
+	}
 
-		utils.LogError(serviceName, request.FromAccountNumber, "Transfer.VerifyPIN",
-			fmt.Errorf("Invalid PIN. Remaining attempts: %d", remainingAttempts))
+	return svc.executeTransfer(ctx, fromAccount, *request, quote)
+}
+
+// executeTransfer posting transfer dari akun yang sudah terotorisasi (PIN, dan OTP untuk nominal
+// besar). quote berisi inquiry yang dipakai request, diambil (sekali pakai) di sini
+func (svc transactionService) executeTransfer(ctx echo.Context, fromAccount models.Account, request models.RequestTransfer,
+	quote *models.TransferInquiry) error {
+	var (
+		result      models.Response
+		serviceName = "TransactionService.Transfer"
+	)
+
+	// Transfer selalu settle di mata uang utama. Dana dari pocket valas harus dikonversi
+	// secara eksplisit (convert=true) sebesar total debit dengan kurs yang berlaku
+	var (
+		fund       func(tx *sql.Tx, totalDebit models.Money) (models.Money, error)
+		conversion models.FxConversion
+	)
+	if request.Currency != "" && request.Currency != constans.DEFAULT_CURRENCY {
+		if !request.Convert {
+			utils.LogError(serviceName, request.FromAccountNumber, "Transfer.ValidateCurrency",
+				fmt.Errorf("Cross-currency transfer from %s pocket without convert", request.Currency))
+			result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE,
+				"Cross-currency transfer is not allowed. Set convert=true to pay from the "+request.Currency+" pocket", nil)
+			return ctx.JSON(http.StatusBadRequest, result)
+		}
 
-		if remainingAttempts <= 0 {
-			result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Account blocked due to multiple failed PIN attempts", nil)
-			return ctx.JSON(http.StatusForbidden, result)
+		fxSvc := fxService.NewFxService(svc.Service)
+		fxQuote, err := fxSvc.GetQuote(request.Currency, constans.DEFAULT_CURRENCY)
+		if err != nil {
+			utils.LogError(serviceName, request.FromAccountNumber, "Transfer.GetFxQuote", err)
+			result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Exchange rate not available", nil)
+			return ctx.JSON(http.StatusNotFound, result)
 		}
 
-		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE,
-			"Invalid PIN. "+strconv.Itoa(remainingAttempts)+" attempt(s) remaining", nil)
-		return ctx.JSON(http.StatusUnauthorized, result)
+		fund = func(tx *sql.Tx, totalDebit models.Money) (models.Money, error) {
+			var err error
+			conversion, err = fxSvc.ConvertWithTx(tx, fromAccount, fxQuote, fxQuote.SourceFor(totalDebit), totalDebit,
+				utils.GenerateReferenceNo(), time.Now().Format(constans.LAYOUT_TIMESTAMP))
+			return conversion.TargetBalance, err
+		}
 	}
 
-	// Reset failed attempts on successful PIN
-	svc.Service.AccountRepo.ResetFailedPINAttempts(request.FromAccountNumber)
+	var inTx func(tx *sql.Tx, response models.TransferResponse) error
+	if quote != nil {
+		// Inquiry sekali pakai, diambil setelah PIN valid agar salah PIN tidak menghanguskan quote
+		if _, err := config.ConsumeTransferInquiry(svc.Service.Context, quote.InquiryID); err != nil {
+			utils.LogError(serviceName, request.FromAccountNumber, "Transfer.ConsumeTransferInquiry", err)
+			result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Transfer inquiry expired or already used", nil)
+			return ctx.JSON(http.StatusConflict, result)
+		}
 
-	if fromAccount.Balance < request.Amount {
-		utils.LogError(serviceName, request.FromAccountNumber, "Transfer.CheckBalance",
-			fmt.Errorf("Insufficient balance. Current: %.2f, Requested: %.2f", fromAccount.Balance, request.Amount))
-		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Insufficient balance", nil)
+		// Biaya aktual harus sama dengan yang ditampilkan saat inquiry
+		inTx = func(tx *sql.Tx, response models.TransferResponse) error {
+			if response.Fee.Cmp(quote.Fee) != 0 {
+				return &utils.TransactionError{
+					Code:    constans.VALIDATE_ERROR_CODE,
+					Message: "Transfer fee has changed since inquiry. Please repeat the inquiry",
+				}
+			}
+			return nil
+		}
+	}
+
+	response, err := svc.ProcessTransfer(fromAccount, request.ToAccountNumber, request.Amount, fund, inTx)
+	if err != nil {
+		if txErr, ok := err.(*utils.TransactionError); ok {
+			utils.LogError(serviceName, request.FromAccountNumber, "Transfer.ProcessTransfer",
+				fmt.Errorf("%s", txErr.Message), fmt.Sprintf("Beneficiary account: %s", request.ToAccountNumber))
+			result = helpers.ResponseJSON(false, txErr.Code, txErr.Message, nil)
+			if txErr.Code == constans.DATA_NOT_FOUND_CODE {
+				return ctx.JSON(http.StatusNotFound, result)
+			}
+			return ctx.JSON(http.StatusBadRequest, result)
+		}
+
+		utils.LogError(serviceName, request.FromAccountNumber, "Transfer.DBTransaction", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Transaction failed: "+err.Error(), nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	if fund != nil {
+		response.Conversion = &conversion
+	}
+
+	helpers.AuditBalanceChange(ctx, response.ReferenceNo,
+		models.AuditBalanceChange{AccountNumber: response.FromAccountNumber, Before: response.FromBalanceBefore, After: response.FromBalanceAfter},
+		models.AuditBalanceChange{AccountNumber: response.ToAccountNumber, Before: response.ToBalanceBefore, After: response.ToBalanceAfter},
+	)
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Transfer successful", response)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// TransferInquiry tahap pertama transfer: validasi penerima, hitung biaya dan simpan quote
+// di Redis dengan masa berlaku singkat. Posting dilakukan lewat Transfer dengan inquiry_id
+func (svc transactionService) TransferInquiry(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result      models.Response
+		serviceName = "TransactionService.TransferInquiry"
+		request     = new(models.RequestTransferInquiry)
+	)
+
+	if err := helpers.BindValidateStruct(ctx, request); err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "TransferInquiry.BindValidateStruct", err)
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	// Subject selalu dari token, bukan dari body request
+	request.FromAccountNumber = helpers.GetAccountNumber(ctx)
+
+	utils.LogInfo(serviceName, request.FromAccountNumber, "TransferInquiry",
+		fmt.Sprintf("To: %s, Amount: %s", request.ToAccountNumber, request.Amount))
+
+	if request.FromAccountNumber == request.ToAccountNumber {
+		utils.LogError(serviceName, request.FromAccountNumber, "TransferInquiry.ValidateSameAccount",
+			fmt.Errorf("Cannot transfer to same account"))
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Cannot transfer to same account", nil)
 		return ctx.JSON(http.StatusBadRequest, result)
 	}
 
+	fromAccount, err := svc.Service.AccountRepo.FindAccountByNumber(request.FromAccountNumber)
+	if err != nil {
+		utils.LogError(serviceName, request.FromAccountNumber, "TransferInquiry.FindSourceAccount", err)
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Source account not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+
+	if pinErr := pinLockoutService.CheckLock(fromAccount, time.Now()); pinErr != nil {
+		utils.LogError(serviceName, request.FromAccountNumber, "TransferInquiry.CheckLock", pinErr)
+		result = helpers.ResponseJSON(false, pinErr.Code, pinErr.Message, nil)
+		return ctx.JSON(pinErr.StatusCode, result)
+	}
+
 	toAccount, err := svc.Service.AccountRepo.FindAccountByNumber(request.ToAccountNumber)
 	if err != nil {
-		utils.LogError(serviceName, request.FromAccountNumber, "Transfer.FindBeneficiaryAccount", err,
+		utils.LogError(serviceName, request.FromAccountNumber, "TransferInquiry.FindBeneficiaryAccount", err,
 			fmt.Sprintf("Beneficiary account: %s", request.ToAccountNumber))
 		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Beneficiary account not found", nil)
 		return ctx.JSON(http.StatusNotFound, result)
 	}
 
+	fee, err := feeService.NewFeeService(svc.Service).CalculateFee(fromAccount, constans.JOURNAL_TYPE_TRANSFER, request.Amount, nil)
+	if err != nil {
+		utils.LogError(serviceName, request.FromAccountNumber, "TransferInquiry.CalculateFee", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to calculate transfer fee", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	totalDebit := request.Amount.Add(fee)
+	if fromAccount.AvailableBalance().LessThan(totalDebit) {
+		utils.LogError(serviceName, request.FromAccountNumber, "TransferInquiry.CheckBalance",
+			fmt.Errorf("Insufficient balance. Available: %s, Required: %s", fromAccount.AvailableBalance(), totalDebit))
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Insufficient balance", nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	inquiryID, err := helpers.GenerateSecureID()
+	if err != nil {
+		utils.LogError(serviceName, request.FromAccountNumber, "TransferInquiry.GenerateSecureID", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to create transfer inquiry", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	ttl := svc.Service.Config.TransferInquiryTTL()
+	inquiry := models.TransferInquiry{
+		InquiryID:         inquiryID,
+		FromAccountNumber: fromAccount.AccountNumber,
+		ToAccountNumber:   toAccount.AccountNumber,
+		BeneficiaryName:   toAccount.AccountName,
+		Amount:            request.Amount,
+		Fee:               fee,
+		TotalDebit:        totalDebit,
+		Currency:          request.Amount.CurrencyCode(),
+		ExpiresAt:         time.Now().Add(ttl),
+	}
+
+	value, _ := json.Marshal(inquiry)
+	if err := config.SetTransferInquiry(svc.Service.Context, inquiryID, string(value), ttl); err != nil {
+		utils.LogError(serviceName, request.FromAccountNumber, "TransferInquiry.SetTransferInquiry", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to create transfer inquiry", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	utils.LogInfo(serviceName, request.FromAccountNumber, "TransferInquiry.Success",
+		fmt.Sprintf("Inquiry: %s, To: %s (%s), Amount: %s, Fee: %s", inquiryID, toAccount.AccountNumber,
+			toAccount.AccountName, request.Amount, fee))
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Transfer inquiry successful", inquiry)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// getTransferInquiry membaca quote transfer dari Redis
+func getTransferInquiry(ctx context.Context, inquiryID string) (models.TransferInquiry, error) {
+	var inquiry models.TransferInquiry
+
+	value, err := config.GetTransferInquiry(ctx, inquiryID)
+	if err != nil {
+		return inquiry, err
+	}
+
+	err = json.Unmarshal([]byte(value), &inquiry)
+	return inquiry, err
+}
+
+// ErrInsufficientBalance saldo pengirim tidak cukup untuk nominal transfer
+var ErrInsufficientBalance = &utils.TransactionError{
+	Code:    constans.VALIDATE_ERROR_CODE,
+	Message: "Insufficient balance",
+}
+
+// ProcessTransfer memindahkan dana dari akun yang sudah terotorisasi (PIN atau jadwal)
+// ke rekening penerima dalam satu DBTransaction. fund opsional mengisi saldo utama sebesar
+// total debit (amount + biaya) di dalam tx, misalnya konversi dari pocket valas, dan
+// mengembalikan saldo utama setelahnya. inTx opsional dijalankan di dalam transaksi yang
+// sama setelah transfer diposting, sehingga pencatatan pemanggil ikut commit atau rollback
+// bersama transfer. Error bisnis dikembalikan sebagai *utils.TransactionError
+func (svc transactionService) ProcessTransfer(fromAccount models.Account, toAccountNumber string, amount models.Money,
+	fund func(tx *sql.Tx, totalDebit models.Money) (models.Money, error),
+	inTx func(tx *sql.Tx, response models.TransferResponse) error) (models.TransferResponse, error) {
+	var (
+		serviceName      = "TransactionService.ProcessTransfer"
+		fromBalanceAfter models.Money
+		toBalanceAfter   models.Money
+		fee              models.Money
+
+		debitTransaction  models.Transaction
+		creditTransaction models.Transaction
+		response          models.TransferResponse
+	)
+
+	if fromAccount.AccountNumber == toAccountNumber {
+		return response, &utils.TransactionError{
+			Code:    constans.VALIDATE_ERROR_CODE,
+			Message: "Cannot transfer to same account",
+		}
+	}
+
+	// Dana yang sedang di-hold tidak bisa ditransfer. Dengan fund saldo dicek di dalam tx
+	if fund == nil && fromAccount.AvailableBalance().LessThan(amount) {
+		utils.LogError(serviceName, fromAccount.AccountNumber, "CheckBalance",
+			fmt.Errorf("Insufficient balance. Available: %s, Requested: %s", fromAccount.AvailableBalance(), amount))
+		return response, ErrInsufficientBalance
+	}
+
+	toAccount, err := svc.Service.AccountRepo.FindAccountByNumber(toAccountNumber)
+	if err != nil {
+		return response, &utils.TransactionError{
+			Code:    constans.DATA_NOT_FOUND_CODE,
+			Message: "Beneficiary account not found",
+		}
+	}
+
 	fromBalanceBefore := fromAccount.Balance
 	toBalanceBefore := toAccount.Balance
 	transactionTime := time.Now()
 	updatedAt := transactionTime.Format(constans.LAYOUT_TIMESTAMP)
+	referenceNo := utils.GenerateReferenceNo()
 
 	err = utils.DBTransaction(svc.Service.RepoDB, func(tx *sql.Tx) error {
 		lastBalance, err := svc.Service.AccountRepo.IncrementDecrementLastBalance(
 			fromAccount.ID,
-			request.Amount,
+			amount,
 			"-",
 			updatedAt,
 			tx,
@@ -380,34 +745,92 @@ func (svc transactionService) Transfer(ctx echo.Context) error {
 		if err != nil {
 			return err
 		}
+
+		if err := limitService.NewLimitService(svc.Service).CheckTransactionLimit(fromAccount, constans.JOURNAL_TYPE_TRANSFER, amount, tx); err != nil {
+			return err
+		}
+
+		fee, err = feeService.NewFeeService(svc.Service).CalculateFee(fromAccount, constans.JOURNAL_TYPE_TRANSFER, amount, tx)
+		if err != nil {
+			return err
+		}
+
+		if fee.IsPositive() {
+			lastBalance, err = svc.Service.AccountRepo.IncrementDecrementLastBalance(fromAccount.ID, fee, "-", updatedAt, tx)
+			if err != nil {
+				return err
+			}
+		}
 		fromBalanceAfter = lastBalance
 
-		if fromBalanceAfter < 0 {
+		if fund != nil {
+			fromBalanceAfter, err = fund(tx, amount.Add(fee))
+			if err != nil {
+				return err
+			}
+		}
+
+		// Dicek terhadap saldo tersedia (saldo buku dikurangi hold aktif) setelah row akun terkunci
+		availableBalance, err := svc.Service.AccountRepo.GetAvailableBalance(fromAccount.ID, tx)
+		if err != nil {
+			return err
+		}
+
+		if availableBalance.IsNegative() {
 			return &utils.TransactionError{
 				Code:    constans.ACCOUNT_BALANCE_BELOW_MINIMUM_CODE,
 				Message: "Sender balance would be negative after transfer",
 			}
 		}
 
+		// Jurnal: Debit rekening pengirim, Credit rekening penerima (+ biaya ke FEE_INCOME)
+		postings := []models.Posting{
+			{LedgerCode: fromAccount.AccountNumber, Direction: "D", Amount: amount},
+			{LedgerCode: toAccount.AccountNumber, Direction: "C", Amount: amount},
+		}
+		if fee.IsPositive() {
+			postings = append(postings,
+				models.Posting{LedgerCode: fromAccount.AccountNumber, Direction: "D", Amount: fee},
+				models.Posting{LedgerCode: constans.LEDGER_FEE_INCOME, Direction: "C", Amount: fee},
+			)
+		}
+
+		journalID, err := svc.Service.LedgerRepo.PostJournal(models.JournalEntry{
+			ReferenceNo: referenceNo,
+			JournalType: constans.JOURNAL_TYPE_TRANSFER,
+			Description: "Transfer " + fromAccount.AccountNumber + " ke " + toAccount.AccountNumber,
+			Postings:    postings,
+		}, tx)
+		if err != nil {
+			return err
+		}
+
 		debitTransaction = models.Transaction{
 			AccountID:         fromAccount.ID,
 			AccountNumber:     fromAccount.AccountNumber,
 			AccountName:       fromAccount.AccountName,
 			TransactionType:   "D",
-			Amount:            request.Amount,
+			Amount:            amount,
+			Fee:               fee,
+			JournalID:         journalID,
 			TransactionTime:   transactionTime,
 			SourceNumber:      fromAccount.AccountNumber,
 			BeneficiaryNumber: toAccount.AccountNumber,
 		}
 
-		_, err = svc.Service.TransactionRepo.AddTransaction(debitTransaction)
+		debitTransaction.ID, err = svc.Service.TransactionRepo.AddTransaction(debitTransaction, tx)
+		if err != nil {
+			return err
+		}
+
+		err = webhookService.NewWebhookService(svc.Service).PublishTransactionCreated(tx, debitTransaction)
 		if err != nil {
 			return err
 		}
 
 		lastBalance, err = svc.Service.AccountRepo.IncrementDecrementLastBalance(
 			toAccount.ID,
-			request.Amount,
+			amount,
 			"+",
 			updatedAt,
 			tx,
@@ -422,57 +845,62 @@ func (svc transactionService) Transfer(ctx echo.Context) error {
 			AccountNumber:     toAccount.AccountNumber,
 			AccountName:       toAccount.AccountName,
 			TransactionType:   "C",
-			Amount:            request.Amount,
+			Amount:            amount,
+			JournalID:         journalID,
 			TransactionTime:   transactionTime,
 			SourceNumber:      fromAccount.AccountNumber,
 			BeneficiaryNumber: toAccount.AccountNumber,
 		}
 
-		_, err = svc.Service.TransactionRepo.AddTransaction(creditTransaction)
+		creditTransaction.ID, err = svc.Service.TransactionRepo.AddTransaction(creditTransaction, tx)
 		if err != nil {
 			return err
 		}
 
+		err = webhookService.NewWebhookService(svc.Service).PublishTransactionCreated(tx, creditTransaction)
+		if err != nil {
+			return err
+		}
+
+		response = models.TransferResponse{
+			ReferenceNo:       referenceNo,
+			FromAccountNumber: fromAccount.AccountNumber,
+			ToAccountNumber:   toAccount.AccountNumber,
+			Amount:            amount,
+			Fee:               fee,
+			TotalDebit:        amount.Add(fee),
+			FromBalanceBefore: fromBalanceBefore,
+			FromBalanceAfter:  fromBalanceAfter,
+			ToBalanceBefore:   toBalanceBefore,
+			ToBalanceAfter:    toBalanceAfter,
+			Currency:          amount.CurrencyCode(),
+			TransactionDate:   transactionTime,
+		}
+
+		if inTx != nil {
+			return inTx(tx, response)
+		}
+
 		return nil
 	})
 
 	if err != nil {
-		if txErr, ok := err.(*utils.TransactionError); ok {
-			if txErr.Code == constans.ACCOUNT_BALANCE_BELOW_MINIMUM_CODE {
-				utils.LogError(serviceName, request.FromAccountNumber, "Transfer.BalanceBelowMinimum",
-					fmt.Errorf("%s", txErr.Message))
-				result = helpers.ResponseJSON(false, constans.ACCOUNT_BALANCE_BELOW_MINIMUM_CODE, txErr.Message, nil)
-				return ctx.JSON(http.StatusBadRequest, result)
-			}
-		}
-
-		utils.LogError(serviceName, request.FromAccountNumber, "Transfer.DBTransaction", err)
-		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Transaction failed: "+err.Error(), nil)
-		return ctx.JSON(http.StatusInternalServerError, result)
+		return models.TransferResponse{}, err
 	}
 
-	utils.LogInfo(serviceName, request.FromAccountNumber, "Transfer.Success",
-		fmt.Sprintf("To: %s (%s), Amount: %.2f, From Balance: %.2f->%.2f, To Balance: %.2f->%.2f",
-			toAccount.AccountNumber, toAccount.AccountName, request.Amount,
+	metrics.RecordTransaction(constans.JOURNAL_TYPE_TRANSFER, amount)
+	utils.LogInfo(serviceName, fromAccount.AccountNumber, "Success",
+		fmt.Sprintf("To: %s (%s), Amount: %s, Fee: %s, From Balance: %s->%s, To Balance: %s->%s",
+			toAccount.AccountNumber, toAccount.AccountName, amount, fee,
 			fromBalanceBefore, fromBalanceAfter, toBalanceBefore, toBalanceAfter))
 
-	response = models.TransferResponse{
-		FromAccountNumber: request.FromAccountNumber,
-		ToAccountNumber:   request.ToAccountNumber,
-		Amount:            request.Amount,
-		FromBalanceBefore: fromBalanceBefore,
-		FromBalanceAfter:  fromBalanceAfter,
-		ToBalanceBefore:   toBalanceBefore,
-		ToBalanceAfter:    toBalanceAfter,
-		TransactionDate:   time.Now(),
-	}
-
-	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Transfer successful", response)
-	return ctx.JSON(http.StatusOK, result)
+	return response, nil
 }
 
 // GetTransactionHistory mendapatkan riwayat transaksi
 func (svc transactionService) GetTransactionHistory(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
 	var (
 		result      models.Response
 		serviceName = "TransactionService.GetTransactionHistory"
@@ -488,6 +916,9 @@ func (svc transactionService) GetTransactionHistory(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, result)
 	}
 
+	// Subject selalu dari token, bukan dari body request
+	request.AccountNumber = helpers.GetAccountNumber(ctx)
+
 	refNo := request.AccountNumber
 	if refNo == "" {
 		refNo = "ALL_ACCOUNTS"
@@ -540,6 +971,7 @@ func (svc transactionService) GetTransactionHistory(ctx echo.Context) error {
 		request.AccountNumber,
 		request.StartDate,
 		request.EndDate,
+		request.Status,
 		limit,
 		page,
 	)
@@ -605,6 +1037,8 @@ func (svc transactionService) GetTransactionHistory(ctx echo.Context) error {
 
 // GetTransactionDetail mendapatkan detail transaksi
 func (svc transactionService) GetTransactionDetail(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
 	var (
 		result      models.Response
 		serviceName = "TransactionService.GetTransactionDetail"
@@ -630,18 +1064,29 @@ func (svc transactionService) GetTransactionDetail(ctx echo.Context) error {
 		return ctx.JSON(http.StatusNotFound, result)
 	}
 
+	// Transaksi hanya boleh dilihat oleh pemilik rekening
+	if transaction.AccountNumber != helpers.GetAccountNumber(ctx) {
+		utils.LogError(serviceName, refNo, "GetTransactionDetail.CheckOwnership",
+			fmt.Errorf("Transaction does not belong to token subject"))
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Transaction not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+
 	utils.LogInfo(serviceName, refNo, "GetTransactionDetail.Success",
-		fmt.Sprintf("Account: %s, Type: %s, Amount: %.2f",
+		fmt.Sprintf("Account: %s, Type: %s, Amount: %s",
 			transaction.AccountNumber, transaction.TransactionType, transaction.Amount))
 
 	response = models.TransactionDetailResponse{
 		ID:                transaction.ID,
+		ReversalOf:        transaction.ReversalOf,
+		Status:            transaction.Status,
 		AccountNumber:     transaction.AccountNumber,
 		AccountName:       transaction.AccountName,
 		SourceNumber:      transaction.SourceNumber,
 		BeneficiaryNumber: transaction.BeneficiaryNumber,
 		TransactionType:   transaction.TransactionType,
 		Amount:            transaction.Amount,
+		Fee:               transaction.Fee,
 		CreatedAt:         time.Now().Format(constans.LAYOUT_TIMESTAMP),
 		TransactionTime:   transaction.TransactionTime.Format(constans.LAYOUT_TIMESTAMP),
 	}
@@ -649,3 +1094,67 @@ func (svc transactionService) GetTransactionDetail(ctx echo.Context) error {
 	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Transaction detail retrieved successfully", response)
 	return ctx.JSON(http.StatusOK, result)
 }
+
+// GetTransactionStatus cek status terkini transaksi (PENDING, SUCCESS, FAILED, REVERSED).
+// status_code response mengikuti status: PENDING_CODE, SUCCESS_CODE atau FAILED_CODE
+func (svc transactionService) GetTransactionStatus(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result      models.Response
+		serviceName = "TransactionService.GetTransactionStatus"
+		request     = new(models.RequestTransactionStatus)
+		code        = constans.SUCCESS_CODE
+	)
+
+	if err := helpers.BindValidateStruct(ctx, request); err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "GetTransactionStatus.BindValidateStruct", err)
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	refNo := fmt.Sprintf("TRX_ID_%d", request.TransactionID)
+
+	transaction, err := svc.Service.TransactionRepo.FindTransactionById(request.TransactionID)
+	if err != nil {
+		utils.LogError(serviceName, refNo, "GetTransactionStatus.FindTransactionById", err)
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Transaction not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+
+	// Transaksi hanya boleh dilihat oleh pemilik rekening
+	if transaction.AccountNumber != helpers.GetAccountNumber(ctx) {
+		utils.LogError(serviceName, refNo, "GetTransactionStatus.CheckOwnership",
+			fmt.Errorf("Transaction does not belong to token subject"))
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Transaction not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+
+	totalReversed, err := svc.Service.TransactionRepo.GetReversedAmount(transaction.ID, nil)
+	if err != nil {
+		utils.LogError(serviceName, refNo, "GetTransactionStatus.GetReversedAmount", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to get transaction status", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	switch transaction.Status {
+	case constans.TRANSACTION_STATUS_PENDING:
+		code = constans.PENDING_CODE
+	case constans.TRANSACTION_STATUS_FAILED:
+		code = constans.FAILED_CODE
+	}
+
+	response := models.TransactionStatusResponse{
+		TransactionID:   transaction.ID,
+		Status:          transaction.Status,
+		TransactionType: transaction.TransactionType,
+		Amount:          transaction.Amount,
+		TotalReversed:   totalReversed,
+		TransactionTime: transaction.TransactionTime.Format(constans.LAYOUT_TIMESTAMP),
+	}
+
+	utils.LogInfo(serviceName, refNo, "GetTransactionStatus.Success", "Status: "+transaction.Status)
+
+	result = helpers.ResponseJSON(true, code, "Transaction status: "+transaction.Status, response)
+	return ctx.JSON(http.StatusOK, result)
+}
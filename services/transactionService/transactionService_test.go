@@ -0,0 +1,684 @@
+package transactionService
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"sample/config"
+	"sample/constans"
+	"sample/helpers"
+	"sample/models"
+	"sample/repositories"
+	"sample/repositories/memoryRepository"
+	"sample/services"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/labstack/echo"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+const testPIN = "123456"
+
+// testValidator validator yang sama dengan main.go: tag money_min untuk models.Money
+type testValidator struct {
+	validator *validator.Validate
+}
+
+func (v testValidator) Validate(i interface{}) error {
+	return v.validator.Struct(i)
+}
+
+// ledgerRepo jurnal selalu berhasil, hanya mencatat nomor referensi
+type ledgerRepo struct {
+	repositories.LedgerRepository
+	mu       sync.Mutex
+	journals []string
+}
+
+func (r *ledgerRepo) PostJournal(journal models.JournalEntry, tx *sql.Tx) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.journals = append(r.journals, journal.ReferenceNo)
+	return len(r.journals), nil
+}
+
+// limitRepo tanpa limit transaksi
+type limitRepo struct {
+	repositories.LimitRepository
+}
+
+func (limitRepo) FindTransactionLimit(tier, transactionType string) (models.TransactionLimit, error) {
+	return models.TransactionLimit{Tier: tier, TransactionType: transactionType}, nil
+}
+
+func (limitRepo) GetLimitUsage(accountID int, transactionType, direction string, now time.Time, tx *sql.Tx) (models.LimitUsage, error) {
+	zero := models.NewMoney(0, constans.DEFAULT_CURRENCY)
+	return models.LimitUsage{DailyAmount: zero, MonthlyAmount: zero}, nil
+}
+
+// feeRepo skema biaya per jenis transaksi, tanpa aturan berarti gratis
+type feeRepo struct {
+	repositories.FeeRepository
+	rules map[string]models.FeeRule
+}
+
+func (r feeRepo) FindFeeRule(transactionType string) (models.FeeRule, error) {
+	rule, ok := r.rules[transactionType]
+	if !ok {
+		return rule, sql.ErrNoRows
+	}
+	return rule, nil
+}
+
+// webhookRepo mencatat event outbox yang dipublikasikan
+type webhookRepo struct {
+	repositories.WebhookRepository
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *webhookRepo) AddOutboxEvent(event models.WebhookEvent, tx *sql.Tx) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event.EventType)
+	return len(r.events), nil
+}
+
+func (r *webhookRepo) count(eventType string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := 0
+	for _, event := range r.events {
+		if event == eventType {
+			count++
+		}
+	}
+	return count
+}
+
+type testEnv struct {
+	echo            *echo.Echo
+	svc             transactionService
+	accountRepo     repositories.AccountRepository
+	transactionRepo repositories.TransactionRepository
+	webhookRepo     *webhookRepo
+}
+
+func newTestEnv(t *testing.T, feeRules map[string]models.FeeRule) *testEnv {
+	t.Helper()
+
+	store := memoryRepository.NewStore()
+	env := &testEnv{
+		echo:            echo.New(),
+		accountRepo:     memoryRepository.NewAccountRepository(store),
+		transactionRepo: memoryRepository.NewTransactionRepository(store),
+		webhookRepo:     &webhookRepo{},
+	}
+
+	validate := validator.New()
+	locale := id.New()
+	trans, _ := ut.New(locale, locale).GetTranslator("id")
+	helpers.RegisterMoneyValidation(validate, trans)
+	env.echo.Validator = testValidator{validator: validate}
+
+	env.svc = NewTransactionService(services.NewUsecaseService(store.DB(),
+		env.accountRepo,
+		env.transactionRepo,
+		&ledgerRepo{},
+		nil,
+		limitRepo{},
+		feeRepo{rules: feeRules},
+		nil,
+		nil,
+		env.webhookRepo,
+		nil,
+		nil,
+		config.Default(),
+	))
+
+	return env
+}
+
+// addAccount membuat akun dengan saldo awal (dalam satuan rupiah) dan PIN testPIN
+func (env *testEnv) addAccount(t *testing.T, accountNumber string, balance string) models.Account {
+	t.Helper()
+
+	hashedPIN, err := helpers.HashPIN(testPIN)
+	if err != nil {
+		t.Fatalf("HashPIN: %v", err)
+	}
+
+	id, err := env.accountRepo.AddAccount(models.Account{
+		AccountNumber: accountNumber,
+		AccountName:   "Nasabah " + accountNumber,
+		Balance:       models.MustParseMoney(balance, constans.DEFAULT_CURRENCY),
+		PIN:           hashedPIN,
+	})
+	if err != nil {
+		t.Fatalf("AddAccount: %v", err)
+	}
+
+	account, _ := env.accountRepo.FindAccountById(id)
+	return account
+}
+
+func (env *testEnv) balance(t *testing.T, accountNumber string) string {
+	t.Helper()
+
+	account, err := env.accountRepo.FindAccountByNumber(accountNumber)
+	if err != nil {
+		t.Fatalf("FindAccountByNumber: %v", err)
+	}
+	return account.Balance.String()
+}
+
+// call memanggil handler langsung seperti setelah middleware JWT
+func (env *testEnv) call(t *testing.T, handler echo.HandlerFunc, accountNumber string, body string, result interface{}) (int, models.Response) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	ctx := env.echo.NewContext(req, rec)
+	ctx.Set(constans.CONTEXT_ACCOUNT_NUMBER, accountNumber)
+
+	if err := handler(ctx); err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+
+	var response struct {
+		models.Response
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode response %q: %v", rec.Body.String(), err)
+	}
+
+	if result != nil && response.Success {
+		if err := json.Unmarshal(response.Result, result); err != nil {
+			t.Fatalf("decode result %s: %v", response.Result, err)
+		}
+	}
+
+	return rec.Code, response.Response
+}
+
+func TestDeposit(t *testing.T) {
+	env := newTestEnv(t, nil)
+	env.addAccount(t, "1000000001", "50000")
+
+	var response models.DepositResponse
+	code, result := env.call(t, env.svc.Deposit, "1000000001", `{"amount":"25000.50","pin":"123456"}`, &response)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d (%s), want 200", code, result.Message)
+	}
+
+	if response.BalanceBefore.String() != "50000.00" || response.BalanceAfter.String() != "75000.50" {
+		t.Errorf("balance before/after = %s/%s, want 50000.00/75000.50", response.BalanceBefore, response.BalanceAfter)
+	}
+	if got := env.balance(t, "1000000001"); got != "75000.50" {
+		t.Errorf("stored balance = %s, want 75000.50", got)
+	}
+	if got := env.webhookRepo.count(constans.WEBHOOK_EVENT_TRANSACTION_CREATED); got != 1 {
+		t.Errorf("transaction.created events = %d, want 1", got)
+	}
+
+	code, _ = env.call(t, env.svc.Deposit, "1000000001", `{"amount":"5000","pin":"123456"}`, nil)
+	if code != http.StatusBadRequest {
+		t.Errorf("deposit below minimum status = %d, want 400", code)
+	}
+}
+
+func TestWithdraw(t *testing.T) {
+	env := newTestEnv(t, map[string]models.FeeRule{
+		constans.JOURNAL_TYPE_WITHDRAW: {
+			TransactionType: constans.JOURNAL_TYPE_WITHDRAW,
+			FeeType:         constans.FEE_TYPE_FLAT,
+			FlatAmount:      models.MustParseMoney("2500", constans.DEFAULT_CURRENCY),
+		},
+	})
+	env.addAccount(t, "1000000001", "100000")
+
+	var response models.WithdrawResponse
+	code, result := env.call(t, env.svc.Withdraw, "1000000001", `{"amount":"40000","pin":"123456"}`, &response)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d (%s), want 200", code, result.Message)
+	}
+
+	if response.Fee.String() != "2500.00" || response.TotalDebit.String() != "42500.00" {
+		t.Errorf("fee/total debit = %s/%s, want 2500.00/42500.00", response.Fee, response.TotalDebit)
+	}
+	if got := env.balance(t, "1000000001"); got != "57500.00" {
+		t.Errorf("stored balance = %s, want 57500.00", got)
+	}
+
+	// Saldo cukup untuk amount tapi tidak untuk biaya: ditolak di dalam tx dan di-rollback
+	code, result = env.call(t, env.svc.Withdraw, "1000000001", `{"amount":"57500","pin":"123456"}`, nil)
+	if code != http.StatusBadRequest || result.StatusCode != constans.ACCOUNT_BALANCE_BELOW_MINIMUM_CODE {
+		t.Errorf("withdraw without fee coverage = %d/%s, want 400/%s", code, result.StatusCode, constans.ACCOUNT_BALANCE_BELOW_MINIMUM_CODE)
+	}
+	if got := env.balance(t, "1000000001"); got != "57500.00" {
+		t.Errorf("balance after rollback = %s, want 57500.00", got)
+	}
+
+	code, result = env.call(t, env.svc.Withdraw, "1000000001", `{"amount":"100000","pin":"123456"}`, nil)
+	if code != http.StatusBadRequest || result.Message != "Insufficient balance" {
+		t.Errorf("withdraw over balance = %d/%s, want 400/Insufficient balance", code, result.Message)
+	}
+
+	_, total, _ := env.transactionRepo.GetTransactionHistory("1000000001", "", "", "", 0, 0)
+	if total != 1 {
+		t.Errorf("transactions = %d, want 1", total)
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	env := newTestEnv(t, nil)
+	env.addAccount(t, "1000000001", "100000")
+	env.addAccount(t, "1000000002", "10000")
+
+	var response models.TransferResponse
+	code, result := env.call(t, env.svc.Transfer, "1000000001",
+		`{"beneficiary_number":"1000000002","amount":"30000","pin":"123456"}`, &response)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d (%s), want 200", code, result.Message)
+	}
+
+	if response.FromBalanceAfter.String() != "70000.00" || response.ToBalanceAfter.String() != "40000.00" {
+		t.Errorf("balances after = %s/%s, want 70000.00/40000.00", response.FromBalanceAfter, response.ToBalanceAfter)
+	}
+	if got := env.balance(t, "1000000001"); got != "70000.00" {
+		t.Errorf("sender balance = %s, want 70000.00", got)
+	}
+	if got := env.balance(t, "1000000002"); got != "40000.00" {
+		t.Errorf("beneficiary balance = %s, want 40000.00", got)
+	}
+
+	for accountNumber, transactionType := range map[string]string{"1000000001": "D", "1000000002": "C"} {
+		transactions, total, _ := env.transactionRepo.GetTransactionHistory(accountNumber, "", "", "", 0, 0)
+		if total != 1 || transactions[0].TransactionType != transactionType {
+			t.Errorf("%s history = %+v, want one %s row", accountNumber, transactions, transactionType)
+		}
+	}
+
+	code, _ = env.call(t, env.svc.Transfer, "1000000001", `{"beneficiary_number":"9999999999","amount":"10000","pin":"123456"}`, nil)
+	if code != http.StatusNotFound {
+		t.Errorf("transfer to unknown account status = %d, want 404", code)
+	}
+
+	code, _ = env.call(t, env.svc.Transfer, "1000000001", `{"beneficiary_number":"1000000001","amount":"10000","pin":"123456"}`, nil)
+	if code != http.StatusBadRequest {
+		t.Errorf("transfer to same account status = %d, want 400", code)
+	}
+}
+
+// Nominal di atas STEP_UP_THRESHOLD_AMOUNT tidak pernah dieksekusi tanpa konfirmasi OTP.
+// Notifier dikosongkan agar challenge gagal dibuat tanpa menyentuh Redis
+func TestStepUpRequired(t *testing.T) {
+	env := newTestEnv(t, nil)
+	env.svc.Service.Config.StepUp.ThresholdAmount = 50000
+	env.svc.Service.Notifier = nil
+	env.addAccount(t, "1000000001", "100000")
+	env.addAccount(t, "1000000002", "0")
+
+	code, result := env.call(t, env.svc.Withdraw, "1000000001", `{"amount":"60000","pin":"123456"}`, nil)
+	if code != http.StatusInternalServerError || result.Message != "Failed to send OTP. Please try again" {
+		t.Errorf("withdraw above threshold = %d/%s, want 500/OTP failure", code, result.Message)
+	}
+	code, result = env.call(t, env.svc.Transfer, "1000000001", `{"beneficiary_number":"1000000002","amount":"50000.01","pin":"123456"}`, nil)
+	if code != http.StatusInternalServerError {
+		t.Errorf("transfer above threshold = %d/%s, want 500", code, result.Message)
+	}
+	if got := env.balance(t, "1000000001"); got != "100000.00" {
+		t.Errorf("balance after step-up = %s, want 100000.00", got)
+	}
+
+	// Tepat di batas tidak butuh OTP
+	code, result = env.call(t, env.svc.Transfer, "1000000001", `{"beneficiary_number":"1000000002","amount":"50000","pin":"123456"}`, nil)
+	if code != http.StatusOK {
+		t.Errorf("transfer at threshold = %d/%s, want 200", code, result.Message)
+	}
+
+	// PIN salah tetap ditolak sebelum challenge dibuat
+	code, _ = env.call(t, env.svc.Withdraw, "1000000001", `{"amount":"60000","pin":"654321"}`, nil)
+	if code != http.StatusUnauthorized {
+		t.Errorf("withdraw above threshold with wrong PIN = %d, want 401", code)
+	}
+}
+
+func TestPINBlocking(t *testing.T) {
+	env := newTestEnv(t, nil)
+	env.addAccount(t, "1000000001", "100000")
+
+	wrongPIN := `{"amount":"10000","pin":"654321"}`
+	failThreeTimes := func() {
+		t.Helper()
+		for i, want := range []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusForbidden} {
+			code, result := env.call(t, env.svc.Withdraw, "1000000001", wrongPIN, nil)
+			if code != want {
+				t.Fatalf("attempt %d status = %d (%s), want %d", i+1, code, result.Message, want)
+			}
+		}
+	}
+
+	// Kegagalan ke-3 mengunci sementara, bukan blokir permanen
+	failThreeTimes()
+	account, _ := env.accountRepo.FindAccountByNumber("1000000001")
+	if account.AccountStatus != constans.ACCOUNT_STATUS_ACTIVE || account.PINLockoutCount != 1 || account.LockedUntil == nil {
+		t.Fatalf("account = %s/%d/%v, want ACTIVE, 1 lockout and locked_until", account.AccountStatus, account.PINLockoutCount, account.LockedUntil)
+	}
+	if got := time.Until(*account.LockedUntil); got <= 14*time.Minute || got > 15*time.Minute {
+		t.Errorf("first lockout = %s, want 15m", got)
+	}
+
+	// PIN benar pun ditolak selama akun terkunci
+	code, result := env.call(t, env.svc.Deposit, "1000000001", `{"amount":"10000","pin":"123456"}`, nil)
+	if code != http.StatusForbidden {
+		t.Errorf("locked deposit status = %d (%s), want 403", code, result.Message)
+	}
+
+	// Kunci sementara ke-4 (setelah 3 kali) menjadi blokir permanen
+	expired := time.Now().Add(-time.Minute)
+	account.PINLockoutCount = 3
+	account.LockedUntil = &expired
+	if err := env.accountRepo.UpdatePINLockStateWithTx(nil, account); err != nil {
+		t.Fatalf("UpdatePINLockStateWithTx: %v", err)
+	}
+
+	failThreeTimes()
+	account, _ = env.accountRepo.FindAccountByNumber("1000000001")
+	if account.AccountStatus != constans.ACCOUNT_STATUS_BLOCKED_PIN || account.FailedPINAttempts != 3 {
+		t.Errorf("account = %s/%d, want BLOCKED_PIN/3", account.AccountStatus, account.FailedPINAttempts)
+	}
+	if got := env.webhookRepo.count(constans.WEBHOOK_EVENT_ACCOUNT_BLOCKED); got != 1 {
+		t.Errorf("account.blocked events = %d, want 1", got)
+	}
+
+	events, _ := env.accountRepo.GetPINLockEvents("1000000001")
+	if len(events) != 2 || events[0].EventType != constans.PIN_LOCK_EVENT_PERMANENT_BLOCK || events[1].EventType != constans.PIN_LOCK_EVENT_TEMPORARY_LOCK {
+		t.Errorf("lock events = %+v, want PERMANENT_BLOCK then TEMPORARY_LOCK", events)
+	}
+
+	code, result = env.call(t, env.svc.Deposit, "1000000001", `{"amount":"10000","pin":"123456"}`, nil)
+	if code != http.StatusForbidden {
+		t.Errorf("blocked deposit status = %d (%s), want 403", code, result.Message)
+	}
+	if got := env.balance(t, "1000000001"); got != "100000.00" {
+		t.Errorf("balance = %s, want 100000.00", got)
+	}
+}
+
+func TestTransactionHistoryPagination(t *testing.T) {
+	env := newTestEnv(t, nil)
+	env.addAccount(t, "1000000001", "0")
+
+	for i := 1; i <= 5; i++ {
+		body := fmt.Sprintf(`{"amount":"%d0000","pin":"123456"}`, i)
+		if code, result := env.call(t, env.svc.Deposit, "1000000001", body, nil); code != http.StatusOK {
+			t.Fatalf("deposit %d status = %d (%s)", i, code, result.Message)
+		}
+	}
+
+	today := time.Now().Format(constans.LAYOUT_DATE)
+	request := func(page int) string {
+		return fmt.Sprintf(`{"start_date":"%s","end_date":"%s","limit":2,"page":%d}`, today, today, page)
+	}
+
+	var response models.TransactionHistorySimpleResponse
+	code, result := env.call(t, env.svc.GetTransactionHistory, "1000000001", request(1), &response)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d (%s), want 200", code, result.Message)
+	}
+
+	pagination := response.Pagination
+	if pagination.TotalRecords != 5 || pagination.TotalPages != 3 || pagination.PerPage != 2 {
+		t.Errorf("pagination = %+v, want 5 records in 3 pages of 2", pagination)
+	}
+	if len(response.Transactions) != 2 || response.Transactions[0].Amount.String() != "50000.00" {
+		t.Errorf("page 1 = %+v, want newest first", response.Transactions)
+	}
+
+	response = models.TransactionHistorySimpleResponse{}
+	env.call(t, env.svc.GetTransactionHistory, "1000000001", request(3), &response)
+	if len(response.Transactions) != 1 || response.Transactions[0].Amount.String() != "10000.00" {
+		t.Errorf("page 3 = %+v, want only the oldest deposit", response.Transactions)
+	}
+
+	// Riwayat hanya milik subject token
+	env.addAccount(t, "1000000002", "0")
+	response = models.TransactionHistorySimpleResponse{}
+	env.call(t, env.svc.GetTransactionHistory, "1000000002", request(1), &response)
+	if response.Pagination.TotalRecords != 0 {
+		t.Errorf("other account records = %d, want 0", response.Pagination.TotalRecords)
+	}
+}
+
+// otpChannel notification.Channel yang menyimpan pesan terkirim agar test bisa membaca OTP
+type otpChannel struct {
+	mu       sync.Mutex
+	messages []models.NotificationMessage
+}
+
+func (c *otpChannel) Name() string {
+	return "test"
+}
+
+func (c *otpChannel) Send(ctx context.Context, message models.NotificationMessage) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.messages = append(c.messages, message)
+	return nil
+}
+
+func (c *otpChannel) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.messages)
+}
+
+// lastOTP kode 6 digit dari pesan terakhir
+func (c *otpChannel) lastOTP(t *testing.T) string {
+	t.Helper()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.messages) == 0 {
+		t.Fatalf("no OTP sent")
+	}
+	otp := otpPattern.FindString(c.messages[len(c.messages)-1].Body)
+	if otp == "" {
+		t.Fatalf("no OTP in %q", c.messages[len(c.messages)-1].Body)
+	}
+	return otp
+}
+
+var otpPattern = regexp.MustCompile(`\b\d{6}\b`)
+
+// testRedis pakai Redis test (REDIS_TEST_HOST / REDIS_TEST_PORT, default localhost:6379),
+// test dilewati jika tidak tersedia
+func testRedis(t *testing.T) {
+	t.Helper()
+
+	cfg := models.Redis{Host: os.Getenv("REDIS_TEST_HOST"), Port: os.Getenv("REDIS_TEST_PORT"), Pass: os.Getenv("REDIS_TEST_PASS")}
+	if cfg.Host == "" {
+		cfg.Host = "localhost"
+	}
+	if cfg.Port == "" {
+		cfg.Port = "6379"
+	}
+
+	if err := config.InitRedisPool(cfg); err != nil {
+		config.CloseRedisPool()
+		t.Skipf("test Redis not available: %v", err)
+	}
+	t.Cleanup(config.CloseRedisPool)
+}
+
+// newStepUpEnv env dengan threshold 50000 dan notifier stub
+func newStepUpEnv(t *testing.T) (*testEnv, *otpChannel) {
+	env := newTestEnv(t, nil)
+	channel := &otpChannel{}
+	env.svc.Service.Config.StepUp.ThresholdAmount = 50000
+	env.svc.Service.Notifier = channel
+	env.addAccount(t, "1000000001", "200000")
+	env.addAccount(t, "1000000002", "0")
+	return env, channel
+}
+
+// startChallenge kirim transaksi di atas threshold, return challenge_id dan OTP yang terkirim
+func (env *testEnv) startChallenge(t *testing.T, channel *otpChannel, handler echo.HandlerFunc, body string) (string, string) {
+	t.Helper()
+
+	var challenge models.StepUpChallengeResponse
+	code, result := env.call(t, handler, "1000000001", body, &challenge)
+	if code != http.StatusAccepted || result.StatusCode != constans.PENDING_CODE || challenge.ChallengeID == "" {
+		t.Fatalf("start step-up = %d/%s (%s), want 202/%s", code, result.StatusCode, result.Message, constans.PENDING_CODE)
+	}
+	return challenge.ChallengeID, channel.lastOTP(t)
+}
+
+func confirmBody(challengeID, otp string) string {
+	return fmt.Sprintf(`{"challenge_id":"%s","otp":"%s"}`, challengeID, otp)
+}
+
+// wrongOTP OTP 6 digit yang pasti berbeda dari otp
+func wrongOTP(otp string) string {
+	if otp == "000000" {
+		return "111111"
+	}
+	return "000000"
+}
+
+func TestStepUpBelowThresholdSkipsChallenge(t *testing.T) {
+	env, channel := newStepUpEnv(t)
+
+	code, result := env.call(t, env.svc.Withdraw, "1000000001", `{"amount":"50000","pin":"123456"}`, nil)
+	if code != http.StatusOK {
+		t.Errorf("withdraw at threshold = %d/%s, want 200", code, result.Message)
+	}
+	code, result = env.call(t, env.svc.Transfer, "1000000001", `{"beneficiary_number":"1000000002","amount":"49999.99","pin":"123456"}`, nil)
+	if code != http.StatusOK {
+		t.Errorf("transfer below threshold = %d/%s, want 200", code, result.Message)
+	}
+
+	if got := channel.count(); got != 0 {
+		t.Errorf("OTP messages = %d, want 0", got)
+	}
+	if got := env.balance(t, "1000000001"); got != "100000.01" {
+		t.Errorf("balance = %s, want 100000.01", got)
+	}
+}
+
+func TestConfirmTransactionExecutesOnce(t *testing.T) {
+	testRedis(t)
+	env, channel := newStepUpEnv(t)
+
+	challengeID, otp := env.startChallenge(t, channel, env.svc.Withdraw, `{"amount":"60000","pin":"123456"}`)
+	if got := env.balance(t, "1000000001"); got != "200000.00" {
+		t.Fatalf("balance before confirm = %s, want 200000.00", got)
+	}
+
+	var withdraw models.WithdrawResponse
+	code, result := env.call(t, env.svc.ConfirmTransaction, "1000000001", confirmBody(challengeID, otp), &withdraw)
+	if code != http.StatusOK || withdraw.BalanceAfter.String() != "140000.00" {
+		t.Fatalf("confirm withdraw = %d/%s, balance after %s, want 200/140000.00", code, result.Message, withdraw.BalanceAfter)
+	}
+
+	// Challenge sekali pakai: replay tidak mengeksekusi ulang
+	code, _ = env.call(t, env.svc.ConfirmTransaction, "1000000001", confirmBody(challengeID, otp), nil)
+	if code != http.StatusNotFound {
+		t.Errorf("replayed confirm = %d, want 404", code)
+	}
+
+	challengeID, otp = env.startChallenge(t, channel, env.svc.Transfer, `{"beneficiary_number":"1000000002","amount":"70000","pin":"123456"}`)
+
+	// Challenge milik nasabah lain dianggap tidak ada
+	code, _ = env.call(t, env.svc.ConfirmTransaction, "1000000002", confirmBody(challengeID, otp), nil)
+	if code != http.StatusNotFound {
+		t.Errorf("confirm by other account = %d, want 404", code)
+	}
+
+	code, result = env.call(t, env.svc.ConfirmTransaction, "1000000001", confirmBody(challengeID, otp), nil)
+	if code != http.StatusOK {
+		t.Fatalf("confirm transfer = %d/%s, want 200", code, result.Message)
+	}
+	code, _ = env.call(t, env.svc.ConfirmTransaction, "1000000001", confirmBody(challengeID, otp), nil)
+	if code != http.StatusNotFound {
+		t.Errorf("replayed confirm = %d, want 404", code)
+	}
+
+	if got := env.balance(t, "1000000001"); got != "70000.00" {
+		t.Errorf("sender balance = %s, want 70000.00", got)
+	}
+	if got := env.balance(t, "1000000002"); got != "70000.00" {
+		t.Errorf("beneficiary balance = %s, want 70000.00", got)
+	}
+	if _, total, _ := env.transactionRepo.GetTransactionHistory("1000000001", "", "", "", 0, 0); total != 2 {
+		t.Errorf("sender transactions = %d, want 2", total)
+	}
+}
+
+func TestConfirmTransactionWrongOTP(t *testing.T) {
+	testRedis(t)
+	env, channel := newStepUpEnv(t)
+
+	challengeID, otp := env.startChallenge(t, channel, env.svc.Withdraw, `{"amount":"60000","pin":"123456"}`)
+
+	// OTP salah dihitung ke counter PIN, percobaan ke-PIN_MAX_ATTEMPTS mengunci akun
+	for i, want := range []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusForbidden} {
+		code, result := env.call(t, env.svc.ConfirmTransaction, "1000000001", confirmBody(challengeID, wrongOTP(otp)), nil)
+		if code != want {
+			t.Fatalf("attempt %d = %d (%s), want %d", i+1, code, result.Message, want)
+		}
+		account, _ := env.accountRepo.FindAccountByNumber("1000000001")
+		if i < 2 && account.FailedPINAttempts != i+1 {
+			t.Errorf("failed attempts after %d = %d", i+1, account.FailedPINAttempts)
+		}
+	}
+
+	// Challenge hangus: OTP benar pun tidak mengeksekusi transaksi
+	code, _ := env.call(t, env.svc.ConfirmTransaction, "1000000001", confirmBody(challengeID, otp), nil)
+	if code != http.StatusNotFound {
+		t.Errorf("confirm after limit = %d, want 404", code)
+	}
+	if got := env.balance(t, "1000000001"); got != "200000.00" {
+		t.Errorf("balance = %s, want 200000.00", got)
+	}
+}
+
+func TestConfirmTransactionExpired(t *testing.T) {
+	testRedis(t)
+	env, channel := newStepUpEnv(t)
+	env.svc.Service.Config.StepUp.CodeTTLSeconds = 1
+
+	challengeID, otp := env.startChallenge(t, channel, env.svc.Withdraw, `{"amount":"60000","pin":"123456"}`)
+	time.Sleep(1500 * time.Millisecond)
+
+	code, _ := env.call(t, env.svc.ConfirmTransaction, "1000000001", confirmBody(challengeID, otp), nil)
+	if code != http.StatusNotFound {
+		t.Errorf("confirm expired challenge = %d, want 404", code)
+	}
+	code, _ = env.call(t, env.svc.ConfirmTransaction, "1000000001", confirmBody("unknown-challenge", otp), nil)
+	if code != http.StatusNotFound {
+		t.Errorf("confirm unknown challenge = %d, want 404", code)
+	}
+	if got := env.balance(t, "1000000001"); got != "200000.00" {
+		t.Errorf("balance = %s, want 200000.00", got)
+	}
+}
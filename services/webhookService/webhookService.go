@@ -0,0 +1,368 @@
+package webhookService
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sample/constans"
+	"sample/helpers"
+	"sample/metrics"
+	"sample/models"
+	"sample/services"
+	"sample/utils"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+type webhookService struct {
+	Service services.UsecaseService
+}
+
+// NewWebhookService
+func NewWebhookService(service services.UsecaseService) webhookService {
+	return webhookService{
+		Service: service,
+	}
+}
+
+// Publish menulis event ke outbox. tx wajib diisi jika event berasal dari perubahan
+// dalam DBTransaction agar event ikut batal saat transaksi di-rollback
+func (svc webhookService) Publish(tx *sql.Tx, eventType string, data interface{}) error {
+	eventID, err := helpers.GenerateSecureID()
+	if err != nil {
+		return err
+	}
+
+	envelope := models.WebhookEnvelope{
+		EventID:   "evt_" + eventID,
+		EventType: eventType,
+		CreatedAt: time.Now(),
+		Data:      data,
+	}
+
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	_, err = svc.Service.WebhookRepo.AddOutboxEvent(models.WebhookEvent{
+		EventID:   envelope.EventID,
+		EventType: eventType,
+		Payload:   payload,
+		CreatedAt: envelope.CreatedAt,
+	}, tx)
+
+	return err
+}
+
+// PublishTransactionCreated event transaction.created, transaction.ID harus sudah terisi
+func (svc webhookService) PublishTransactionCreated(tx *sql.Tx, transaction models.Transaction) error {
+	// Default yang sama dengan AddTransaction: transaksi sinkron langsung SUCCESS
+	if transaction.Status == "" {
+		transaction.Status = constans.TRANSACTION_STATUS_SUCCESS
+	}
+	if transaction.CreatedAt.IsZero() {
+		transaction.CreatedAt = time.Now()
+	}
+
+	return svc.Publish(tx, constans.WEBHOOK_EVENT_TRANSACTION_CREATED, models.TransactionCreatedData{
+		Transaction: transaction,
+		Currency:    transaction.Amount.CurrencyCode(),
+	})
+}
+
+// PublishAccountBlocked event account.blocked setelah percobaan PIN gagal memblokir akun.
+// Di luar transaksi karena blokir PIN langsung di-commit, kegagalan hanya dicatat di log
+func (svc webhookService) PublishAccountBlocked(accountNumber string, failedAttempts int) {
+	metrics.AccountsBlockedTotal.Inc()
+
+	err := svc.Publish(nil, constans.WEBHOOK_EVENT_ACCOUNT_BLOCKED, models.AccountBlockedData{
+		AccountNumber:  accountNumber,
+		AccountStatus:  constans.ACCOUNT_STATUS_BLOCKED_PIN,
+		FailedAttempts: failedAttempts,
+		BlockedAt:      time.Now(),
+	})
+	if err != nil {
+		utils.LogError("WebhookService.PublishAccountBlocked", accountNumber, "Publish", err)
+	}
+}
+
+// PublishPINReset event pin.reset, ikut dalam tx update PIN
+func (svc webhookService) PublishPINReset(tx *sql.Tx, accountNumber string) error {
+	return svc.Publish(tx, constans.WEBHOOK_EVENT_PIN_RESET, models.PINResetData{
+		AccountNumber: accountNumber,
+		ResetAt:       time.Now(),
+	})
+}
+
+// Sign tanda tangan callback: hex(HMAC-SHA256(secret, "<timestamp>.<body>")).
+// Partner menghitung ulang dari header X-Webhook-Timestamp dan body mentah lalu
+// membandingkan dengan X-Webhook-Signature ("sha256=<hex>")
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// CreateSubscription mendaftarkan URL partner (admin). Secret HMAC hanya ditampilkan di response ini
+func (svc webhookService) CreateSubscription(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result      models.Response
+		serviceName = "WebhookService.CreateSubscription"
+		request     = new(models.RequestCreateWebhookSubscription)
+	)
+
+	if err := helpers.BindValidateStruct(ctx, request); err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "BindValidateStruct", err)
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	utils.LogInfo(serviceName, request.PartnerName, "Request received",
+		fmt.Sprintf("URL: %s, Events: %v", request.URL, request.EventTypes))
+
+	secret, err := helpers.GenerateSecureID()
+	if err != nil {
+		utils.LogError(serviceName, request.PartnerName, "GenerateSecureID", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to generate webhook secret", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	subscription := models.WebhookSubscription{
+		PartnerName: request.PartnerName,
+		URL:         request.URL,
+		Secret:      "whsec_" + secret,
+		EventTypes:  request.EventTypes,
+		IsActive:    true,
+	}
+
+	subscription.ID, err = svc.Service.WebhookRepo.AddWebhookSubscription(subscription)
+	if err != nil {
+		utils.LogError(serviceName, request.PartnerName, "AddWebhookSubscription", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, "Failed to create webhook subscription", nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	subscription, err = svc.Service.WebhookRepo.FindWebhookSubscriptionById(subscription.ID)
+	if err != nil {
+		utils.LogError(serviceName, request.PartnerName, "FindWebhookSubscriptionById", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	utils.LogInfo(serviceName, request.PartnerName, "Success", fmt.Sprintf("Subscription ID: %d", subscription.ID))
+	helpers.AuditAfter(ctx, subscription)
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Webhook subscription created. Store the secret, it will not be shown again",
+		models.WebhookSubscriptionResponse{Subscription: subscription, Secret: subscription.Secret})
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// GetSubscriptionList daftar subscription tanpa secret (admin)
+func (svc webhookService) GetSubscriptionList(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result      models.Response
+		serviceName = "WebhookService.GetSubscriptionList"
+	)
+
+	subscriptions, err := svc.Service.WebhookRepo.GetWebhookSubscriptions()
+	if err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "GetWebhookSubscriptions", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	if subscriptions == nil {
+		subscriptions = []models.WebhookSubscription{}
+	}
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Webhook subscriptions retrieved successfully", subscriptions)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// UpdateSubscription mengubah URL, jenis event atau menonaktifkan subscription (admin).
+// Delivery yang sudah dibuat untuk subscription nonaktif ditandai FAILED oleh worker
+func (svc webhookService) UpdateSubscription(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result      models.Response
+		serviceName = "WebhookService.UpdateSubscription"
+		request     = new(models.RequestUpdateWebhookSubscription)
+	)
+
+	if err := helpers.BindValidateStruct(ctx, request); err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "BindValidateStruct", err)
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	refNo := strconv.Itoa(request.ID)
+
+	subscription, err := svc.Service.WebhookRepo.FindWebhookSubscriptionById(request.ID)
+	if err != nil {
+		utils.LogError(serviceName, refNo, "FindWebhookSubscriptionById", err)
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Webhook subscription not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+	helpers.AuditBefore(ctx, subscription)
+
+	if request.URL != "" {
+		subscription.URL = request.URL
+	}
+	if len(request.EventTypes) > 0 {
+		subscription.EventTypes = request.EventTypes
+	}
+	if request.IsActive != nil {
+		subscription.IsActive = *request.IsActive
+	}
+
+	if err := svc.Service.WebhookRepo.UpdateWebhookSubscription(subscription); err != nil {
+		utils.LogError(serviceName, refNo, "UpdateWebhookSubscription", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	utils.LogInfo(serviceName, refNo, "Success",
+		fmt.Sprintf("URL: %s, Events: %v, Active: %t", subscription.URL, subscription.EventTypes, subscription.IsActive))
+	helpers.AuditAfter(ctx, subscription)
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Webhook subscription updated successfully",
+		models.WebhookSubscriptionResponse{Subscription: subscription})
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// GetDeliveryList log pengiriman webhook (admin)
+func (svc webhookService) GetDeliveryList(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result      models.Response
+		serviceName = "WebhookService.GetDeliveryList"
+		request     = new(models.RequestWebhookDeliveryList)
+	)
+
+	if err := helpers.BindValidateStruct(ctx, request); err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "BindValidateStruct", err)
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	if request.Limit <= 0 {
+		request.Limit = 50
+	}
+
+	deliveries, err := svc.Service.WebhookRepo.GetWebhookDeliveries(*request)
+	if err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "GetWebhookDeliveries", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	if deliveries == nil {
+		deliveries = []models.WebhookDelivery{}
+	}
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Webhook deliveries retrieved successfully", deliveries)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// GetDeliveryDetail detail delivery, payload dan log setiap percobaan (admin)
+func (svc webhookService) GetDeliveryDetail(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result      models.Response
+		serviceName = "WebhookService.GetDeliveryDetail"
+		request     = new(models.RequestWebhookDeliveryByID)
+	)
+
+	if err := helpers.BindValidateStruct(ctx, request); err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "BindValidateStruct", err)
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	refNo := strconv.Itoa(request.ID)
+
+	delivery, err := svc.Service.WebhookRepo.FindWebhookDeliveryById(request.ID)
+	if err != nil {
+		utils.LogError(serviceName, refNo, "FindWebhookDeliveryById", err)
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Webhook delivery not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+
+	attempts, err := svc.Service.WebhookRepo.GetWebhookDeliveryAttempts(delivery.ID)
+	if err != nil {
+		utils.LogError(serviceName, refNo, "GetWebhookDeliveryAttempts", err)
+		result = helpers.ResponseJSON(false, constans.SYSTEM_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusInternalServerError, result)
+	}
+
+	if attempts == nil {
+		attempts = []models.WebhookDeliveryAttempt{}
+	}
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Webhook delivery retrieved successfully",
+		models.WebhookDeliveryDetailResponse{Delivery: delivery, Attempts: attempts})
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// Redeliver menjadwalkan ulang pengiriman (admin), dikirim worker pada putaran berikutnya
+// dengan body dan event_id yang sama sehingga partner bisa mendeteksi duplikat
+func (svc webhookService) Redeliver(ctx echo.Context) error {
+	svc.Service = svc.Service.WithContext(ctx.Request().Context())
+
+	var (
+		result      models.Response
+		serviceName = "WebhookService.Redeliver"
+		request     = new(models.RequestWebhookDeliveryByID)
+	)
+
+	if err := helpers.BindValidateStruct(ctx, request); err != nil {
+		utils.LogError(serviceName, constans.EMPTY_VALUE, "BindValidateStruct", err)
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	refNo := strconv.Itoa(request.ID)
+
+	delivery, err := svc.Service.WebhookRepo.FindWebhookDeliveryById(request.ID)
+	if err != nil {
+		utils.LogError(serviceName, refNo, "FindWebhookDeliveryById", err)
+		result = helpers.ResponseJSON(false, constans.DATA_NOT_FOUND_CODE, "Webhook delivery not found", nil)
+		return ctx.JSON(http.StatusNotFound, result)
+	}
+	helpers.AuditBefore(ctx, delivery)
+
+	if !delivery.IsActive {
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, "Webhook subscription is inactive", nil)
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	if err := svc.Service.WebhookRepo.RedeliverWebhookDelivery(delivery.ID); err != nil {
+		utils.LogError(serviceName, refNo, "RedeliverWebhookDelivery", err)
+		result = helpers.ResponseJSON(false, constans.VALIDATE_ERROR_CODE, err.Error(), nil)
+		return ctx.JSON(http.StatusConflict, result)
+	}
+
+	utils.LogInfo(serviceName, refNo, "Success", fmt.Sprintf("Event: %s (%s)", delivery.EventID, delivery.EventType))
+
+	delivery, _ = svc.Service.WebhookRepo.FindWebhookDeliveryById(delivery.ID)
+	helpers.AuditAfter(ctx, delivery)
+
+	result = helpers.ResponseJSON(true, constans.SUCCESS_CODE, "Webhook delivery scheduled for redelivery", delivery)
+	return ctx.JSON(http.StatusOK, result)
+}
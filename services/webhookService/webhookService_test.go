@@ -0,0 +1,173 @@
+package webhookService
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sample/config"
+	"sample/constans"
+	"sample/models"
+	"sample/repositories"
+	"sample/repositories/memoryRepository"
+	"sample/services"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testSecret = "whsec_test"
+
+// deliveryRepo satu delivery yang di-claim selama PENDING dan jatuh tempo, seperti ClaimDueWebhookDeliveries
+type deliveryRepo struct {
+	repositories.WebhookRepository
+	mu       sync.Mutex
+	delivery models.WebhookDelivery
+	attempts []models.WebhookDeliveryAttempt
+}
+
+func (r *deliveryRepo) ClaimDueWebhookDeliveries(workerID string, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.delivery.Status != constans.WEBHOOK_DELIVERY_STATUS_PENDING || r.delivery.NextAttemptAt.After(time.Now()) {
+		return nil, nil
+	}
+	return []models.WebhookDelivery{r.delivery}, nil
+}
+
+func (r *deliveryRepo) AddWebhookDeliveryAttempt(attempt models.WebhookDeliveryAttempt, tx *sql.Tx) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.attempts = append(r.attempts, attempt)
+	return len(r.attempts), nil
+}
+
+func (r *deliveryRepo) CompleteWebhookDeliveryWithTx(tx *sql.Tx, delivery models.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.delivery = delivery
+	return nil
+}
+
+// due majukan jadwal retry agar tick berikutnya langsung mengirim ulang
+func (r *deliveryRepo) due() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.delivery.NextAttemptAt = time.Now().Add(-time.Second)
+}
+
+func newTestWorker(repo *deliveryRepo) webhookWorker {
+	cfg := config.Default()
+	cfg.Webhook.RetryBaseSeconds = 30
+
+	store := memoryRepository.NewStore()
+	return NewWebhookWorker(services.NewUsecaseService(store.DB(),
+		nil, nil, nil, nil, nil, nil, nil, nil,
+		repo,
+		nil, nil,
+		cfg,
+	))
+}
+
+func TestWorkerDeliverRetriesThenSucceeds(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		statuses = []int{http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusOK}
+		requests int
+		problems []string
+	)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// Verifikasi seperti partner: HMAC-SHA256(secret, "<timestamp>.<body>")
+		body, _ := ioutil.ReadAll(r.Body)
+		mac := hmac.New(sha256.New, []byte(testSecret))
+		mac.Write([]byte(r.Header.Get(constans.WEBHOOK_TIMESTAMP_HEADER) + "." + string(body)))
+		want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		if got := r.Header.Get(constans.WEBHOOK_SIGNATURE_HEADER); !hmac.Equal([]byte(got), []byte(want)) {
+			problems = append(problems, "signature = "+got+", want "+want)
+		}
+		if r.Header.Get(constans.WEBHOOK_ID_HEADER) != "evt-1" || r.Header.Get(constans.WEBHOOK_EVENT_HEADER) != constans.WEBHOOK_EVENT_TRANSACTION_CREATED {
+			problems = append(problems, "unexpected event headers")
+		}
+
+		w.WriteHeader(statuses[requests])
+		requests++
+	}))
+	defer receiver.Close()
+
+	repo := &deliveryRepo{delivery: models.WebhookDelivery{
+		ID:            1,
+		EventID:       "evt-1",
+		EventType:     constans.WEBHOOK_EVENT_TRANSACTION_CREATED,
+		Status:        constans.WEBHOOK_DELIVERY_STATUS_PENDING,
+		NextAttemptAt: time.Now().Add(-time.Second),
+		URL:           receiver.URL,
+		Secret:        testSecret,
+		IsActive:      true,
+		Payload:       []byte(`{"event_id":"evt-1","data":{"amount":"10000.00"}}`),
+	}}
+	worker := newTestWorker(repo)
+
+	// Percobaan gagal menambah attempts dan menjadwalkan retry dengan backoff 30s, 60s
+	for attempt, wantBackoff := range []time.Duration{30 * time.Second, 60 * time.Second} {
+		before := time.Now()
+		worker.DeliverDue()
+
+		delivery := repo.delivery
+		if delivery.Status != constans.WEBHOOK_DELIVERY_STATUS_PENDING || delivery.Attempts != attempt+1 {
+			t.Fatalf("after attempt %d: status = %s, attempts = %d", attempt+1, delivery.Status, delivery.Attempts)
+		}
+		if delivery.LastStatusCode != statuses[attempt] || delivery.DeliveredAt != nil {
+			t.Errorf("after attempt %d: last status = %d, delivered at = %v", attempt+1, delivery.LastStatusCode, delivery.DeliveredAt)
+		}
+		if backoff := delivery.NextAttemptAt.Sub(before); backoff < wantBackoff || backoff > wantBackoff+5*time.Second {
+			t.Errorf("after attempt %d: next attempt in %s, want %s", attempt+1, backoff, wantBackoff)
+		}
+
+		// Belum jatuh tempo: tidak dikirim ulang
+		worker.DeliverDue()
+		if repo.delivery.Attempts != attempt+1 {
+			t.Fatalf("delivery retried before next_attempt_at")
+		}
+		repo.due()
+	}
+
+	worker.DeliverDue()
+
+	delivery := repo.delivery
+	if delivery.Status != constans.WEBHOOK_DELIVERY_STATUS_SUCCESS || delivery.Attempts != 3 || delivery.DeliveredAt == nil {
+		t.Fatalf("after 2xx: status = %s, attempts = %d, delivered at = %v", delivery.Status, delivery.Attempts, delivery.DeliveredAt)
+	}
+	if len(repo.attempts) != 3 || repo.attempts[2].StatusCode != http.StatusOK || repo.attempts[0].Error == "" {
+		t.Errorf("attempt log = %+v", repo.attempts)
+	}
+
+	// Sudah terkirim: tidak di-claim lagi
+	worker.DeliverDue()
+	if requests != 3 {
+		t.Errorf("receiver got %d requests, want 3", requests)
+	}
+	for _, problem := range problems {
+		t.Error(problem)
+	}
+}
+
+func TestWorkerBackoffCapped(t *testing.T) {
+	worker := webhookWorker{RetryBase: 30 * time.Second}
+
+	for attempt, want := range map[int]time.Duration{1: 30 * time.Second, 2: time.Minute, 4: 4 * time.Minute, 20: maxRetryInterval} {
+		if got := worker.backoff(attempt); got != want {
+			t.Errorf("backoff(%d) = %s, want %s", attempt, got, want)
+		}
+	}
+}
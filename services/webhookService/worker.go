@@ -0,0 +1,222 @@
+package webhookService
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sample/constans"
+	"sample/models"
+	"sample/services"
+	"sample/tracing"
+	"sample/utils"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Batas backoff dan potongan body response yang disimpan di log percobaan
+const (
+	maxRetryInterval    = 6 * time.Hour
+	maxLoggedBodyLength = 1024
+)
+
+type webhookWorker struct {
+	Service     services.UsecaseService
+	WorkerID    string
+	Interval    time.Duration
+	Lease       time.Duration
+	RetryBase   time.Duration
+	MaxAttempts int
+	BatchSize   int
+	Client      *http.Client
+}
+
+// NewWebhookWorker konfigurasi dari service.Config.Webhook (WEBHOOK_*)
+func NewWebhookWorker(service services.UsecaseService) webhookWorker {
+	hostname, _ := os.Hostname()
+	cfg := service.Config.Webhook
+	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+	batchSize := cfg.BatchSize
+
+	return webhookWorker{
+		Service:     service,
+		WorkerID:    fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		Interval:    time.Duration(cfg.IntervalSeconds) * time.Second,
+		Lease:       time.Duration(batchSize)*timeout + time.Minute,
+		RetryBase:   time.Duration(cfg.RetryBaseSeconds) * time.Second,
+		MaxAttempts: cfg.MaxAttempts,
+		BatchSize:   batchSize,
+		Client:      &http.Client{Timeout: timeout},
+	}
+}
+
+// Start menjalankan worker sampai ctx selesai. Aman dijalankan di banyak instance:
+// outbox dan delivery di-claim dengan FOR UPDATE SKIP LOCKED, hasil disimpan dengan guard lease
+func (w webhookWorker) Start(ctx context.Context) {
+	serviceName := "WebhookWorker.Start"
+	utils.LogInfo(serviceName, w.WorkerID, "Started", fmt.Sprintf("Interval: %s", w.Interval))
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		w.DispatchOutbox()
+		w.DeliverDue()
+
+		select {
+		case <-ctx.Done():
+			utils.LogInfo(serviceName, w.WorkerID, "Stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchOutbox membuat delivery untuk event outbox yang belum diproses
+func (w webhookWorker) DispatchOutbox() {
+	serviceName := "WebhookWorker.DispatchOutbox"
+
+	for {
+		dispatched, err := w.Service.WebhookRepo.DispatchOutboxEvents(w.BatchSize)
+		if err != nil {
+			utils.LogError(serviceName, w.WorkerID, "DispatchOutboxEvents", err)
+			return
+		}
+
+		if dispatched < w.BatchSize {
+			return
+		}
+	}
+}
+
+// DeliverDue mengirim semua delivery yang jatuh tempo
+func (w webhookWorker) DeliverDue() {
+	serviceName := "WebhookWorker.DeliverDue"
+
+	for {
+		deliveries, err := w.Service.WebhookRepo.ClaimDueWebhookDeliveries(w.WorkerID, w.BatchSize, w.Lease)
+		if err != nil {
+			utils.LogError(serviceName, w.WorkerID, "ClaimDueWebhookDeliveries", err)
+			return
+		}
+
+		for _, delivery := range deliveries {
+			w.deliver(delivery)
+		}
+
+		if len(deliveries) < w.BatchSize {
+			return
+		}
+	}
+}
+
+// deliver satu percobaan pengiriman, lalu simpan log dan jadwal retry dalam satu transaksi
+func (w webhookWorker) deliver(delivery models.WebhookDelivery) {
+	serviceName := "WebhookWorker.deliver"
+	refNo := strconv.Itoa(delivery.ID)
+
+	spanCtx, span := tracing.Start(context.Background(), serviceName, attribute.Int("webhook.delivery_id", delivery.ID))
+	defer span.End()
+	w.Service = w.Service.WithContext(spanCtx)
+
+	var attempt models.WebhookDeliveryAttempt
+	if delivery.IsActive {
+		attempt = w.Send(delivery)
+	} else {
+		attempt = models.WebhookDeliveryAttempt{Error: "Webhook subscription is inactive"}
+	}
+
+	now := time.Now()
+	delivery.Attempts++
+	attempt.DeliveryID = delivery.ID
+	attempt.Attempt = delivery.Attempts
+	delivery.LastStatusCode = attempt.StatusCode
+	delivery.LastError = attempt.Error
+
+	switch {
+	case attempt.StatusCode >= 200 && attempt.StatusCode < 300:
+		delivery.Status = constans.WEBHOOK_DELIVERY_STATUS_SUCCESS
+		delivery.DeliveredAt = &now
+	case !delivery.IsActive || delivery.Attempts >= w.MaxAttempts:
+		delivery.Status = constans.WEBHOOK_DELIVERY_STATUS_FAILED
+	default:
+		delivery.NextAttemptAt = now.Add(w.backoff(delivery.Attempts))
+	}
+
+	err := utils.DBTransaction(w.Service.RepoDB, func(tx *sql.Tx) error {
+		if _, err := w.Service.WebhookRepo.AddWebhookDeliveryAttempt(attempt, tx); err != nil {
+			return err
+		}
+		return w.Service.WebhookRepo.CompleteWebhookDeliveryWithTx(tx, delivery)
+	})
+	if err != nil {
+		utils.LogError(serviceName, refNo, "CompleteWebhookDeliveryWithTx", err)
+		return
+	}
+
+	utils.LogInfo(serviceName, refNo, delivery.Status,
+		fmt.Sprintf("Event: %s (%s), Attempt: %d, Status Code: %d, Error: %s",
+			delivery.EventID, delivery.EventType, delivery.Attempts, attempt.StatusCode, attempt.Error))
+}
+
+// Send POST payload ke URL partner dengan header tanda tangan HMAC. Response 2xx dianggap sukses
+func (w webhookWorker) Send(delivery models.WebhookDelivery) models.WebhookDeliveryAttempt {
+	var attempt models.WebhookDeliveryAttempt
+
+	timestamp := time.Now().Unix()
+
+	request, err := http.NewRequest(http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(constans.WEBHOOK_ID_HEADER, delivery.EventID)
+	request.Header.Set(constans.WEBHOOK_EVENT_HEADER, delivery.EventType)
+	request.Header.Set(constans.WEBHOOK_TIMESTAMP_HEADER, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(constans.WEBHOOK_SIGNATURE_HEADER, Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	// Header traceparent agar partner bisa menyambung trace pengiriman ini
+	otel.GetTextMapPropagator().Inject(w.Service.Context, propagation.HeaderCarrier(request.Header))
+
+	start := time.Now()
+	response, err := w.Client.Do(request)
+	attempt.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer response.Body.Close()
+
+	body, _ := ioutil.ReadAll(io.LimitReader(response.Body, maxLoggedBodyLength))
+	attempt.StatusCode = response.StatusCode
+	attempt.ResponseBody = string(body)
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		attempt.Error = fmt.Sprintf("Unexpected status code %d", response.StatusCode)
+	}
+
+	return attempt
+}
+
+// backoff exponential: RetryBase * 2^(attempt-1), maksimal maxRetryInterval
+func (w webhookWorker) backoff(attempt int) time.Duration {
+	interval := w.RetryBase
+	for i := 1; i < attempt && interval < maxRetryInterval; i++ {
+		interval *= 2
+	}
+
+	if interval > maxRetryInterval {
+		return maxRetryInterval
+	}
+	return interval
+}
@@ -0,0 +1,113 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sample/models"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exporter span yang bisa dipilih lewat TRACING_EXPORTER
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Exporters daftar nilai TRACING_EXPORTER yang valid
+var Exporters = []string{ExporterNone, ExporterStdout, ExporterOTLP}
+
+// TraceIDHeader header response berisi trace ID request, untuk dicari di backend tracing
+const TraceIDHeader = "X-Trace-Id"
+
+const instrumentationName = "sample"
+
+// Init memasang tracer provider global sesuai cfg.Tracing. Propagator W3C (traceparent, baggage)
+// selalu dipasang agar trace ID dari client tetap diteruskan walaupun exporter none.
+// Fungsi yang dikembalikan mengirim span yang tersisa, dipanggil saat shutdown
+func Init(cfg models.Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	switch cfg.Tracing.Exporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		stdout, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, fmt.Errorf("create stdout exporter: %w", err)
+		}
+		exporter = stdout
+	case ExporterOTLP:
+		options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Tracing.OTLPEndpoint)}
+		if cfg.Tracing.OTLPInsecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		otlp, err := otlptracehttp.New(context.Background(), options...)
+		if err != nil {
+			return nil, fmt.Errorf("create otlp exporter: %w", err)
+		}
+		exporter = otlp
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", cfg.Tracing.Exporter)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceNameKey.String(cfg.AppName),
+			semconv.ServiceVersionKey.String(cfg.AppVersion),
+			semconv.DeploymentEnvironmentKey.String(cfg.AppEnv),
+		)),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(float64(cfg.Tracing.SamplePercent)/100))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Start membuat span anak dari span di ctx, ctx nil dianggap context.Background()
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End menandai span gagal jika err tidak nil lalu menutupnya
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// TraceID trace ID span di ctx, kosong jika ctx tidak membawa span yang valid
+func TraceID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+	return spanContext.TraceID().String()
+}
+
+// Detach context baru yang tidak ikut dibatalkan saat ctx selesai tetapi tetap membawa span
+// di ctx sebagai parent, untuk pekerjaan background yang berjalan setelah response dikirim
+func Detach(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return trace.ContextWithSpanContext(context.Background(), trace.SpanContextFromContext(ctx))
+}
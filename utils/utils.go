@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"crypto/rand"
 	"database/sql"
 	"encoding/hex"
@@ -31,6 +32,17 @@ func DBTransaction(db *sql.DB, txFunc func(*sql.Tx) error) (err error) {
 	return err
 }
 
+// DBReadSnapshot menjalankan beberapa query baca pada snapshot data yang sama (REPEATABLE READ, read only)
+func DBReadSnapshot(db *sql.DB, txFunc func(*sql.Tx) error) error {
+	tx, err := db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	return txFunc(tx)
+}
+
 // TransactionError untuk error kustom dalam transaksi
 type TransactionError struct {
 	Code    string
@@ -72,15 +84,16 @@ func LogInfo(svcName, refNo, methodName string, data ...string) {
 	log.Println(str)
 }
 
-// GenerateReferenceNo generates a unique 16-character reference number
-// Format: YYYYMMDDHHMMSS + 2 random uppercase hex characters (total 16 chars)
-// Example: 2025121614302501
+// GenerateReferenceNo generates a unique 30-character reference number
+// Format: YYYYMMDDHHMMSS + 16 random uppercase hex characters (total 30 chars)
+// Example: 20251216143025A1B2C3D4E5F60718
+// Bagian acak 64 bit agar tidak bentrok di detik yang sama (journal_entry.reference_no unik)
 func GenerateReferenceNo() string {
 	timestamp := time.Now().Format("20060102150405") // 14 characters
-	randomBytes := make([]byte, 1)                   // 1 byte = 2 hex characters
+	randomBytes := make([]byte, 8)                   // 8 bytes = 16 hex characters
 	rand.Read(randomBytes)
 	randomHex := strings.ToUpper(hex.EncodeToString(randomBytes))
-	return fmt.Sprintf("%s%s", timestamp, randomHex) // 14 + 2 = 16 characters
+	return fmt.Sprintf("%s%s", timestamp, randomHex) // 14 + 16 = 30 characters
 }
 
 // GenerateReferenceNoWithPrefix generates a reference number with custom prefix